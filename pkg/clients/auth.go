@@ -0,0 +1,85 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/identity"
+)
+
+// User is an account in auth-service.
+type User struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// Role is one of owner, admin or master.
+	Role string `json:"role"`
+}
+
+// AuthClient calls auth-service.
+type AuthClient interface {
+	Register(ctx context.Context, req RegisterRequest) (*User, error)
+	Login(ctx context.Context, email, password string) (string, error)
+	// Unregister deletes the user with the given credentials.
+	Unregister(ctx context.Context, email, password string) error
+	// Verify checks an access token; the gateway authenticates requests with it.
+	Verify(ctx context.Context, token string) (*identity.Claims, error)
+	Ping(ctx context.Context) error
+}
+
+var _ identity.Verifier = AuthClient(nil)
+
+type authClient struct {
+	*client
+}
+
+func NewAuth(baseURL string, opts Options) AuthClient {
+	return &authClient{client: newClient("auth-service", baseURL, opts)}
+}
+
+func (c *authClient) Register(ctx context.Context, req RegisterRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPost, "/auth/register", nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *authClient) Login(ctx context.Context, email, password string) (string, error) {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	req := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/auth/login", nil, req, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+func (c *authClient) Unregister(ctx context.Context, email, password string) error {
+	req := map[string]string{"email": email, "password": password}
+	return c.do(ctx, http.MethodPost, "/auth/unregister", nil, req, nil)
+}
+
+func (c *authClient) Verify(ctx context.Context, token string) (*identity.Claims, error) {
+	h := make(http.Header)
+	h.Set(echo.HeaderAuthorization, "Bearer "+token)
+	var claims identity.Claims
+	if err := c.do(WithHeaders(ctx, h), http.MethodGet, "/auth/verify", nil, nil, &claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (c *authClient) Ping(ctx context.Context) error {
+	return c.ping(ctx)
+}
@@ -0,0 +1,92 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Slot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	IsFree    bool      `json:"is_free"`
+}
+
+// ServiceChoice is a service with the variant and add-ons the client picked;
+// together they decide how long a slot is.
+type ServiceChoice struct {
+	ServiceID uint
+	VariantID *uint
+	AddOnIDs  []uint
+}
+
+type CreateBookingRequest struct {
+	EmployeeID uint      `json:"employee_id"`
+	ServiceID  uint      `json:"service_id"`
+	VariantID  *uint     `json:"variant_id,omitempty"`
+	AddOnIDs   []uint    `json:"addon_ids,omitempty"`
+	ClientID   uint      `json:"client_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Comment    string    `json:"comment,omitempty"`
+}
+
+type Appointment struct {
+	ID         uint      `json:"id"`
+	EmployeeID uint      `json:"employee_id"`
+	ServiceID  uint      `json:"service_id"`
+	ClientID   uint      `json:"client_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	VariantID  *uint     `json:"variant_id,omitempty"`
+	AddOnIDs   []uint    `json:"addon_ids,omitempty"`
+	Price      float64   `json:"price"`
+	Status     string    `json:"status"`
+	Comment    string    `json:"comment"`
+}
+
+// BookingClient calls booking-service.
+type BookingClient interface {
+	GetSlots(ctx context.Context, employeeID uint, service ServiceChoice, date time.Time) ([]Slot, error)
+	CreateBooking(ctx context.Context, req CreateBookingRequest) (*Appointment, error)
+	Ping(ctx context.Context) error
+}
+
+type bookingClient struct {
+	*client
+}
+
+func NewBooking(baseURL string, opts Options) BookingClient {
+	return &bookingClient{client: newClient("booking-service", baseURL, opts)}
+}
+
+func (c *bookingClient) GetSlots(ctx context.Context, employeeID uint, service ServiceChoice, date time.Time) ([]Slot, error) {
+	q := url.Values{
+		"employee_id": {strconv.FormatUint(uint64(employeeID), 10)},
+		"service_id":  {strconv.FormatUint(uint64(service.ServiceID), 10)},
+		"date":        {date.Format(time.RFC3339)},
+	}
+	if service.VariantID != nil {
+		q.Set("variant_id", strconv.FormatUint(uint64(*service.VariantID), 10))
+	}
+	for _, id := range service.AddOnIDs {
+		q.Add("addon_ids", strconv.FormatUint(uint64(id), 10))
+	}
+	var slots []Slot
+	err := c.do(ctx, http.MethodGet, "/slots", q, nil, &slots)
+	return slots, err
+}
+
+func (c *bookingClient) CreateBooking(ctx context.Context, req CreateBookingRequest) (*Appointment, error) {
+	var appointment Appointment
+	if err := c.do(ctx, http.MethodPost, "/bookings", nil, req, &appointment); err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+func (c *bookingClient) Ping(ctx context.Context) error {
+	return c.ping(ctx)
+}
@@ -0,0 +1,273 @@
+// Package clients holds typed HTTP clients for calling TimeHub services from
+// one another. Every call takes a context, is bounded by a per-attempt
+// timeout, forwards the caller's identity headers, retries idempotent
+// requests with exponential backoff and turns error responses back into
+// *erru.AppError so they can be returned as-is from usecases.
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/identity"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tracing"
+)
+
+// Options tune every client.
+type Options struct {
+	// Timeout bounds a single attempt.
+	Timeout time.Duration
+	// Retries is the number of extra attempts for idempotent requests.
+	Retries int
+	// Backoff is the delay before the first retry; it doubles on each attempt.
+	Backoff time.Duration
+	// Transport defaults to a traced http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// OptionsFrom reads client settings from the upstreams configuration.
+func OptionsFrom(cfg config.UpstreamsConfig) Options {
+	return Options{Timeout: cfg.Timeout, Retries: cfg.Retries, Backoff: cfg.RetryBackoff}
+}
+
+// forwardedHeaders are copied from the incoming request to outgoing calls.
+var forwardedHeaders = []string{
+	echo.HeaderAuthorization,
+	echo.HeaderXRequestID,
+	middleware.HeaderUserID,
+	middleware.HeaderCompanyID,
+	identity.HeaderSignature,
+}
+
+type headersKey struct{}
+
+// WithHeaders stores the headers to forward with calls made under ctx.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	fwd := make(http.Header, len(forwardedHeaders))
+	for _, name := range forwardedHeaders {
+		if v := h.Get(name); v != "" {
+			fwd.Set(name, v)
+		}
+	}
+	return context.WithValue(ctx, headersKey{}, fwd)
+}
+
+// ForwardHeaders is Echo middleware that makes the identity and request ID
+// of the incoming request available to clients called from its handlers.
+func ForwardHeaders(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		h := req.Header.Clone()
+		if h.Get(echo.HeaderXRequestID) == "" {
+			h.Set(echo.HeaderXRequestID, c.Response().Header().Get(echo.HeaderXRequestID))
+		}
+		c.SetRequest(req.WithContext(WithHeaders(req.Context(), h)))
+		return next(c)
+	}
+}
+
+// client is the transport shared by the typed clients.
+type client struct {
+	service string
+	baseURL string
+	http    *http.Client
+	opts    Options
+}
+
+func newClient(service, baseURL string, opts Options) *client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 100 * time.Millisecond
+	}
+	if opts.Transport == nil {
+		opts.Transport = tracing.Transport(nil)
+	}
+	return &client{
+		service: service,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Transport: opts.Transport},
+		opts:    opts,
+	}
+}
+
+// do sends in as JSON (when non-nil) and decodes a 2xx response into out (when non-nil).
+func (c *client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("encode %s request: %w", c.service, err)
+		}
+	}
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	attempts := 1
+	if idempotent(method) {
+		attempts += c.opts.Retries
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			if werr := sleep(ctx, c.backoff(i)); werr != nil {
+				return c.transportError(werr)
+			}
+		}
+		var retry bool
+		retry, err = c.attempt(ctx, method, target, body, out)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// list follows next_cursor until every page of a paginated endpoint is read.
+func list[T any](ctx context.Context, c *client, path string, query url.Values) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set(pagination.ParamLimit, strconv.Itoa(pagination.MaxLimit))
+	items := []T{}
+	for {
+		var page pagination.Page[T]
+		if err := c.do(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		query.Set(pagination.ParamCursor, page.NextCursor)
+	}
+}
+
+// attempt performs one request and reports whether a failure is worth retrying.
+func (c *client) attempt(ctx context.Context, method, target string, body []byte, out any) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if fwd, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for name, values := range fwd {
+			req.Header[name] = values
+		}
+	}
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	if body != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, c.transportError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			io.Copy(io.Discard, resp.Body)
+			return false, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, erru.Wrap(err, erru.CodeInternal, "Invalid response from "+c.service)
+		}
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable ||
+		resp.StatusCode == http.StatusGatewayTimeout
+	return retry, decodeError(resp)
+}
+
+func (c *client) transportError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return erru.Wrap(err, erru.CodeTimeout, c.service+" did not respond in time")
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return erru.Wrap(err, erru.CodeUnavailable, c.service+" is unavailable")
+}
+
+func (c *client) backoff(attempt int) time.Duration {
+	d := c.opts.Backoff << (attempt - 1)
+	return d + rand.N(d/2+1)
+}
+
+// decodeError turns a problem+json (or any other) error response into an *erru.AppError.
+func decodeError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var p erru.Problem
+	if err := json.Unmarshal(raw, &p); err == nil && (p.Code != "" || p.Title != "") {
+		code := p.Code
+		if code == "" {
+			code = erru.CodeForStatus(resp.StatusCode)
+		}
+		msg := p.Detail
+		if msg == "" {
+			msg = p.Title
+		}
+		e := erru.E(code, msg)
+		e.Status = resp.StatusCode
+		e.Fields = p.Errors
+		return e
+	}
+
+	msg := strings.TrimSpace(string(raw))
+	if msg == "" || len(msg) > 200 {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return erru.New(resp.StatusCode, msg)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// ping checks the service's readiness endpoint.
+func (c *client) ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/readyz", nil, nil, nil)
+}
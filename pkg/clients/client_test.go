@@ -0,0 +1,210 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+)
+
+// replies serves the statuses in order, repeating the last one, and counts
+// the attempts.
+func replies(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(attempts.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			io.WriteString(w, `{"id":7}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func testClient(url string) *client {
+	return newClient("test-service", url, Options{Timeout: time.Second, Retries: 2, Backoff: time.Millisecond})
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statuses     []int
+		wantAttempts int32
+		wantStatus   int
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantAttempts: 1},
+		{name: "unavailable then success", method: http.MethodGet, statuses: []int{503, 200}, wantAttempts: 2},
+		{name: "too many requests", method: http.MethodGet, statuses: []int{429, 502, 200}, wantAttempts: 3},
+		{name: "retries exhausted", method: http.MethodPut, statuses: []int{504}, wantAttempts: 3, wantStatus: 504},
+		{name: "idempotent delete", method: http.MethodDelete, statuses: []int{503, 200}, wantAttempts: 2},
+		{name: "post is not retried", method: http.MethodPost, statuses: []int{503, 200}, wantAttempts: 1, wantStatus: 503},
+		{name: "internal error is not retried", method: http.MethodGet, statuses: []int{500, 200}, wantAttempts: 1, wantStatus: 500},
+		{name: "client error is not retried", method: http.MethodGet, statuses: []int{404, 200}, wantAttempts: 1, wantStatus: 404},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, attempts := replies(t, tc.statuses...)
+			var out struct {
+				ID uint `json:"id"`
+			}
+			err := testClient(srv.URL).do(context.Background(), tc.method, "/x", nil, map[string]string{"a": "b"}, &out)
+			if got := attempts.Load(); got != tc.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tc.wantAttempts)
+			}
+			if tc.wantStatus == 0 {
+				if err != nil || out.ID != 7 {
+					t.Fatalf("err = %v, out = %+v, want id 7", err, out)
+				}
+				return
+			}
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || appErr.Status != tc.wantStatus {
+				t.Fatalf("err = %v, want status %d", err, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDoTimeoutAndCancel(t *testing.T) {
+	release := make(chan struct{})
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := newClient("slow-service", srv.URL, Options{Timeout: 20 * time.Millisecond, Retries: 1, Backoff: time.Millisecond})
+	err := c.do(context.Background(), http.MethodGet, "/", nil, nil, nil)
+	var appErr *erru.AppError
+	if !errors.As(err, &appErr) || appErr.Code != erru.CodeTimeout {
+		t.Fatalf("err = %v, want %s", err, erru.CodeTimeout)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("attempts = %d, want each attempt timed out and retried once", attempts.Load())
+	}
+
+	// A cancelled caller gets its own error back, without retries.
+	attempts.Store(0)
+	c.opts.Timeout = time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	err = c.do(ctx, http.MethodGet, "/", nil, nil, nil)
+	if !errors.Is(err, context.Canceled) || errors.As(err, &appErr) {
+		t.Fatalf("err = %v, want context.Canceled as is", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("attempts = %d, want 1", attempts.Load())
+	}
+}
+
+func TestDoUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	err := testClient(url).do(context.Background(), http.MethodGet, "/", nil, nil, nil)
+	var appErr *erru.AppError
+	if !errors.As(err, &appErr) || appErr.Code != erru.CodeUnavailable {
+		t.Fatalf("err = %v, want %s", err, erru.CodeUnavailable)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := newClient("test-service", "http://x", Options{Backoff: 100 * time.Millisecond})
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		for range 20 {
+			if d := c.backoff(attempt); d < base || d > base+base/2 {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, base, base+base/2)
+			}
+		}
+	}
+}
+
+func TestForwardHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+	c := testClient(srv.URL)
+
+	e := echo.New()
+	e.GET("/", func(ec echo.Context) error {
+		return c.do(ec.Request().Context(), http.MethodGet, "/", nil, nil, nil)
+	}, ForwardHeaders)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.HeaderUserID, "7")
+	req.Header.Set(echo.HeaderAuthorization, "Bearer t")
+	req.Header.Set("Cookie", "session=1")
+	rec := httptest.NewRecorder()
+	rec.Header().Set(echo.HeaderXRequestID, "req-1")
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got.Get(middleware.HeaderUserID) != "7" || got.Get(echo.HeaderAuthorization) != "Bearer t" || got.Get(echo.HeaderXRequestID) != "req-1" {
+		t.Errorf("forwarded headers = %v, want user, authorization and request ID", got)
+	}
+	if got.Get("Cookie") != "" {
+		t.Errorf("cookie forwarded: %v", got)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantCode    erru.Code
+		wantMessage string
+		wantFields  int
+	}{
+		{
+			name:   "problem",
+			status: http.StatusUnprocessableEntity,
+			body: `{"type":"https://timehub.dev/problems/validation_failed","title":"Unprocessable Entity","status":422,` +
+				`"detail":"Invalid input","code":"validation_failed","errors":[{"field":"name","rule":"required","message":"is required"}]}`,
+			wantCode: erru.CodeValidation, wantMessage: "Invalid input", wantFields: 1,
+		},
+		{
+			name: "problem without code", status: http.StatusConflict, body: `{"title":"Conflict","status":409}`,
+			wantCode: erru.CodeConflict, wantMessage: "Conflict",
+		},
+		{name: "plain text", status: http.StatusNotFound, body: "no such branch\n", wantCode: erru.CodeNotFound, wantMessage: "no such branch"},
+		{name: "empty", status: http.StatusBadGateway, wantCode: erru.CodeInternal, wantMessage: "Bad Gateway"},
+		{name: "long text", status: http.StatusServiceUnavailable, body: strings.Repeat("x", 300), wantCode: erru.CodeUnavailable, wantMessage: "Service Unavailable"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Body: io.NopCloser(strings.NewReader(tc.body))}
+			var appErr *erru.AppError
+			if err := decodeError(resp); !errors.As(err, &appErr) {
+				t.Fatalf("err = %v, want *erru.AppError", err)
+			}
+			if appErr.Status != tc.status || appErr.Code != tc.wantCode || appErr.Message != tc.wantMessage || len(appErr.Fields) != tc.wantFields {
+				t.Errorf("err = %+v, want status %d, code %s, message %q and %d fields",
+					appErr, tc.status, tc.wantCode, tc.wantMessage, tc.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Company is a company as returned by company-service.
+type Company struct {
+	ID       uint     `json:"id"`
+	Name     string   `json:"name"`
+	OwnerID  uint     `json:"owner_id"`
+	Branches []Branch `json:"branches,omitempty"`
+}
+
+type Branch struct {
+	ID        uint   `json:"id"`
+	CompanyID uint   `json:"company_id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	IsMain    bool   `json:"is_main"`
+}
+
+type Service struct {
+	ID              uint    `json:"id"`
+	CompanyID       uint    `json:"company_id"`
+	BranchID        uint    `json:"branch_id"`
+	CategoryID      *uint   `json:"category_id"`
+	Name            string  `json:"name"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+	// Options are the service's variants and add-ons.
+	Options []ServiceOption `json:"options,omitempty"`
+}
+
+// ServiceOption is a variant or add-on of a service; the deltas are added to
+// its price and duration.
+type ServiceOption struct {
+	ID            uint    `json:"id"`
+	ServiceID     uint    `json:"service_id"`
+	Kind          string  `json:"kind"`
+	Name          string  `json:"name"`
+	PriceDelta    float64 `json:"price_delta"`
+	DurationDelta int     `json:"duration_delta_minutes"`
+}
+
+// FirstChoice is the service with its first variant, if it has any, and no
+// add-ons: the choice availability overviews look at.
+func (s Service) FirstChoice() ServiceChoice {
+	choice := ServiceChoice{ServiceID: s.ID}
+	for _, o := range s.Options {
+		if o.Kind == "variant" {
+			choice.VariantID = &o.ID
+			break
+		}
+	}
+	return choice
+}
+
+// Category groups a branch's services.
+type Category struct {
+	ID        uint      `json:"id"`
+	CompanyID uint      `json:"company_id"`
+	BranchID  uint      `json:"branch_id"`
+	Name      string    `json:"name"`
+	Services  []Service `json:"services,omitempty"`
+}
+
+// AddServiceRequest adds a service to a branch catalog.
+type AddServiceRequest struct {
+	CategoryID      *uint   `json:"category_id,omitempty"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description,omitempty"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+}
+
+type Employee struct {
+	ID       uint   `json:"id"`
+	BranchID uint   `json:"branch_id"`
+	UserID   *uint  `json:"user_id"`
+	Name     string `json:"name"`
+	Position string `json:"position"`
+}
+
+// EmployeeService is one entry of an employee's menu: price and duration of a service.
+type EmployeeService struct {
+	EmployeeID      uint     `json:"employee_id"`
+	ServiceID       uint     `json:"service_id"`
+	Price           float64  `json:"price"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Service         *Service `json:"service,omitempty"`
+}
+
+// Quote is the price and duration of a service with the chosen variant and
+// add-ons, performed by an employee.
+type Quote struct {
+	EmployeeID      uint    `json:"employee_id"`
+	ServiceID       uint    `json:"service_id"`
+	VariantID       *uint   `json:"variant_id,omitempty"`
+	AddOnIDs        []uint  `json:"addon_ids,omitempty"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+	// BranchID is where the service is performed.
+	BranchID uint `json:"branch_id"`
+	// CompanyID is the tenant the booking belongs to.
+	CompanyID uint `json:"company_id"`
+	// Requirements are the resources a booking must hold alongside the employee.
+	Requirements []ResourceNeed `json:"requirements,omitempty"`
+}
+
+// ResourceNeed asks for Quantity of the resources in ResourceIDs, e.g. one
+// of the branch's massage rooms.
+type ResourceNeed struct {
+	TypeID      uint   `json:"type_id"`
+	Quantity    int    `json:"quantity"`
+	ResourceIDs []uint `json:"resource_ids"`
+}
+
+// Bundle is a set of services sold together, performed in item order.
+type Bundle struct {
+	ID        uint         `json:"id"`
+	CompanyID uint         `json:"company_id"`
+	BranchID  uint         `json:"branch_id"`
+	Name      string       `json:"name"`
+	Price     float64      `json:"price"`
+	Items     []BundleItem `json:"items"`
+}
+
+// BundleItem is one service of a bundle, followed by GapMinutes of pause.
+type BundleItem struct {
+	Position   int   `json:"position"`
+	ServiceID  uint  `json:"service_id"`
+	VariantID  *uint `json:"variant_id,omitempty"`
+	GapMinutes int   `json:"gap_minutes"`
+}
+
+// Membership is the role of the user in X-User-ID in a company.
+type Membership struct {
+	CompanyID uint   `json:"company_id"`
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+}
+
+// CompanyClient calls company-service. List methods read every page.
+type CompanyClient interface {
+	// CreateCompany creates a company with a main branch, owned by the
+	// user in the X-User-ID header (see WithHeaders).
+	CreateCompany(ctx context.Context, name string) (*Company, error)
+	GetCompany(ctx context.Context, id uint) (*Company, error)
+	// Membership returns the caller's role in the company, or a not-found
+	// error when they are not a member.
+	Membership(ctx context.Context, companyID uint) (*Membership, error)
+	GetBranchCategories(ctx context.Context, branchID uint) ([]Category, error)
+	AddCategory(ctx context.Context, branchID uint, name string) (*Category, error)
+	AddService(ctx context.Context, branchID uint, req AddServiceRequest) (*Service, error)
+	GetBranchServices(ctx context.Context, branchID uint) ([]Service, error)
+	GetEmployees(ctx context.Context, companyID uint) ([]Employee, error)
+	GetEmployeeMenu(ctx context.Context, employeeID uint) ([]EmployeeService, error)
+	Quote(ctx context.Context, employeeID, serviceID uint, variantID *uint, addOnIDs []uint) (*Quote, error)
+	GetBundle(ctx context.Context, id uint) (*Bundle, error)
+	Ping(ctx context.Context) error
+}
+
+type companyClient struct {
+	*client
+}
+
+func NewCompany(baseURL string, opts Options) CompanyClient {
+	return &companyClient{client: newClient("company-service", baseURL, opts)}
+}
+
+func (c *companyClient) CreateCompany(ctx context.Context, name string) (*Company, error) {
+	var company Company
+	req := map[string]string{"name": name}
+	if err := c.do(ctx, http.MethodPost, "/companies", nil, req, &company); err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+func (c *companyClient) GetBranchCategories(ctx context.Context, branchID uint) ([]Category, error) {
+	return list[Category](ctx, c.client, fmt.Sprintf("/branches/%d/categories", branchID), nil)
+}
+
+func (c *companyClient) AddCategory(ctx context.Context, branchID uint, name string) (*Category, error) {
+	var category Category
+	req := map[string]string{"name": name}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/branches/%d/categories", branchID), nil, req, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (c *companyClient) AddService(ctx context.Context, branchID uint, req AddServiceRequest) (*Service, error) {
+	var service Service
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/branches/%d/services", branchID), nil, req, &service); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+func (c *companyClient) GetCompany(ctx context.Context, id uint) (*Company, error) {
+	var company Company
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/companies/%d", id), nil, nil, &company); err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+func (c *companyClient) Membership(ctx context.Context, companyID uint) (*Membership, error) {
+	var member Membership
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/companies/%d/membership", companyID), nil, nil, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (c *companyClient) GetBranchServices(ctx context.Context, branchID uint) ([]Service, error) {
+	return list[Service](ctx, c.client, fmt.Sprintf("/branches/%d/services", branchID), nil)
+}
+
+func (c *companyClient) GetEmployees(ctx context.Context, companyID uint) ([]Employee, error) {
+	q := url.Values{"company_id": {strconv.FormatUint(uint64(companyID), 10)}}
+	return list[Employee](ctx, c.client, "/employees", q)
+}
+
+func (c *companyClient) GetEmployeeMenu(ctx context.Context, employeeID uint) ([]EmployeeService, error) {
+	return list[EmployeeService](ctx, c.client, fmt.Sprintf("/employees/%d/services", employeeID), nil)
+}
+
+func (c *companyClient) Quote(ctx context.Context, employeeID, serviceID uint, variantID *uint, addOnIDs []uint) (*Quote, error) {
+	q := url.Values{}
+	if variantID != nil {
+		q.Set("variant_id", strconv.FormatUint(uint64(*variantID), 10))
+	}
+	for _, id := range addOnIDs {
+		q.Add("addon_ids", strconv.FormatUint(uint64(id), 10))
+	}
+	var quote Quote
+	path := fmt.Sprintf("/employees/%d/services/%d/quote", employeeID, serviceID)
+	if err := c.do(ctx, http.MethodGet, path, q, nil, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (c *companyClient) GetBundle(ctx context.Context, id uint) (*Bundle, error) {
+	var bundle Bundle
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/bundles/%d", id), nil, nil, &bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (c *companyClient) Ping(ctx context.Context) error {
+	return c.ping(ctx)
+}
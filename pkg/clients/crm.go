@@ -0,0 +1,22 @@
+package clients
+
+import "context"
+
+// CRMClient calls crm-service. The service learns about clients from booking
+// events and exposes no HTTP API yet, so only the readiness probe is wrapped;
+// methods are added here as its endpoints appear.
+type CRMClient interface {
+	Ping(ctx context.Context) error
+}
+
+type crmClient struct {
+	*client
+}
+
+func NewCRM(baseURL string, opts Options) CRMClient {
+	return &crmClient{client: newClient("crm-service", baseURL, opts)}
+}
+
+func (c *crmClient) Ping(ctx context.Context) error {
+	return c.ping(ctx)
+}
@@ -0,0 +1,313 @@
+// Package fake provides in-memory implementations of the pkg/clients
+// interfaces for usecase unit tests. Set the Err field to make every call
+// fail, or seed the maps with the data the test needs.
+package fake
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/identity"
+)
+
+// Auth registers users in memory and rejects duplicate emails with a conflict.
+type Auth struct {
+	Err error
+
+	mu        sync.Mutex
+	Users     map[string]*clients.User
+	passwords map[string]string
+	nextID    uint
+}
+
+var _ clients.AuthClient = (*Auth)(nil)
+
+func (a *Auth) Register(_ context.Context, req clients.RegisterRequest) (*clients.User, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Err != nil {
+		return nil, a.Err
+	}
+	if a.Users == nil {
+		a.Users = make(map[string]*clients.User)
+	}
+	if _, ok := a.Users[req.Email]; ok {
+		return nil, erru.E(erru.CodeConflict, "user already exists")
+	}
+	a.nextID++
+	user := &clients.User{ID: a.nextID, Email: req.Email, Role: req.Role, CreatedAt: time.Now()}
+	a.Users[req.Email] = user
+	if a.passwords == nil {
+		a.passwords = make(map[string]string)
+	}
+	a.passwords[req.Email] = req.Password
+	return user, nil
+}
+
+// Unregister removes a user registered with the password.
+func (a *Auth) Unregister(_ context.Context, email, password string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Err != nil {
+		return a.Err
+	}
+	if _, ok := a.Users[email]; !ok || a.passwords[email] != password {
+		return erru.E(erru.CodeUnauthorized, "invalid credentials")
+	}
+	delete(a.Users, email)
+	delete(a.passwords, email)
+	return nil
+}
+
+func (a *Auth) Login(_ context.Context, email, _ string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Err != nil {
+		return "", a.Err
+	}
+	if _, ok := a.Users[email]; !ok {
+		return "", erru.E(erru.CodeUnauthorized, "invalid credentials")
+	}
+	return "fake-token:" + email, nil
+}
+
+// Verify accepts the tokens returned by Login.
+func (a *Auth) Verify(_ context.Context, token string) (*identity.Claims, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Err != nil {
+		return nil, a.Err
+	}
+	user, ok := a.Users[strings.TrimPrefix(token, "fake-token:")]
+	if !ok || !strings.HasPrefix(token, "fake-token:") {
+		return nil, erru.E(erru.CodeUnauthorized, "invalid token")
+	}
+	return &identity.Claims{UserID: user.ID, Role: user.Role}, nil
+}
+
+func (a *Auth) Ping(context.Context) error { return a.Err }
+
+// Company serves companies, services and menus from maps keyed by ID.
+type Company struct {
+	Err error
+
+	Companies map[uint]*clients.Company
+	// Services by branch ID.
+	Services map[uint][]clients.Service
+	// Employees by company ID.
+	Employees map[uint][]clients.Employee
+	// Menus by employee ID.
+	Menus map[uint][]clients.EmployeeService
+	// Categories by branch ID.
+	Categories map[uint][]clients.Category
+	// Bundles by ID.
+	Bundles map[uint]*clients.Bundle
+	// Requirements of services by service ID.
+	Requirements map[uint][]clients.ResourceNeed
+	// Roles of the caller by company ID.
+	Roles map[uint]string
+
+	mu     sync.Mutex
+	nextID uint
+}
+
+var _ clients.CompanyClient = (*Company)(nil)
+
+func (c *Company) Membership(_ context.Context, companyID uint) (*clients.Membership, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	role, ok := c.Roles[companyID]
+	if !ok {
+		return nil, erru.ErrNotFound
+	}
+	return &clients.Membership{CompanyID: companyID, Role: role}, nil
+}
+
+func (c *Company) GetCompany(_ context.Context, id uint) (*clients.Company, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	company, ok := c.Companies[id]
+	if !ok {
+		return nil, erru.ErrNotFound
+	}
+	return company, nil
+}
+
+func (c *Company) CreateCompany(_ context.Context, name string) (*clients.Company, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	company := &clients.Company{ID: c.newID(), Name: name}
+	company.Branches = []clients.Branch{{ID: c.newID(), CompanyID: company.ID, Name: "Main Branch", IsMain: true}}
+	if c.Companies == nil {
+		c.Companies = make(map[uint]*clients.Company)
+	}
+	c.Companies[company.ID] = company
+	return company, nil
+}
+
+func (c *Company) GetBranchCategories(_ context.Context, branchID uint) ([]clients.Category, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Categories[branchID], c.Err
+}
+
+func (c *Company) AddCategory(_ context.Context, branchID uint, name string) (*clients.Category, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	category := clients.Category{ID: c.newID(), BranchID: branchID, Name: name}
+	if c.Categories == nil {
+		c.Categories = make(map[uint][]clients.Category)
+	}
+	c.Categories[branchID] = append(c.Categories[branchID], category)
+	return &category, nil
+}
+
+func (c *Company) AddService(_ context.Context, branchID uint, req clients.AddServiceRequest) (*clients.Service, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	service := clients.Service{
+		ID:              c.newID(),
+		BranchID:        branchID,
+		CategoryID:      req.CategoryID,
+		Name:            req.Name,
+		Price:           req.Price,
+		DurationMinutes: req.DurationMinutes,
+	}
+	if c.Services == nil {
+		c.Services = make(map[uint][]clients.Service)
+	}
+	c.Services[branchID] = append(c.Services[branchID], service)
+	return &service, nil
+}
+
+// newID must be called with mu held.
+func (c *Company) newID() uint {
+	c.nextID++
+	return c.nextID
+}
+
+func (c *Company) GetBranchServices(_ context.Context, branchID uint) ([]clients.Service, error) {
+	return c.Services[branchID], c.Err
+}
+
+func (c *Company) GetEmployees(_ context.Context, companyID uint) ([]clients.Employee, error) {
+	return c.Employees[companyID], c.Err
+}
+
+func (c *Company) GetEmployeeMenu(_ context.Context, employeeID uint) ([]clients.EmployeeService, error) {
+	return c.Menus[employeeID], c.Err
+}
+
+// Quote takes the branch, company, price and length from the branch
+// catalogs, then the price and length from the employee's menu; options are
+// not priced.
+func (c *Company) Quote(_ context.Context, employeeID, serviceID uint, variantID *uint, addOnIDs []uint) (*clients.Quote, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	quote := &clients.Quote{
+		EmployeeID:   employeeID,
+		ServiceID:    serviceID,
+		VariantID:    variantID,
+		AddOnIDs:     addOnIDs,
+		Requirements: c.Requirements[serviceID],
+	}
+	found := false
+	for _, services := range c.Services {
+		for _, s := range services {
+			if s.ID == serviceID {
+				quote.Price, quote.DurationMinutes = s.Price, s.DurationMinutes
+				quote.BranchID, quote.CompanyID = s.BranchID, s.CompanyID
+				found = true
+			}
+		}
+	}
+	for _, m := range c.Menus[employeeID] {
+		if m.ServiceID == serviceID {
+			quote.Price, quote.DurationMinutes = m.Price, m.DurationMinutes
+			found = true
+		}
+	}
+	if !found {
+		return nil, erru.ErrNotFound
+	}
+	return quote, nil
+}
+
+func (c *Company) GetBundle(_ context.Context, id uint) (*clients.Bundle, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	bundle, ok := c.Bundles[id]
+	if !ok {
+		return nil, erru.ErrNotFound
+	}
+	return bundle, nil
+}
+
+func (c *Company) Ping(context.Context) error { return c.Err }
+
+// Booking returns preset slots and records created bookings.
+type Booking struct {
+	Err error
+
+	// Slots by employee ID.
+	Slots map[uint][]clients.Slot
+
+	mu       sync.Mutex
+	Bookings []clients.Appointment
+}
+
+var _ clients.BookingClient = (*Booking)(nil)
+
+func (b *Booking) GetSlots(_ context.Context, employeeID uint, _ clients.ServiceChoice, _ time.Time) ([]clients.Slot, error) {
+	return b.Slots[employeeID], b.Err
+}
+
+func (b *Booking) CreateBooking(_ context.Context, req clients.CreateBookingRequest) (*clients.Appointment, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	a := clients.Appointment{
+		ID:         uint(len(b.Bookings) + 1),
+		EmployeeID: req.EmployeeID,
+		ServiceID:  req.ServiceID,
+		ClientID:   req.ClientID,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		VariantID:  req.VariantID,
+		AddOnIDs:   req.AddOnIDs,
+		Status:     "confirmed",
+		Comment:    req.Comment,
+	}
+	b.Bookings = append(b.Bookings, a)
+	return &a, nil
+}
+
+func (b *Booking) Ping(context.Context) error { return b.Err }
+
+// CRM is a no-op CRMClient.
+type CRM struct {
+	Err error
+}
+
+var _ clients.CRMClient = (*CRM)(nil)
+
+func (c *CRM) Ping(context.Context) error { return c.Err }
@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/vipos89/timehub/pkg/identity"
+)
+
+// InProcess returns a transport that serves requests with h directly instead
+// of going over the network. The all-in-one binary uses it so services call
+// each other through the same clients, middleware and handlers as when
+// deployed separately.
+func InProcess(h http.Handler) http.RoundTripper {
+	return inProcess{h: h}
+}
+
+type inProcess struct {
+	h http.Handler
+}
+
+func (t inProcess) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	// Handlers expect a server-side request.
+	in := req.Clone(identity.Internal(req.Context()))
+	in.RequestURI = req.URL.RequestURI()
+	in.RemoteAddr = "127.0.0.1:0"
+
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, in)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		// want is part of the error, empty when the config is valid.
+		want string
+	}{
+		{name: "defaults", change: func(c *Config) {}},
+		{name: "log level warning", change: func(c *Config) { c.Log.Level = "warning" }},
+		{name: "log level in capitals", change: func(c *Config) { c.Log.Level = " WARN " }},
+		{name: "component levels", change: func(c *Config) { c.Log.Levels = "gorm=warning, http=debug," }},
+		{name: "unknown log level", change: func(c *Config) { c.Log.Level = "verbose" }, want: "log.level"},
+		{name: "malformed component level", change: func(c *Config) { c.Log.Levels = "gorm" }, want: "log.levels"},
+		{name: "unknown component level", change: func(c *Config) { c.Log.Levels = "gorm=loud" }, want: "log.levels"},
+		{name: "missing port", change: func(c *Config) { c.HTTP.Port = "" }, want: "http.port"},
+		{name: "negative pool", change: func(c *Config) { c.Database.MaxIdleConns = -1 }, want: "pool sizes"},
+		{name: "idle above open", change: func(c *Config) { c.Database.MaxIdleConns = 200 }, want: "max_idle_conns"},
+		{name: "schema", change: func(c *Config) { c.Database.Schema = "company" }},
+		{name: "quoted schema", change: func(c *Config) { c.Database.Schema = `a"; drop` }, want: "database.schema"},
+		{name: "schema starting with a digit", change: func(c *Config) { c.Database.Schema = "1st" }, want: "database.schema"},
+		{name: "row-level security", change: func(c *Config) { c.Database.RowLevelSecurity, c.Database.SystemRole = true, "timehub_system" }},
+		{name: "row-level security without a system role", change: func(c *Config) { c.Database.RowLevelSecurity = true }, want: "database.system_role"},
+		{name: "quoted system role", change: func(c *Config) { c.Database.SystemRole = `a"b` }, want: "database.system_role"},
+		{name: "broker", change: func(c *Config) { c.Broker.MaxAttempts = 0 }, want: "broker"},
+		{name: "outbox", change: func(c *Config) { c.Outbox.BatchSize = 0 }, want: "outbox"},
+		{name: "upstreams", change: func(c *Config) { c.Upstreams.Retries = -1 }, want: "upstreams"},
+		{name: "context timeout", change: func(c *Config) { c.Timeouts.Context = 0 }, want: "timeouts.context"},
+		{name: "shutdown delay", change: func(c *Config) { c.Timeouts.ShutdownDelay = c.Timeouts.Shutdown }, want: "shutdown_delay"},
+		{name: "shutdown delay below shutdown", change: func(c *Config) { c.Timeouts.ShutdownDelay = 5 * time.Second }},
+		{name: "exporter", change: func(c *Config) { c.Tracing.Exporter = "jaeger" }, want: "tracing.exporter"},
+		{name: "sample ratio", change: func(c *Config) { c.Tracing.SampleRatio = 1.5 }, want: "tracing.sample_ratio"},
+		{
+			name: "required by service",
+			change: func(c *Config) {
+				c.Service = "timehub"
+				c.Database.URL = "postgres://db"
+			},
+			want: "auth.jwt_secret is required",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := Default()
+			tc.change(cfg)
+			err := cfg.Validate()
+			switch {
+			case tc.want == "" && err != nil:
+				t.Fatalf("err = %v, want none", err)
+			case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateJoinsErrors(t *testing.T) {
+	cfg := Default()
+	cfg.HTTP.Port = ""
+	cfg.Log.Level = "loud"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "http.port") || !strings.Contains(err.Error(), "log.level") {
+		t.Fatalf("err = %v, want both problems", err)
+	}
+}
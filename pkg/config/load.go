@@ -0,0 +1,323 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// requiredBy lists the settings (by key) each service refuses to start without.
+var requiredBy = map[string][]string{
+	"api-gateway":     {"auth.identity_secret"},
+	"auth-service":    {"database.url", "auth.jwt_secret", "auth.identity_secret"},
+	"company-service": {"database.url", "auth.identity_secret"},
+	"booking-service": {"database.url", "auth.identity_secret"},
+	"timehub":         {"database.url", "auth.jwt_secret", "auth.identity_secret"},
+}
+
+// field is a single leaf setting addressable by its dotted key, e.g. "database.url".
+type field struct {
+	key   string
+	env   string
+	value reflect.Value
+}
+
+// fileConfig is the on-disk layout: shared settings plus per-service sections
+// that are applied on top of them.
+type fileConfig struct {
+	Config   `yaml:",inline"`
+	Services map[string]yaml.Node `yaml:"services"`
+}
+
+// Load builds the configuration for the given service from defaults, the
+// config file (--config or CONFIG_FILE), environment variables and flags.
+// It returns an error when a value can't be parsed or a required one is missing.
+func Load(service string, args []string) (*Config, error) {
+	cfg, _, err := load(service, args)
+	return cfg, err
+}
+
+// MustLoad is Load for main packages: it handles --print-config and exits
+// the process with a readable message when the configuration is invalid.
+func MustLoad(service string) *Config {
+	cfg, printOnly, err := load(service, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid configuration: %v\n", service, err)
+		os.Exit(1)
+	}
+	if printOnly {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", service, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	return cfg
+}
+
+// Print writes the effective configuration as YAML with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Validate checks value ranges and the settings required by c.Service.
+func (c *Config) Validate() error {
+	var errs []error
+
+	values := make(map[string]reflect.Value)
+	for _, f := range fields(c) {
+		values[f.key] = f.value
+	}
+	for _, key := range requiredBy[c.Service] {
+		if v, ok := values[key]; ok && v.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	if c.HTTP.Port == "" {
+		errs = append(errs, errors.New("http.port is required"))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed database.max_open_conns"))
+	}
+	if !validIdentifier(c.Database.Schema) {
+		errs = append(errs, fmt.Errorf("database.schema %q must be a lowercase identifier", c.Database.Schema))
+	}
+	if !validIdentifier(c.Database.SystemRole) {
+		errs = append(errs, fmt.Errorf("database.system_role %q must be a lowercase identifier", c.Database.SystemRole))
+	}
+	if c.Database.RowLevelSecurity && c.Database.SystemRole == "" {
+		errs = append(errs, errors.New("database.system_role is required with database.row_level_security"))
+	}
+	if c.Broker.Exchange == "" || c.Broker.MaxAttempts < 1 || c.Broker.RetryDelay <= 0 || c.Broker.Prefetch < 0 {
+		errs = append(errs, errors.New("broker.exchange, broker.max_attempts and broker.retry_delay must be set"))
+	}
+	if c.Outbox.PollInterval <= 0 || c.Outbox.BatchSize < 1 || c.Outbox.MaxAttempts < 1 ||
+		c.Outbox.RetryBackoff <= 0 || c.Outbox.Retention <= 0 || c.Outbox.CleanupInterval <= 0 {
+		errs = append(errs, errors.New("outbox settings must be positive"))
+	}
+	if c.Upstreams.Timeout <= 0 || c.Upstreams.Retries < 0 || c.Upstreams.RetryBackoff <= 0 {
+		errs = append(errs, errors.New("upstreams.timeout and upstreams.retry_backoff must be positive, upstreams.retries must not be negative"))
+	}
+	if c.Timeouts.Context <= 0 {
+		errs = append(errs, errors.New("timeouts.context must be positive"))
+	}
+	if c.Timeouts.HealthCheck <= 0 {
+		errs = append(errs, errors.New("timeouts.health_check must be positive"))
+	}
+	if c.Timeouts.Shutdown <= 0 {
+		errs = append(errs, errors.New("timeouts.shutdown must be positive"))
+	}
+	if c.Timeouts.ShutdownDelay < 0 || c.Timeouts.ShutdownDelay >= c.Timeouts.Shutdown {
+		errs = append(errs, errors.New("timeouts.shutdown_delay must be between 0 and timeouts.shutdown"))
+	}
+	switch c.Tracing.Exporter {
+	case "", "none", "otlp", "stdout", "file":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter %q is not one of none, otlp, stdout, file", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+	if !validLevel(c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level %q is not one of debug, info, warn (warning), error", c.Log.Level))
+	}
+	for _, pair := range strings.Split(c.Log.Levels, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" || !validLevel(strings.TrimSpace(level)) {
+			errs = append(errs, fmt.Errorf("log.levels entry %q must look like component=level", pair))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validIdentifier accepts unquoted Postgres identifiers so schema and role
+// names can be used in SQL as-is.
+func validIdentifier(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validLevel accepts the names logger.ParseLevel understands.
+func validLevel(level string) bool {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	}
+	return false
+}
+
+func load(service string, args []string) (*Config, bool, error) {
+	cfg := Default()
+	cfg.Service = service
+
+	fs := flag.NewFlagSet(service, flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	printOnly := fs.Bool("print-config", false, "print the effective configuration (secrets redacted) and exit")
+
+	overrides := make(map[string]string)
+	for _, f := range fields(cfg) {
+		key := f.key
+		usage := "overrides " + key
+		if f.env != "" {
+			usage += " (env " + f.env + ")"
+		}
+		fs.Func(key, usage, func(s string) error {
+			overrides[key] = s
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+	cfg.Args = fs.Args()
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, false, err
+		}
+	}
+
+	for _, f := range fields(cfg) {
+		if f.env == "" {
+			continue
+		}
+		if s, ok := os.LookupEnv(f.env); ok {
+			if err := setValue(f.value, s); err != nil {
+				return nil, false, fmt.Errorf("env %s: %w", f.env, err)
+			}
+		}
+	}
+
+	for _, f := range fields(cfg) {
+		if s, ok := overrides[f.key]; ok {
+			if err := setValue(f.value, s); err != nil {
+				return nil, false, fmt.Errorf("flag --%s: %w", f.key, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, false, err
+	}
+	return cfg, *printOnly, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	fc := fileConfig{Config: *c}
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	if section, ok := fc.Services[c.Service]; ok {
+		if err := section.Decode(&fc.Config); err != nil {
+			return fmt.Errorf("parse services.%s section: %w", c.Service, err)
+		}
+	}
+
+	service, args := c.Service, c.Args
+	*c = fc.Config
+	c.Service, c.Args = service, args
+	return nil
+}
+
+// fields flattens the settable leaves of cfg, keyed by their YAML path.
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			if name == "-" || name == "" {
+				continue
+			}
+			key := name
+			if prefix != "" {
+				key = prefix + "." + name
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Struct {
+				walk(fv, key)
+				continue
+			}
+			out = append(out, field{key: key, env: sf.Tag.Get("env"), value: fv})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
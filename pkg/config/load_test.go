@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	cfg, err := Load("gateway", nil)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want := Default()
+	if cfg.HTTP.Port != want.HTTP.Port || cfg.Database.MaxOpenConns != want.Database.MaxOpenConns ||
+		cfg.Outbox.Retention != want.Outbox.Retention || cfg.Log.Level != "info" {
+		t.Errorf("cfg = %+v, want the defaults", cfg)
+	}
+	if cfg.Service != "gateway" {
+		t.Errorf("service = %q", cfg.Service)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+http:
+  port: "9000"
+database:
+  url: postgres://file
+  max_open_conns: 20
+  max_idle_conns: 5
+auth:
+  identity_secret: shared
+log:
+  level: debug
+services:
+  company-service:
+    http:
+      port: "9002"
+    database:
+      max_idle_conns: 7
+`)
+	tests := []struct {
+		name    string
+		service string
+		env     map[string]string
+		args    []string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "file over defaults", service: "booking-service",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.HTTP.Port != "9000" || cfg.Database.MaxOpenConns != 20 || cfg.Log.Level != "debug" {
+					t.Errorf("cfg = %+v", cfg)
+				}
+				if cfg.Database.ConnMaxLifetime != time.Hour {
+					t.Errorf("conn_max_lifetime = %s, want the default", cfg.Database.ConnMaxLifetime)
+				}
+			},
+		},
+		{
+			name: "service section over shared settings", service: "company-service",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.HTTP.Port != "9002" || cfg.Database.MaxIdleConns != 7 || cfg.Database.MaxOpenConns != 20 {
+					t.Errorf("cfg = %+v", cfg)
+				}
+			},
+		},
+		{
+			name: "env over file", service: "company-service",
+			env: map[string]string{"HTTP_PORT": "9100", "DATABASE_URL": "postgres://env"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.HTTP.Port != "9100" || cfg.Database.URL.Reveal() != "postgres://env" {
+					t.Errorf("port = %s, url = %s", cfg.HTTP.Port, cfg.Database.URL.Reveal())
+				}
+			},
+		},
+		{
+			name: "flags over env", service: "company-service",
+			env:  map[string]string{"HTTP_PORT": "9100", "DB_CONN_MAX_LIFETIME": "5m"},
+			args: []string{"--http.port=9200", "--database.conn_max_lifetime", "10m", "migrate", "up"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.HTTP.Port != "9200" || cfg.Database.ConnMaxLifetime != 10*time.Minute {
+					t.Errorf("port = %s, lifetime = %s", cfg.HTTP.Port, cfg.Database.ConnMaxLifetime)
+				}
+				if strings.Join(cfg.Args, " ") != "migrate up" {
+					t.Errorf("args = %v", cfg.Args)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", path)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := Load(tc.service, tc.args)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			tc.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		env     map[string]string
+		args    []string
+		want    string
+	}{
+		{name: "required setting", service: "auth-service", env: map[string]string{"DATABASE_URL": "postgres://x"}, want: "auth.jwt_secret is required"},
+		{name: "bad env value", service: "gateway", env: map[string]string{"DB_MAX_OPEN_CONNS": "many"}, want: "env DB_MAX_OPEN_CONNS"},
+		{name: "bad flag value", service: "gateway", args: []string{"--timeouts.context=soon"}, want: "flag --timeouts.context"},
+		{name: "invalid value", service: "gateway", args: []string{"--tracing.sample_ratio=2"}, want: "tracing.sample_ratio"},
+		{name: "missing file", service: "gateway", args: []string{"--config", "/nonexistent/timehub.yaml"}, want: "read config file"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load(tc.service, tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Database.URL = "postgres://user:hunter2@db/timehub"
+	cfg.Auth.JWTSecret = "s3cret"
+
+	var out strings.Builder
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	printed := out.String()
+	if strings.Contains(printed, "hunter2") || strings.Contains(printed, "s3cret") {
+		t.Fatalf("printed config leaks a secret:\n%s", printed)
+	}
+	if !strings.Contains(printed, "jwt_secret: '[REDACTED]'") && !strings.Contains(printed, `jwt_secret: "[REDACTED]"`) {
+		t.Errorf("jwt_secret is not shown as redacted:\n%s", printed)
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	s := Secret("hunter2")
+
+	if s.Reveal() != "hunter2" {
+		t.Errorf("Reveal = %q", s.Reveal())
+	}
+	if got := fmt.Sprintf("%v %s", s, s); got != redacted+" "+redacted {
+		t.Errorf("formatted = %q", got)
+	}
+
+	data, err := json.Marshal(struct{ Password Secret }{s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("JSON = %s", data)
+	}
+
+	out, err := yaml.Marshal(struct{ Password Secret }{s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "hunter2") {
+		t.Errorf("YAML = %s", out)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("connect", "password", s)
+	if strings.Contains(buf.String(), "hunter2") || !strings.Contains(buf.String(), redacted) {
+		t.Errorf("log = %s", buf.String())
+	}
+}
+
+func TestEmptySecret(t *testing.T) {
+	var s Secret
+	if s.String() != "" {
+		t.Errorf("String = %q, want empty so unset secrets stay visible as unset", s.String())
+	}
+}
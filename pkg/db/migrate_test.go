@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSplitMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		up, down string
+		wantErr  bool
+	}{
+		{
+			name: "up and down",
+			content: "-- +goose Up\nCREATE TABLE a (id int);\nCREATE INDEX a_id ON a (id);\n" +
+				"-- +goose Down\nDROP TABLE a;\n",
+			up:   "CREATE TABLE a (id int);\nCREATE INDEX a_id ON a (id);\n",
+			down: "DROP TABLE a;\n",
+		},
+		{
+			name:    "up only",
+			content: "-- +goose Up\nALTER TABLE a ADD COLUMN b text;",
+			up:      "ALTER TABLE a ADD COLUMN b text;",
+		},
+		{
+			name:    "text before the first section is dropped",
+			content: "-- creates a\n\n-- +goose Up\nCREATE TABLE a ();\n",
+			up:      "CREATE TABLE a ();\n",
+		},
+		{
+			name:    "markers with surrounding whitespace and CRLF",
+			content: "  -- +goose Up  \r\nSELECT 1;\r\n\t-- +goose Down\r\nSELECT 2;\r\n",
+			up:      "SELECT 1;\r\n",
+			down:    "SELECT 2;\r\n",
+		},
+		{
+			name: "statement bodies keep their lines",
+			content: "-- +goose Up\nCREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  -- +goose Down is only a marker on its own line\n" +
+				"  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n-- +goose Down\nDROP FUNCTION f();\n",
+			up: "CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  -- +goose Down is only a marker on its own line\n" +
+				"  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n",
+			down: "DROP FUNCTION f();\n",
+		},
+		{
+			name:    "sections in reverse order",
+			content: "-- +goose Down\nDROP TABLE a;\n-- +goose Up\nCREATE TABLE a ();\n",
+			up:      "CREATE TABLE a ();\n",
+			down:    "DROP TABLE a;\n",
+		},
+		{name: "no up section", content: "CREATE TABLE a ();\n", wantErr: true},
+		{name: "empty up section", content: "-- +goose Up\n\n-- +goose Down\nDROP TABLE a;\n", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			up, down, err := splitMigration(tc.content)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("up = %q, want an error", up)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if up != tc.up {
+				t.Errorf("up = %q, want %q", up, tc.up)
+			}
+			if down != tc.down {
+				t.Errorf("down = %q, want %q", down, tc.down)
+			}
+		})
+	}
+}
+
+func TestLoadMigrationsOrdersByVersion(t *testing.T) {
+	body := &fstest.MapFile{Data: []byte("-- +goose Up\nSELECT 1;\n")}
+	fsys := fstest.MapFS{
+		"20240117000010_ten.sql":     body,
+		"20240117000002_two.sql":     body,
+		"9_legacy.sql":               body,
+		"20240117000001_one_two.sql": body,
+		"README.md":                  {Data: []byte("not a migration")},
+		"nested/1_ignored.sql":       body,
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	var got []string
+	for _, m := range migrations {
+		got = append(got, m.Name)
+	}
+	if want := "legacy,one_two,two,ten"; strings.Join(got, ",") != want {
+		t.Errorf("order = %v, want %s", got, want)
+	}
+	if migrations[0].Version != 9 || migrations[3].Version != 20240117000010 {
+		t.Errorf("versions = %d, %d", migrations[0].Version, migrations[3].Version)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	body := &fstest.MapFile{Data: []byte("-- +goose Up\nSELECT 1;\n")}
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{name: "no name", fsys: fstest.MapFS{"20240101.sql": body}, want: "expected <version>_<name>.sql"},
+		{name: "bad version", fsys: fstest.MapFS{"v1_init.sql": body}, want: "invalid version"},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{"1_init.sql": body, "01_again.sql": body},
+			want: "version 1 already used",
+		},
+		{
+			name: "missing up section",
+			fsys: fstest.MapFS{"1_init.sql": {Data: []byte("-- +goose Down\nSELECT 1;\n")}},
+			want: "1_init.sql: missing -- +goose Up",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadMigrations(tc.fsys)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunMigrateCommandUsage(t *testing.T) {
+	m := &Migrator{}
+	for _, args := range [][]string{nil, {"sideways"}, {"down", "0"}, {"down", "two"}} {
+		if err := RunMigrateCommand(context.Background(), m, args, io.Discard); err == nil {
+			t.Errorf("args %v: want an error", args)
+		}
+	}
+}
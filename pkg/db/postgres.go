@@ -2,11 +2,13 @@ package db
 
 import (
 	"fmt"
-	"time"
+	"net/url"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/tenant"
 )
 
 type Config struct {
@@ -24,23 +26,85 @@ func Connect(cfg Config) (*gorm.DB, error) {
 	return ConnectDSN(dsn)
 }
 
+// ConnectDSN opens a connection with the default pool settings.
 func ConnectDSN(dsn string) (*gorm.DB, error) {
+	defaults := config.Default().Database
+	defaults.URL = config.Secret(dsn)
+	return Open(defaults)
+}
+
+// Open connects using the database section of the service config.
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dsn := cfg.URL.Reveal()
+	if cfg.Schema != "" {
+		if err := createSchema(cfg); err != nil {
+			return nil, err
+		}
+		dsn = withSearchPath(dsn, cfg.Schema)
+	}
+
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: NewGormLogger(cfg.SlowQueryThreshold),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db connection: %w", err)
 	}
 
+	if err := db.Use(newTracingPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing: %w", err)
+	}
+	if cfg.RowLevelSecurity {
+		if err := db.Use(tenant.RLS{SystemRole: cfg.SystemRole}); err != nil {
+			return nil, fmt.Errorf("failed to register row-level security: %w", err)
+		}
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
 
 	// Connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	return db, nil
 }
+
+// createSchema makes sure schema exists before connections start using it.
+// The name is validated by config.Validate.
+func createSchema(cfg config.DatabaseConfig) error {
+	db, err := gorm.Open(postgres.Open(cfg.URL.Reveal()), &gorm.Config{
+		Logger: NewGormLogger(cfg.SlowQueryThreshold),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open db connection: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	// Migrations run as the system role, so it must be able to create tables in the schema.
+	stmt := "CREATE SCHEMA IF NOT EXISTS " + cfg.Schema
+	if cfg.SystemRole != "" {
+		stmt += " AUTHORIZATION " + tenant.QuoteIdent(cfg.SystemRole)
+	}
+	if err := db.Exec(stmt).Error; err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", cfg.Schema, err)
+	}
+	return nil
+}
+
+// withSearchPath sets the search_path runtime parameter on a URL or
+// key=value DSN, so every pooled connection resolves tables in schema.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
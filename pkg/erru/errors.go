@@ -2,28 +2,128 @@ package erru
 
 import "net/http"
 
+// Code is a stable, machine-readable error identifier. It is exposed to
+// clients as part of the problem type, so existing values must not change.
+type Code string
+
+const (
+	CodeBadRequest   Code = "bad_request"
+	CodeValidation   Code = "validation_failed"
+	CodeUnauthorized Code = "unauthorized"
+	CodeForbidden    Code = "forbidden"
+	CodeNotFound     Code = "not_found"
+	CodeConflict     Code = "conflict"
+	CodeUnavailable  Code = "unavailable"
+	CodeTimeout      Code = "timeout"
+	CodeInternal     Code = "internal"
+)
+
+var statusByCode = map[Code]int{
+	CodeBadRequest:   http.StatusBadRequest,
+	CodeValidation:   http.StatusUnprocessableEntity,
+	CodeUnauthorized: http.StatusUnauthorized,
+	CodeForbidden:    http.StatusForbidden,
+	CodeNotFound:     http.StatusNotFound,
+	CodeConflict:     http.StatusConflict,
+	CodeUnavailable:  http.StatusServiceUnavailable,
+	CodeTimeout:      http.StatusGatewayTimeout,
+	CodeInternal:     http.StatusInternalServerError,
+}
+
+// Status returns the HTTP status for the code (500 for unknown codes).
+func (c Code) Status() int {
+	if s, ok := statusByCode[c]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeForStatus maps an HTTP status back to the closest code.
+func CodeForStatus(status int) Code {
+	for code, s := range statusByCode {
+		if s == status {
+			return code
+		}
+	}
+	if status >= 400 && status < 500 {
+		return CodeBadRequest
+	}
+	return CodeInternal
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
 type AppError struct {
-	Code    int    `json:"code"`
-	Message string `json:"error"`
+	Code    Code         `json:"code"`
+	Status  int          `json:"status"`
+	Message string       `json:"error"`
+	Fields  []FieldError `json:"fields,omitempty"`
+
+	cause error
 }
 
 func (e *AppError) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
 	return e.Message
 }
 
-func New(code int, message string) *AppError {
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *AppError with the same code, so
+// errors.Is(err, erru.ErrNotFound) matches any not-found error.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// New creates an error from an HTTP status, kept for handlers that think in statuses.
+func New(status int, message string) *AppError {
+	return &AppError{
+		Code:    CodeForStatus(status),
+		Status:  status,
+		Message: message,
+	}
+}
+
+// E creates an error with the given code and a client-facing message.
+func E(code Code, message string) *AppError {
 	return &AppError{
 		Code:    code,
+		Status:  code.Status(),
 		Message: message,
 	}
 }
 
+// Wrap attaches a code and client-facing message to err. The cause is kept
+// for errors.Is/As and logs but is never sent to clients.
+func Wrap(err error, code Code, message string) *AppError {
+	e := E(code, message)
+	e.cause = err
+	return e
+}
+
+// Validation creates a validation error carrying per-field details.
+func Validation(fields ...FieldError) *AppError {
+	e := E(CodeValidation, "Request validation failed")
+	e.Fields = fields
+	return e
+}
+
 // Common errors
 var (
-	ErrBadRequest          = New(http.StatusBadRequest, "Bad Request")
-	ErrUnauthorized        = New(http.StatusUnauthorized, "Unauthorized")
-	ErrForbidden           = New(http.StatusForbidden, "Forbidden")
-	ErrNotFound            = New(http.StatusNotFound, "Not Found")
-	ErrConflict            = New(http.StatusConflict, "Conflict")
-	ErrInternalServerError = New(http.StatusInternalServerError, "Internal Server Error")
+	ErrBadRequest          = E(CodeBadRequest, "Bad Request")
+	ErrUnauthorized        = E(CodeUnauthorized, "Unauthorized")
+	ErrForbidden           = E(CodeForbidden, "Forbidden")
+	ErrNotFound            = E(CodeNotFound, "Not Found")
+	ErrConflict            = E(CodeConflict, "Conflict")
+	ErrInternalServerError = E(CodeInternal, "Internal Server Error")
 )
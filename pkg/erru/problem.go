@@ -0,0 +1,36 @@
+package erru
+
+import "net/http"
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	// ProblemTypePrefix prefixes the code to form the stable problem `type` URI.
+	ProblemTypePrefix = "urn:timehub:problem:"
+)
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Problem renders the error for the response to the request at instance.
+func (e *AppError) Problem(instance, requestID string) Problem {
+	return Problem{
+		Type:      ProblemTypePrefix + string(e.Code),
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+}
@@ -0,0 +1,58 @@
+package erru
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type registration struct {
+	target error
+	code   Code
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = []registration{
+		{target: context.DeadlineExceeded, code: CodeTimeout},
+		{target: context.Canceled, code: CodeUnavailable},
+	}
+)
+
+// Register maps a domain sentinel error (e.g. domain.ErrUserAlreadyExists)
+// to a code. Registering the same target again replaces its code.
+func Register(target error, code Code) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, r := range registry {
+		if r.target == target {
+			registry[i].code = code
+			return
+		}
+	}
+	registry = append(registry, registration{target: target, code: code})
+}
+
+// From resolves any error to an *AppError. Errors that are neither an
+// *AppError nor registered become internal errors with a generic message,
+// so SQL and other implementation details never reach the client.
+func From(err error) *AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, r := range registry {
+		if errors.Is(err, r.target) {
+			return Wrap(err, r.code, r.target.Error())
+		}
+	}
+	return Wrap(err, CodeInternal, ErrInternalServerError.Message)
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DedupeStore records envelopes a consumer group has already handled.
+type DedupeStore interface {
+	// Seen reports whether key was marked and has not expired.
+	Seen(ctx context.Context, key string) (bool, error)
+	Mark(ctx context.Context, key string) error
+}
+
+// MemoryDedupe keeps handled keys in memory for ttl. It covers redeliveries
+// within one process lifetime; durable consumers should back it with a table.
+type MemoryDedupe struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	seen  map[string]time.Time
+	sweep time.Time
+}
+
+func NewMemoryDedupe(ttl time.Duration) *MemoryDedupe {
+	return &MemoryDedupe{ttl: ttl, seen: make(map[string]time.Time)}
+}
+
+func (d *MemoryDedupe) Seen(_ context.Context, key string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	at, ok := d.seen[key]
+	return ok && time.Since(at) < d.ttl, nil
+}
+
+func (d *MemoryDedupe) Mark(_ context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	d.seen[key] = now
+	if now.Sub(d.sweep) > d.ttl {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.ttl {
+				delete(d.seen, k)
+			}
+		}
+		d.sweep = now
+	}
+	return nil
+}
+
+// dedupe skips envelopes the group has already handled successfully.
+func dedupe(store DedupeStore, group string, h Handler) Handler {
+	return func(ctx context.Context, env Envelope) error {
+		key := group + ":" + env.ID
+		if seen, err := store.Seen(ctx, key); err != nil {
+			return err
+		} else if seen {
+			handled.WithLabelValues(group, env.Type, resultDuplicate).Inc()
+			return nil
+		}
+		if err := h(ctx, env); err != nil {
+			return err
+		}
+		return store.Mark(ctx, key)
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryDedupeExpires(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDedupe(20 * time.Millisecond)
+	if err := d.Mark(ctx, "g:1"); err != nil {
+		t.Fatal(err)
+	}
+	if seen, _ := d.Seen(ctx, "g:1"); !seen {
+		t.Fatal("a marked key is not seen")
+	}
+	if seen, _ := d.Seen(ctx, "g:2"); seen {
+		t.Fatal("an unmarked key is seen")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if seen, _ := d.Seen(ctx, "g:1"); seen {
+		t.Fatal("the key is still seen after its ttl")
+	}
+
+	// The next mark sweeps expired keys.
+	if err := d.Mark(ctx, "g:3"); err != nil {
+		t.Fatal(err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen["g:1"]; ok || len(d.seen) != 1 {
+		t.Errorf("seen = %v, want only g:3", d.seen)
+	}
+}
+
+func TestDedupeMarksOnlyHandled(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDedupe(time.Hour)
+	calls := 0
+	fail := true
+	h := dedupe(store, "notifications", func(context.Context, Envelope) error {
+		calls++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	env := Envelope{ID: "e1", Type: "booking.created"}
+
+	if err := h(ctx, env); err == nil {
+		t.Fatal("want the handler error")
+	}
+	fail = false
+	for i := 0; i < 2; i++ {
+		if err := h(ctx, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want a retry after the failure and no rerun after success", calls)
+	}
+	if seen, _ := store.Seen(ctx, "reports:e1"); seen {
+		t.Error("keys leak across groups")
+	}
+}
@@ -0,0 +1,123 @@
+// Package events is the asynchronous message bus between TimeHub services.
+//
+// Every message is an Envelope carrying a typed payload. Publishers emit
+// envelopes to a topic exchange keyed by event type; subscribers consume them
+// through a named group (one durable queue per group), so each group sees
+// every matching event once. Failed handlers are retried with a delay and,
+// after MaxAttempts, moved to the group's dead-letter queue. Deliveries are
+// at-least-once, so handlers are deduplicated by envelope ID.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vipos89/timehub/pkg/config"
+)
+
+// Envelope wraps a payload with the metadata every consumer relies on.
+type Envelope struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	TenantID uint   `json:"tenant_id"`
+	// Version is the schema version of Payload for this Type.
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Event is a payload that knows its routing key, e.g. "booking.created".
+type Event interface {
+	EventType() string
+}
+
+// Versioned is implemented by events whose payload schema has evolved past 1.
+type Versioned interface {
+	EventVersion() int
+}
+
+// New wraps ev in an envelope with a fresh ID for the given tenant (company).
+func New(tenantID uint, ev Event) (Envelope, error) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("encode %s payload: %w", ev.EventType(), err)
+	}
+	version := 1
+	if v, ok := ev.(Versioned); ok {
+		version = v.EventVersion()
+	}
+	return Envelope{
+		ID:         uuid.NewString(),
+		Type:       ev.EventType(),
+		TenantID:   tenantID,
+		Version:    version,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}, nil
+}
+
+// Decode unmarshals the payload into v.
+func (e Envelope) Decode(v any) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("decode %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// Handler processes one envelope. Returning an error schedules a retry.
+type Handler func(ctx context.Context, env Envelope) error
+
+// Publisher emits envelopes. Publish returns once the broker has accepted them.
+type Publisher interface {
+	Publish(ctx context.Context, envs ...Envelope) error
+}
+
+// Subscriber consumes envelopes whose type matches one of the patterns
+// ("booking.created", "booking.*", "#"). Subscribe blocks until ctx is
+// cancelled, which makes it a natural server.Worker.
+type Subscriber interface {
+	Subscribe(ctx context.Context, group string, h Handler, patterns ...string) error
+}
+
+// Bus is a connected publisher and subscriber.
+type Bus interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// Options control delivery of both bus implementations.
+type Options struct {
+	// MaxAttempts is how many times a handler runs before the envelope is dead-lettered.
+	MaxAttempts int
+	// RetryDelay is the wait between attempts.
+	RetryDelay time.Duration
+	// Dedupe remembers handled envelope IDs per group.
+	Dedupe DedupeStore
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = 10 * time.Second
+	}
+	if o.Dedupe == nil {
+		o.Dedupe = NewMemoryDedupe(24 * time.Hour)
+	}
+	return o
+}
+
+// Open connects to the broker in cfg. Without a broker URL it falls back to
+// the in-process bus, which only delivers within the current binary.
+func Open(cfg config.BrokerConfig) (Bus, error) {
+	if cfg.URL == "" {
+		return NewMemory(Options{MaxAttempts: cfg.MaxAttempts, RetryDelay: cfg.RetryDelay}), nil
+	}
+	return NewRabbitMQ(cfg)
+}
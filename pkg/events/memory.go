@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/logger"
+)
+
+// DeadLetter is an envelope that exhausted its attempts.
+type DeadLetter struct {
+	Group    string
+	Envelope Envelope
+	Err      string
+}
+
+// Memory is an in-process Bus for tests and the single-binary build. It
+// follows the broker semantics: groups only receive envelopes published after
+// they subscribed, retries wait RetryDelay and failures end up in DeadLetters.
+// A group stops when its last subscriber returns; what it still had queued is
+// dropped and its pending retries are dead-lettered.
+type Memory struct {
+	opts Options
+
+	mu     sync.RWMutex
+	groups map[string]*memoryGroup
+	dead   []DeadLetter
+}
+
+type memoryGroup struct {
+	patterns    []string
+	queue       chan delivery
+	subscribers int
+	// done is closed when the group stops, releasing senders blocked on queue.
+	done chan struct{}
+}
+
+type delivery struct {
+	env      Envelope
+	attempts int
+}
+
+func NewMemory(opts Options) *Memory {
+	return &Memory{opts: opts.withDefaults(), groups: make(map[string]*memoryGroup)}
+}
+
+func (m *Memory) Publish(ctx context.Context, envs ...Envelope) error {
+	for _, env := range envs {
+		m.mu.RLock()
+		var targets []*memoryGroup
+		for _, g := range m.groups {
+			if matchesAny(g.patterns, env.Type) {
+				targets = append(targets, g)
+			}
+		}
+		m.mu.RUnlock()
+
+		for _, g := range targets {
+			select {
+			case g.queue <- delivery{env: env}:
+			case <-g.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		published.WithLabelValues(env.Type).Inc()
+	}
+	return nil
+}
+
+func (m *Memory) Subscribe(ctx context.Context, group string, h Handler, patterns ...string) error {
+	m.mu.Lock()
+	g, ok := m.groups[group]
+	if !ok {
+		g = &memoryGroup{queue: make(chan delivery, 1024), done: make(chan struct{})}
+		m.groups[group] = g
+	}
+	g.patterns = append(g.patterns, patterns...)
+	g.subscribers++
+	m.mu.Unlock()
+	defer m.leave(group, g)
+
+	h = dedupe(m.opts.Dedupe, group, h)
+	log := logger.Named("events")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d := <-g.queue:
+			err := h(ctx, d.env)
+			if err == nil {
+				handled.WithLabelValues(group, d.env.Type, resultOK).Inc()
+				continue
+			}
+			d.attempts++
+			if d.attempts >= m.opts.MaxAttempts {
+				handled.WithLabelValues(group, d.env.Type, resultDeadLetter).Inc()
+				log.Error("Event dead-lettered", "group", group, "event_id", d.env.ID, "type", d.env.Type, "error", err)
+				m.deadLetter(group, d.env, err)
+				continue
+			}
+			handled.WithLabelValues(group, d.env.Type, resultRetry).Inc()
+			time.AfterFunc(m.opts.RetryDelay, func() {
+				if !g.stopped() {
+					select {
+					case g.queue <- d:
+						return
+					case <-g.done:
+					}
+				}
+				handled.WithLabelValues(group, d.env.Type, resultDeadLetter).Inc()
+				log.Error("Event dead-lettered, group stopped before its retry", "group", group, "event_id", d.env.ID, "type", d.env.Type, "error", err)
+				m.deadLetter(group, d.env, err)
+			})
+		}
+	}
+}
+
+func (g *memoryGroup) stopped() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// leave stops g once its last subscriber has returned.
+func (m *Memory) leave(group string, g *memoryGroup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g.subscribers--
+	if g.subscribers > 0 {
+		return
+	}
+	close(g.done)
+	if m.groups[group] == g {
+		delete(m.groups, group)
+	}
+}
+
+func (m *Memory) deadLetter(group string, env Envelope, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dead = append(m.dead, DeadLetter{Group: group, Envelope: env, Err: err.Error()})
+}
+
+// DeadLetters returns the envelopes that exhausted their attempts.
+func (m *Memory) DeadLetters() []DeadLetter {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]DeadLetter(nil), m.dead...)
+}
+
+func (m *Memory) Close() error { return nil }
+
+func matchesAny(patterns []string, eventType string) bool {
+	for _, p := range patterns {
+		if matchTopic(strings.Split(p, "."), strings.Split(eventType, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTopic applies AMQP topic rules: "*" matches one word, "#" zero or more.
+func matchTopic(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if matchTopic(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && matchTopic(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && key[0] == pattern[0] && matchTopic(pattern[1:], key[1:])
+	}
+}
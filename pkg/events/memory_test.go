@@ -0,0 +1,292 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func (testEvent) EventType() string { return "booking.created" }
+
+// subscribe runs a group until the test ends and waits for it to register.
+func subscribe(t *testing.T, m *Memory, group string, h Handler, patterns ...string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Subscribe(ctx, group, h, patterns...)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	for {
+		m.mu.RLock()
+		_, ok := m.groups[group]
+		m.mu.RUnlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newEnvelope(t *testing.T) Envelope {
+	t.Helper()
+	env, err := New(3, testEvent{Name: "cut"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func TestMemoryDeliversToMatchingGroups(t *testing.T) {
+	m := NewMemory(Options{})
+	var mu sync.Mutex
+	got := map[string][]string{}
+	record := func(group string) Handler {
+		return func(_ context.Context, env Envelope) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got[group] = append(got[group], env.Type)
+			return nil
+		}
+	}
+	subscribe(t, m, "notifications", record("notifications"), "booking.*")
+	subscribe(t, m, "reports", record("reports"), "company.#")
+
+	env := newEnvelope(t)
+	if err := m.Publish(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "delivery", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got["notifications"]) == 1
+	})
+
+	var payload testEvent
+	if err := env.Decode(&payload); err != nil || payload.Name != "cut" || env.Version != 1 || env.TenantID != 3 {
+		t.Errorf("envelope = %+v, payload = %+v, err = %v", env, payload, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got["reports"]) != 0 {
+		t.Errorf("reports received %v, want nothing", got["reports"])
+	}
+}
+
+func TestMemoryRetriesThenDeadLetters(t *testing.T) {
+	m := NewMemory(Options{MaxAttempts: 3, RetryDelay: time.Millisecond})
+	var mu sync.Mutex
+	calls := 0
+	subscribe(t, m, "notifications", func(context.Context, Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return errors.New("smtp down")
+	}, "#")
+
+	env := newEnvelope(t)
+	if err := m.Publish(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "dead letter", func() bool { return len(m.DeadLetters()) == 1 })
+
+	dead := m.DeadLetters()[0]
+	if dead.Group != "notifications" || dead.Envelope.ID != env.ID || dead.Err != "smtp down" {
+		t.Errorf("dead letter = %+v", dead)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want MaxAttempts (3)", calls)
+	}
+}
+
+func TestMemoryRetrySucceeds(t *testing.T) {
+	m := NewMemory(Options{MaxAttempts: 3, RetryDelay: time.Millisecond})
+	var mu sync.Mutex
+	calls := 0
+	subscribe(t, m, "notifications", func(context.Context, Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			return errors.New("flaky")
+		}
+		return nil
+	}, "booking.created")
+
+	if err := m.Publish(context.Background(), newEnvelope(t)); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "second attempt", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls == 2
+	})
+	if dead := m.DeadLetters(); len(dead) != 0 {
+		t.Errorf("dead letters = %+v", dead)
+	}
+}
+
+// run subscribes group in the background; stop cancels it and waits for
+// Subscribe to return.
+func run(t *testing.T, m *Memory, group string, h Handler) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Subscribe(ctx, group, h, "#")
+	}()
+	waitFor(t, "subscription", func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.groups[group] != nil
+	})
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestMemoryStoppedGroupDeadLettersRetries(t *testing.T) {
+	m := NewMemory(Options{MaxAttempts: 3, RetryDelay: 20 * time.Millisecond})
+	var calls atomic.Int32
+	stop := run(t, m, "notifications", func(context.Context, Envelope) error {
+		calls.Add(1)
+		return errors.New("smtp down")
+	})
+
+	env := newEnvelope(t)
+	if err := m.Publish(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "first attempt", func() bool { return calls.Load() == 1 })
+	stop()
+
+	// The retry finds the group gone instead of blocking on its queue.
+	waitFor(t, "dead letter", func() bool { return len(m.DeadLetters()) == 1 })
+	if dead := m.DeadLetters()[0]; dead.Group != "notifications" || dead.Envelope.ID != env.ID || dead.Err != "smtp down" {
+		t.Errorf("dead letter = %+v", dead)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times after stopping, want 1", n)
+	}
+}
+
+func TestMemoryPublishToStoppedGroup(t *testing.T) {
+	m := NewMemory(Options{})
+	handling := make(chan struct{})
+	var once sync.Once
+	stop := run(t, m, "reports", func(ctx context.Context, _ Envelope) error {
+		once.Do(func() { close(handling) })
+		<-ctx.Done()
+		return nil
+	})
+
+	// More than the queue holds while the handler is stuck on the first.
+	envs := make([]Envelope, 1100)
+	for i := range envs {
+		envs[i] = newEnvelope(t)
+	}
+	published := make(chan error, 1)
+	go func() { published <- m.Publish(context.Background(), envs...) }()
+	<-handling
+	waitFor(t, "a full queue", func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		g := m.groups["reports"]
+		return len(g.queue) == cap(g.queue)
+	})
+	stop()
+
+	select {
+	case err := <-published:
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked on the stopped group's queue")
+	}
+	if err := m.Publish(context.Background(), newEnvelope(t)); err != nil {
+		t.Errorf("publish after stop: %v", err)
+	}
+}
+
+func TestMemoryDeduplicatesRedeliveries(t *testing.T) {
+	m := NewMemory(Options{})
+	var mu sync.Mutex
+	calls := 0
+	subscribe(t, m, "notifications", func(context.Context, Envelope) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil
+	}, "#")
+
+	env := newEnvelope(t)
+	other := newEnvelope(t)
+	if err := m.Publish(context.Background(), env, env, other); err != nil {
+		t.Fatal(err)
+	}
+	// The queue is FIFO, so a handled marker proves the earlier ones were processed.
+	marker := newEnvelope(t)
+	if err := m.Publish(context.Background(), marker); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "marker", func() bool {
+		seen, _ := m.opts.Dedupe.Seen(context.Background(), "notifications:"+marker.ID)
+		return seen
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want the duplicate skipped", calls)
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"booking.created", "booking.created", true},
+		{"booking.created", "booking.cancelled", false},
+		{"booking.*", "booking.created", true},
+		{"booking.*", "booking", false},
+		{"booking.*", "booking.created.v2", false},
+		{"booking.#", "booking", true},
+		{"booking.#", "booking.created.v2", true},
+		{"#", "company.branch.updated", true},
+		{"*.created", "company.created", true},
+		{"#.created", "company.branch.created", true},
+		{"#.created", "company.branch.updated", false},
+	}
+	for _, tc := range tests {
+		if got := matchesAny([]string{tc.pattern}, tc.key); got != tc.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tc.pattern, tc.key, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/vipos89/timehub/pkg/metrics"
+)
+
+// Outcomes of handling one delivery.
+const (
+	resultOK         = "ok"
+	resultRetry      = "retry"
+	resultDeadLetter = "dead_letter"
+	resultDuplicate  = "duplicate"
+)
+
+var (
+	published = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "events_published_total",
+		Help:      "Envelopes accepted by the bus, by type.",
+	}, []string{"type"})
+
+	handled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "events_handled_total",
+		Help:      "Deliveries processed by consumer groups, by outcome.",
+	}, []string{"group", "type", "result"})
+)
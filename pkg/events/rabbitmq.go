@@ -0,0 +1,286 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/logger"
+)
+
+// Header carrying the number of failed attempts of a delivery.
+const headerAttempts = "x-attempts"
+
+// RabbitMQ is the AMQP Bus. Envelopes go to a durable topic exchange keyed
+// by type. Each group declares three queues:
+//
+//	<group>        bound to the exchange for the subscribed patterns
+//	<group>.retry  holds failed deliveries for RetryDelay, then dead-letters them back to <group>
+//	<group>.dlq    keeps deliveries that exhausted MaxAttempts
+type RabbitMQ struct {
+	url      string
+	exchange string
+	prefetch int
+	opts     Options
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	pub  *amqp.Channel
+}
+
+// NewRabbitMQ dials the broker and declares the exchange. An unreachable
+// broker is not fatal: publishers and consumers reconnect on their next call,
+// so a service (and its outbox) keeps working through broker restarts.
+func NewRabbitMQ(cfg config.BrokerConfig) (*RabbitMQ, error) {
+	if cfg.Exchange == "" {
+		return nil, errors.New("broker exchange is required")
+	}
+	r := &RabbitMQ{
+		url:      cfg.URL.Reveal(),
+		exchange: cfg.Exchange,
+		prefetch: cfg.Prefetch,
+		opts:     Options{MaxAttempts: cfg.MaxAttempts, RetryDelay: cfg.RetryDelay}.withDefaults(),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.connect(); err != nil {
+		logger.Named("events").Warn("Broker unavailable, will reconnect on demand", "error", err)
+	}
+	return r, nil
+}
+
+// connect (re)opens the connection and the confirming publish channel. Callers hold mu.
+func (r *RabbitMQ) connect() (*amqp.Connection, error) {
+	if r.conn != nil && !r.conn.IsClosed() && r.pub != nil && !r.pub.IsClosed() {
+		return r.conn, nil
+	}
+	if r.conn == nil || r.conn.IsClosed() {
+		conn, err := amqp.Dial(r.url)
+		if err != nil {
+			return nil, fmt.Errorf("dial broker: %w", err)
+		}
+		r.conn = conn
+	}
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("open channel: %w", err)
+	}
+	if err := ch.ExchangeDeclare(r.exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("declare exchange %s: %w", r.exchange, err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("enable publisher confirms: %w", err)
+	}
+	r.pub = ch
+	return r.conn, nil
+}
+
+// Publish sends envelopes as persistent messages and waits for broker confirms.
+func (r *RabbitMQ) Publish(ctx context.Context, envs ...Envelope) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.connect(); err != nil {
+		return err
+	}
+
+	for _, env := range envs {
+		body, err := json.Marshal(env)
+		if err != nil {
+			return fmt.Errorf("encode envelope %s: %w", env.ID, err)
+		}
+		confirm, err := r.pub.PublishWithDeferredConfirmWithContext(ctx, r.exchange, env.Type, true, false, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    env.ID,
+			Type:         env.Type,
+			Timestamp:    env.OccurredAt,
+			Body:         body,
+		})
+		if err != nil {
+			return fmt.Errorf("publish %s: %w", env.Type, err)
+		}
+		ok, err := confirm.WaitContext(ctx)
+		if err != nil {
+			return fmt.Errorf("confirm %s: %w", env.Type, err)
+		}
+		if !ok {
+			return fmt.Errorf("broker rejected %s %s", env.Type, env.ID)
+		}
+		published.WithLabelValues(env.Type).Inc()
+	}
+	return nil
+}
+
+// Subscribe consumes the group's queue until ctx is cancelled, reconnecting
+// after broker failures.
+func (r *RabbitMQ) Subscribe(ctx context.Context, group string, h Handler, patterns ...string) error {
+	h = dedupe(r.opts.Dedupe, group, h)
+	log := logger.Named("events")
+	for {
+		err := r.consume(ctx, group, h, patterns)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Error("Consumer interrupted, reconnecting", "group", group, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.opts.RetryDelay):
+		}
+	}
+}
+
+func (r *RabbitMQ) consume(ctx context.Context, group string, h Handler, patterns []string) error {
+	r.mu.Lock()
+	conn, err := r.connect()
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := r.declareGroup(ch, group, patterns); err != nil {
+		return err
+	}
+	if r.prefetch > 0 {
+		if err := ch.Qos(r.prefetch, 0, false); err != nil {
+			return err
+		}
+	}
+	deliveries, err := ch.ConsumeWithContext(ctx, group, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("consume %s: %w", group, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("delivery channel closed")
+			}
+			r.handle(ctx, ch, group, h, d)
+		}
+	}
+}
+
+func (r *RabbitMQ) declareGroup(ch *amqp.Channel, group string, patterns []string) error {
+	if _, err := ch.QueueDeclare(group, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare queue %s: %w", group, err)
+	}
+	if _, err := ch.QueueDeclare(group+".retry", true, false, false, false, r.retryQueueArgs(group)); err != nil {
+		return fmt.Errorf("declare queue %s.retry: %w", group, err)
+	}
+	if _, err := ch.QueueDeclare(group+".dlq", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare queue %s.dlq: %w", group, err)
+	}
+	for _, p := range patterns {
+		if err := ch.QueueBind(group, p, r.exchange, false, nil); err != nil {
+			return fmt.Errorf("bind %s to %s: %w", group, p, err)
+		}
+	}
+	return nil
+}
+
+// retryQueueArgs make <group>.retry expire messages after RetryDelay back
+// into <group> through the default exchange.
+func (r *RabbitMQ) retryQueueArgs(group string) amqp.Table {
+	return amqp.Table{
+		"x-message-ttl":             r.opts.RetryDelay.Milliseconds(),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": group,
+	}
+}
+
+// requeuer publishes the copy of a failed delivery; *amqp.Channel implements it.
+type requeuer interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// handle runs h and routes a failed delivery to the retry queue or the DLQ.
+// The original delivery is acked only after the copy is safely queued.
+func (r *RabbitMQ) handle(ctx context.Context, ch requeuer, group string, h Handler, d amqp.Delivery) {
+	log := logger.Named("events")
+
+	var env Envelope
+	err := json.Unmarshal(d.Body, &env)
+	attempts := attemptsOf(d)
+	if err == nil {
+		err = h(ctx, env)
+		if err == nil {
+			handled.WithLabelValues(group, env.Type, resultOK).Inc()
+			d.Ack(false)
+			return
+		}
+		attempts++
+	} else {
+		// Malformed messages will never succeed.
+		attempts = r.opts.MaxAttempts
+		env.Type = d.Type
+	}
+
+	target, result := group+".retry", resultRetry
+	if attempts >= r.opts.MaxAttempts {
+		target, result = group+".dlq", resultDeadLetter
+		log.Error("Event dead-lettered", "group", group, "event_id", d.MessageId, "type", d.Type, "error", err)
+	}
+
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[headerAttempts] = int64(attempts)
+	headers["x-last-error"] = err.Error()
+
+	pubErr := ch.PublishWithContext(ctx, "", target, false, false, amqp.Publishing{
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Type:         d.Type,
+		Timestamp:    d.Timestamp,
+		Headers:      headers,
+		Body:         d.Body,
+	})
+	if pubErr != nil {
+		log.Error("Failed to requeue event", "group", group, "event_id", d.MessageId, "error", pubErr)
+		d.Nack(false, true)
+		return
+	}
+	handled.WithLabelValues(group, env.Type, result).Inc()
+	d.Ack(false)
+}
+
+func attemptsOf(d amqp.Delivery) int {
+	switch v := d.Headers[headerAttempts].(type) {
+	case int64:
+		return int(v)
+	case int32:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func (r *RabbitMQ) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn == nil || r.conn.IsClosed() {
+		return nil
+	}
+	return r.conn.Close()
+}
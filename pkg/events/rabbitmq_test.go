@@ -0,0 +1,171 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// recorder captures requeued copies and the fate of the original delivery.
+type recorder struct {
+	publishErr error
+
+	target string
+	msg    amqp.Publishing
+	acked  bool
+	nacked bool
+}
+
+func (r *recorder) PublishWithContext(_ context.Context, exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	if exchange != "" {
+		return errors.New("copies go through the default exchange")
+	}
+	if r.publishErr != nil {
+		return r.publishErr
+	}
+	r.target, r.msg = key, msg
+	return nil
+}
+
+func (r *recorder) Ack(uint64, bool) error { r.acked = true; return nil }
+
+func (r *recorder) Nack(_ uint64, _, requeue bool) error { r.nacked = requeue; return nil }
+
+func (r *recorder) Reject(uint64, bool) error { return nil }
+
+func newDelivery(t *testing.T, rec *recorder, headers amqp.Table) amqp.Delivery {
+	t.Helper()
+	body, err := json.Marshal(Envelope{ID: "e1", Type: "booking.created", TenantID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amqp.Delivery{
+		Acknowledger: rec,
+		Headers:      headers,
+		ContentType:  "application/json",
+		MessageId:    "e1",
+		Type:         "booking.created",
+		Body:         body,
+	}
+}
+
+func newTestRabbitMQ() *RabbitMQ {
+	return &RabbitMQ{opts: Options{MaxAttempts: 3, RetryDelay: 2 * time.Second}.withDefaults()}
+}
+
+func TestRabbitMQRetryQueueArgs(t *testing.T) {
+	args := newTestRabbitMQ().retryQueueArgs("notifications")
+	if args["x-message-ttl"] != int64(2000) {
+		t.Errorf("ttl = %v, want the retry delay in ms", args["x-message-ttl"])
+	}
+	if args["x-dead-letter-exchange"] != "" || args["x-dead-letter-routing-key"] != "notifications" {
+		t.Errorf("expired retries route to %v/%v, want the group queue", args["x-dead-letter-exchange"], args["x-dead-letter-routing-key"])
+	}
+}
+
+func TestAttemptsOf(t *testing.T) {
+	tests := []struct {
+		header any
+		want   int
+	}{
+		{nil, 0},
+		{int64(2), 2},
+		{int32(3), 3},
+		{4, 4},
+		{"5", 0},
+	}
+	for _, tc := range tests {
+		d := amqp.Delivery{Headers: amqp.Table{}}
+		if tc.header != nil {
+			d.Headers[headerAttempts] = tc.header
+		}
+		if got := attemptsOf(d); got != tc.want {
+			t.Errorf("attemptsOf(%#v) = %d, want %d", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestRabbitMQHandle(t *testing.T) {
+	failing := func(context.Context, Envelope) error { return errors.New("smtp down") }
+	tests := []struct {
+		name     string
+		handler  Handler
+		headers  amqp.Table
+		body     string
+		target   string
+		attempts int64
+	}{
+		{
+			name:    "success",
+			handler: func(context.Context, Envelope) error { return nil },
+		},
+		{
+			name: "first failure goes to the retry queue", handler: failing,
+			target: "notifications.retry", attempts: 1,
+		},
+		{
+			name: "attempt count carries over", handler: failing,
+			headers: amqp.Table{headerAttempts: int64(1), "x-trace": "abc"},
+			target:  "notifications.retry", attempts: 2,
+		},
+		{
+			name: "last attempt goes to the DLQ", handler: failing,
+			headers: amqp.Table{headerAttempts: int32(2)},
+			target:  "notifications.dlq", attempts: 3,
+		},
+		{
+			name: "malformed body goes straight to the DLQ", handler: failing,
+			body:   "{",
+			target: "notifications.dlq", attempts: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := &recorder{}
+			d := newDelivery(t, rec, tc.headers)
+			if tc.body != "" {
+				d.Body = []byte(tc.body)
+			}
+			newTestRabbitMQ().handle(context.Background(), rec, "notifications", tc.handler, d)
+
+			if !rec.acked {
+				t.Fatal("the original delivery was not acked")
+			}
+			if rec.target != tc.target {
+				t.Fatalf("requeued to %q, want %q", rec.target, tc.target)
+			}
+			if tc.target == "" {
+				return
+			}
+			if got := rec.msg.Headers[headerAttempts]; got != tc.attempts {
+				t.Errorf("attempts header = %v, want %d", got, tc.attempts)
+			}
+			if rec.msg.Headers["x-last-error"] == "" {
+				t.Error("x-last-error is not set")
+			}
+			if string(rec.msg.Body) != string(d.Body) || rec.msg.MessageId != "e1" || rec.msg.DeliveryMode != amqp.Persistent {
+				t.Errorf("copy = %+v, want the original persistent message", rec.msg)
+			}
+			for k, v := range tc.headers {
+				if k != headerAttempts && rec.msg.Headers[k] != v {
+					t.Errorf("header %s = %v, want it kept", k, rec.msg.Headers[k])
+				}
+			}
+		})
+	}
+}
+
+func TestRabbitMQHandleRequeueFailure(t *testing.T) {
+	rec := &recorder{publishErr: errors.New("channel closed")}
+	d := newDelivery(t, rec, nil)
+	newTestRabbitMQ().handle(context.Background(), rec, "notifications", func(context.Context, Envelope) error {
+		return errors.New("boom")
+	}, d)
+	if rec.acked || !rec.nacked {
+		t.Fatalf("acked = %v, nacked = %v: a delivery whose copy was lost must go back to the queue", rec.acked, rec.nacked)
+	}
+}
@@ -0,0 +1,247 @@
+package events
+
+import "time"
+
+// Event types published by TimeHub services.
+const (
+	TypeBookingCreated = "booking.created"
+
+	TypeCompanyCreated  = "company.created"
+	TypeCompanyUpdated  = "company.updated"
+	TypeCompanyDeleted  = "company.deleted"
+	TypeCompanyRestored = "company.restored"
+
+	TypeBranchCreated  = "branch.created"
+	TypeBranchUpdated  = "branch.updated"
+	TypeBranchDeleted  = "branch.deleted"
+	TypeBranchRestored = "branch.restored"
+
+	TypeCategoryCreated  = "category.created"
+	TypeCategoryUpdated  = "category.updated"
+	TypeCategoryDeleted  = "category.deleted"
+	TypeCategoryRestored = "category.restored"
+
+	TypeServiceCreated = "service.created"
+	TypeServiceUpdated = "service.updated"
+
+	TypeEmployeeCreated  = "employee.created"
+	TypeEmployeeUpdated  = "employee.updated"
+	TypeEmployeeDeleted  = "employee.deleted"
+	TypeEmployeeRestored = "employee.restored"
+)
+
+// BookingCreated is published by booking-service once an appointment is confirmed.
+type BookingCreated struct {
+	AppointmentID uint      `json:"appointment_id"`
+	EmployeeID    uint      `json:"employee_id"`
+	ServiceID     uint      `json:"service_id"`
+	ClientID      uint      `json:"client_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Price         float64   `json:"price"`
+	Status        string    `json:"status"`
+	// BundleID and GroupID are set on each appointment of a bundle booking.
+	BundleID *uint  `json:"bundle_id,omitempty"`
+	GroupID  string `json:"group_id,omitempty"`
+}
+
+func (BookingCreated) EventType() string { return TypeBookingCreated }
+
+// CompanyCreated is published by company-service together with the main branch.
+type CompanyCreated struct {
+	CompanyID uint   `json:"company_id"`
+	OwnerID   uint   `json:"owner_id"`
+	Name      string `json:"name"`
+}
+
+func (CompanyCreated) EventType() string { return TypeCompanyCreated }
+
+type BranchCreated struct {
+	BranchID  uint     `json:"branch_id"`
+	CompanyID uint     `json:"company_id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Phone     string   `json:"phone"`
+	IsMain    bool     `json:"is_main"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	BranchHours
+}
+
+func (BranchCreated) EventType() string { return TypeBranchCreated }
+
+// BranchHours say when a branch is open. Times are "HH:MM" on the wall clock
+// of Timezone (an IANA name). A weekday without hours is closed, unless the
+// branch has no hours at all, in which case it does not restrict bookings.
+type BranchHours struct {
+	Timezone     string           `json:"timezone"`
+	OpeningHours []OpeningHours   `json:"opening_hours"`
+	Exceptions   []HoursException `json:"hours_exceptions"`
+}
+
+type OpeningHours struct {
+	DayOfWeek int    `json:"day_of_week"` // 0 = Sunday
+	Open      string `json:"open"`
+	Close     string `json:"close"`
+}
+
+// HoursException replaces the weekly hours on Date ("2006-01-02").
+type HoursException struct {
+	Date   string `json:"date"`
+	Closed bool   `json:"closed"`
+	Open   string `json:"open"`
+	Close  string `json:"close"`
+}
+
+type CategoryCreated struct {
+	CategoryID uint   `json:"category_id"`
+	CompanyID  uint   `json:"company_id"`
+	BranchID   uint   `json:"branch_id"`
+	Name       string `json:"name"`
+}
+
+func (CategoryCreated) EventType() string { return TypeCategoryCreated }
+
+// ServiceSnapshot is the state of a catalog service after a change.
+type ServiceSnapshot struct {
+	ServiceID       uint    `json:"service_id"`
+	CompanyID       uint    `json:"company_id"`
+	BranchID        uint    `json:"branch_id"`
+	CategoryID      *uint   `json:"category_id"`
+	Name            string  `json:"name"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+}
+
+type ServiceCreated struct{ ServiceSnapshot }
+
+func (ServiceCreated) EventType() string { return TypeServiceCreated }
+
+type ServiceUpdated struct{ ServiceSnapshot }
+
+func (ServiceUpdated) EventType() string { return TypeServiceUpdated }
+
+// BranchID of the employee events is the primary branch; BranchIDs lists
+// every branch they work at, including it.
+type EmployeeCreated struct {
+	EmployeeID uint   `json:"employee_id"`
+	CompanyID  uint   `json:"company_id"`
+	BranchID   uint   `json:"branch_id"`
+	BranchIDs  []uint `json:"branch_ids"`
+	UserID     *uint  `json:"user_id"`
+	Name       string `json:"name"`
+	Position   string `json:"position"`
+}
+
+func (EmployeeCreated) EventType() string { return TypeEmployeeCreated }
+
+// Updated events carry the state after the change.
+
+type CompanyUpdated struct {
+	CompanyID uint   `json:"company_id"`
+	Name      string `json:"name"`
+	TaxID     string `json:"tax_id"`
+}
+
+func (CompanyUpdated) EventType() string { return TypeCompanyUpdated }
+
+// BranchUpdated is also published when the branch's hours change.
+type BranchUpdated struct {
+	BranchID  uint     `json:"branch_id"`
+	CompanyID uint     `json:"company_id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Phone     string   `json:"phone"`
+	IsMain    bool     `json:"is_main"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	BranchHours
+}
+
+func (BranchUpdated) EventType() string { return TypeBranchUpdated }
+
+type CategoryUpdated struct {
+	CategoryID uint   `json:"category_id"`
+	CompanyID  uint   `json:"company_id"`
+	BranchID   uint   `json:"branch_id"`
+	Name       string `json:"name"`
+}
+
+func (CategoryUpdated) EventType() string { return TypeCategoryUpdated }
+
+type EmployeeUpdated struct {
+	EmployeeID uint   `json:"employee_id"`
+	CompanyID  uint   `json:"company_id"`
+	BranchID   uint   `json:"branch_id"`
+	BranchIDs  []uint `json:"branch_ids"`
+	UserID     *uint  `json:"user_id"`
+	Name       string `json:"name"`
+	Position   string `json:"position"`
+	Role       string `json:"role"`
+}
+
+func (EmployeeUpdated) EventType() string { return TypeEmployeeUpdated }
+
+// Deleted events mean the row is soft-deleted: it is no longer offered for
+// booking until the matching Restored event. Deleting a company or branch
+// does not emit events for the rows below it; consumers treat them as gone too.
+
+type CompanyDeleted struct {
+	CompanyID uint `json:"company_id"`
+}
+
+func (CompanyDeleted) EventType() string { return TypeCompanyDeleted }
+
+type CompanyRestored struct {
+	CompanyID uint `json:"company_id"`
+}
+
+func (CompanyRestored) EventType() string { return TypeCompanyRestored }
+
+type BranchDeleted struct {
+	BranchID  uint `json:"branch_id"`
+	CompanyID uint `json:"company_id"`
+}
+
+func (BranchDeleted) EventType() string { return TypeBranchDeleted }
+
+type BranchRestored struct {
+	BranchID  uint `json:"branch_id"`
+	CompanyID uint `json:"company_id"`
+}
+
+func (BranchRestored) EventType() string { return TypeBranchRestored }
+
+// CategoryDeleted is followed by ServiceUpdated for every service that was
+// detached from the category; the services themselves stay bookable.
+type CategoryDeleted struct {
+	CategoryID uint `json:"category_id"`
+	CompanyID  uint `json:"company_id"`
+	BranchID   uint `json:"branch_id"`
+}
+
+func (CategoryDeleted) EventType() string { return TypeCategoryDeleted }
+
+type CategoryRestored struct {
+	CategoryID uint `json:"category_id"`
+	CompanyID  uint `json:"company_id"`
+	BranchID   uint `json:"branch_id"`
+}
+
+func (CategoryRestored) EventType() string { return TypeCategoryRestored }
+
+type EmployeeDeleted struct {
+	EmployeeID uint `json:"employee_id"`
+	CompanyID  uint `json:"company_id"`
+	BranchID   uint `json:"branch_id"`
+}
+
+func (EmployeeDeleted) EventType() string { return TypeEmployeeDeleted }
+
+type EmployeeRestored struct {
+	EmployeeID uint `json:"employee_id"`
+	CompanyID  uint `json:"company_id"`
+	BranchID   uint `json:"branch_id"`
+}
+
+func (EmployeeRestored) EventType() string { return TypeEmployeeRestored }
@@ -0,0 +1,191 @@
+// Package health implements liveness and readiness probes backed by a
+// registry of pluggable checks (database, message broker, downstream services).
+//
+// /livez only reports that the process is serving requests. /readyz runs every
+// registered check concurrently, each bounded by a timeout, and answers 503
+// when a critical check fails. Failing optional checks mark the service as
+// degraded but keep it ready.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Overall and per-check statuses.
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+)
+
+// Checker reports the health of a single dependency; nil means healthy.
+type Checker func(ctx context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// Report is the JSON body served by the probes.
+type Report struct {
+	Service string            `json:"service"`
+	Status  string            `json:"status"`
+	Checks  map[string]Result `json:"checks,omitempty"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       Checker
+}
+
+// Registry holds the checks of one service and its readiness flag.
+type Registry struct {
+	service string
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+
+	// notReady is set while the service is shutting down.
+	notReady atomic.Bool
+}
+
+// NewRegistry creates an empty registry whose checks are each bounded by timeout.
+func NewRegistry(service string, timeout time.Duration) *Registry {
+	return &Registry{service: service, timeout: timeout}
+}
+
+// Register adds a critical check: when it fails the service is not ready.
+func (r *Registry) Register(name string, fn Checker) {
+	r.add(check{name: name, critical: true, fn: fn})
+}
+
+// RegisterOptional adds a check whose failure only degrades the service.
+func (r *Registry) RegisterOptional(name string, fn Checker) {
+	r.add(check{name: name, fn: fn})
+}
+
+func (r *Registry) add(c check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, c)
+}
+
+// SetReady flips readiness. Shutdown sets it to false so load balancers stop
+// routing new traffic before connections are drained.
+func (r *Registry) SetReady(ready bool) {
+	r.notReady.Store(!ready)
+}
+
+// Run executes all checks concurrently and aggregates their results.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Service: r.service, Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		res := results[i]
+		report.Checks[c.name] = res
+		if res.Status == StatusUp {
+			continue
+		}
+		if c.critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() { errc <- c.fn(ctx) }()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := Result{Status: StatusUp, Critical: c.critical, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Livez answers 200 while the process is able to serve HTTP.
+func (r *Registry) Livez(c echo.Context) error {
+	return c.JSON(http.StatusOK, Report{Service: r.service, Status: StatusUp})
+}
+
+// Readyz runs the checks and answers 503 when the service must not receive traffic.
+func (r *Registry) Readyz(c echo.Context) error {
+	if r.notReady.Load() {
+		return c.JSON(http.StatusServiceUnavailable, Report{Service: r.service, Status: StatusDown})
+	}
+	report := r.Run(c.Request().Context())
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
+
+// Aggregate serves the report without the readiness gate. Failing checks
+// degrade the status; it answers 503 only when every check is down. The
+// gateway uses it to fan out to its upstreams.
+func (r *Registry) Aggregate(c echo.Context) error {
+	report := r.Run(c.Request().Context())
+	down := 0
+	for _, res := range report.Checks {
+		if res.Status == StatusDown {
+			down++
+		}
+	}
+	status := http.StatusOK
+	if len(report.Checks) > 0 && down == len(report.Checks) {
+		report.Status = StatusDown
+		status = http.StatusServiceUnavailable
+	} else if down > 0 {
+		report.Status = StatusDegraded
+	}
+	return c.JSON(status, report)
+}
+
+// Mount registers /livez and /readyz, and keeps /health as an alias of
+// /readyz for existing probes.
+func (r *Registry) Mount(e *echo.Echo) {
+	e.GET("/livez", r.Livez)
+	e.GET("/readyz", r.Readyz)
+	e.GET("/health", r.Readyz)
+}
@@ -0,0 +1,151 @@
+// Package identity establishes who is calling. The gateway verifies the
+// caller's access token with auth-service, drops any identity headers the
+// client sent and sets X-User-ID and X-Company-ID from the token claims,
+// signed with a secret shared by the services. Services only accept identity
+// headers carrying that signature, so they cannot be forged from outside.
+package identity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/middleware"
+)
+
+// HeaderSignature proves X-User-ID and X-Company-ID were set by the gateway
+// or another service holding the identity secret.
+const HeaderSignature = "X-Identity-Signature"
+
+var (
+	ErrInvalidToken = erru.E(erru.CodeUnauthorized, "Invalid or expired token")
+	ErrUntrusted    = erru.E(erru.CodeUnauthorized, "Identity headers are not signed")
+)
+
+// Claims is what an access token says about its holder.
+type Claims struct {
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+	// CompanyID is the company the user signed in to; 0 for none.
+	CompanyID uint `json:"company_id,omitempty"`
+}
+
+// Verifier checks an access token and returns its claims. auth-service
+// implements it; the gateway calls it over HTTP.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Claims, error)
+}
+
+// Set replaces the identity headers in h with the signed claims.
+func Set(h http.Header, secret string, claims Claims) {
+	strip(h)
+	user := strconv.FormatUint(uint64(claims.UserID), 10)
+	company := ""
+	if claims.CompanyID != 0 {
+		company = strconv.FormatUint(uint64(claims.CompanyID), 10)
+		h.Set(middleware.HeaderCompanyID, company)
+	}
+	h.Set(middleware.HeaderUserID, user)
+	h.Set(HeaderSignature, sign(secret, user, company))
+}
+
+// Signed reports whether h carries identity headers signed with secret.
+func Signed(h http.Header, secret string) bool {
+	got, err := hex.DecodeString(h.Get(HeaderSignature))
+	if err != nil || secret == "" || h.Get(middleware.HeaderUserID) == "" {
+		return false
+	}
+	want, _ := hex.DecodeString(sign(secret, h.Get(middleware.HeaderUserID), h.Get(middleware.HeaderCompanyID)))
+	return hmac.Equal(got, want)
+}
+
+func sign(secret, user, company string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(user + "\n" + company))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func strip(h http.Header) {
+	h.Del(middleware.HeaderUserID)
+	h.Del(middleware.HeaderCompanyID)
+	h.Del(HeaderSignature)
+}
+
+type internalKey struct{}
+
+// Internal marks ctx as a call between services in the same process, whose
+// signed identity headers Authenticate keeps instead of replacing.
+func Internal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+func isInternal(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalKey{}).(bool)
+	return internal
+}
+
+// Authenticate is the gateway's middleware. It drops the identity headers
+// the client sent and, when the request has a bearer token, replaces them
+// with its verified claims. Requests without a token continue anonymously;
+// an invalid token is rejected with 401. Signed headers are kept only on
+// in-process calls (see Internal).
+func Authenticate(v Verifier, secret string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			h := req.Header
+			if isInternal(req.Context()) && Signed(h, secret) {
+				return next(c)
+			}
+			strip(h)
+			token, ok := bearer(h.Get(echo.HeaderAuthorization))
+			if !ok {
+				return next(c)
+			}
+			claims, err := v.Verify(req.Context(), token)
+			if err != nil {
+				if errors.Is(err, erru.ErrUnauthorized) {
+					return ErrInvalidToken
+				}
+				return err
+			}
+			Set(h, secret, *claims)
+			return next(c)
+		}
+	}
+}
+
+// Trust is the services' middleware: it rejects requests whose identity
+// headers were not signed with secret. Requests without any continue
+// anonymously, e.g. public reads.
+func Trust(secret string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			h := c.Request().Header
+			present := h.Get(middleware.HeaderUserID) != "" || h.Get(middleware.HeaderCompanyID) != "" || h.Get(HeaderSignature) != ""
+			if present && !Signed(h, secret) {
+				logger.FromContext(c.Request().Context()).Warn("Unsigned identity headers rejected",
+					"user_id", h.Get(middleware.HeaderUserID), "company_id", h.Get(middleware.HeaderCompanyID))
+				return ErrUntrusted
+			}
+			return next(c)
+		}
+	}
+}
+
+func bearer(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
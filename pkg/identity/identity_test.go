@@ -0,0 +1,117 @@
+package identity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+)
+
+const secret = "shared"
+
+type verifier map[string]Claims
+
+func (v verifier) Verify(_ context.Context, token string) (*Claims, error) {
+	claims, ok := v[token]
+	if !ok {
+		return nil, erru.ErrUnauthorized
+	}
+	return &claims, nil
+}
+
+func TestSigned(t *testing.T) {
+	h := http.Header{}
+	Set(h, secret, Claims{UserID: 7, CompanyID: 3})
+	if !Signed(h, secret) {
+		t.Fatal("headers set with the secret are not signed")
+	}
+	if Signed(h, "other") {
+		t.Error("headers are signed with another secret")
+	}
+	h.Set(middleware.HeaderCompanyID, "4")
+	if Signed(h, secret) {
+		t.Error("a changed company is still signed")
+	}
+}
+
+// echoed serves the identity headers the handler saw.
+func echoed(mw echo.MiddlewareFunc) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = middleware.ErrorHandler
+	e.GET("/", func(c echo.Context) error {
+		h := c.Request().Header
+		return c.String(http.StatusOK, h.Get(middleware.HeaderUserID)+"/"+h.Get(middleware.HeaderCompanyID))
+	}, mw)
+	return e
+}
+
+func TestAuthenticate(t *testing.T) {
+	e := echoed(Authenticate(verifier{"good": {UserID: 7, CompanyID: 3}}, secret))
+	signed := http.Header{}
+	Set(signed, secret, Claims{UserID: 9})
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		internal bool
+		want     int
+		wantBody string
+	}{
+		{name: "token", header: http.Header{"Authorization": {"Bearer good"}}, want: http.StatusOK, wantBody: "7/3"},
+		{name: "forged headers dropped", header: http.Header{"Authorization": {"Bearer good"}, "X-User-Id": {"1"}, "X-Company-Id": {"2"}}, want: http.StatusOK, wantBody: "7/3"},
+		{name: "anonymous", header: http.Header{"X-User-Id": {"1"}}, want: http.StatusOK, wantBody: "/"},
+		{name: "signed headers from a client", header: signed, want: http.StatusOK, wantBody: "/"},
+		{name: "signed headers in process", header: signed, internal: true, want: http.StatusOK, wantBody: "9/"},
+		{name: "invalid token", header: http.Header{"Authorization": {"Bearer bad"}}, want: http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header = tc.header.Clone()
+			if tc.internal {
+				req = req.WithContext(Internal(req.Context()))
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+			if tc.wantBody != "" && rec.Body.String() != tc.wantBody {
+				t.Errorf("identity = %q, want %q", rec.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestTrust(t *testing.T) {
+	e := echoed(Trust(secret))
+	signed := http.Header{}
+	Set(signed, secret, Claims{UserID: 9, CompanyID: 3})
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{name: "signed", header: signed, want: http.StatusOK},
+		{name: "none", header: http.Header{}, want: http.StatusOK},
+		{name: "unsigned user", header: http.Header{"X-User-Id": {"1"}}, want: http.StatusUnauthorized},
+		{name: "unsigned company", header: http.Header{"X-Company-Id": {"2"}}, want: http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header = tc.header.Clone()
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
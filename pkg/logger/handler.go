@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// levelHandler filters records by level. Deriving it with a component
+// attribute (logger.Named or .With(KeyComponent, name)) switches to the
+// level configured for that component.
+type levelHandler struct {
+	next       slog.Handler
+	level      slog.Level
+	components map[string]slog.Level
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	level := h.level
+	for _, a := range attrs {
+		if a.Key != KeyComponent {
+			continue
+		}
+		if l, ok := h.components[a.Value.String()]; ok {
+			level = l
+		}
+	}
+	return &levelHandler{next: h.next.WithAttrs(attrs), level: level, components: h.components}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), level: h.level, components: h.components}
+}
@@ -3,16 +3,63 @@ package logger
 import (
 	"log/slog"
 	"os"
+	"strings"
+
+	"github.com/vipos89/timehub/pkg/config"
 )
 
-var Log *slog.Logger
+// KeyComponent is the attribute that names the part of the code a log line
+// comes from (e.g. "gorm", "http"). Per-component levels are matched on it.
+const KeyComponent = "logger"
+
+// Log is replaced by Init; until then it is slog's default logger so packages
+// used without Init (tools, tests) can log safely.
+var Log = slog.Default()
 
-func Init() {
-	opts := &slog.HandlerOptions{
-		Level: slog.LevelDebug,
+// Init sets up the global JSON logger: values are redacted before they are
+// written and each component may log at its own level (see config.LogConfig).
+func Init(cfg config.LogConfig) {
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug, // filtering is done by levelHandler
+	})
+	handler = &redactHandler{next: handler}
+	handler = &levelHandler{
+		next:       handler,
+		level:      ParseLevel(cfg.Level),
+		components: parseComponentLevels(cfg.Levels),
 	}
-	handler := slog.NewJSONHandler(os.Stdout, opts)
 	Log = slog.New(handler)
+	slog.SetDefault(Log)
+}
+
+// Named returns the global logger tagged with a component name.
+func Named(component string) *slog.Logger {
+	return Log.With(KeyComponent, component)
+}
+
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func parseComponentLevels(s string) map[string]slog.Level {
+	levels := make(map[string]slog.Level)
+	for _, pair := range strings.Split(s, ",") {
+		name, level, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		levels[strings.TrimSpace(name)] = ParseLevel(level)
+	}
+	return levels
 }
 
 func Info(msg string, args ...any) {
@@ -0,0 +1,96 @@
+// Package metrics exposes Prometheus metrics for TimeHub services: RED
+// metrics for HTTP handlers, database pool stats and the /metrics handler.
+// Domain counters are declared by each service with promauto.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/vipos89/timehub/pkg/erru"
+)
+
+// Namespace prefixes every TimeHub metric.
+const Namespace = "timehub"
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template and status code.",
+	}, []string{"service", "method", "route", "status"})
+
+	requestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_request_errors_total",
+		Help:      "HTTP requests that ended with a 5xx status.",
+	}, []string{"service", "method", "route"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "route"})
+)
+
+// Middleware records request rate, errors and latency. Routes are labelled by
+// their template (e.g. /branches/:id/services) to keep cardinality bounded.
+func Middleware(service string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Path() == "/metrics" {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method := c.Request().Method
+			status := responseStatus(c, err)
+
+			requestsTotal.WithLabelValues(service, method, route, strconv.Itoa(status)).Inc()
+			requestDuration.WithLabelValues(service, method, route).Observe(time.Since(start).Seconds())
+			if status >= http.StatusInternalServerError {
+				requestErrors.WithLabelValues(service, method, route).Inc()
+			}
+			return err
+		}
+	}
+}
+
+// responseStatus returns the status the error handler will send when err
+// has not been rendered yet.
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return erru.From(err).Status
+}
+
+// RegisterDB exports sql.DB pool stats (open, in use, idle, wait count/duration).
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the default registry in the Prometheus text format.
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.Handler())
+}
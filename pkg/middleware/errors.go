@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/logger"
+)
+
+// ErrorHandler is an echo.HTTPErrorHandler that renders every error returned
+// by handlers as application/problem+json. Install it with
+// e.HTTPErrorHandler = middleware.ErrorHandler.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var appErr *erru.AppError
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && !errors.As(err, &appErr) {
+		// Routing errors (404/405) and Bind failures come from echo itself.
+		appErr = erru.Wrap(err, erru.CodeForStatus(httpErr.Code), fmt.Sprint(httpErr.Message))
+		appErr.Status = httpErr.Code
+		if httpErr.Code >= http.StatusInternalServerError {
+			appErr.Message = erru.ErrInternalServerError.Message
+		}
+	} else {
+		appErr = erru.From(err)
+	}
+
+	requestID := RequestID(c)
+	if appErr.Status >= http.StatusInternalServerError {
+		logger.FromContext(c.Request().Context()).Error("Request failed",
+			"error", err,
+			"method", c.Request().Method,
+			"path", c.Request().URL.Path,
+		)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		c.NoContent(appErr.Status)
+		return
+	}
+
+	body, mErr := json.Marshal(appErr.Problem(c.Request().URL.Path, requestID))
+	if mErr != nil {
+		c.NoContent(http.StatusInternalServerError)
+		return
+	}
+	c.Blob(appErr.Status, erru.ProblemContentType, body)
+}
+
+// RequestID returns the request ID assigned by echo's RequestID middleware
+// (or passed in by the caller).
+func RequestID(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
 	"runtime/debug"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/vipos89/timehub/pkg/erru"
 	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/tracing"
 )
 
 func PanicRecovery(next echo.HandlerFunc) echo.HandlerFunc {
@@ -18,22 +21,46 @@ func PanicRecovery(next echo.HandlerFunc) echo.HandlerFunc {
 					err = erru.New(http.StatusInternalServerError, "Unknown panic")
 				}
 				stack := string(debug.Stack())
-				logger.Error("Panic recovered", "error", err, "stack", stack)
+				logger.FromContext(c.Request().Context()).Error("Panic recovered", "error", err, "stack", stack)
 
-				c.JSON(http.StatusInternalServerError, erru.ErrInternalServerError)
+				c.Error(erru.Wrap(err, erru.CodeInternal, erru.ErrInternalServerError.Message))
 			}
 		}()
 		return next(c)
 	}
 }
 
+const (
+	// HeaderUserID and HeaderCompanyID identify the caller; the gateway sets
+	// them from the verified access token and signs them, and services
+	// reject unsigned ones (see pkg/identity).
+	HeaderUserID    = "X-User-ID"
+	HeaderCompanyID = "X-Company-ID"
+)
+
+// RequestLogger stores a request-scoped logger (request ID, user, company) in
+// the request context and logs one line per request when it completes.
+// It must run after echo's RequestID and the tracing middleware.
 func RequestLogger(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		logger.Info("Request started",
-			"method", c.Request().Method,
-			"path", c.Request().URL.Path,
-			"ip", c.RealIP(),
-		)
+		start := time.Now()
+		req := c.Request()
+
+		attrs := []any{"request_id", RequestID(c)}
+		if traceID, spanID := tracing.IDs(req.Context()); traceID != "" {
+			attrs = append(attrs, "trace_id", traceID, "span_id", spanID)
+		}
+		if userID := req.Header.Get(HeaderUserID); userID != "" {
+			attrs = append(attrs, "user_id", userID)
+		}
+		if companyID := req.Header.Get(HeaderCompanyID); companyID != "" {
+			attrs = append(attrs, "company_id", companyID)
+		}
+		l := logger.Log.With(attrs...)
+		ctx := logger.WithContext(req.Context(), l)
+		c.SetRequest(req.WithContext(ctx))
+
+		l.Debug("Request started", "method", req.Method, "path", req.URL.Path)
 
 		err := next(c)
 
@@ -41,8 +68,22 @@ func RequestLogger(next echo.HandlerFunc) echo.HandlerFunc {
 			c.Error(err)
 		}
 
-		logger.Info("Request finished",
-			"status", c.Response().Status,
+		status := c.Response().Status
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		l.Log(ctx, level, "Request finished",
+			"method", req.Method,
+			"route", c.Path(),
+			"path", req.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"bytes_out", c.Response().Size,
+			"ip", c.RealIP(),
 		)
 
 		return nil
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/erru"
+)
+
+var hhmmPattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// Validator enforces `validate` struct tags on bound requests. Install it
+// with e.Validator = middleware.NewValidator().
+//
+// On top of the built-in rules it registers:
+//   - hhmm: a "15:04" wall-clock time
+//   - future: a time.Time after now
+//   - positive_money: an amount > 0 with at most two decimal places
+type Validator struct {
+	validate *validator.Validate
+}
+
+func NewValidator() *Validator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the name clients send them as.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "param"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+
+	v.RegisterValidation("hhmm", func(fl validator.FieldLevel) bool {
+		return hhmmPattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return ok && t.After(time.Now())
+	})
+	v.RegisterValidation("positive_money", func(fl validator.FieldLevel) bool {
+		var amount float64
+		switch fl.Field().Kind() {
+		case reflect.Float32, reflect.Float64:
+			amount = fl.Field().Float()
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			amount = float64(fl.Field().Int())
+		default:
+			return false
+		}
+		cents := amount * 100
+		return amount > 0 && math.Abs(cents-math.Round(cents)) < 1e-6
+	})
+
+	return &Validator{validate: v}
+}
+
+// Validate implements echo.Validator. Slices are validated element by element.
+func (cv *Validator) Validate(i interface{}) error {
+	var err error
+	v := reflect.Indirect(reflect.ValueOf(i))
+	if v.Kind() == reflect.Slice {
+		err = cv.validate.Var(v.Interface(), "dive")
+	} else {
+		err = cv.validate.Struct(i)
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	fields := make([]erru.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, erru.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return erru.Validation(fields...)
+}
+
+// BindAndValidate binds the request into req and validates it, returning
+// a problem-ready error on failure.
+func BindAndValidate(c echo.Context, req interface{}) error {
+	if err := c.Bind(req); err != nil {
+		return erru.Wrap(err, erru.CodeBadRequest, "Invalid request body")
+	}
+	return c.Validate(req)
+}
+
+// ParamID parses a required positive numeric path parameter.
+func ParamID(c echo.Context, name string) (uint, error) {
+	return parseID(name, c.Param(name), true)
+}
+
+// QueryID parses a numeric query parameter; zero is returned when it is optional and absent.
+func QueryID(c echo.Context, name string, required bool) (uint, error) {
+	return parseID(name, c.QueryParam(name), required)
+}
+
+func parseID(name, raw string, required bool) (uint, error) {
+	if raw == "" && !required {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return 0, erru.Validation(erru.FieldError{
+			Field:   name,
+			Rule:    "required",
+			Message: "must be a positive integer",
+		})
+	}
+	return uint(id), nil
+}
+
+// fieldPath drops the top-level struct name: "createBookingRequest.start_time" -> "start_time".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok && !strings.HasPrefix(ns, "[") {
+		return rest
+	}
+	return ns
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "gtfield":
+		return fmt.Sprintf("must be after %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "hhmm":
+		return "must be a time in HH:MM format"
+	case "datetime":
+		return fmt.Sprintf("must be in %s format", fe.Param())
+	case "timezone":
+		return "must be an IANA time zone, e.g. Europe/Moscow"
+	case "required_with":
+		return fmt.Sprintf("is required with %s", fe.Param())
+	case "future":
+		return "must be in the future"
+	case "positive_money":
+		return "must be a positive amount with at most two decimal places"
+	default:
+		return fmt.Sprintf("failed %q validation", fe.Tag())
+	}
+}
@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/vipos89/timehub/pkg/metrics"
+)
+
+var (
+	pendingGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "outbox_pending",
+		Help:      "Outbox events waiting to be published.",
+	}, []string{"service"})
+
+	failedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "outbox_failed",
+		Help:      "Outbox events given up on after max attempts and not yet cleaned up.",
+	}, []string{"service"})
+
+	publishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "outbox_published_total",
+		Help:      "Outbox events published to the bus.",
+	}, []string{"service"})
+
+	failuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "outbox_publish_failures_total",
+		Help:      "Failed publish attempts, by outcome (retry or gave_up).",
+	}, []string{"service", "outcome"})
+
+	lagSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "outbox_lag_seconds",
+		Help:      "Time between an event being stored and published.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"service"})
+)
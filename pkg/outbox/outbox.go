@@ -0,0 +1,104 @@
+// Package outbox implements the transactional outbox: services store events
+// in an outbox table inside the same transaction as the state change, and a
+// Relay publishes them to the bus afterwards. An event is therefore published
+// if and only if its transaction committed (at least once; consumers dedupe
+// by envelope ID).
+//
+// Each service creates the table in its own migrations:
+//
+//	CREATE TABLE outbox (
+//	    id BIGSERIAL PRIMARY KEY,
+//	    event_id TEXT NOT NULL UNIQUE,
+//	    event_type TEXT NOT NULL,
+//	    tenant_id BIGINT NOT NULL DEFAULT 0,
+//	    envelope JSONB NOT NULL,
+//	    attempts INT NOT NULL DEFAULT 0,
+//	    last_error TEXT NOT NULL DEFAULT '',
+//	    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+//	    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+//	    published_at TIMESTAMPTZ,
+//	    failed_at TIMESTAMPTZ
+//	);
+//	CREATE INDEX idx_outbox_pending ON outbox (id) WHERE published_at IS NULL AND failed_at IS NULL;
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/events"
+)
+
+// Message is one row of the outbox table.
+type Message struct {
+	ID            uint64          `gorm:"primaryKey"`
+	EventID       string          `gorm:"column:event_id"`
+	EventType     string          `gorm:"column:event_type"`
+	TenantID      uint            `gorm:"column:tenant_id"`
+	Envelope      json.RawMessage `gorm:"column:envelope;type:jsonb"`
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+	FailedAt      *time.Time
+}
+
+func (Message) TableName() string { return "outbox" }
+
+// Add stores envelopes in the outbox. Pass the *gorm.DB of the running
+// transaction so the events commit or roll back with the state change.
+func Add(tx *gorm.DB, envs ...events.Envelope) error {
+	if len(envs) == 0 {
+		return nil
+	}
+	rows := make([]Message, 0, len(envs))
+	now := time.Now()
+	for _, env := range envs {
+		body, err := json.Marshal(env)
+		if err != nil {
+			return fmt.Errorf("encode envelope %s: %w", env.ID, err)
+		}
+		rows = append(rows, Message{
+			EventID:       env.ID,
+			EventType:     env.Type,
+			TenantID:      env.TenantID,
+			Envelope:      body,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	return tx.Create(&rows).Error
+}
+
+// AddEvent wraps ev in an envelope for tenantID and stores it.
+func AddEvent(tx *gorm.DB, tenantID uint, ev events.Event) error {
+	env, err := events.New(tenantID, ev)
+	if err != nil {
+		return err
+	}
+	return Add(tx, env)
+}
+
+// Pending counts events that are waiting to be published.
+func Pending(ctx context.Context, db *gorm.DB) (int64, error) {
+	var n int64
+	err := db.WithContext(ctx).Model(&Message{}).
+		Where("published_at IS NULL AND failed_at IS NULL").
+		Count(&n).Error
+	return n, err
+}
+
+// Failed counts events given up on after MaxAttempts that cleanup has not
+// deleted yet.
+func Failed(ctx context.Context, db *gorm.DB) (int64, error) {
+	var n int64
+	err := db.WithContext(ctx).Model(&Message{}).
+		Where("failed_at IS NOT NULL").
+		Count(&n).Error
+	return n, err
+}
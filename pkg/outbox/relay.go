@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/logger"
+)
+
+// Relay publishes outbox rows in insertion order.
+//
+// Replicas lock batches with FOR UPDATE SKIP LOCKED, so a row is never
+// published twice concurrently. To keep order, a replica only publishes when
+// its batch starts at the oldest pending row; otherwise another replica owns
+// the head and it waits for the next poll. A failed row blocks the rows behind
+// it until it succeeds or exhausts MaxAttempts, after which it is marked
+// failed and skipped. Each publish is bounded by PublishTimeout, so a stalled
+// broker holds the batch's locks for at most BatchSize timeouts.
+type Relay struct {
+	db        *gorm.DB
+	publisher events.Publisher
+	service   string
+	cfg       config.OutboxConfig
+}
+
+func NewRelay(db *gorm.DB, publisher events.Publisher, service string, cfg config.OutboxConfig) *Relay {
+	return &Relay{db: db, publisher: publisher, service: service, cfg: cfg}
+}
+
+// Run polls until ctx is cancelled. It fits server.Runner.Worker.
+func (r *Relay) Run(ctx context.Context) error {
+	log := logger.Named("outbox")
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		for {
+			n, err := r.PublishBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Error("Outbox relay failed", "service", r.service, "error", err)
+			}
+			// Keep draining while batches come back full.
+			if err != nil || n < r.cfg.BatchSize {
+				break
+			}
+		}
+		if pending, err := Pending(ctx, r.db); err == nil {
+			pendingGauge.WithLabelValues(r.service).Set(float64(pending))
+		}
+		if failed, err := Failed(ctx, r.db); err == nil {
+			failedGauge.WithLabelValues(r.service).Set(float64(failed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-cleanup.C:
+			if n, err := r.Cleanup(ctx); err != nil {
+				log.Error("Outbox cleanup failed", "service", r.service, "error", err)
+			} else if n > 0 {
+				log.Info("Outbox cleaned up", "service", r.service, "deleted", n)
+			}
+		case <-poll.C:
+		}
+	}
+}
+
+// PublishBatch publishes up to BatchSize due rows and returns how many were published.
+func (r *Relay) PublishBatch(ctx context.Context) (int, error) {
+	published := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var batch []Message
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("id").
+			Limit(r.cfg.BatchSize).
+			Find(&batch).Error
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+
+		var head Message
+		err = tx.Select("id").
+			Where("published_at IS NULL AND failed_at IS NULL").
+			Order("id").
+			Take(&head).Error
+		if err != nil {
+			return err
+		}
+		if head.ID != batch[0].ID {
+			// An older row is locked by another replica or waiting for a retry.
+			return nil
+		}
+
+		for i := range batch {
+			msg := &batch[i]
+			if err := r.publish(ctx, msg); err != nil {
+				return r.markFailed(tx, msg, err)
+			}
+			now := time.Now()
+			if err := tx.Model(msg).Updates(map[string]any{"published_at": now, "attempts": msg.Attempts + 1}).Error; err != nil {
+				return err
+			}
+			publishedTotal.WithLabelValues(r.service).Inc()
+			lagSeconds.WithLabelValues(r.service).Observe(now.Sub(msg.CreatedAt).Seconds())
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+func (r *Relay) publish(ctx context.Context, msg *Message) error {
+	var env events.Envelope
+	if err := json.Unmarshal(msg.Envelope, &env); err != nil {
+		return err
+	}
+	if r.cfg.PublishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.PublishTimeout)
+		defer cancel()
+	}
+	return r.publisher.Publish(ctx, env)
+}
+
+// markFailed schedules a retry with exponential backoff, or gives up after MaxAttempts.
+func (r *Relay) markFailed(tx *gorm.DB, msg *Message, cause error) error {
+	attempts := msg.Attempts + 1
+	updates := map[string]any{"attempts": attempts, "last_error": cause.Error()}
+	if attempts >= r.cfg.MaxAttempts {
+		updates["failed_at"] = time.Now()
+		failuresTotal.WithLabelValues(r.service, "gave_up").Inc()
+		logger.Named("outbox").Error("Outbox event abandoned", "service", r.service, "event_id", msg.EventID, "type", msg.EventType, "attempts", attempts, "error", cause)
+	} else {
+		updates["next_attempt_at"] = time.Now().Add(backoff(r.cfg.RetryBackoff, attempts))
+		failuresTotal.WithLabelValues(r.service, "retry").Inc()
+	}
+	if err := tx.Model(msg).Updates(updates).Error; err != nil {
+		return errors.Join(cause, err)
+	}
+	return nil
+}
+
+// Cleanup deletes rows published or given up on longer than Retention ago.
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-r.cfg.Retention)
+	res := r.db.WithContext(ctx).
+		Where("published_at < ? OR failed_at < ?", cutoff, cutoff).
+		Delete(&Message{})
+	return res.RowsAffected, res.Error
+}
+
+func backoff(base time.Duration, attempts int) time.Duration {
+	d := base << (attempts - 1)
+	if d <= 0 || d > time.Hour {
+		return time.Hour
+	}
+	return d
+}
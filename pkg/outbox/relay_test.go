@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/events"
+)
+
+// fakePublisher records published envelope IDs, fails the ones in fail and
+// hangs on the ones in stall until ctx is done.
+type fakePublisher struct {
+	fail      map[string]bool
+	stall     map[string]bool
+	published []string
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, envs ...events.Envelope) error {
+	for _, env := range envs {
+		if p.fail[env.ID] {
+			return errors.New("broker unavailable")
+		}
+		if p.stall[env.ID] {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		p.published = append(p.published, env.ID)
+	}
+	return nil
+}
+
+func newMockRelay(t *testing.T, pub *fakePublisher) (*Relay, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default().Outbox
+	cfg.BatchSize = 10
+	cfg.MaxAttempts = 3
+	return NewRelay(db, pub, "booking-service", cfg), mock
+}
+
+// pendingRows returns outbox rows for ids, each with the given attempts.
+func pendingRows(t *testing.T, attempts int, ids ...uint64) *sqlmock.Rows {
+	t.Helper()
+	rows := sqlmock.NewRows([]string{"id", "event_id", "event_type", "tenant_id", "envelope", "attempts", "created_at"})
+	for _, id := range ids {
+		env := events.Envelope{ID: eventID(id), Type: "booking.created", TenantID: 1}
+		body, err := json.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.AddRow(id, env.ID, env.Type, 1, body, attempts, time.Now().Add(-time.Second))
+	}
+	return rows
+}
+
+func eventID(id uint64) string { return fmt.Sprintf("evt-%d", id) }
+
+func expectBatch(mock sqlmock.Sqlmock, batch, head *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "outbox" WHERE .*next_attempt_at <= .* ORDER BY id LIMIT .* FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(batch)
+	mock.ExpectQuery(`SELECT "id" FROM "outbox" WHERE published_at IS NULL AND failed_at IS NULL ORDER BY id LIMIT`).
+		WillReturnRows(head)
+}
+
+func expectSent(mock sqlmock.Sqlmock, id uint64) {
+	mock.ExpectExec(`UPDATE "outbox" SET "attempts"=\$1,"published_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(1, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestPublishBatchMarksSentInOrder(t *testing.T) {
+	pub := &fakePublisher{}
+	relay, mock := newMockRelay(t, pub)
+	expectBatch(mock, pendingRows(t, 0, 1, 2, 3), sqlmock.NewRows([]string{"id"}).AddRow(1))
+	expectSent(mock, 1)
+	expectSent(mock, 2)
+	expectSent(mock, 3)
+	mock.ExpectCommit()
+
+	n, err := relay.PublishBatch(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if got := pub.published; len(got) != 3 || got[0] != eventID(1) || got[1] != eventID(2) || got[2] != eventID(3) {
+		t.Errorf("published = %v, want insertion order", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPublishBatchWaitsForHead(t *testing.T) {
+	pub := &fakePublisher{}
+	relay, mock := newMockRelay(t, pub)
+	// Row 1 is locked by another replica or waiting for its retry.
+	expectBatch(mock, pendingRows(t, 0, 2, 3), sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	n, err := relay.PublishBatch(context.Background())
+	if err != nil || n != 0 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if len(pub.published) != 0 {
+		t.Errorf("published %v ahead of the head row", pub.published)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPublishBatchFailureBlocksLaterRows(t *testing.T) {
+	pub := &fakePublisher{fail: map[string]bool{eventID(2): true}}
+	relay, mock := newMockRelay(t, pub)
+	expectBatch(mock, pendingRows(t, 0, 1, 2, 3), sqlmock.NewRows([]string{"id"}).AddRow(1))
+	expectSent(mock, 1)
+	mock.ExpectExec(`UPDATE "outbox" SET "attempts"=\$1,"last_error"=\$2,"next_attempt_at"=\$3 WHERE "id" = \$4`).
+		WithArgs(1, "broker unavailable", sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := relay.PublishBatch(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if len(pub.published) != 1 {
+		t.Errorf("published = %v, want row 3 held behind the failed row 2", pub.published)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPublishBatchGivesUpAfterMaxAttempts(t *testing.T) {
+	pub := &fakePublisher{fail: map[string]bool{eventID(1): true}}
+	relay, mock := newMockRelay(t, pub)
+	expectBatch(mock, pendingRows(t, 2, 1, 2), sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "outbox" SET "attempts"=\$1,"failed_at"=\$2,"last_error"=\$3 WHERE "id" = \$4`).
+		WithArgs(3, sqlmock.AnyArg(), "broker unavailable", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := relay.PublishBatch(context.Background())
+	if err != nil || n != 0 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPublishBatchTimesOutStalledPublish(t *testing.T) {
+	pub := &fakePublisher{stall: map[string]bool{eventID(2): true}}
+	relay, mock := newMockRelay(t, pub)
+	relay.cfg.PublishTimeout = 20 * time.Millisecond
+	expectBatch(mock, pendingRows(t, 0, 1, 2, 3), sqlmock.NewRows([]string{"id"}).AddRow(1))
+	expectSent(mock, 1)
+	mock.ExpectExec(`UPDATE "outbox" SET "attempts"=\$1,"last_error"=\$2,"next_attempt_at"=\$3 WHERE "id" = \$4`).
+		WithArgs(1, context.DeadlineExceeded.Error(), sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	start := time.Now()
+	n, err := relay.PublishBatch(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("batch took %v, want the stalled publish cut off", elapsed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCleanupDeletesPublishedAndFailed(t *testing.T) {
+	relay, mock := newMockRelay(t, &fakePublisher{})
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "outbox" WHERE published_at < \$1 OR failed_at < \$2`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	n, err := relay.Cleanup(context.Background())
+	if err != nil || n != 4 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	relay, mock := newMockRelay(t, &fakePublisher{})
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "outbox" WHERE failed_at IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	n, err := Failed(context.Background(), relay.db)
+	if err != nil || n != 2 {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{13, time.Hour},
+		{80, time.Hour},
+	}
+	for _, tc := range tests {
+		if got := backoff(time.Second, tc.attempts); got != tc.want {
+			t.Errorf("backoff(%d) = %s, want %s", tc.attempts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package pagination implements cursor-based paging, sorting and filtering
+// for list endpoints.
+//
+// A list endpoint declares a Spec with the fields clients may sort and filter
+// on, parses the query string with Parse and loads rows with Find:
+//
+//	GET /employees?company_id=1&limit=20&sort=-created_at&filter=position:eq:master
+//
+// Responses are wrapped in a Page whose next_cursor is passed back as
+// ?cursor= to continue. Cursors are opaque to clients; they encode the sort
+// values of the last row, so pages stay stable while rows are inserted.
+package pagination
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/erru"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Query parameters read by Parse.
+const (
+	ParamLimit  = "limit"
+	ParamCursor = "cursor"
+	ParamSort   = "sort"
+	ParamFilter = "filter"
+)
+
+// Op is a filter operator.
+type Op string
+
+const (
+	OpEq   Op = "eq"
+	OpNe   Op = "ne"
+	OpLt   Op = "lt"
+	OpLte  Op = "lte"
+	OpGt   Op = "gt"
+	OpGte  Op = "gte"
+	OpLike Op = "like" // case-insensitive substring match
+	OpIn   Op = "in"   // comma-separated values
+)
+
+var sqlOps = map[Op]string{
+	OpEq:   "=",
+	OpNe:   "<>",
+	OpLt:   "<",
+	OpLte:  "<=",
+	OpGt:   ">",
+	OpGte:  ">=",
+	OpLike: "ILIKE",
+	OpIn:   "IN",
+}
+
+// Field whitelists a column for sorting or filtering. Column may be
+// qualified ("employees.name") when the list query joins other tables.
+type Field struct {
+	Column string
+	Ops    []Op // allowed filter operators; empty means the field is not filterable
+	NoSort bool
+	// Nullable marks a column that may hold NULL. It sorts as the zero value
+	// of its Go type, so keyset pages neither skip nor repeat NULL rows.
+	// Pointer fields are treated as nullable without it.
+	Nullable bool
+}
+
+// Spec describes what a list endpoint accepts.
+type Spec struct {
+	// Fields maps the public name used in sort= and filter= to a column.
+	Fields map[string]Field
+	// DefaultSort is used when the request has no sort= (e.g. "name" or "-created_at").
+	DefaultSort string
+	// Key is the unique column that breaks ties between equal sort values; "id" by default.
+	Key string
+	// DefaultLimit and MaxLimit override the package defaults.
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Sort orders rows by a whitelisted column.
+type Sort struct {
+	Field    string
+	Column   string
+	Desc     bool
+	Nullable bool
+}
+
+// Filter restricts rows by a whitelisted column.
+type Filter struct {
+	Field  string
+	Column string
+	Op     Op
+	Value  string
+}
+
+// Request is a parsed page request.
+type Request struct {
+	Limit   int
+	Cursor  string
+	Sort    []Sort
+	Filters []Filter
+	Key     string
+}
+
+// Page is the response envelope of list endpoints.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+}
+
+// Parse reads limit, cursor, sort and filter query parameters, rejecting
+// fields the spec does not allow.
+func Parse(c echo.Context, spec Spec) (Request, error) {
+	q := c.QueryParams()
+	return spec.Request(q.Get(ParamLimit), q.Get(ParamCursor), q.Get(ParamSort), q[ParamFilter]...)
+}
+
+// Request builds a Request from raw parameter values, for callers that do
+// not read them from an HTTP query string.
+func (s Spec) Request(limit, cursor, sort string, filters ...string) (Request, error) {
+	req := Request{
+		Limit:  s.DefaultLimit,
+		Cursor: cursor,
+		Key:    s.Key,
+	}
+	if req.Limit == 0 {
+		req.Limit = DefaultLimit
+	}
+	if req.Key == "" {
+		req.Key = "id"
+	}
+	maxLimit := s.MaxLimit
+	if maxLimit == 0 {
+		maxLimit = MaxLimit
+	}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxLimit {
+			return Request{}, invalid(ParamLimit, "range", "must be between 1 and "+strconv.Itoa(maxLimit))
+		}
+		req.Limit = n
+	}
+
+	if sort == "" {
+		sort = s.DefaultSort
+	}
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		f, ok := s.Fields[name]
+		if !ok || f.NoSort {
+			return Request{}, invalid(ParamSort, "oneof", "cannot sort by "+strconv.Quote(name))
+		}
+		req.Sort = append(req.Sort, Sort{Field: name, Column: f.Column, Desc: desc, Nullable: f.Nullable})
+	}
+
+	for _, expr := range filters {
+		// field:op:value; the value may itself contain colons.
+		parts := strings.SplitN(expr, ":", 3)
+		if len(parts) != 3 {
+			return Request{}, invalid(ParamFilter, "format", "must look like field:op:value")
+		}
+		name, op := parts[0], Op(parts[1])
+		f, ok := s.Fields[name]
+		if !ok || !allows(f.Ops, op) {
+			return Request{}, invalid(ParamFilter, "oneof", "cannot filter "+strconv.Quote(name)+" with "+strconv.Quote(string(op)))
+		}
+		req.Filters = append(req.Filters, Filter{Field: name, Column: f.Column, Op: op, Value: parts[2]})
+	}
+	return req, nil
+}
+
+func allows(ops []Op, op Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func invalid(field, rule, message string) error {
+	return erru.Validation(erru.FieldError{Field: field, Rule: rule, Message: message})
+}
@@ -0,0 +1,119 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vipos89/timehub/pkg/erru"
+)
+
+var testSpec = Spec{
+	Fields: map[string]Field{
+		"id":         {Column: "id", Ops: []Op{OpEq, OpIn}},
+		"name":       {Column: "items.name", Ops: []Op{OpEq, OpLike}},
+		"position":   {Column: "position", Ops: []Op{OpEq}, Nullable: true},
+		"address":    {Column: "address", Ops: []Op{OpLike}, NoSort: true},
+		"created_at": {Column: "created_at", Ops: []Op{OpGt, OpLt}},
+	},
+	DefaultSort: "name",
+	MaxLimit:    100,
+}
+
+// invalidField returns the field of a validation error, or "" for other errors.
+func invalidField(err error) string {
+	var e *erru.AppError
+	if !errors.As(err, &e) || len(e.Fields) != 1 {
+		return ""
+	}
+	return e.Fields[0].Field
+}
+
+func TestSpecRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   string
+		sort    string
+		filters []string
+		want    Request
+	}{
+		{
+			name: "defaults",
+			want: Request{Limit: DefaultLimit, Key: "id", Sort: []Sort{{Field: "name", Column: "items.name"}}},
+		},
+		{
+			name: "multi-column sort", limit: "10", sort: "-created_at, position",
+			want: Request{Limit: 10, Key: "id", Sort: []Sort{
+				{Field: "created_at", Column: "created_at", Desc: true},
+				{Field: "position", Column: "position", Nullable: true},
+			}},
+		},
+		{
+			name: "filters", sort: "id", filters: []string{"name:like:a:b", "id:in:1,2"},
+			want: Request{Limit: DefaultLimit, Key: "id", Sort: []Sort{{Field: "id", Column: "id"}}, Filters: []Filter{
+				{Field: "name", Column: "items.name", Op: OpLike, Value: "a:b"},
+				{Field: "id", Column: "id", Op: OpIn, Value: "1,2"},
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := testSpec.Request(tc.limit, "", tc.sort, tc.filters...)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("request = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSpecRequestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   string
+		sort    string
+		filters []string
+		field   string
+	}{
+		{name: "limit not a number", limit: "ten", field: ParamLimit},
+		{name: "limit zero", limit: "0", field: ParamLimit},
+		{name: "limit above the spec max", limit: "101", field: ParamLimit},
+		{name: "unknown sort", sort: "price", field: ParamSort},
+		{name: "unsortable field", sort: "-address", field: ParamSort},
+		{name: "filter without a value", filters: []string{"name:eq"}, field: ParamFilter},
+		{name: "unknown filter field", filters: []string{"price:eq:1"}, field: ParamFilter},
+		{name: "operator not allowed", filters: []string{"created_at:eq:2024-01-01"}, field: ParamFilter},
+		{name: "unfilterable operator", filters: []string{"id:like:1"}, field: ParamFilter},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := testSpec.Request(tc.limit, "", tc.sort, tc.filters...)
+			if got := invalidField(err); got != tc.field {
+				t.Fatalf("err = %v, want a validation error on %q", err, tc.field)
+			}
+		})
+	}
+}
+
+func TestRequestOrderAndSignature(t *testing.T) {
+	tests := []struct {
+		sort      string
+		signature string
+	}{
+		{"name", "name,id"},
+		{"-created_at", "-created_at,-id"},
+		{"-created_at,name", "-created_at,name,id"},
+		{"id,name", "id,name"},
+	}
+	for _, tc := range tests {
+		req, err := testSpec.Request("", "", tc.sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := req.signature(); got != tc.signature {
+			t.Errorf("sort %q: signature = %q, want %q", tc.sort, got, tc.signature)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/vipos89/timehub/pkg/erru"
+)
+
+// cursor is the decoded form of Page.NextCursor.
+type cursor struct {
+	Sort   string            `json:"s"` // sort the cursor was issued for
+	Values []json.RawMessage `json:"v"` // last row's sort values, then its key
+}
+
+// Find loads one page of T from db, which carries the endpoint's own
+// conditions (tenant scope, parent ID, joins). Total counts every row
+// matching those conditions and the filters. preloads apply to the page only.
+func Find[T any](db *gorm.DB, req Request, preloads ...string) (Page[T], error) {
+	page := Page[T]{Items: []T{}, Limit: req.Limit}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return page, err
+	}
+	order := req.order()
+	fields := make([]*schema.Field, len(order))
+	keys := make([]key, len(order))
+	for i, s := range order {
+		fields[i] = stmt.Schema.LookUpField(bareColumn(s.Column))
+		if fields[i] == nil {
+			return page, erru.E(erru.CodeInternal, "Unknown sort column "+s.Column)
+		}
+		keys[i] = keyOf(s, fields[i])
+	}
+
+	query := db.Model(new(T))
+	for _, f := range req.Filters {
+		field := stmt.Schema.LookUpField(bareColumn(f.Column))
+		if field == nil {
+			return page, erru.E(erru.CodeInternal, "Unknown filter column "+f.Column)
+		}
+		cond, err := filterClause(f, field)
+		if err != nil {
+			return page, err
+		}
+		query = query.Where(cond)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, req.signature(), fields)
+		if err != nil {
+			return page, err
+		}
+		query = query.Where(afterClause(keys, after))
+	}
+	query = query.Order(orderClause(keys))
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+
+	// One extra row tells whether another page follows.
+	var items []T
+	if err := query.Limit(req.Limit + 1).Find(&items).Error; err != nil {
+		return page, err
+	}
+	if len(items) > req.Limit {
+		items = items[:req.Limit]
+		next, err := encodeCursor(db.Statement.Context, req.signature(), fields, &items[len(items)-1])
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = next
+	}
+	page.Items = items
+	return page, nil
+}
+
+// order is the requested sort followed by the key as a tie-breaker.
+func (r Request) order() []Sort {
+	order := append([]Sort(nil), r.Sort...)
+	for _, s := range order {
+		if s.Column == r.Key {
+			return order
+		}
+	}
+	desc := false
+	if len(order) > 0 {
+		desc = order[len(order)-1].Desc
+	}
+	return append(order, Sort{Field: bareColumn(r.Key), Column: r.Key, Desc: desc})
+}
+
+// signature identifies the sort a cursor belongs to.
+func (r Request) signature() string {
+	parts := make([]string, 0, len(r.Sort)+1)
+	for _, s := range r.order() {
+		if s.Desc {
+			parts = append(parts, "-"+s.Field)
+		} else {
+			parts = append(parts, s.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// key is one column of the keyset. A nullable column is compared as
+// COALESCE(column, zero), which matches the zero value NULL scans into, so
+// the order stays total and cursors round-trip.
+type key struct {
+	Sort
+	expr     any // clause.Column, or a COALESCE clause.Expr
+	nullable bool
+	zero     any
+}
+
+func keyOf(s Sort, field *schema.Field) key {
+	k := key{Sort: s, expr: clause.Column{Name: s.Column}}
+	if s.Nullable || field.FieldType.Kind() == reflect.Pointer {
+		k.nullable = true
+		k.zero = reflect.Zero(field.IndirectFieldType).Interface()
+		k.expr = clause.Expr{SQL: "COALESCE(?, ?)", Vars: []any{clause.Column{Name: s.Column}, k.zero}}
+	}
+	return k
+}
+
+// value is the cursor value as the key compares it: nil pointers become
+// the zero value and other pointers are dereferenced.
+func (k key) value(v any) any {
+	if !k.nullable {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer {
+		return v
+	}
+	if rv.IsNil() {
+		return k.zero
+	}
+	return rv.Elem().Interface()
+}
+
+func orderClause(keys []key) clause.OrderBy {
+	terms := make([]string, len(keys))
+	vars := make([]any, len(keys))
+	for i, k := range keys {
+		terms[i] = "?"
+		if k.Desc {
+			terms[i] += " DESC"
+		}
+		vars[i] = k.expr
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(terms, ", "), Vars: vars, WithoutParentheses: true}}
+}
+
+// afterClause selects rows strictly after the cursor row:
+// (a > va) OR (a = va AND b > vb) OR ...
+func afterClause(keys []key, values []any) clause.Expression {
+	var or []clause.Expression
+	for i, k := range keys {
+		and := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Expr{SQL: "? = ?", Vars: []any{keys[j].expr, keys[j].value(values[j])}})
+		}
+		op := "? > ?"
+		if k.Desc {
+			op = "? < ?"
+		}
+		and = append(and, clause.Expr{SQL: op, Vars: []any{k.expr, k.value(values[i])}})
+		or = append(or, clause.And(and...))
+	}
+	return clause.Or(or...)
+}
+
+func filterClause(f Filter, field *schema.Field) (clause.Expression, error) {
+	col := clause.Column{Name: f.Column}
+	switch f.Op {
+	case OpLike:
+		return clause.Expr{SQL: "? ILIKE ?", Vars: []any{col, "%" + escapeLike(f.Value) + "%"}}, nil
+	case OpIn:
+		raw := strings.Split(f.Value, ",")
+		values := make([]any, len(raw))
+		for i, r := range raw {
+			v, err := convert(field, r)
+			if err != nil {
+				return nil, filterError(f)
+			}
+			values[i] = v
+		}
+		return clause.IN{Column: col, Values: values}, nil
+	}
+	v, err := convert(field, f.Value)
+	if err != nil {
+		return nil, filterError(f)
+	}
+	return clause.Expr{SQL: "? " + sqlOps[f.Op] + " ?", Vars: []any{col, v}}, nil
+}
+
+// convert parses a filter value into the field's Go type, so numbers, bools
+// and times are compared as such. Bare strings are accepted for any type
+// that unmarshals from a JSON string (e.g. time.Time).
+func convert(field *schema.Field, raw string) (any, error) {
+	ptr := reflect.New(field.FieldType)
+	if err := json.Unmarshal([]byte(raw), ptr.Interface()); err != nil {
+		if err := json.Unmarshal([]byte(strconv.Quote(raw)), ptr.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+func filterError(f Filter) error {
+	return invalid(ParamFilter, "type", "invalid value for "+strconv.Quote(f.Field))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func encodeCursor(ctx context.Context, signature string, fields []*schema.Field, item any) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	row := reflect.ValueOf(item).Elem()
+	c := cursor{Sort: signature, Values: make([]json.RawMessage, len(fields))}
+	for i, f := range fields {
+		v, _ := f.ValueOf(ctx, row)
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = b
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(raw, signature string, fields []*schema.Field) ([]any, error) {
+	bad := invalid(ParamCursor, "format", "is invalid or was issued for a different sort")
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, bad
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != signature || len(c.Values) != len(fields) {
+		return nil, bad
+	}
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		ptr := reflect.New(f.FieldType)
+		if err := json.Unmarshal(c.Values[i], ptr.Interface()); err != nil {
+			return nil, bad
+		}
+		values[i] = ptr.Elem().Interface()
+	}
+	return values, nil
+}
+
+// bareColumn strips the table qualifier: "employees.name" -> "name".
+func bareColumn(column string) string {
+	if i := strings.LastIndexByte(column, '.'); i >= 0 {
+		return column[i+1:]
+	}
+	return column
+}
@@ -0,0 +1,284 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+type item struct {
+	ID        uint
+	Name      string
+	Position  string // NULL in the database scans as ""
+	Rank      *int
+	CreatedAt time.Time
+}
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+// keysFor resolves the keyset of req against item.
+func keysFor(t *testing.T, db *gorm.DB, req Request) ([]*schema.Field, []key) {
+	t.Helper()
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	var fields []*schema.Field
+	var keys []key
+	for _, s := range req.order() {
+		f := stmt.Schema.LookUpField(bareColumn(s.Column))
+		fields = append(fields, f)
+		keys = append(keys, keyOf(s, f))
+	}
+	return fields, keys
+}
+
+var itemSpec = Spec{Fields: map[string]Field{
+	"id":         {Column: "id"},
+	"name":       {Column: "name"},
+	"position":   {Column: "position", Nullable: true},
+	"rank":       {Column: "rank"},
+	"created_at": {Column: "created_at"},
+}}
+
+func TestCursorRoundTrip(t *testing.T) {
+	db, _ := newMockDB(t)
+	req, err := itemSpec.Request("", "", "-created_at,position,rank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, _ := keysFor(t, db, req)
+	rank := 3
+	at := time.Date(2024, 1, 17, 9, 30, 0, 0, time.UTC)
+
+	for _, it := range []item{
+		{ID: 7, Position: "Stylist", Rank: &rank, CreatedAt: at},
+		{ID: 8, CreatedAt: at},
+	} {
+		raw, err := encodeCursor(context.Background(), req.signature(), fields, &it)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeCursor(raw, req.signature(), fields)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		want := []any{it.CreatedAt, it.Position, it.Rank, it.ID}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("values = %#v, want %#v", got, want)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsTampering(t *testing.T) {
+	db, _ := newMockDB(t)
+	req, err := itemSpec.Request("", "", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, _ := keysFor(t, db, req)
+	valid, err := encodeCursor(context.Background(), req.signature(), fields, &item{ID: 1, Name: "Ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name      string
+		raw       string
+		signature string
+	}{
+		{name: "not base64", raw: "%%%"},
+		{name: "not json", raw: encode("{")},
+		{name: "other sort", raw: valid, signature: "-name,-id"},
+		{name: "missing value", raw: encode(`{"s":"name,id","v":["Ann"]}`)},
+		{name: "extra value", raw: encode(`{"s":"name,id","v":["Ann",1,2]}`)},
+		{name: "wrong type", raw: encode(`{"s":"name,id","v":["Ann","one"]}`)},
+		{name: "padded", raw: valid + "=="},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			signature := req.signature()
+			if tc.signature != "" {
+				signature = tc.signature
+			}
+			_, err := decodeCursor(tc.raw, signature, fields)
+			if got := invalidField(err); got != ParamCursor {
+				t.Fatalf("err = %v, want a validation error on %q", err, ParamCursor)
+			}
+		})
+	}
+}
+
+func TestKeysetClauses(t *testing.T) {
+	rank := 2
+	at := time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		sort   string
+		values []any
+		where  string
+		order  string
+		vars   []any
+	}{
+		{
+			name:   "ascending",
+			sort:   "name",
+			values: []any{"Ann", uint(4)},
+			where:  `("name" > $1 OR ("name" = $2 AND "id" > $3))`,
+			order:  `"name", "id"`,
+			vars:   []any{"Ann", "Ann", uint(4)},
+		},
+		{
+			name:   "descending with a descending key",
+			sort:   "-created_at",
+			values: []any{at, uint(4)},
+			where:  `("created_at" < $1 OR ("created_at" = $2 AND "id" < $3))`,
+			order:  `"created_at" DESC, "id" DESC`,
+			vars:   []any{at, at, uint(4)},
+		},
+		{
+			name:   "mixed directions",
+			sort:   "-created_at,name",
+			values: []any{at, "Ann", uint(4)},
+			where: `("created_at" < $1 OR ("created_at" = $2 AND "name" > $3) OR ` +
+				`("created_at" = $4 AND "name" = $5 AND "id" > $6))`,
+			order: `"created_at" DESC, "name", "id"`,
+			vars:  []any{at, at, "Ann", at, "Ann", uint(4)},
+		},
+		{
+			name:   "nullable column compares as its zero value",
+			sort:   "position",
+			values: []any{"", uint(4)},
+			where:  `(COALESCE("position", $1) > $2 OR (COALESCE("position", $3) = $4 AND "id" > $5))`,
+			order:  `COALESCE("position", $6), "id"`,
+			vars:   []any{"", "", "", "", uint(4), ""},
+		},
+		{
+			name:   "pointer column is nullable",
+			sort:   "-rank",
+			values: []any{(*int)(nil), uint(4)},
+			where:  `(COALESCE("rank", $1) < $2 OR (COALESCE("rank", $3) = $4 AND "id" < $5))`,
+			order:  `COALESCE("rank", $6) DESC, "id" DESC`,
+			vars:   []any{0, 0, 0, 0, uint(4), 0},
+		},
+		{
+			name:   "pointer value is dereferenced",
+			sort:   "rank",
+			values: []any{&rank, uint(4)},
+			where:  `(COALESCE("rank", $1) > $2 OR (COALESCE("rank", $3) = $4 AND "id" > $5))`,
+			order:  `COALESCE("rank", $6), "id"`,
+			vars:   []any{0, 2, 0, 2, uint(4), 0},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, _ := newMockDB(t)
+			req, err := itemSpec.Request("", "", tc.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, keys := keysFor(t, db, req)
+			stmt := db.Session(&gorm.Session{DryRun: true}).
+				Where(afterClause(keys, tc.values)).
+				Order(orderClause(keys)).
+				Find(&[]item{}).Statement
+
+			want := `SELECT * FROM "items" WHERE ` + tc.where + ` ORDER BY ` + tc.order
+			if got := stmt.SQL.String(); got != want {
+				t.Errorf("sql =\n%s\nwant\n%s", got, want)
+			}
+			if !reflect.DeepEqual(stmt.Vars, tc.vars) {
+				t.Errorf("vars = %#v, want %#v", stmt.Vars, tc.vars)
+			}
+		})
+	}
+}
+
+func TestFindPagesOverNullRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	req, err := itemSpec.Request("2", "", "position")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols := []string{"id", "name", "position", "rank", "created_at"}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT \* FROM "items" ORDER BY COALESCE\("position", \$1\), "id" LIMIT \$2`).
+		WithArgs("", 3).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(1, "Ann", nil, nil, time.Now()).
+			AddRow(5, "Bob", nil, nil, time.Now()).
+			AddRow(2, "Cid", "Stylist", nil, time.Now()))
+
+	page, err := Find[item](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("page = %+v", page)
+	}
+
+	// The second page starts after the last NULL row instead of skipping
+	// the rest of them.
+	req.Cursor = page.NextCursor
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT \* FROM "items" WHERE \(COALESCE\("position", \$1\) > \$2 OR \(COALESCE\("position", \$3\) = \$4 AND "id" > \$5\)\) ORDER BY`).
+		WithArgs("", "", "", "", 5, "", 3).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(2, "Cid", "Stylist", nil, time.Now()))
+
+	page, err = Find[item](db, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != 2 || page.NextCursor != "" {
+		t.Fatalf("page = %+v", page)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFindRejectsBadFilterValue(t *testing.T) {
+	db, _ := newMockDB(t)
+	spec := Spec{Fields: map[string]Field{"rank": {Column: "rank", Ops: []Op{OpEq, OpIn}}}}
+	for _, filter := range []string{"rank:eq:high", "rank:in:1,x"} {
+		req, err := spec.Request("", "", "", filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Find[item](db, req); invalidField(err) != ParamFilter {
+			t.Errorf("%s: err = %v, want a validation error on %q", filter, err, ParamFilter)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := escapeLike(`50%_off\`); got != `50\%\_off\\` {
+		t.Errorf("escapeLike = %q", got)
+	}
+}
@@ -0,0 +1,230 @@
+// Package server runs a TimeHub service until SIGINT/SIGTERM and then shuts
+// it down in order: readiness goes false, listeners stop accepting and drain
+// in-flight requests, background workers stop, and finally resources
+// registered with OnShutdown (DB pool, tracer) are closed.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+
+	"github.com/vipos89/timehub/pkg/health"
+	"github.com/vipos89/timehub/pkg/logger"
+)
+
+// Worker is a background loop that must return once ctx is cancelled.
+type Worker func(ctx context.Context) error
+
+type worker struct {
+	name string
+	run  Worker
+}
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Runner owns the listeners and shutdown sequence of one service.
+type Runner struct {
+	service string
+	// timeout is the deadline for the whole shutdown sequence.
+	timeout time.Duration
+	// delay keeps the listeners open after readiness flips so load
+	// balancers can stop routing before connections are closed.
+	delay time.Duration
+
+	echo     *echo.Echo
+	httpAddr string
+	grpc     *grpc.Server
+	grpcAddr string
+	health   *health.Registry
+	workers  []worker
+	closers  []closer
+}
+
+// New creates a runner whose shutdown must finish within timeout.
+func New(service string, timeout, delay time.Duration) *Runner {
+	return &Runner{service: service, timeout: timeout, delay: delay}
+}
+
+// HTTP serves e on addr (":8080").
+func (r *Runner) HTTP(e *echo.Echo, addr string) *Runner {
+	r.echo, r.httpAddr = e, addr
+	return r
+}
+
+// GRPC serves s on addr (":50051").
+func (r *Runner) GRPC(s *grpc.Server, addr string) *Runner {
+	r.grpc, r.grpcAddr = s, addr
+	return r
+}
+
+// Health makes the runner mark the service unready when shutdown starts.
+func (r *Runner) Health(h *health.Registry) *Runner {
+	r.health = h
+	return r
+}
+
+// Worker starts fn in the background; it is cancelled after the listeners drain.
+func (r *Runner) Worker(name string, fn Worker) *Runner {
+	r.workers = append(r.workers, worker{name: name, run: fn})
+	return r
+}
+
+// OnShutdown registers a cleanup step such as closing the DB pool or the
+// tracer. Steps run last, once in-flight requests have drained and workers
+// have stopped, in reverse order of registration, like deferred calls. Call
+// Close instead of Run to release them when the service exits without
+// serving, e.g. after a one-off command.
+func (r *Runner) OnShutdown(name string, fn func(ctx context.Context) error) *Runner {
+	r.closers = append(r.closers, closer{name: name, fn: fn})
+	return r
+}
+
+// Run blocks until a termination signal arrives or a listener fails, then
+// shuts down. The returned error joins the failures of every step.
+func (r *Runner) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return r.RunContext(ctx)
+}
+
+// RunContext is Run with the shutdown trigger supplied by the caller.
+func (r *Runner) RunContext(ctx context.Context) error {
+	log := logger.Named("server")
+	errc := make(chan error, 2)
+
+	if r.echo != nil {
+		go func() {
+			log.Info("HTTP server listening", "service", r.service, "addr", r.httpAddr)
+			if err := r.echo.Start(r.httpAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errc <- fmt.Errorf("http server: %w", err)
+			}
+		}()
+	}
+	if r.grpc != nil {
+		lis, err := net.Listen("tcp", r.grpcAddr)
+		if err != nil {
+			return fmt.Errorf("grpc listen: %w", err)
+		}
+		go func() {
+			log.Info("gRPC server listening", "service", r.service, "addr", r.grpcAddr)
+			if err := r.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				errc <- fmt.Errorf("grpc server: %w", err)
+			}
+		}()
+	}
+
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+	var wg sync.WaitGroup
+	for _, w := range r.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.run(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Error("Worker stopped", "worker", w.name, "error", err)
+			}
+		}()
+	}
+
+	var errs []error
+	select {
+	case <-ctx.Done():
+		log.Info("Shutdown signal received", "service", r.service)
+	case err := <-errc:
+		log.Error("Server failed", "service", r.service, "error", err)
+		errs = append(errs, err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if r.health != nil {
+		r.health.SetReady(false)
+	}
+	if r.delay > 0 {
+		select {
+		case <-time.After(r.delay):
+		case <-shutdownCtx.Done():
+		}
+	}
+
+	if r.echo != nil {
+		if err := r.echo.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+		}
+	}
+	if r.grpc != nil {
+		stopGRPC(shutdownCtx, r.grpc)
+	}
+
+	cancelWorkers()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		errs = append(errs, errors.New("workers did not stop before the shutdown deadline"))
+	}
+
+	if err := r.close(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		log.Error("Shutdown finished with errors", "service", r.service, "error", err)
+	} else {
+		log.Info("Shutdown complete", "service", r.service)
+	}
+	return err
+}
+
+// Close runs the OnShutdown steps within the shutdown timeout. The returned
+// error joins the failures of every step.
+func (r *Runner) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	return r.close(ctx)
+}
+
+func (r *Runner) close(ctx context.Context) error {
+	var errs []error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		c := r.closers[i]
+		if err := c.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopGRPC drains gRPC streams, forcing them closed at the deadline.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
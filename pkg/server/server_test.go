@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// closing registers steps a, b and c on r; b fails. The returned slice
+// records the order they ran in.
+func closing(r *Runner) *[]string {
+	var ran []string
+	for _, name := range []string{"a", "b", "c"} {
+		r.OnShutdown(name, func(context.Context) error {
+			ran = append(ran, name)
+			if name == "b" {
+				return errors.New("busy")
+			}
+			return nil
+		})
+	}
+	return &ran
+}
+
+func TestClose(t *testing.T) {
+	r := New("test", time.Second, 0)
+	ran := closing(r)
+
+	err := r.Close()
+	if err == nil || err.Error() != "b: busy" {
+		t.Errorf("err = %v, want b: busy", err)
+	}
+	if want := []string{"c", "b", "a"}; !slices.Equal(*ran, want) {
+		t.Errorf("ran %v, want %v", *ran, want)
+	}
+}
+
+func TestRunContextReleasesResources(t *testing.T) {
+	r := New("test", time.Second, 0)
+	ran := closing(r)
+	stopped := false
+	r.Worker("loop", func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped = true
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.RunContext(ctx); err == nil || err.Error() != "b: busy" {
+		t.Errorf("err = %v, want b: busy", err)
+	}
+	if !stopped {
+		t.Error("worker still running when resources were released")
+	}
+	if want := []string{"c", "b", "a"}; !slices.Equal(*ran, want) {
+		t.Errorf("ran %v, want %v", *ran, want)
+	}
+}
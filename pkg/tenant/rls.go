@@ -0,0 +1,118 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Setting is the Postgres run-time parameter row-level-security policies
+// compare against. An unset or empty setting matches no rows:
+//
+//	CREATE POLICY tenant_isolation ON branches USING (
+//	    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+const Setting = "app.company_id"
+
+const txKey = "tenant:tx"
+
+// RLS is a GORM plugin that sets app.company_id for every statement issued
+// with a tenant context, turning on the policies installed by the migrations.
+// Statements from System contexts switch to SystemRole, a role with
+// BYPASSRLS, and statements without either see no tenant rows at all.
+// Writes already run in GORM's default transaction; queries are wrapped in a
+// short read transaction so the setting and role are local to them and never
+// leak to other users of the pooled connection.
+type RLS struct {
+	SystemRole string
+}
+
+func (RLS) Name() string { return "tenant:rls" }
+
+func (r RLS) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("gorm:begin_transaction").Register("tenant:set_create", r.setLocal),
+		cb.Update().After("gorm:begin_transaction").Register("tenant:set_update", r.setLocal),
+		cb.Delete().After("gorm:begin_transaction").Register("tenant:set_delete", r.setLocal),
+		cb.Query().Before("gorm:query").Register("tenant:begin_query", r.beginQuery),
+		cb.Query().After("gorm:after_query").Register("tenant:end_query", endQuery),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setLocal scopes the running transaction to the tenant, or to the system
+// role for System contexts.
+func (r RLS) setLocal(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if !scoped(ctx) || db.Error != nil {
+		return
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+		return
+	}
+	var err error
+	if id, ok := FromContext(ctx); ok {
+		_, err = db.Statement.ConnPool.ExecContext(ctx,
+			"SELECT set_config('"+Setting+"', $1, true)", strconv.FormatUint(uint64(id), 10))
+	} else {
+		_, err = db.Statement.ConnPool.ExecContext(ctx, "SET LOCAL ROLE "+QuoteIdent(r.SystemRole))
+	}
+	if err != nil {
+		db.AddError(err)
+	}
+}
+
+// beginQuery opens a transaction for a tenant or System query that is not
+// already in one.
+func (r RLS) beginQuery(db *gorm.DB) {
+	if !scoped(db.Statement.Context) || db.Error != nil {
+		return
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+		beginner, ok := db.Statement.ConnPool.(gorm.TxBeginner)
+		if !ok {
+			return
+		}
+		tx, err := beginner.BeginTx(db.Statement.Context, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		db.InstanceSet(txKey, db.Statement.ConnPool)
+		db.Statement.ConnPool = tx
+	}
+	r.setLocal(db)
+}
+
+// endQuery commits the transaction opened by beginQuery and restores the pool.
+func endQuery(db *gorm.DB) {
+	pool, ok := db.InstanceGet(txKey)
+	if !ok {
+		return
+	}
+	if tx, ok := db.Statement.ConnPool.(*sql.Tx); ok {
+		if db.Error != nil {
+			tx.Rollback()
+		} else if err := tx.Commit(); err != nil {
+			db.AddError(err)
+		}
+	}
+	db.Statement.ConnPool = pool.(gorm.ConnPool)
+}
+
+func scoped(ctx context.Context) bool {
+	_, ok := FromContext(ctx)
+	return ok || IsSystem(ctx)
+}
+
+// QuoteIdent quotes a Postgres identifier such as a role name.
+func QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,180 @@
+// Package tenant carries the caller's company through context.Context and
+// restricts repository queries to it.
+//
+// The company comes from the X-Company-ID header, which the gateway sets for
+// authenticated requests, or from the resource a request targets. Repositories
+// apply Scope to every query on a tenant-owned table and call
+// Authorize/Guard before touching rows by ID, so a request for another
+// company's data is logged and answered with 404. All of them fail closed: a
+// context without a tenant gets ErrNoTenant (403) instead of every company's
+// rows. Lookups that must see every company (access checks, the public
+// search) mark their context with System and query without Scope.
+package tenant
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/metrics"
+	"github.com/vipos89/timehub/pkg/middleware"
+)
+
+// Header carries the caller's company ID.
+const Header = middleware.HeaderCompanyID
+
+type scopeKey struct{}
+
+// scope is what a context may see: one company, or every company for System.
+type scope struct {
+	companyID uint
+	system    bool
+}
+
+// ErrNoTenant rejects queries from a context that is neither scoped to a
+// company nor marked System.
+var ErrNoTenant = erru.E(erru.CodeForbidden, "The request is not scoped to a company")
+
+var crossTenant = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "tenant_cross_access_total",
+	Help:      "Requests denied because they targeted another company's data.",
+}, []string{"resource"})
+
+// WithCompany returns ctx scoped to companyID.
+func WithCompany(ctx context.Context, companyID uint) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope{companyID: companyID})
+}
+
+// Enter scopes ctx to companyID, the owner of a resource the caller may
+// access, unless ctx already belongs to another company.
+func Enter(ctx context.Context, resource string, companyID uint) (context.Context, error) {
+	if id, ok := FromContext(ctx); ok && id != companyID {
+		return ctx, denied(ctx, resource, "company_id", companyID)
+	}
+	return WithCompany(ctx, companyID), nil
+}
+
+// System returns ctx for lookups that must see every company. Such queries
+// do not use Scope; with row-level security they run as the bypass role.
+func System(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope{system: true})
+}
+
+// IsSystem reports whether ctx was marked by System.
+func IsSystem(ctx context.Context) bool {
+	s, _ := ctx.Value(scopeKey{}).(scope)
+	return s.system
+}
+
+// FromContext returns the caller's company, if any.
+func FromContext(ctx context.Context) (uint, bool) {
+	s, _ := ctx.Value(scopeKey{}).(scope)
+	return s.companyID, s.companyID != 0
+}
+
+// ID returns the caller's company or 0.
+func ID(ctx context.Context) uint {
+	id, _ := FromContext(ctx)
+	return id
+}
+
+// Middleware reads X-Company-ID into the request context. Requests without
+// it carry no tenant, so their tenant queries fail until a handler scopes
+// them to the company owning the requested resource.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		raw := c.Request().Header.Get(Header)
+		if raw == "" {
+			return next(c)
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			return erru.E(erru.CodeBadRequest, "Invalid "+Header+" header")
+		}
+		req := c.Request()
+		c.SetRequest(req.WithContext(WithCompany(req.Context(), uint(id))))
+		return next(c)
+	}
+}
+
+// Require rejects requests without a tenant with 403. Routes that only make
+// sense inside one company (staff schedules, shifts) use it.
+func Require(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, ok := FromContext(c.Request().Context()); !ok {
+			return ErrNoTenant
+		}
+		return next(c)
+	}
+}
+
+// Scope restricts a query to the caller's company through column
+// (e.g. "company_id" or "employees.company_id"). Without a tenant the query
+// fails with ErrNoTenant; it never runs unscoped, not even for System.
+func Scope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		id, ok := FromContext(ctx)
+		if !ok {
+			db.AddError(ErrNoTenant)
+			return db
+		}
+		return db.Where(column+" = ?", id)
+	}
+}
+
+// Authorize denies access to companyID unless it is the caller's company.
+// System contexts may access every company.
+func Authorize(ctx context.Context, resource string, companyID uint) error {
+	id, ok := FromContext(ctx)
+	switch {
+	case ok && id == companyID:
+		return nil
+	case ok:
+		return denied(ctx, resource, "company_id", companyID)
+	case IsSystem(ctx):
+		return nil
+	}
+	return ErrNoTenant
+}
+
+// Guard denies access when the row id of table exists but belongs to
+// another company. Missing rows are left to the caller.
+func Guard(ctx context.Context, db *gorm.DB, table string, id uint) error {
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		if IsSystem(ctx) {
+			return nil
+		}
+		return ErrNoTenant
+	}
+	var owners []uint
+	err := db.WithContext(System(ctx)).Table(table).Where("id = ?", id).Limit(1).Pluck("company_id", &owners).Error
+	if err != nil {
+		return err
+	}
+	if len(owners) == 0 || owners[0] == tenantID {
+		return nil
+	}
+	return denied(ctx, table, "resource_id", id, "company_id", owners[0])
+}
+
+// Deny logs a cross-tenant access attempt on the row id of resource and
+// returns a not-found error, so callers cannot probe which IDs exist in
+// other companies.
+func Deny(ctx context.Context, resource string, id uint) error {
+	return denied(ctx, resource, "resource_id", id)
+}
+
+func denied(ctx context.Context, resource string, attrs ...any) error {
+	crossTenant.WithLabelValues(resource).Inc()
+	logger.FromContext(ctx).Warn("Cross-tenant access denied",
+		append([]any{"tenant_id", ID(ctx), "resource", resource}, attrs...)...)
+	return erru.ErrNotFound
+}
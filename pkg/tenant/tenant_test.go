@@ -0,0 +1,120 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+)
+
+type branch struct {
+	ID        uint
+	CompanyID uint
+}
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		DryRun: true,
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestScope(t *testing.T) {
+	db := newDryRunDB(t)
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantSQL string
+		wantErr error
+	}{
+		{name: "tenant", ctx: WithCompany(context.Background(), 3), wantSQL: `SELECT * FROM "branches" WHERE company_id = $1`},
+		{name: "no tenant", ctx: context.Background(), wantErr: ErrNoTenant},
+		{name: "system", ctx: System(context.Background()), wantErr: ErrNoTenant},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var rows []branch
+			stmt := db.WithContext(tc.ctx).Scopes(Scope(tc.ctx, "company_id")).Find(&rows)
+			if !errors.Is(stmt.Error, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", stmt.Error, tc.wantErr)
+			}
+			if tc.wantErr == nil && stmt.Statement.SQL.String() != tc.wantSQL {
+				t.Errorf("sql = %s, want %s", stmt.Statement.SQL.String(), tc.wantSQL)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{name: "own company", ctx: WithCompany(context.Background(), 3)},
+		{name: "other company", ctx: WithCompany(context.Background(), 4), want: erru.ErrNotFound},
+		{name: "system", ctx: System(context.Background())},
+		{name: "no tenant", ctx: context.Background(), want: ErrNoTenant},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Authorize(tc.ctx, "branches", 3); !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnter(t *testing.T) {
+	ctx, err := Enter(context.Background(), "companies", 3)
+	if id, _ := FromContext(ctx); err != nil || id != 3 {
+		t.Fatalf("tenant = %d, err = %v, want company 3", id, err)
+	}
+	if _, err := Enter(WithCompany(context.Background(), 4), "companies", 3); !errors.Is(err, erru.ErrNotFound) {
+		t.Errorf("err = %v, want a denial for another tenant", err)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = middleware.ErrorHandler
+	e.GET("/shifts", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, Middleware, Require)
+
+	for _, tc := range []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusForbidden},
+		{"3", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/shifts", nil)
+		if tc.header != "" {
+			req.Header.Set(Header, tc.header)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("X-Company-ID %q: status = %d, want %d", tc.header, rec.Code, tc.want)
+		}
+	}
+}
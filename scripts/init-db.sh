@@ -7,4 +7,18 @@ psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-E
 	CREATE DATABASE booking_db;
 	CREATE DATABASE crm_db;
 	CREATE DATABASE report_db;
+
+	-- Bypasses row-level security for migrations and cross-company lookups
+	-- (database.system_role). Services switch to it with SET ROLE.
+	CREATE ROLE timehub_system NOLOGIN BYPASSRLS;
+	GRANT timehub_system TO "$POSTGRES_USER";
 EOSQL
+
+# Migrations run as the system role: it creates the schemas, extensions and
+# tables, so it needs CREATE on every database and its public schema.
+for db in "$POSTGRES_DB" auth_db company_db booking_db crm_db report_db; do
+	psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$db" <<-EOSQL
+		GRANT CREATE ON DATABASE "$db" TO timehub_system;
+		GRANT CREATE ON SCHEMA public TO timehub_system;
+	EOSQL
+done
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/url"
 
@@ -8,8 +9,15 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 
+	"github.com/vipos89/timehub/pkg/clients"
 	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/health"
+	"github.com/vipos89/timehub/pkg/identity"
 	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/metrics"
+	customMiddleware "github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/server"
+	"github.com/vipos89/timehub/pkg/tracing"
 	_ "github.com/vipos89/timehub/services/api-gateway/docs" // for swagger docs
 )
 
@@ -19,67 +27,88 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
-	logger.Init()
-	cfg := config.Load()
+	cfg := config.MustLoad("api-gateway")
+	logger.Init(cfg.Log)
+	logger.Info("Configuration loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to init tracing", "error", err)
+		log.Fatalf("Failed to init tracing: %v", err)
+	}
+
+	srv := server.New(cfg.Service, cfg.Timeouts.Shutdown, cfg.Timeouts.ShutdownDelay)
+	srv.OnShutdown("tracing", shutdownTracing)
 
 	e := echo.New()
+	e.HTTPErrorHandler = customMiddleware.ErrorHandler
+	e.Validator = customMiddleware.NewValidator()
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Use(tracing.Middleware(cfg.Service))
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		// Forward the ID upstream so every service logs the same correlation ID.
+		RequestIDHandler: func(c echo.Context, id string) {
+			c.Request().Header.Set(echo.HeaderXRequestID, id)
+		},
+	}))
+	e.Use(metrics.Middleware(cfg.Service))
 	e.Use(middleware.Recover())
+	e.Use(customMiddleware.RequestLogger)
 	e.Use(middleware.CORS())
+	// Identity headers sent by clients are dropped; the services only see
+	// X-User-ID and X-Company-ID taken from a verified access token.
+	auth := clients.NewAuth(cfg.Upstreams.AuthServiceURL, clients.OptionsFrom(cfg.Upstreams))
+	e.Use(identity.Authenticate(auth, cfg.Auth.IdentitySecret.Reveal()))
 
 	// Proxy Config
-	authURL, _ := url.Parse(cfg.AuthServiceURL)
-	companyURL, _ := url.Parse(cfg.CompanyServiceURL)
+	authURL, _ := url.Parse(cfg.Upstreams.AuthServiceURL)
+	companyURL, _ := url.Parse(cfg.Upstreams.CompanyServiceURL)
 	// API Proxy
 	// Auth Service
-	authGroup := e.Group("/auth", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: authURL},
-	})))
+	authGroup := e.Group("/auth", proxyTo(authURL))
 	authGroup.Any("/*", func(c echo.Context) error { return nil })
 
 	// Company Service
-	companyGroup := e.Group("/companies", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: companyURL},
-	})))
+	companyGroup := e.Group("/companies", proxyTo(companyURL))
 	companyGroup.Any("/*", func(c echo.Context) error { return nil })
 
-	branchGroup := e.Group("/branches", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: companyURL},
-	})))
+	branchGroup := e.Group("/branches", proxyTo(companyURL))
 	branchGroup.Any("/*", func(c echo.Context) error { return nil })
 
-	employeeGroup := e.Group("/employees", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: companyURL},
-	})))
+	employeeGroup := e.Group("/employees", proxyTo(companyURL))
 	employeeGroup.Any("/*", func(c echo.Context) error { return nil })
 
-	servicesGroup := e.Group("/services", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: companyURL},
-	})))
+	servicesGroup := e.Group("/services", proxyTo(companyURL))
 	servicesGroup.Any("/*", func(c echo.Context) error { return nil })
 
+	optionsGroup := e.Group("/options", proxyTo(companyURL))
+	optionsGroup.Any("/*", func(c echo.Context) error { return nil })
+
+	bundlesGroup := e.Group("/bundles", proxyTo(companyURL))
+	bundlesGroup.Any("/*", func(c echo.Context) error { return nil })
+
+	resourceTypesGroup := e.Group("/resource-types", proxyTo(companyURL))
+	resourceTypesGroup.Any("/*", func(c echo.Context) error { return nil })
+
+	resourcesGroup := e.Group("/resources", proxyTo(companyURL))
+	resourcesGroup.Any("/*", func(c echo.Context) error { return nil })
+
+	publicBranchesGroup := e.Group("/public/branches", proxyTo(companyURL))
+	publicBranchesGroup.Any("/*", func(c echo.Context) error { return nil })
+
 	// Booking Service
-	bookingURL, _ := url.Parse(cfg.BookingServiceURL)
-	bookingGroup := e.Group("/bookings", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: bookingURL},
-	})))
+	bookingURL, _ := url.Parse(cfg.Upstreams.BookingServiceURL)
+	bookingGroup := e.Group("/bookings", proxyTo(bookingURL))
 	bookingGroup.Any("/*", func(c echo.Context) error { return nil })
 
-	slotsGroup := e.Group("/slots", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: bookingURL},
-	})))
+	slotsGroup := e.Group("/slots", proxyTo(bookingURL))
 	slotsGroup.Any("/*", func(c echo.Context) error { return nil })
 
-	schedulesGroup := e.Group("/schedules", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: bookingURL},
-	})))
+	schedulesGroup := e.Group("/schedules", proxyTo(bookingURL))
 	schedulesGroup.Any("/*", func(c echo.Context) error { return nil })
 
-	shiftsGroup := e.Group("/shifts", middleware.Proxy(middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
-		{URL: bookingURL},
-	})))
+	shiftsGroup := e.Group("/shifts", proxyTo(bookingURL))
 	shiftsGroup.Any("/*", func(c echo.Context) error { return nil })
 
 	// Swagger Proxy (Aggregate Documentation)
@@ -88,6 +117,7 @@ func main() {
 		Balancer: middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
 			{URL: authURL},
 		}),
+		Transport: tracing.Transport(nil),
 		Rewrite: map[string]string{
 			"^/swagger/auth/*": "/swagger/$1",
 		},
@@ -98,6 +128,7 @@ func main() {
 		Balancer: middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
 			{URL: companyURL},
 		}),
+		Transport: tracing.Transport(nil),
 		Rewrite: map[string]string{
 			"^/swagger/company/*": "/swagger/$1",
 		},
@@ -108,6 +139,7 @@ func main() {
 		Balancer: middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
 			{URL: bookingURL},
 		}),
+		Transport: tracing.Transport(nil),
 		Rewrite: map[string]string{
 			"^/swagger/booking/*": "/swagger/$1",
 		},
@@ -117,20 +149,49 @@ func main() {
 	e.File("/swagger/index.html", "docs/index_agg.html")
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	// Health Check
-	// @Summary Health Check
-	// @Description Check if the service is running
+	// Metrics
+	e.GET("/metrics", metrics.Handler())
+
+	// Health Checks
+	checks := health.NewRegistry(cfg.Service, cfg.Timeouts.HealthCheck)
+	checks.Mount(e)
+
+	// Aggregated health of every upstream; unreachable services degrade the status.
+	// @Summary Aggregated health
+	// @Description Fan out to /readyz of every upstream service
 	// @Tags health
-	// @Produce plain
-	// @Success 200 {string} string "API Gateway is running"
-	// @Router /health [get]
-	e.GET("/health", func(c echo.Context) error {
-		return c.String(200, "API Gateway is running")
-	})
+	// @Produce json
+	// @Success 200 {object} health.Report
+	// @Failure 503 {object} health.Report
+	// @Router /health/all [get]
+	upstreams := health.NewRegistry(cfg.Service, cfg.Timeouts.HealthCheck)
+	for name, target := range map[string]string{
+		"auth-service":    cfg.Upstreams.AuthServiceURL,
+		"company-service": cfg.Upstreams.CompanyServiceURL,
+		"booking-service": cfg.Upstreams.BookingServiceURL,
+		"crm-service":     cfg.Upstreams.CRMServiceURL,
+		"report-service":  cfg.Upstreams.ReportServiceURL,
+	} {
+		upstreams.RegisterOptional(name, health.Service(nil, target))
+	}
+	e.GET("/health/all", upstreams.Aggregate)
 
-	logger.Info("Starting API Gateway", "port", cfg.HTTPPort)
-	if err := e.Start(":" + cfg.HTTPPort); err != nil {
+	srv.HTTP(e, ":"+cfg.HTTP.Port).Health(checks)
+
+	logger.Info("Starting API Gateway", "port", cfg.HTTP.Port)
+	if err := srv.Run(); err != nil {
 		logger.Error("Failed to start server", "error", err)
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// proxyTo forwards requests to target through a traced transport, so the
+// upstream service continues the gateway's trace via the traceparent header.
+func proxyTo(target *url.URL) echo.MiddlewareFunc {
+	return middleware.ProxyWithConfig(middleware.ProxyConfig{
+		Balancer: middleware.NewRoundRobinBalancer([]*middleware.ProxyTarget{
+			{URL: target},
+		}),
+		Transport: tracing.Transport(nil),
+	})
+}
@@ -0,0 +1,44 @@
+// Package app assembles auth-service from its layers, so it can run as its
+// own binary or be mounted into the all-in-one timehub binary.
+package app
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/identity"
+	"github.com/vipos89/timehub/services/auth-service/internal/delivery/http"
+	"github.com/vipos89/timehub/services/auth-service/internal/repository/postgres"
+	"github.com/vipos89/timehub/services/auth-service/internal/usecase"
+	"github.com/vipos89/timehub/services/auth-service/migrations"
+)
+
+// Migrations is the service's versioned SQL schema.
+var Migrations = migrations.FS
+
+// Mount registers the auth routes on e. company checks memberships when
+// users sign in to a company.
+func Mount(e *echo.Echo, database *gorm.DB, cfg config.AuthConfig, company clients.CompanyClient, timeout time.Duration) {
+	http.NewAuthHandler(e, newAuthUsecase(database, cfg, company, timeout))
+}
+
+// Verifier checks access tokens directly against database, for binaries
+// that authenticate requests without a gateway in front.
+func Verifier(database *gorm.DB, cfg config.AuthConfig, timeout time.Duration) identity.Verifier {
+	return newAuthUsecase(database, cfg, nil, timeout)
+}
+
+func newAuthUsecase(database *gorm.DB, cfg config.AuthConfig, company clients.CompanyClient, timeout time.Duration) usecase.AuthUsecase {
+	userRepo := postgres.NewUserRepository(database)
+	keyRepo := postgres.NewSigningKeyRepository(database)
+	return usecase.NewAuthUsecase(userRepo, keyRepo, company, timeout, cfg.JWTSecret.Reveal(), cfg.IdentitySecret.Reveal(), cfg.TokenTTL)
+}
+
+// Keys manages the token signing keys stored in database.
+func Keys(database *gorm.DB, timeout time.Duration) usecase.KeyUsecase {
+	return usecase.NewKeyUsecase(postgres.NewSigningKeyRepository(database), timeout)
+}
@@ -1,22 +1,25 @@
 package main
 
 import (
+	"context"
 	"log"
-	"time"
+	"os"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 
+	"github.com/vipos89/timehub/pkg/clients"
 	"github.com/vipos89/timehub/pkg/config"
 	"github.com/vipos89/timehub/pkg/db"
+	"github.com/vipos89/timehub/pkg/health"
 	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/metrics"
 	customMiddleware "github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/server"
+	"github.com/vipos89/timehub/pkg/tracing"
+	"github.com/vipos89/timehub/services/auth-service/app"
 	_ "github.com/vipos89/timehub/services/auth-service/docs" // for swagger docs
-	"github.com/vipos89/timehub/services/auth-service/internal/delivery/http"
-	"github.com/vipos89/timehub/services/auth-service/internal/domain"
-	"github.com/vipos89/timehub/services/auth-service/internal/repository/postgres"
-	"github.com/vipos89/timehub/services/auth-service/internal/usecase"
 )
 
 // @title Auth Service API
@@ -25,57 +28,92 @@ import (
 // @host localhost:8081
 // @BasePath /
 func main() {
-	logger.Init()
-	cfg := config.Load() // Setup config to load JWT_SECRET
+	cfg := config.MustLoad("auth-service")
+	logger.Init(cfg.Log)
+	logger.Info("Configuration loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to init tracing", "error", err)
+		log.Fatalf("Failed to init tracing: %v", err)
+	}
+
+	srv := server.New(cfg.Service, cfg.Timeouts.Shutdown, cfg.Timeouts.ShutdownDelay)
+	srv.OnShutdown("tracing", shutdownTracing)
 
 	// Initialize DB (GORM) - connects to auth_db
-	database, err := db.ConnectDSN(cfg.DBUrl)
+	database, err := db.Open(cfg.Database)
 	if err != nil {
 		logger.Error("Failed to connect to database", "error", err)
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto Migrate
-	err = database.AutoMigrate(&domain.User{})
+	sqlDB, _ := database.DB()
+	srv.OnShutdown("postgres", func(context.Context) error { return sqlDB.Close() })
+	if err := metrics.RegisterDB(sqlDB, cfg.Service); err != nil {
+		logger.Error("Failed to register DB metrics", "error", err)
+	}
+
+	// Migrations
+	migrator, err := db.NewMigrator(sqlDB, app.Migrations, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to load migrations", "error", err)
+		log.Fatal(err)
+	}
+	migrator.WithRole(cfg.Database.SystemRole)
+	if len(cfg.Args) > 0 && cfg.Args[0] == "migrate" {
+		err := db.RunMigrateCommand(context.Background(), migrator, cfg.Args[1:], os.Stdout)
+		if cerr := srv.Close(); cerr != nil {
+			logger.Error("Failed to release resources", "error", cerr)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
 		logger.Error("Failed to migrate database", "error", err)
 		log.Fatal(err)
 	}
-
-	sqlDB, _ := database.DB()
-	defer sqlDB.Close()
+	logger.Info("Database schema is up to date", "applied", len(applied))
 
 	logger.Info("Connected to database")
 
-	// Init Layers
-	userRepo := postgres.NewUserRepository(database)
-	timeout := time.Duration(2) * time.Second
-	jwtSecret := "secret_key_change_me" // In real app, load from cfg.JWTSecret
-	authUsecase := usecase.NewAuthUsecase(userRepo, timeout, jwtSecret)
-
 	e := echo.New()
+	e.HTTPErrorHandler = customMiddleware.ErrorHandler
+	e.Validator = customMiddleware.NewValidator()
 
 	// Middleware
+	e.Use(tracing.Middleware(cfg.Service))
+	e.Use(middleware.RequestID())
+	e.Use(metrics.Middleware(cfg.Service))
 	e.Use(middleware.Recover())
 	e.Use(customMiddleware.RequestLogger)
 	e.Use(customMiddleware.PanicRecovery)
 
 	// Handlers
-	http.NewAuthHandler(e, authUsecase)
+	company := clients.NewCompany(cfg.Upstreams.CompanyServiceURL, clients.OptionsFrom(cfg.Upstreams))
+	app.Mount(e, database, cfg.Auth, company, cfg.Timeouts.Context)
 
 	// Swagger
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	// Health Check
-	e.GET("/health", func(c echo.Context) error {
-		if err := sqlDB.Ping(); err != nil {
-			return c.String(500, "Database unreachable")
-		}
-		return c.String(200, "Auth Service is healthy")
-	})
+	// Metrics
+	e.GET("/metrics", metrics.Handler())
+
+	// Health Checks
+	checks := health.NewRegistry(cfg.Service, cfg.Timeouts.HealthCheck)
+	checks.Register("postgres", health.DB(sqlDB))
+	if cfg.Broker.URL != "" {
+		checks.RegisterOptional("broker", health.TCP(cfg.Broker.URL.Reveal()))
+	}
+	checks.Mount(e)
+
+	srv.HTTP(e, ":"+cfg.HTTP.Port).Health(checks)
 
-	logger.Info("Starting Auth Service", "port", cfg.HTTPPort)
-	if err := e.Start(":" + cfg.HTTPPort); err != nil {
+	logger.Info("Starting Auth Service", "port", cfg.HTTP.Port)
+	if err := srv.Run(); err != nil {
 		logger.Error("Server failed", "error", err)
 		log.Fatalf("Server failed: %v", err)
 	}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/auth-service/internal/domain"
+)
+
+// registerErrors maps auth domain errors to problem codes.
+func registerErrors() {
+	erru.Register(domain.ErrUserAlreadyExists, erru.CodeConflict)
+	erru.Register(domain.ErrInvalidCredentials, erru.CodeUnauthorized)
+	erru.Register(domain.ErrInvalidToken, erru.CodeUnauthorized)
+	erru.Register(domain.ErrNotMember, erru.CodeForbidden)
+}
@@ -1,12 +1,12 @@
 package http
 
 import (
-	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/vipos89/timehub/pkg/erru"
-	"github.com/vipos89/timehub/services/auth-service/internal/domain"
+	"github.com/vipos89/timehub/pkg/middleware"
 	"github.com/vipos89/timehub/services/auth-service/internal/usecase"
 )
 
@@ -18,15 +18,18 @@ func NewAuthHandler(e *echo.Echo, us usecase.AuthUsecase) {
 	handler := &AuthHandler{
 		AuthUsecase: us,
 	}
+	registerErrors()
 
 	e.POST("/auth/register", handler.Register)
 	e.POST("/auth/login", handler.Login)
+	e.POST("/auth/unregister", handler.Unregister)
+	e.GET("/auth/verify", handler.Verify)
 }
 
 type registerRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role" validate:"required"` // owner, admin, master
+	Role     string `json:"role" validate:"required,oneof=owner admin master"`
 }
 
 // Register godoc
@@ -37,21 +40,19 @@ type registerRequest struct {
 // @Produce json
 // @Param input body registerRequest true "Register Input"
 // @Success 201 {object} domain.User
-// @Failure 400 {object} erru.AppError
-// @Failure 500 {object} erru.AppError
+// @Failure 400 {object} erru.Problem
+// @Failure 409 {object} erru.Problem
+// @Failure 500 {object} erru.Problem
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c echo.Context) error {
 	var req registerRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	user, err := h.AuthUsecase.Register(c.Request().Context(), req.Email, req.Password, req.Role)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserAlreadyExists) {
-			return c.JSON(http.StatusConflict, erru.New(http.StatusConflict, err.Error()))
-		}
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, user)
@@ -60,6 +61,9 @@ func (h *AuthHandler) Register(c echo.Context) error {
 type loginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// CompanyID signs in to one of the user's companies; its staff routes
+	// then work on that company.
+	CompanyID uint `json:"company_id"`
 }
 
 type loginResponse struct {
@@ -68,25 +72,73 @@ type loginResponse struct {
 
 // Login godoc
 // @Summary Login user
-// @Description Login with email and password to get JWT token
+// @Description Login with email and password to get JWT token. With company_id the token is scoped to that company, which the user must belong to.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body loginRequest true "Login Input"
 // @Success 200 {object} loginResponse
-// @Failure 400 {object} erru.AppError
-// @Failure 401 {object} erru.AppError
+// @Failure 400 {object} erru.Problem
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req loginRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	token, err := h.AuthUsecase.Login(c.Request().Context(), req.Email, req.Password)
+	token, err := h.AuthUsecase.Login(c.Request().Context(), req.Email, req.Password, req.CompanyID)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, erru.ErrUnauthorized)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, loginResponse{Token: token})
 }
+
+type unregisterRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Unregister godoc
+// @Summary Delete a user
+// @Description Deletes the account with the given credentials. company-service calls it to remove an employee's account when adding the employee fails.
+// @Tags auth
+// @Accept json
+// @Param input body unregisterRequest true "Credentials"
+// @Success 204
+// @Failure 400 {object} erru.Problem
+// @Failure 401 {object} erru.Problem
+// @Router /auth/unregister [post]
+func (h *AuthHandler) Unregister(c echo.Context) error {
+	var req unregisterRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.AuthUsecase.Unregister(c.Request().Context(), req.Email, req.Password); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Verify godoc
+// @Summary Verify an access token
+// @Description Checks the bearer token and returns its claims. The gateway calls it to authenticate requests.
+// @Tags auth
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} identity.Claims
+// @Failure 401 {object} erru.Problem
+// @Router /auth/verify [get]
+func (h *AuthHandler) Verify(c echo.Context) error {
+	scheme, token, ok := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return erru.E(erru.CodeUnauthorized, "Missing bearer token")
+	}
+	claims, err := h.AuthUsecase.Verify(c.Request().Context(), token)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, claims)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SigningKey is an HMAC secret for access tokens. The newest key that is not
+// retired signs new tokens and its KID goes into the token's "kid" header.
+// Without any key the configured JWT secret is used.
+type SigningKey struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	KID       string     `json:"kid" gorm:"column:kid;uniqueIndex;not null"`
+	Secret    string     `json:"-" gorm:"not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at"`
+}
+
+// ConfiguredKID stands for the configured JWT secret, which signs tokens
+// without a "kid". Retiring it stores a retired key with this KID and no
+// secret; tokens without a "kid" are refused from then on.
+const ConfiguredKID = "configured"
+
+type SigningKeyRepository interface {
+	// Transaction runs fn with a repository bound to a single database transaction.
+	Transaction(ctx context.Context, fn func(repo SigningKeyRepository) error) error
+	// Current returns the active key or nil when there is none.
+	Current(ctx context.Context) (*SigningKey, error)
+	// Active returns the unretired key with kid or nil when there is none.
+	Active(ctx context.Context, kid string) (*SigningKey, error)
+	List(ctx context.Context) ([]SigningKey, error)
+	Create(ctx context.Context, key *SigningKey) error
+	// RetireOthers retires every active key except keepID and returns them.
+	RetireOthers(ctx context.Context, keepID uint, at time.Time) ([]SigningKey, error)
+	// RetireConfigured retires the configured JWT secret and returns its
+	// key, or nil when it was retired before.
+	RetireConfigured(ctx context.Context, at time.Time) (*SigningKey, error)
+	// Retired reports whether the key kid has been retired.
+	Retired(ctx context.Context, kid string) (bool, error)
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vipos89/timehub/services/auth-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) domain.SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) Transaction(ctx context.Context, fn func(repo domain.SigningKeyRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&signingKeyRepository{db: tx})
+	})
+}
+
+func (r *signingKeyRepository) Current(ctx context.Context) (*domain.SigningKey, error) {
+	var key domain.SigningKey
+	err := r.db.WithContext(ctx).Where("retired_at IS NULL").Order("created_at DESC, id DESC").First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *signingKeyRepository) Active(ctx context.Context, kid string) (*domain.SigningKey, error) {
+	var key domain.SigningKey
+	err := r.db.WithContext(ctx).Where("kid = ? AND retired_at IS NULL", kid).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *signingKeyRepository) List(ctx context.Context) ([]domain.SigningKey, error) {
+	var keys []domain.SigningKey
+	err := r.db.WithContext(ctx).Order("created_at DESC, id DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *signingKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *signingKeyRepository) RetireConfigured(ctx context.Context, at time.Time) (*domain.SigningKey, error) {
+	retired, err := r.Retired(ctx, domain.ConfiguredKID)
+	if err != nil || retired {
+		return nil, err
+	}
+	key := &domain.SigningKey{KID: domain.ConfiguredKID, CreatedAt: at, RetiredAt: &at}
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *signingKeyRepository) Retired(ctx context.Context, kid string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.SigningKey{}).Where("kid = ? AND retired_at IS NOT NULL", kid).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *signingKeyRepository) RetireOthers(ctx context.Context, keepID uint, at time.Time) ([]domain.SigningKey, error) {
+	var keys []domain.SigningKey
+	err := r.db.WithContext(ctx).Where("retired_at IS NULL AND id <> ?", keepID).Find(&keys).Error
+	if err != nil || len(keys) == 0 {
+		return keys, err
+	}
+	ids := make([]uint, len(keys))
+	for i := range keys {
+		ids[i] = keys[i].ID
+		keys[i].RetiredAt = &at
+	}
+	err = r.db.WithContext(ctx).Model(&domain.SigningKey{}).Where("id IN ?", ids).Update("retired_at", at).Error
+	return keys, err
+}
@@ -3,29 +3,49 @@ package usecase
 import (
 	"context"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/vipos89/timehub/services/auth-service/internal/domain"
 	"golang.org/x/crypto/bcrypt"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/identity"
+	"github.com/vipos89/timehub/services/auth-service/internal/domain"
 )
 
 type AuthUsecase interface {
 	Register(ctx context.Context, email, password, role string) (*domain.User, error)
-	Login(ctx context.Context, email, password string) (string, error)
+	// Login returns an access token. With a companyID the user must be a
+	// member of that company, which becomes the token's tenant.
+	Login(ctx context.Context, email, password string, companyID uint) (string, error)
+	// Unregister deletes the user after checking their password, so only
+	// whoever holds the credentials can remove the account.
+	Unregister(ctx context.Context, email, password string) error
+	// Verify checks a token's signature, key and expiry and returns its claims.
+	Verify(ctx context.Context, token string) (*identity.Claims, error)
 }
 
 type authUsecase struct {
 	userRepo       domain.UserRepository
+	keys           domain.SigningKeyRepository
+	company        clients.CompanyClient
 	contextTimeout time.Duration
 	jwtSecret      string
+	identitySecret string
+	tokenTTL       time.Duration
 }
 
-func NewAuthUsecase(userRepo domain.UserRepository, timeout time.Duration, jwtSecret string) AuthUsecase {
+func NewAuthUsecase(userRepo domain.UserRepository, keys domain.SigningKeyRepository, company clients.CompanyClient, timeout time.Duration, jwtSecret, identitySecret string, tokenTTL time.Duration) AuthUsecase {
 	return &authUsecase{
 		userRepo:       userRepo,
+		keys:           keys,
+		company:        company,
 		contextTimeout: timeout,
 		jwtSecret:      jwtSecret,
+		identitySecret: identitySecret,
+		tokenTTL:       tokenTTL,
 	}
 }
 
@@ -59,7 +79,7 @@ func (u *authUsecase) Register(ctx context.Context, email, password, role string
 	return user, nil
 }
 
-func (u *authUsecase) Login(ctx context.Context, email, password string) (string, error) {
+func (u *authUsecase) Login(ctx context.Context, email, password string, companyID uint) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
@@ -68,23 +88,115 @@ func (u *authUsecase) Login(ctx context.Context, email, password string) (string
 		return "", err
 	}
 	if user == nil {
-		return "", errors.New("invalid credentials")
+		loginFailures.WithLabelValues(loginUnknownUser).Inc()
+		return "", domain.ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid credentials")
+		loginFailures.WithLabelValues(loginWrongPassword).Inc()
+		return "", domain.ErrInvalidCredentials
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"user_id": user.ID,
 		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
-	})
+		"exp":     time.Now().Add(u.tokenTTL).Unix(),
+	}
+	if companyID != 0 {
+		if err := u.checkMembership(ctx, user.ID, companyID); err != nil {
+			return "", err
+		}
+		claims["company_id"] = companyID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Rotated keys take precedence over the configured secret.
+	secret := u.jwtSecret
+	key, err := u.keys.Current(ctx)
+	if err != nil {
+		return "", err
+	}
+	if key != nil {
+		secret = key.Secret
+		token.Header["kid"] = key.KID
+	}
 
-	tokenString, err := token.SignedString([]byte(u.jwtSecret))
+	tokenString, err := token.SignedString([]byte(secret))
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
 }
+
+func (u *authUsecase) Unregister(ctx context.Context, email, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	user, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return domain.ErrInvalidCredentials
+	}
+	return u.userRepo.Delete(ctx, user.ID)
+}
+
+// checkMembership asks company-service, as the user, whether they belong to
+// the company.
+func (u *authUsecase) checkMembership(ctx context.Context, userID, companyID uint) error {
+	if u.company == nil {
+		return erru.E(erru.CodeInternal, "Signing in to a company needs company-service")
+	}
+	h := make(http.Header)
+	identity.Set(h, u.identitySecret, identity.Claims{UserID: userID})
+	_, err := u.company.Membership(clients.WithHeaders(ctx, h), companyID)
+	if errors.Is(err, erru.ErrNotFound) {
+		return domain.ErrNotMember
+	}
+	return err
+}
+
+func (u *authUsecase) Verify(ctx context.Context, tokenString string) (*identity.Claims, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var claims struct {
+		jwt.RegisteredClaims
+		identity.Claims
+	}
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			// The configured secret is valid until a rotation retires it.
+			retired, err := u.keys.Retired(ctx, domain.ConfiguredKID)
+			if err != nil {
+				return nil, err
+			}
+			if retired {
+				return nil, domain.ErrInvalidToken
+			}
+			return []byte(u.jwtSecret), nil
+		}
+		// Tokens signed with a retired key are no longer accepted.
+		key, err := u.keys.Active(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			return nil, domain.ErrInvalidToken
+		}
+		return []byte(key.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidToken
+	}
+	if claims.UserID == 0 {
+		return nil, domain.ErrInvalidToken
+	}
+	return &claims.Claims, nil
+}
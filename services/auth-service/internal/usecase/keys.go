@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/vipos89/timehub/services/auth-service/internal/domain"
+)
+
+// KeyUsecase manages token signing keys. It is used by timehubctl rather
+// than exposed over HTTP.
+type KeyUsecase interface {
+	ListKeys(ctx context.Context) ([]domain.SigningKey, error)
+	// RotateKey creates a new signing key. With retirePrevious the older keys
+	// and the configured JWT secret are marked retired; with dryRun nothing
+	// is stored.
+	RotateKey(ctx context.Context, retirePrevious, dryRun bool) (*KeyRotation, error)
+}
+
+// KeyRotation is the outcome of RotateKey.
+type KeyRotation struct {
+	Key     domain.SigningKey   `json:"key"`
+	Retired []domain.SigningKey `json:"retired"`
+	DryRun  bool                `json:"dry_run"`
+}
+
+// errDryRun rolls back the rotation transaction.
+var errDryRun = errors.New("dry run")
+
+type keyUsecase struct {
+	repo           domain.SigningKeyRepository
+	contextTimeout time.Duration
+}
+
+func NewKeyUsecase(repo domain.SigningKeyRepository, timeout time.Duration) KeyUsecase {
+	return &keyUsecase{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *keyUsecase) ListKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.List(ctx)
+}
+
+func (u *keyUsecase) RotateKey(ctx context.Context, retirePrevious, dryRun bool) (*KeyRotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &KeyRotation{
+		Key:    domain.SigningKey{KID: kid, Secret: secret},
+		DryRun: dryRun,
+	}
+	// A dry run goes through the same statements and rolls them back.
+	err = u.repo.Transaction(ctx, func(repo domain.SigningKeyRepository) error {
+		if err := repo.Create(ctx, &rotation.Key); err != nil {
+			return err
+		}
+		if retirePrevious {
+			retired, err := repo.RetireOthers(ctx, rotation.Key.ID, rotation.Key.CreatedAt)
+			if err != nil {
+				return err
+			}
+			rotation.Retired = retired
+			configured, err := repo.RetireConfigured(ctx, rotation.Key.CreatedAt)
+			if err != nil {
+				return err
+			}
+			if configured != nil {
+				rotation.Retired = append(rotation.Retired, *configured)
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
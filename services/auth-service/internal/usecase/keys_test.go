@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/vipos89/timehub/services/auth-service/internal/domain"
+)
+
+const configuredSecret = "configured-secret"
+
+// keyRepo keeps signing keys in memory; a failed transaction restores them.
+type keyRepo struct {
+	keys []domain.SigningKey
+}
+
+func (r *keyRepo) Transaction(_ context.Context, fn func(repo domain.SigningKeyRepository) error) error {
+	saved := append([]domain.SigningKey(nil), r.keys...)
+	if err := fn(r); err != nil {
+		r.keys = saved
+		return err
+	}
+	return nil
+}
+
+func (r *keyRepo) Current(context.Context) (*domain.SigningKey, error) {
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if r.keys[i].RetiredAt == nil {
+			return &r.keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *keyRepo) Active(_ context.Context, kid string) (*domain.SigningKey, error) {
+	for i := range r.keys {
+		if r.keys[i].KID == kid && r.keys[i].RetiredAt == nil {
+			return &r.keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *keyRepo) List(context.Context) ([]domain.SigningKey, error) {
+	return r.keys, nil
+}
+
+func (r *keyRepo) Create(_ context.Context, key *domain.SigningKey) error {
+	key.ID = uint(len(r.keys) + 1)
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *keyRepo) RetireOthers(_ context.Context, keepID uint, at time.Time) ([]domain.SigningKey, error) {
+	var retired []domain.SigningKey
+	for i := range r.keys {
+		if r.keys[i].ID != keepID && r.keys[i].RetiredAt == nil {
+			r.keys[i].RetiredAt = &at
+			retired = append(retired, r.keys[i])
+		}
+	}
+	return retired, nil
+}
+
+func (r *keyRepo) RetireConfigured(ctx context.Context, at time.Time) (*domain.SigningKey, error) {
+	if retired, _ := r.Retired(ctx, domain.ConfiguredKID); retired {
+		return nil, nil
+	}
+	key := domain.SigningKey{ID: uint(len(r.keys) + 1), KID: domain.ConfiguredKID, CreatedAt: at, RetiredAt: &at}
+	r.keys = append(r.keys, key)
+	return &key, nil
+}
+
+func (r *keyRepo) Retired(_ context.Context, kid string) (bool, error) {
+	for _, k := range r.keys {
+		if k.KID == kid && k.RetiredAt != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// userRepo holds users by email.
+type userRepo struct {
+	users map[string]*domain.User
+}
+
+func (r *userRepo) Create(_ context.Context, user *domain.User) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *userRepo) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	return r.users[email], nil
+}
+
+func (r *userRepo) GetByID(_ context.Context, id uint) (*domain.User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userRepo) Delete(_ context.Context, id uint) error {
+	for email, u := range r.users {
+		if u.ID == id {
+			delete(r.users, email)
+		}
+	}
+	return nil
+}
+
+func newAuth(t *testing.T) (AuthUsecase, KeyUsecase) {
+	t.Helper()
+	keys := &keyRepo{}
+	auth := NewAuthUsecase(&userRepo{users: map[string]*domain.User{}}, keys, nil, time.Second, configuredSecret, "identity", time.Hour)
+	if _, err := auth.Register(context.Background(), "ann@example.com", "secret1", "owner"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return auth, NewKeyUsecase(keys, time.Second)
+}
+
+func login(t *testing.T, auth AuthUsecase) string {
+	t.Helper()
+	token, err := auth.Login(context.Background(), "ann@example.com", "secret1", 0)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return token
+}
+
+// forged is a token signed with the configured secret, as anyone who
+// learned it could mint.
+func forged(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1, "role": "owner", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(configuredSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyAcrossRotations(t *testing.T) {
+	auth, keys := newAuth(t)
+	ctx := context.Background()
+	verifies := func(name, token string, want bool) {
+		t.Helper()
+		claims, err := auth.Verify(ctx, token)
+		switch {
+		case want && (err != nil || claims.UserID != 1):
+			t.Errorf("%s: claims = %+v, err = %v, want user 1", name, claims, err)
+		case !want && !errors.Is(err, domain.ErrInvalidToken):
+			t.Errorf("%s: err = %v, want %v", name, err, domain.ErrInvalidToken)
+		}
+	}
+
+	configured := login(t, auth)
+	verifies("configured secret", configured, true)
+	verifies("forged with the configured secret", forged(t), true)
+
+	if _, err := keys.RotateKey(ctx, false, false); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	first := login(t, auth)
+	verifies("configured secret after a rotation", configured, true)
+	verifies("first key", first, true)
+
+	rotation, err := keys.RotateKey(ctx, true, false)
+	if err != nil {
+		t.Fatalf("rotate and retire: %v", err)
+	}
+	if len(rotation.Retired) != 2 || rotation.Retired[1].KID != domain.ConfiguredKID {
+		t.Errorf("retired = %+v, want the first key and the configured secret", rotation.Retired)
+	}
+	verifies("second key", login(t, auth), true)
+	verifies("retired first key", first, false)
+	verifies("retired configured secret", configured, false)
+	verifies("forged after retiring the configured secret", forged(t), false)
+
+	rotation, err = keys.RotateKey(ctx, true, false)
+	if err != nil {
+		t.Fatalf("rotate again: %v", err)
+	}
+	if len(rotation.Retired) != 1 || rotation.Retired[0].KID == domain.ConfiguredKID {
+		t.Errorf("retired = %+v, want the second key only", rotation.Retired)
+	}
+}
+
+func TestRotateKeyDryRun(t *testing.T) {
+	auth, keys := newAuth(t)
+	ctx := context.Background()
+	configured := login(t, auth)
+
+	rotation, err := keys.RotateKey(ctx, true, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !rotation.DryRun || len(rotation.Retired) != 1 {
+		t.Errorf("rotation = %+v, want a dry run retiring the configured secret", rotation)
+	}
+	if stored, _ := keys.ListKeys(ctx); len(stored) != 0 {
+		t.Errorf("keys = %+v, want none stored", stored)
+	}
+	if _, err := auth.Verify(ctx, configured); err != nil {
+		t.Errorf("configured secret after a dry run: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/vipos89/timehub/pkg/metrics"
+)
+
+// Reasons a login attempt fails.
+const (
+	loginUnknownUser   = "unknown_user"
+	loginWrongPassword = "wrong_password"
+)
+
+var loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "auth_login_failures_total",
+	Help:      "Failed login attempts, by reason.",
+}, []string{"reason"})
@@ -1,12 +1,15 @@
 -- +goose Up
-CREATE TABLE users (
-    id SERIAL PRIMARY KEY,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    password_hash VARCHAR(255) NOT NULL,
-    role VARCHAR(50) NOT NULL, -- 'owner', 'admin', 'master'
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+-- Written with IF NOT EXISTS so databases previously created by GORM AutoMigrate are adopted as-is.
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL, -- 'owner', 'admin', 'master'
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
 );
 
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
 -- +goose Down
-DROP TABLE users;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,7 @@
+// Package migrations embeds the service's versioned SQL schema.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
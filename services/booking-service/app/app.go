@@ -0,0 +1,27 @@
+// Package app assembles booking-service from its layers, so it can run as
+// its own binary or be mounted into the all-in-one timehub binary.
+package app
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/services/booking-service/internal/delivery/http"
+	"github.com/vipos89/timehub/services/booking-service/internal/repository/postgres"
+	"github.com/vipos89/timehub/services/booking-service/internal/usecase"
+	"github.com/vipos89/timehub/services/booking-service/migrations"
+)
+
+// Migrations is the service's versioned SQL schema.
+var Migrations = migrations.FS
+
+// Mount registers the slot, booking, schedule and shift routes on e. Slot
+// lengths and prices are quoted by company.
+func Mount(e *echo.Echo, database *gorm.DB, company clients.CompanyClient, timeout time.Duration) {
+	bookingRepo := postgres.NewBookingRepository(database)
+	bookingUsecase := usecase.NewBookingUsecase(bookingRepo, timeout, company)
+	http.NewBookingHandler(e, bookingUsecase)
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+	"github.com/vipos89/timehub/services/booking-service/internal/repository/postgres"
+	"github.com/vipos89/timehub/services/booking-service/internal/usecase"
+)
+
+// ConsumeCompanyEvents keeps booking-service's view of company-service up
+// to date under the consumer group "<service>.company" until ctx is
+// cancelled: branch timezones and hours, employees' branches, and which
+// companies, branches and employees are deleted.
+func ConsumeCompanyEvents(bus events.Subscriber, database *gorm.DB, service string, timeout time.Duration) func(ctx context.Context) error {
+	bookingUsecase := usecase.NewBookingUsecase(postgres.NewBookingRepository(database), timeout, nil)
+	return func(ctx context.Context) error {
+		return bus.Subscribe(ctx, service+".company", func(ctx context.Context, env events.Envelope) error {
+			switch env.Type {
+			case events.TypeBranchCreated, events.TypeBranchUpdated:
+				// Both carry the full state of the branch.
+				var ev events.BranchCreated
+				if err := env.Decode(&ev); err != nil {
+					return err
+				}
+				return bookingUsecase.SyncBranch(ctx, &domain.Branch{
+					ID:           ev.BranchID,
+					CompanyID:    ev.CompanyID,
+					Timezone:     ev.Timezone,
+					OpeningHours: ev.OpeningHours,
+					Exceptions:   ev.Exceptions,
+					ChangedAt:    env.OccurredAt,
+				})
+			case events.TypeEmployeeCreated, events.TypeEmployeeUpdated:
+				var ev events.EmployeeUpdated
+				if err := env.Decode(&ev); err != nil {
+					return err
+				}
+				return bookingUsecase.SyncEmployee(ctx, &domain.EmployeeBranch{
+					EmployeeID: ev.EmployeeID,
+					CompanyID:  ev.CompanyID,
+					BranchID:   ev.BranchID,
+					BranchIDs:  ev.BranchIDs,
+					ChangedAt:  env.OccurredAt,
+				})
+			}
+			return suspend(ctx, bookingUsecase, env)
+		},
+			events.TypeBranchCreated, events.TypeBranchUpdated,
+			events.TypeEmployeeCreated, events.TypeEmployeeUpdated,
+			events.TypeCompanyDeleted, events.TypeCompanyRestored,
+			events.TypeBranchDeleted, events.TypeBranchRestored,
+			events.TypeEmployeeDeleted, events.TypeEmployeeRestored)
+	}
+}
+
+// suspend applies deleted and restored events, which share their payload shape.
+func suspend(ctx context.Context, bookingUsecase domain.BookingUsecase, env events.Envelope) error {
+	var (
+		kind      domain.SuspensionKind
+		id        uint
+		companyID uint
+		suspended bool
+	)
+	switch env.Type {
+	case events.TypeCompanyDeleted, events.TypeCompanyRestored:
+		var ev events.CompanyDeleted
+		if err := env.Decode(&ev); err != nil {
+			return err
+		}
+		kind, id, companyID = domain.SuspendCompany, ev.CompanyID, ev.CompanyID
+		suspended = env.Type == events.TypeCompanyDeleted
+	case events.TypeBranchDeleted, events.TypeBranchRestored:
+		var ev events.BranchDeleted
+		if err := env.Decode(&ev); err != nil {
+			return err
+		}
+		kind, id, companyID = domain.SuspendBranch, ev.BranchID, ev.CompanyID
+		suspended = env.Type == events.TypeBranchDeleted
+	case events.TypeEmployeeDeleted, events.TypeEmployeeRestored:
+		var ev events.EmployeeDeleted
+		if err := env.Decode(&ev); err != nil {
+			return err
+		}
+		kind, id, companyID = domain.SuspendEmployee, ev.EmployeeID, ev.CompanyID
+		suspended = env.Type == events.TypeEmployeeDeleted
+	default:
+		return nil
+	}
+	logger.Info("Availability changed", "event_id", env.ID, "kind", kind, "id", id, "suspended", suspended)
+	return bookingUsecase.Suspend(ctx, kind, id, companyID, suspended, env.OccurredAt)
+}
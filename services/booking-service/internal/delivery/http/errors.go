@@ -0,0 +1,11 @@
+package http
+
+import (
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// registerErrors maps booking domain errors to problem codes.
+func registerErrors() {
+	erru.Register(domain.ErrSlotUnavailable, erru.CodeConflict)
+}
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,6 +8,9 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
 	"github.com/vipos89/timehub/services/booking-service/internal/domain"
 )
 
@@ -20,50 +22,76 @@ func NewBookingHandler(e *echo.Echo, us domain.BookingUsecase) {
 	handler := &BookingHandler{
 		Usecase: us,
 	}
+	registerErrors()
 
 	e.GET("/slots", handler.GetSlots)
+	e.GET("/slots/bundle", handler.GetBundleSlots)
 	e.POST("/bookings", handler.CreateBooking)
-	e.GET("/schedules/:employee_id", handler.GetSchedule)
-	e.POST("/schedules/:employee_id", handler.SetSchedule)
+	e.POST("/bookings/bundle", handler.CreateBundleBooking)
+
+	// Staff routes work inside the caller's company.
+	e.GET("/schedules/:employee_id", handler.GetSchedule, tenant.Require)
+	e.POST("/schedules/:employee_id", handler.SetSchedule, tenant.Require)
 
 	// Work Shifts
-	e.GET("/shifts", handler.GetShifts)
-	e.POST("/shifts", handler.SaveShifts)
+	e.GET("/shifts", handler.GetShifts, tenant.Require)
+	e.POST("/shifts", handler.SaveShifts, tenant.Require)
 }
 
 type getSlotsRequest struct {
 	EmployeeID uint      `query:"employee_id" validate:"required"`
 	ServiceID  uint      `query:"service_id" validate:"required"`
+	VariantID  *uint     `query:"variant_id" validate:"omitempty,gt=0"`
+	AddOnIDs   []uint    `query:"addon_ids" validate:"max=20,dive,gt=0"`
 	Date       time.Time `query:"date" validate:"required" example:"2026-01-20T00:00:00Z"`
 }
 
 // GetSlots godoc
 // @Summary Get available slots
-// @Description Calculate available time slots for an employee and service on a specific date
+// @Description Calculate available time slots for an employee and service on a specific date. Slots last as long as the service with the chosen variant and add-ons.
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param employee_id query int true "Employee ID"
 // @Param service_id query int true "Service ID"
+// @Param variant_id query int false "Variant ID, required when the service has variants"
+// @Param addon_ids query []int false "Add-on IDs" collectionFormat(multi)
 // @Param date query string true "Date (ISO8601)"
 // @Success 200 {array} domain.Slot
-// @Failure 400 {object} erru.AppError
-// @Failure 500 {object} erru.AppError
+// @Failure 400 {object} erru.Problem
+// @Failure 500 {object} erru.Problem
 // @Router /slots [get]
 func (h *BookingHandler) GetSlots(c echo.Context) error {
-	empID, _ := strconv.Atoi(c.QueryParam("employee_id"))
-	svcID, _ := strconv.Atoi(c.QueryParam("service_id"))
+	empID, err := middleware.QueryID(c, "employee_id", true)
+	if err != nil {
+		return err
+	}
+	svcID, err := middleware.QueryID(c, "service_id", true)
+	if err != nil {
+		return err
+	}
+	variantID, err := middleware.QueryID(c, "variant_id", false)
+	if err != nil {
+		return err
+	}
+	addOnIDs, err := queryIDs(c, "addon_ids")
+	if err != nil {
+		return err
+	}
 	dateStr := c.QueryParam("date")
 
 	date, err := parseDate(dateStr)
 	if err != nil {
-		log.Printf("Error parsing date '%s': %v", dateStr, err)
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+		return erru.Wrap(err, erru.CodeBadRequest, "Invalid date")
 	}
 
-	slots, err := h.Usecase.GetAvailableSlots(c.Request().Context(), uint(empID), uint(svcID), date)
+	service := domain.ServiceChoice{ServiceID: svcID, AddOnIDs: addOnIDs}
+	if variantID != 0 {
+		service.VariantID = &variantID
+	}
+	slots, err := h.Usecase.GetAvailableSlots(c.Request().Context(), empID, service, date)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 
 	return c.JSON(http.StatusOK, slots)
@@ -72,46 +100,130 @@ func (h *BookingHandler) GetSlots(c echo.Context) error {
 type createBookingRequest struct {
 	EmployeeID uint      `json:"employee_id" validate:"required"`
 	ServiceID  uint      `json:"service_id" validate:"required"`
+	VariantID  *uint     `json:"variant_id" validate:"omitempty,gt=0"`
+	AddOnIDs   []uint    `json:"addon_ids" validate:"max=20,dive,gt=0"`
 	ClientID   uint      `json:"client_id" validate:"required"`
-	StartTime  time.Time `json:"start_time" validate:"required"`
-	EndTime    time.Time `json:"end_time" validate:"required"`
-	Comment    string    `json:"comment"`
+	StartTime  time.Time `json:"start_time" validate:"required,future"`
+	// EndTime is ignored: the service decides how long the appointment is.
+	EndTime time.Time `json:"end_time" validate:"omitempty,gtfield=StartTime"`
+	Comment string    `json:"comment"`
 }
 
 // CreateBooking godoc
 // @Summary Book an appointment
-// @Description Create a new appointment if the slot is available
+// @Description Create a new appointment if the slot is available. The end time and price follow from the service, variant and add-ons.
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param body body createBookingRequest true "Booking Info"
 // @Success 201 {object} domain.Appointment
-// @Failure 400 {object} erru.AppError
-// @Failure 500 {object} erru.AppError
+// @Failure 400 {object} erru.Problem
+// @Failure 409 {object} erru.Problem
+// @Failure 500 {object} erru.Problem
 // @Router /bookings [post]
 func (h *BookingHandler) CreateBooking(c echo.Context) error {
 	var req createBookingRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	appointment := &domain.Appointment{
 		EmployeeID: req.EmployeeID,
 		ServiceID:  req.ServiceID,
+		VariantID:  req.VariantID,
+		AddOnIDs:   req.AddOnIDs,
 		ClientID:   req.ClientID,
 		StartTime:  req.StartTime,
-		EndTime:    req.EndTime,
 		Comment:    req.Comment,
 	}
 
 	err := h.Usecase.CreateBooking(c.Request().Context(), appointment)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, appointment)
 }
 
+type getBundleSlotsRequest struct {
+	BundleID    uint      `query:"bundle_id" validate:"required"`
+	EmployeeIDs []uint    `query:"employee_ids" validate:"required,min=1,max=20"`
+	Date        time.Time `query:"date" validate:"required" example:"2026-01-20T00:00:00Z"`
+}
+
+// GetBundleSlots godoc
+// @Summary Get available slots for a bundle
+// @Description Calculate when every item of a bundle fits back to back. Each slot spans the whole bundle.
+// @Tags bookings
+// @Produce json
+// @Param bundle_id query int true "Bundle ID"
+// @Param employee_ids query []int true "Employee per item in order, or one for all items" collectionFormat(multi)
+// @Param date query string true "Date (ISO8601)"
+// @Success 200 {array} domain.Slot
+// @Failure 400 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /slots/bundle [get]
+func (h *BookingHandler) GetBundleSlots(c echo.Context) error {
+	bundleID, err := middleware.QueryID(c, "bundle_id", true)
+	if err != nil {
+		return err
+	}
+	employeeIDs, err := queryIDs(c, "employee_ids")
+	if err != nil {
+		return err
+	}
+	if len(employeeIDs) == 0 {
+		return erru.Validation(erru.FieldError{Field: "employee_ids", Rule: "required", Message: "employee_ids is required"})
+	}
+	date, err := parseDate(c.QueryParam("date"))
+	if err != nil {
+		return erru.Wrap(err, erru.CodeBadRequest, "Invalid date")
+	}
+
+	bundle := domain.BundleChoice{BundleID: bundleID, EmployeeIDs: employeeIDs}
+	slots, err := h.Usecase.GetBundleSlots(c.Request().Context(), bundle, date)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, slots)
+}
+
+type createBundleBookingRequest struct {
+	BundleID    uint      `json:"bundle_id" validate:"required"`
+	EmployeeIDs []uint    `json:"employee_ids" validate:"required,min=1,max=20,dive,gt=0"`
+	ClientID    uint      `json:"client_id" validate:"required"`
+	StartTime   time.Time `json:"start_time" validate:"required,future"`
+	Comment     string    `json:"comment"`
+}
+
+// CreateBundleBooking godoc
+// @Summary Book a bundle
+// @Description Books every item of a bundle back to back from start_time, as one booking with an appointment per item.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param body body createBundleBookingRequest true "Bundle Booking Info"
+// @Success 201 {object} domain.BundleBooking
+// @Failure 400 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 409 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /bookings/bundle [post]
+func (h *BookingHandler) CreateBundleBooking(c echo.Context) error {
+	var req createBundleBookingRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	bundle := domain.BundleChoice{BundleID: req.BundleID, EmployeeIDs: req.EmployeeIDs}
+	booking, err := h.Usecase.CreateBundleBooking(c.Request().Context(), bundle, req.ClientID, req.StartTime, req.Comment)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, booking)
+}
+
 // GetSchedule godoc
 // @Summary Get employee schedule
 // @Description Get weekly working schedule for an employee
@@ -120,12 +232,16 @@ func (h *BookingHandler) CreateBooking(c echo.Context) error {
 // @Produce json
 // @Param employee_id path int true "Employee ID"
 // @Success 200 {array} domain.Schedule
+// @Failure 403 {object} erru.Problem
 // @Router /schedules/{employee_id} [get]
 func (h *BookingHandler) GetSchedule(c echo.Context) error {
-	empID, _ := strconv.Atoi(c.Param("employee_id"))
-	schedules, err := h.Usecase.GetEmployeeSchedule(c.Request().Context(), uint(empID))
+	empID, err := middleware.ParamID(c, "employee_id")
+	if err != nil {
+		return err
+	}
+	schedules, err := h.Usecase.GetEmployeeSchedule(c.Request().Context(), empID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusOK, schedules)
 }
@@ -139,17 +255,21 @@ func (h *BookingHandler) GetSchedule(c echo.Context) error {
 // @Param employee_id path int true "Employee ID"
 // @Param body body []domain.Schedule true "Schedules Array"
 // @Success 200 {string} string "OK"
+// @Failure 403 {object} erru.Problem
 // @Router /schedules/{employee_id} [post]
 func (h *BookingHandler) SetSchedule(c echo.Context) error {
-	empID, _ := strconv.Atoi(c.Param("employee_id"))
+	empID, err := middleware.ParamID(c, "employee_id")
+	if err != nil {
+		return err
+	}
 	var schedules []domain.Schedule
-	if err := c.Bind(&schedules); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &schedules); err != nil {
+		return err
 	}
 
-	err := h.Usecase.SetEmployeeSchedule(c.Request().Context(), uint(empID), schedules)
+	err = h.Usecase.SetEmployeeSchedule(c.Request().Context(), empID, schedules)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 
 	return c.JSON(http.StatusOK, "OK")
@@ -163,22 +283,41 @@ func (h *BookingHandler) SetSchedule(c echo.Context) error {
 // @Param employee_id query int false "Employee ID"
 // @Param branch_id query int false "Branch ID"
 // @Param month query string true "Month (ISO8601, e.g., 2026-01-01T00:00:00Z)"
-// @Success 200 {array} domain.WorkShift
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.WorkShift]
+// @Failure 422 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
 // @Router /shifts [get]
 func (h *BookingHandler) GetShifts(c echo.Context) error {
-	empID, _ := strconv.Atoi(c.QueryParam("employee_id"))
-	branchID, _ := strconv.Atoi(c.QueryParam("branch_id"))
+	empID, err := middleware.QueryID(c, "employee_id", false)
+	if err != nil {
+		return err
+	}
+	branchID, err := middleware.QueryID(c, "branch_id", false)
+	if err != nil {
+		return err
+	}
+	if empID == 0 && branchID == 0 {
+		return erru.Validation(erru.FieldError{Field: "employee_id", Rule: "required_without", Message: "employee_id or branch_id is required"})
+	}
 	monthStr := c.QueryParam("month")
 
 	month, err := parseDate(monthStr)
 	if err != nil {
-		log.Printf("Error parsing month '%s': %v", monthStr, err)
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+		return erru.Wrap(err, erru.CodeBadRequest, "Invalid month")
 	}
 
-	shifts, err := h.Usecase.GetShifts(c.Request().Context(), uint(empID), uint(branchID), month)
+	page, err := pagination.Parse(c, shiftList)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+
+	shifts, err := h.Usecase.GetShifts(c.Request().Context(), empID, branchID, month, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, shifts)
 }
@@ -191,16 +330,17 @@ func (h *BookingHandler) GetShifts(c echo.Context) error {
 // @Produce json
 // @Param body body []domain.WorkShift true "Shifts Array"
 // @Success 200 {string} string "OK"
+// @Failure 403 {object} erru.Problem
 // @Router /shifts [post]
 func (h *BookingHandler) SaveShifts(c echo.Context) error {
 	var shifts []domain.WorkShift
-	if err := c.Bind(&shifts); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &shifts); err != nil {
+		return err
 	}
 
 	err := h.Usecase.SaveShifts(c.Request().Context(), shifts)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusOK, "OK")
 }
@@ -241,3 +381,17 @@ func parseDate(s string) (time.Time, error) {
 
 	return time.Parse(time.RFC3339, s) // Final try for meaningful error
 }
+
+// queryIDs parses a repeated numeric query parameter such as
+// addon_ids=1&addon_ids=2.
+func queryIDs(c echo.Context, name string) ([]uint, error) {
+	var ids []uint
+	for _, raw := range c.QueryParams()[name] {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			return nil, erru.Validation(erru.FieldError{Field: name, Rule: "gt", Message: name + " must be positive IDs"})
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
@@ -0,0 +1,17 @@
+package http
+
+import "github.com/vipos89/timehub/pkg/pagination"
+
+// Sort and filter fields accepted by the list endpoints. Columns without a
+// NOT NULL constraint are marked Nullable so keyset pages include NULL rows.
+
+var shiftList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":          {Column: "id", Ops: []pagination.Op{pagination.OpEq, pagination.OpIn}},
+		"date":        {Column: "date", Ops: []pagination.Op{pagination.OpEq, pagination.OpLt, pagination.OpLte, pagination.OpGt, pagination.OpGte}},
+		"employee_id": {Column: "employee_id", Ops: []pagination.Op{pagination.OpEq, pagination.OpIn}},
+		"is_day_off":  {Column: "is_day_off", Ops: []pagination.Op{pagination.OpEq}, Nullable: true},
+	},
+	DefaultSort: "date,employee_id",
+	MaxLimit:    1000,
+}
@@ -2,7 +2,15 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/pagination"
+)
+
+var (
+	ErrSlotUnavailable = errors.New("slot is already taken or out of working hours")
 )
 
 type AppointmentStatus string
@@ -13,12 +21,16 @@ const (
 	StatusCancelled AppointmentStatus = "cancelled"
 )
 
+// Schedule is an employee's weekly template. BranchID 0 means their primary
+// branch.
 type Schedule struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
+	CompanyID  uint      `json:"company_id" gorm:"not null;default:0;index"`
 	EmployeeID uint      `json:"employee_id" gorm:"not null;index"`
-	DayOfWeek  int       `json:"day_of_week" gorm:"not null"` // 0 = Sunday, 1 = Monday, ...
-	StartTime  string    `json:"start_time" gorm:"not null"`  // e.g. "09:00"
-	EndTime    string    `json:"end_time" gorm:"not null"`    // e.g. "18:00"
+	BranchID   uint      `json:"branch_id" gorm:"not null;default:0"`
+	DayOfWeek  int       `json:"day_of_week" gorm:"not null" validate:"gte=0,lte=6"`   // 0 = Sunday, 1 = Monday, ...
+	StartTime  string    `json:"start_time" gorm:"not null" validate:"omitempty,hhmm"` // e.g. "09:00"
+	EndTime    string    `json:"end_time" gorm:"not null" validate:"omitempty,hhmm"`   // e.g. "18:00"
 	IsDayOff   bool      `json:"is_day_off" gorm:"default:false"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -26,11 +38,12 @@ type Schedule struct {
 
 type WorkShift struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
-	EmployeeID uint      `json:"employee_id" gorm:"not null;index"`
-	BranchID   uint      `json:"branch_id" gorm:"not null;index"`
-	Date       time.Time `json:"date" gorm:"type:date;not null;index"`
-	StartTime  string    `json:"start_time" gorm:"not null"` // e.g. "09:00"
-	EndTime    string    `json:"end_time" gorm:"not null"`   // e.g. "18:00"
+	CompanyID  uint      `json:"company_id" gorm:"not null;default:0;index"`
+	EmployeeID uint      `json:"employee_id" gorm:"not null;index" validate:"required"`
+	BranchID   uint      `json:"branch_id" gorm:"not null;index" validate:"required"`
+	Date       time.Time `json:"date" gorm:"type:date;not null;index" validate:"required"`
+	StartTime  string    `json:"start_time" gorm:"not null" validate:"omitempty,hhmm"` // e.g. "09:00"
+	EndTime    string    `json:"end_time" gorm:"not null" validate:"omitempty,hhmm"`   // e.g. "18:00"
 	IsDayOff   bool      `json:"is_day_off" gorm:"default:false"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -38,15 +51,63 @@ type WorkShift struct {
 
 type Appointment struct {
 	ID         uint              `json:"id" gorm:"primaryKey"`
+	CompanyID  uint              `json:"company_id" gorm:"not null;default:0;index"`
 	EmployeeID uint              `json:"employee_id" gorm:"not null;index"`
 	ServiceID  uint              `json:"service_id" gorm:"not null"`
+	VariantID  *uint             `json:"variant_id,omitempty"`
+	AddOnIDs   []uint            `json:"addon_ids,omitempty" gorm:"column:addon_ids;serializer:json;type:jsonb"`
 	ClientID   uint              `json:"client_id" gorm:"not null;index"`
 	StartTime  time.Time         `json:"start_time" gorm:"not null;index"`
 	EndTime    time.Time         `json:"end_time" gorm:"not null"`
+	Price      float64           `json:"price" gorm:"not null;default:0"`
+	BundleID   *uint             `json:"bundle_id,omitempty"`
+	GroupID    string            `json:"group_id,omitempty" gorm:"type:text;index"`
 	Status     AppointmentStatus `json:"status" gorm:"type:text;default:'pending'"`
 	Comment    string            `json:"comment"`
 	CreatedAt  time.Time         `json:"created_at"`
 	UpdatedAt  time.Time         `json:"updated_at"`
+
+	// Relations
+	Reservations []Reservation `json:"reservations,omitempty" gorm:"foreignKey:AppointmentID"`
+}
+
+// Reservation holds a company-service resource (a room, a chair, a device)
+// for an appointment, from its start to its end. Reservations of cancelled
+// appointments no longer count.
+type Reservation struct {
+	ID            uint      `json:"-" gorm:"primaryKey"`
+	CompanyID     uint      `json:"-" gorm:"not null;default:0;index"`
+	AppointmentID uint      `json:"-" gorm:"not null;index"`
+	ResourceID    uint      `json:"resource_id" gorm:"not null;index"`
+	TypeID        uint      `json:"type_id" gorm:"not null"`
+	StartTime     time.Time `json:"-" gorm:"not null"`
+	EndTime       time.Time `json:"-" gorm:"not null"`
+}
+
+// ServiceChoice is a service with the variant and add-ons the client picked;
+// together they decide the length of a slot.
+type ServiceChoice struct {
+	ServiceID uint
+	VariantID *uint
+	AddOnIDs  []uint
+}
+
+// BundleChoice is a bundle with the employees performing its items: one per
+// item in order, or a single one for all of them.
+type BundleChoice struct {
+	BundleID    uint
+	EmployeeIDs []uint
+}
+
+// BundleBooking is a bundle booked as one: an appointment per item, back to
+// back, sharing a GroupID. The bundle price is split over the appointments.
+type BundleBooking struct {
+	GroupID      string        `json:"group_id"`
+	BundleID     uint          `json:"bundle_id"`
+	Price        float64       `json:"price"`
+	StartTime    time.Time     `json:"start_time"`
+	EndTime      time.Time     `json:"end_time"`
+	Appointments []Appointment `json:"appointments"`
 }
 
 type Slot struct {
@@ -55,29 +116,129 @@ type Slot struct {
 	IsFree    bool      `json:"is_free"`
 }
 
+// SuspensionKind names what a Suspension refers to.
+type SuspensionKind string
+
+const (
+	SuspendCompany  SuspensionKind = "company"
+	SuspendBranch   SuspensionKind = "branch"
+	SuspendEmployee SuspensionKind = "employee"
+)
+
+// Suspension mirrors a soft-deleted company, branch or employee from
+// company-service; suspended ones offer no slots. ChangedAt is the time of the
+// event that set it, so redelivered or reordered events cannot undo a newer one.
+type Suspension struct {
+	Kind      SuspensionKind `json:"kind" gorm:"type:text;primaryKey"`
+	RefID     uint           `json:"ref_id" gorm:"primaryKey;autoIncrement:false"`
+	CompanyID uint           `json:"company_id" gorm:"not null;default:0;index"`
+	Suspended bool           `json:"suspended" gorm:"not null"`
+	ChangedAt time.Time      `json:"changed_at" gorm:"not null"`
+}
+
+// Branch is booking-service's copy of a company-service branch: its
+// timezone and opening hours, kept up to date from branch events.
+type Branch struct {
+	ID           uint                    `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	CompanyID    uint                    `json:"company_id" gorm:"not null;default:0;index"`
+	Timezone     string                  `json:"timezone" gorm:"not null;default:UTC"`
+	OpeningHours []events.OpeningHours   `json:"opening_hours" gorm:"serializer:json;type:jsonb"`
+	Exceptions   []events.HoursException `json:"hours_exceptions" gorm:"serializer:json;type:jsonb"`
+	ChangedAt    time.Time               `json:"changed_at" gorm:"not null"`
+}
+
+// EmployeeBranch records where an employee works: BranchID when no shift or
+// schedule says otherwise, and any of BranchIDs when one does.
+type EmployeeBranch struct {
+	EmployeeID uint      `json:"employee_id" gorm:"primaryKey;autoIncrement:false"`
+	CompanyID  uint      `json:"company_id" gorm:"not null;default:0;index"`
+	BranchID   uint      `json:"branch_id" gorm:"not null"`
+	BranchIDs  []uint    `json:"branch_ids" gorm:"column:branch_ids;serializer:json;type:jsonb"`
+	ChangedAt  time.Time `json:"changed_at" gorm:"not null"`
+}
+
+// WorksAt reports whether the employee is assigned to the branch. Copies
+// made before assignments were carried know the primary branch only.
+func (e *EmployeeBranch) WorksAt(branchID uint) bool {
+	if branchID == e.BranchID {
+		return true
+	}
+	for _, id := range e.BranchIDs {
+		if id == branchID {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftFilter selects shifts of an employee or a whole branch within [From, To].
+type ShiftFilter struct {
+	EmployeeID uint
+	BranchID   uint
+	From, To   time.Time
+}
+
 type BookingRepository interface {
+	// Transaction runs fn with a repository bound to a single database transaction.
+	Transaction(ctx context.Context, fn func(repo BookingRepository) error) error
+	// AddEvents stores domain events in the outbox; use it inside Transaction.
+	AddEvents(ctx context.Context, envs ...events.Envelope) error
+
 	// Schedule
 	GetScheduleByEmployee(ctx context.Context, employeeID uint) ([]Schedule, error)
 	UpdateSchedule(ctx context.Context, schedule []Schedule) error
 
 	// Appointments
+	// CreateAppointment returns ErrSlotUnavailable when the appointment or
+	// one of its reservations overlaps a live one.
 	CreateAppointment(ctx context.Context, appointment *Appointment) error
 	GetAppointmentsByEmployee(ctx context.Context, employeeID uint, start, end time.Time) ([]Appointment, error)
+	// UpdateAppointmentStatus releases the reservations of a cancelled appointment.
 	UpdateAppointmentStatus(ctx context.Context, id uint, status AppointmentStatus) error
+	// GetReservations returns the live reservations of the resources that
+	// overlap [start, end).
+	GetReservations(ctx context.Context, resourceIDs []uint, start, end time.Time) ([]Reservation, error)
 
 	// Work Shifts
+	// GetShiftsByEmployee returns the shifts on the days from start to end,
+	// both taken in their own location.
 	GetShiftsByEmployee(ctx context.Context, employeeID uint, start, end time.Time) ([]WorkShift, error)
 	GetShiftsByBranch(ctx context.Context, branchID uint, start, end time.Time) ([]WorkShift, error)
+	ListShifts(ctx context.Context, filter ShiftFilter, page pagination.Request) (pagination.Page[WorkShift], error)
 	UpsertShifts(ctx context.Context, shifts []WorkShift) error
+
+	// Suspensions
+	SaveSuspension(ctx context.Context, suspension *Suspension) error
+	IsSuspended(ctx context.Context, kind SuspensionKind, id uint) (bool, error)
+
+	// Branches and employees copied from company-service. Save* ignore
+	// changes older than the stored ones; Get* return nil when unknown.
+	SaveBranch(ctx context.Context, branch *Branch) error
+	GetBranch(ctx context.Context, id uint) (*Branch, error)
+	SaveEmployeeBranch(ctx context.Context, employee *EmployeeBranch) error
+	GetEmployeeBranch(ctx context.Context, employeeID uint) (*EmployeeBranch, error)
 }
 
 type BookingUsecase interface {
-	GetAvailableSlots(ctx context.Context, employeeID uint, serviceID uint, date time.Time) ([]Slot, error)
+	GetAvailableSlots(ctx context.Context, employeeID uint, service ServiceChoice, date time.Time) ([]Slot, error)
+	// CreateBooking sets the end time and price from the chosen service.
 	CreateBooking(ctx context.Context, appointment *Appointment) error
+	// GetBundleSlots lists when a whole bundle fits; a slot spans every item.
+	GetBundleSlots(ctx context.Context, bundle BundleChoice, date time.Time) ([]Slot, error)
+	// CreateBundleBooking books every item of a bundle starting at start.
+	CreateBundleBooking(ctx context.Context, bundle BundleChoice, clientID uint, start time.Time, comment string) (*BundleBooking, error)
 	GetEmployeeSchedule(ctx context.Context, employeeID uint) ([]Schedule, error)
+	// SetEmployeeSchedule and SaveShifts refuse branches the employee is not
+	// assigned to.
 	SetEmployeeSchedule(ctx context.Context, employeeID uint, schedules []Schedule) error
 
 	// Work Shifts
-	GetShifts(ctx context.Context, employeeID uint, branchID uint, month time.Time) ([]WorkShift, error)
+	GetShifts(ctx context.Context, employeeID uint, branchID uint, month time.Time, page pagination.Request) (pagination.Page[WorkShift], error)
 	SaveShifts(ctx context.Context, shifts []WorkShift) error
+
+	// Suspend stops or resumes slots of a company, branch or employee as of at.
+	Suspend(ctx context.Context, kind SuspensionKind, id, companyID uint, suspended bool, at time.Time) error
+	// SyncBranch and SyncEmployee store the state carried by company events.
+	SyncBranch(ctx context.Context, branch *Branch) error
+	SyncEmployee(ctx context.Context, employee *EmployeeBranch) error
 }
@@ -2,12 +2,21 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/outbox"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
 	"github.com/vipos89/timehub/services/booking-service/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// bookingRepository restricts every query to the tenant in ctx and stamps new
+// rows with it (see pkg/tenant).
 type bookingRepository struct {
 	db *gorm.DB
 }
@@ -16,15 +25,36 @@ func NewBookingRepository(db *gorm.DB) domain.BookingRepository {
 	return &bookingRepository{db: db}
 }
 
+func (r *bookingRepository) Transaction(ctx context.Context, fn func(repo domain.BookingRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&bookingRepository{db: tx})
+	})
+}
+
+func (r *bookingRepository) AddEvents(ctx context.Context, envs ...events.Envelope) error {
+	return outbox.Add(r.db.WithContext(ctx), envs...)
+}
+
+// scoped starts a query restricted to the caller's company.
+func (r *bookingRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Scopes(tenant.Scope(ctx, "company_id"))
+}
+
 func (r *bookingRepository) GetScheduleByEmployee(ctx context.Context, employeeID uint) ([]domain.Schedule, error) {
 	var schedules []domain.Schedule
-	err := r.db.WithContext(ctx).Where("employee_id = ?", employeeID).Order("day_of_week ASC").Find(&schedules).Error
+	err := r.scoped(ctx).Where("employee_id = ?", employeeID).Order("day_of_week ASC").Find(&schedules).Error
 	return schedules, err
 }
 
 func (r *bookingRepository) UpdateSchedule(ctx context.Context, schedules []domain.Schedule) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		for _, s := range schedules {
+			if s.ID != 0 {
+				if err := tenant.Guard(ctx, tx, "schedules", s.ID); err != nil {
+					return err
+				}
+			}
+			s.CompanyID = tenant.ID(ctx)
 			if err := tx.Save(&s).Error; err != nil {
 				return err
 			}
@@ -34,25 +64,67 @@ func (r *bookingRepository) UpdateSchedule(ctx context.Context, schedules []doma
 }
 
 func (r *bookingRepository) CreateAppointment(ctx context.Context, appointment *domain.Appointment) error {
-	return r.db.WithContext(ctx).Create(appointment).Error
+	appointment.CompanyID = tenant.ID(ctx)
+	for i := range appointment.Reservations {
+		appointment.Reservations[i].CompanyID = appointment.CompanyID
+	}
+	return slotTaken(r.db.WithContext(ctx).Create(appointment).Error)
+}
+
+func (r *bookingRepository) GetReservations(ctx context.Context, resourceIDs []uint, start, end time.Time) ([]domain.Reservation, error) {
+	var reservations []domain.Reservation
+	if len(resourceIDs) == 0 {
+		return reservations, nil
+	}
+	err := r.db.WithContext(ctx).Scopes(tenant.Scope(ctx, "reservations.company_id")).
+		Joins("JOIN appointments ON appointments.id = reservations.appointment_id AND appointments.status != ?", domain.StatusCancelled).
+		Where("reservations.resource_id IN ? AND reservations.start_time < ? AND reservations.end_time > ?", resourceIDs, end, start).
+		Find(&reservations).Error
+	return reservations, err
 }
 
 func (r *bookingRepository) GetAppointmentsByEmployee(ctx context.Context, employeeID uint, start, end time.Time) ([]domain.Appointment, error) {
 	var appointments []domain.Appointment
-	err := r.db.WithContext(ctx).
+	err := r.scoped(ctx).
 		Where("employee_id = ? AND start_time >= ? AND start_time < ? AND status != ?", employeeID, start, end, domain.StatusCancelled).
 		Find(&appointments).Error
 	return appointments, err
 }
 
 func (r *bookingRepository) UpdateAppointmentStatus(ctx context.Context, id uint, status domain.AppointmentStatus) error {
-	return r.db.WithContext(ctx).Model(&domain.Appointment{}).Where("id = ?", id).Update("status", status).Error
+	if err := tenant.Guard(ctx, r.db, "appointments", id); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Scopes(tenant.Scope(ctx, "company_id")).Model(&domain.Appointment{}).Where("id = ?", id).Update("status", status).Error
+		if err != nil || status != domain.StatusCancelled {
+			return slotTaken(err)
+		}
+		// Cancelling releases the resources for other bookings.
+		return tx.Scopes(tenant.Scope(ctx, "company_id")).Where("appointment_id = ?", id).Delete(&domain.Reservation{}).Error
+	})
+}
+
+// exclusionViolation is the SQLSTATE of a row overlapping another one under
+// an EXCLUDE constraint.
+const exclusionViolation = "23P01"
+
+// slotTaken reports an appointment or reservation that overlaps a live one,
+// i.e. a booking that lost a race for the slot, as domain.ErrSlotUnavailable.
+func slotTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+		return domain.ErrSlotUnavailable
+	}
+	return err
 }
 
 func (r *bookingRepository) GetShiftsByEmployee(ctx context.Context, employeeID uint, start, end time.Time) ([]domain.WorkShift, error) {
 	var shifts []domain.WorkShift
-	err := r.db.WithContext(ctx).
-		Where("employee_id = ? AND date >= ? AND date <= ?", employeeID, start, end).
+	// date is a calendar day; compare it to the days of start and end in
+	// their own location, not to instants converted to the session timezone.
+	err := r.scoped(ctx).
+		Where("employee_id = ? AND date >= ? AND date <= ?", employeeID, start.Format(time.DateOnly), end.Format(time.DateOnly)).
 		Order("date ASC").
 		Find(&shifts).Error
 	return shifts, err
@@ -60,20 +132,35 @@ func (r *bookingRepository) GetShiftsByEmployee(ctx context.Context, employeeID
 
 func (r *bookingRepository) GetShiftsByBranch(ctx context.Context, branchID uint, start, end time.Time) ([]domain.WorkShift, error) {
 	var shifts []domain.WorkShift
-	err := r.db.WithContext(ctx).
+	err := r.scoped(ctx).
 		Where("branch_id = ? AND date >= ? AND date <= ?", branchID, start, end).
 		Order("date ASC, employee_id ASC").
 		Find(&shifts).Error
 	return shifts, err
 }
 
+func (r *bookingRepository) ListShifts(ctx context.Context, filter domain.ShiftFilter, page pagination.Request) (pagination.Page[domain.WorkShift], error) {
+	query := r.scoped(ctx).Where("date >= ? AND date <= ?", filter.From, filter.To)
+	if filter.BranchID > 0 {
+		query = query.Where("branch_id = ?", filter.BranchID)
+	}
+	if filter.EmployeeID > 0 {
+		query = query.Where("employee_id = ?", filter.EmployeeID)
+	}
+	return pagination.Find[domain.WorkShift](query, page)
+}
+
 func (r *bookingRepository) UpsertShifts(ctx context.Context, shifts []domain.WorkShift) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		for _, s := range shifts {
+			s.CompanyID = tenant.ID(ctx)
 			// Upsert logic: find by employee and date
 			var existing domain.WorkShift
 			res := tx.Where("employee_id = ? AND date = ?", s.EmployeeID, s.Date).First(&existing)
 			if res.Error == nil {
+				if err := tenant.Authorize(ctx, "work_shifts", existing.CompanyID); err != nil {
+					return err
+				}
 				s.ID = existing.ID
 				if err := tx.Save(&s).Error; err != nil {
 					return err
@@ -89,3 +176,64 @@ func (r *bookingRepository) UpsertShifts(ctx context.Context, shifts []domain.Wo
 		return nil
 	})
 }
+
+// SaveSuspension stores suspension unless a newer change is already recorded.
+func (r *bookingRepository) SaveSuspension(ctx context.Context, suspension *domain.Suspension) error {
+	return r.upsertNewer(ctx, "suspensions", []string{"kind", "ref_id"}, suspension, "company_id", "suspended", "changed_at")
+}
+
+func (r *bookingRepository) IsSuspended(ctx context.Context, kind domain.SuspensionKind, id uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Suspension{}).
+		Where("kind = ? AND ref_id = ? AND suspended", kind, id).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *bookingRepository) SaveBranch(ctx context.Context, branch *domain.Branch) error {
+	return r.upsertNewer(ctx, "branches", []string{"id"}, branch, "company_id", "timezone", "opening_hours", "exceptions", "changed_at")
+}
+
+func (r *bookingRepository) GetBranch(ctx context.Context, id uint) (*domain.Branch, error) {
+	var branch domain.Branch
+	err := r.db.WithContext(ctx).First(&branch, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *bookingRepository) SaveEmployeeBranch(ctx context.Context, employee *domain.EmployeeBranch) error {
+	return r.upsertNewer(ctx, "employee_branches", []string{"employee_id"}, employee, "company_id", "branch_id", "branch_ids", "changed_at")
+}
+
+func (r *bookingRepository) GetEmployeeBranch(ctx context.Context, employeeID uint) (*domain.EmployeeBranch, error) {
+	var employee domain.EmployeeBranch
+	err := r.db.WithContext(ctx).Where("employee_id = ?", employeeID).First(&employee).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// upsertNewer inserts row or updates columns of the existing one, unless that
+// has a changed_at at least as recent: events may arrive twice or out of order.
+func (r *bookingRepository) upsertNewer(ctx context.Context, table string, keys []string, row any, columns ...string) error {
+	conflict := make([]clause.Column, len(keys))
+	for i, key := range keys {
+		conflict[i] = clause.Column{Name: key}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   conflict,
+		DoUpdates: clause.AssignmentColumns(columns),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: table + ".changed_at < excluded.changed_at"},
+		}},
+	}).Create(row).Error
+}
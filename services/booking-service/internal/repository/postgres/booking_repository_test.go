@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+func TestSlotTaken(t *testing.T) {
+	other := errors.New("connection reset")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "overlap", err: &pgconn.PgError{Code: exclusionViolation, ConstraintName: "appointments_no_overlap"}, want: domain.ErrSlotUnavailable},
+		{name: "wrapped overlap", err: fmt.Errorf("insert: %w", &pgconn.PgError{Code: exclusionViolation}), want: domain.ErrSlotUnavailable},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "other error", err: other, want: other},
+		{name: "none"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := slotTaken(tc.err)
+			if tc.want == nil {
+				if got != tc.err {
+					t.Errorf("err = %v, want it unchanged", got)
+				}
+				return
+			}
+			if !errors.Is(got, tc.want) {
+				t.Errorf("err = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func newMockRepository(t *testing.T) (domain.BookingRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBookingRepository(db), mock
+}
+
+func TestUpdateAppointmentStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      domain.AppointmentStatus
+		wantRelease bool
+	}{
+		{name: "cancel releases reservations", status: domain.StatusCancelled, wantRelease: true},
+		{name: "confirm keeps reservations", status: domain.StatusConfirmed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock := newMockRepository(t)
+			mock.ExpectQuery(`SELECT "company_id" FROM "appointments" WHERE id = \$1`).
+				WithArgs(5, 1).WillReturnRows(sqlmock.NewRows([]string{"company_id"}).AddRow(1))
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "appointments" SET "status"=\$1,"updated_at"=\$2 WHERE id = \$3 AND company_id = \$4`).
+				WithArgs(tc.status, sqlmock.AnyArg(), 5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			if tc.wantRelease {
+				mock.ExpectExec(`DELETE FROM "reservations" WHERE appointment_id = \$1 AND company_id = \$2`).
+					WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 2))
+			}
+			mock.ExpectCommit()
+
+			if err := repo.UpdateAppointmentStatus(tenant.WithCompany(context.Background(), 1), 5, tc.status); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestUpdateAppointmentStatusOverlap(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	mock.ExpectQuery(`SELECT "company_id" FROM "appointments"`).WillReturnRows(sqlmock.NewRows([]string{"company_id"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "appointments"`).WillReturnError(&pgconn.PgError{Code: exclusionViolation})
+	mock.ExpectRollback()
+
+	err := repo.UpdateAppointmentStatus(tenant.WithCompany(context.Background(), 1), 5, domain.StatusConfirmed)
+	if !errors.Is(err, domain.ErrSlotUnavailable) {
+		t.Fatalf("err = %v, want %v", err, domain.ErrSlotUnavailable)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
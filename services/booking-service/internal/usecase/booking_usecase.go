@@ -5,27 +5,175 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
 	"github.com/vipos89/timehub/services/booking-service/internal/domain"
 )
 
+// slotStep is how far apart slots start; it is also their length when the
+// service's duration is unknown.
+const slotStep = 30 * time.Minute
+
 type bookingUsecase struct {
 	repo    domain.BookingRepository
 	timeout time.Duration
+	company clients.CompanyClient
 }
 
-func NewBookingUsecase(repo domain.BookingRepository, timeout time.Duration) domain.BookingUsecase {
+// NewBookingUsecase builds the usecase. Without a company client every
+// service takes slotStep and costs nothing, as for the event consumer that
+// never books.
+func NewBookingUsecase(repo domain.BookingRepository, timeout time.Duration, company clients.CompanyClient) domain.BookingUsecase {
 	return &bookingUsecase{
 		repo:    repo,
 		timeout: timeout,
+		company: company,
 	}
 }
 
-func (u *bookingUsecase) GetAvailableSlots(ctx context.Context, employeeID uint, serviceID uint, date time.Time) ([]domain.Slot, error) {
+func (u *bookingUsecase) GetAvailableSlots(ctx context.Context, employeeID uint, service domain.ServiceChoice, date time.Time) ([]domain.Slot, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
 
+	plan, err := u.quote(ctx, employeeID, service)
+	if err != nil {
+		return nil, err
+	}
+	ctx = plan.scope(ctx)
+	start := time.Now()
+	slots, err := u.availableSlots(ctx, employeeID, plan, date)
+	slotCalculationDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	slotsComputed.Add(float64(len(slots)))
+	return slots, nil
+}
+
+// servicePlan is what a service takes when an employee performs it: its
+// length, its price and the resources it holds meanwhile.
+type servicePlan struct {
+	duration time.Duration
+	price    float64
+	needs    []clients.ResourceNeed
+	// branchID is where the service is performed; 0 when unknown.
+	branchID uint
+	// companyID owns the service; 0 when unknown.
+	companyID uint
+}
+
+// scope runs the rest of a client request in the company owning the
+// service: clients book with any company, but every query stays in one.
+func (p servicePlan) scope(ctx context.Context) context.Context {
+	if p.companyID == 0 {
+		return ctx
+	}
+	return tenant.WithCompany(ctx, p.companyID)
+}
+
+// quote asks company-service how long the chosen service takes with the
+// employee, what it costs and which resources it needs.
+func (u *bookingUsecase) quote(ctx context.Context, employeeID uint, service domain.ServiceChoice) (servicePlan, error) {
+	if u.company == nil {
+		return servicePlan{duration: slotStep}, nil
+	}
+	quote, err := u.company.Quote(ctx, employeeID, service.ServiceID, service.VariantID, service.AddOnIDs)
+	if err != nil {
+		return servicePlan{}, err
+	}
+	plan := servicePlan{
+		duration:  slotStep,
+		price:     quote.Price,
+		needs:     quote.Requirements,
+		branchID:  quote.BranchID,
+		companyID: quote.CompanyID,
+	}
+	if quote.DurationMinutes > 0 {
+		plan.duration = time.Duration(quote.DurationMinutes) * time.Minute
+	}
+	return plan, nil
+}
+
+// availableSlots reads the year, month and day of date as a date at the
+// employee's branch. Slots start every slotStep and last plan.duration; a
+// free slot leaves the employee and enough resources available. There are
+// none when the employee works at another branch than the service's that day.
+func (u *bookingUsecase) availableSlots(ctx context.Context, employeeID uint, plan servicePlan, date time.Time) ([]domain.Slot, error) {
+	day, err := u.workday(ctx, employeeID, date)
+	if err != nil {
+		return nil, err
+	}
+	if !day.at(plan.branchID) {
+		return []domain.Slot{}, nil
+	}
+	held, err := u.reservations(ctx, plan.needs, day.start, day.end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate slots long enough for the chosen service
+	var slots []domain.Slot
+	for t := day.start; !t.Add(plan.duration).After(day.end); t = t.Add(slotStep) {
+		end := t.Add(plan.duration)
+		free := day.free(t, end)
+		if free {
+			_, free = pickResources(plan.needs, held, t, end)
+		}
+		slots = append(slots, domain.Slot{StartTime: t, EndTime: end, IsFree: free})
+	}
+	return slots, nil
+}
+
+// workday is when and where an employee works on a day and what they have
+// booked then.
+type workday struct {
+	start, end   time.Time
+	branchID     uint
+	appointments []domain.Appointment
+}
+
+// at reports whether the employee works at the branch that day. A nil day
+// is a day off; branch 0 stands for any.
+func (d *workday) at(branchID uint) bool {
+	return d != nil && (branchID == 0 || d.branchID == 0 || d.branchID == branchID)
+}
+
+// free reports whether [start, end) lies within the workday and overlaps no
+// appointment.
+func (d *workday) free(start, end time.Time) bool {
+	if start.Before(d.start) || end.After(d.end) {
+		return false
+	}
+	for _, app := range d.appointments {
+		if start.Before(app.EndTime) && end.After(app.StartTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// workday reads the year, month and day of date as a date at the employee's
+// branch. It returns nil when the employee does not work that day, or would
+// work at a branch they are no longer assigned to.
+func (u *bookingUsecase) workday(ctx context.Context, employeeID uint, date time.Time) (*workday, error) {
+	// The date is a day on the wall clock of the employee's branch, so find
+	// its timezone before deciding which shifts fall on it.
+	placement, err := u.repo.GetEmployeeBranch(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	var home *domain.Branch
+	if placement != nil {
+		if home, err = u.repo.GetBranch(ctx, placement.BranchID); err != nil {
+			return nil, err
+		}
+	}
+
 	// 1. Check for WorkShift override (date-specific)
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, branchLocation(home, date.Location()))
 	endOfDay := startOfDay.AddDate(0, 0, 1).Add(-time.Nanosecond)
 
 	shifts, err := u.repo.GetShiftsByEmployee(ctx, employeeID, startOfDay, endOfDay)
@@ -36,8 +184,10 @@ func (u *bookingUsecase) GetAvailableSlots(ctx context.Context, employeeID uint,
 	var startTime, endTime string
 	var isDayOff bool
 	var hasOverride bool
+	var branchID, companyID uint
 
 	if len(shifts) > 0 {
+		branchID, companyID = shifts[0].BranchID, shifts[0].CompanyID
 		startTime = shifts[0].StartTime
 		endTime = shifts[0].EndTime
 		isDayOff = shifts[0].IsDayOff
@@ -52,6 +202,7 @@ func (u *bookingUsecase) GetAvailableSlots(ctx context.Context, employeeID uint,
 
 		for _, s := range schedules {
 			if s.DayOfWeek == dayOfWeek {
+				branchID, companyID = s.BranchID, s.CompanyID
 				startTime = s.StartTime
 				endTime = s.EndTime
 				isDayOff = s.IsDayOff
@@ -62,71 +213,141 @@ func (u *bookingUsecase) GetAvailableSlots(ctx context.Context, employeeID uint,
 	}
 
 	if !hasOverride || isDayOff {
-		return []domain.Slot{}, nil
+		return nil, nil
 	}
-
-	// 3. Define working hours for the date
-	startStr := fmt.Sprintf("%s %s:00", date.Format("2006-01-02"), startTime)
-	endStr := fmt.Sprintf("%s %s:00", date.Format("2006-01-02"), endTime)
-
-	workingStart, _ := time.ParseInLocation("2006-01-02 15:04:05", startStr, date.Location())
-	workingEnd, _ := time.ParseInLocation("2006-01-02 15:04:05", endStr, date.Location())
-
-	// 3. Get existing appointments for this day
-	appointments, err := u.repo.GetAppointmentsByEmployee(ctx, employeeID, workingStart, workingEnd)
-	if err != nil {
+	if placement != nil {
+		if branchID == 0 {
+			branchID = placement.BranchID
+		}
+		if !placement.WorksAt(branchID) {
+			return nil, nil
+		}
+	}
+	suspended, err := u.suspended(ctx, employeeID, branchID, companyID)
+	if err != nil || suspended {
 		return nil, err
 	}
 
-	// 4. Generate slots (simplified: 30 min duration for now, should get from Service)
-	duration := 30 * time.Minute // TODO: Fetch from company service / employee_service price matrix
-
-	var slots []domain.Slot
-	for t := workingStart; t.Add(duration).Before(workingEnd) || t.Add(duration).Equal(workingEnd); t = t.Add(duration) {
-		slot := domain.Slot{
-			StartTime: t,
-			EndTime:   t.Add(duration),
-			IsFree:    true,
+	// 3. Define working hours for the date, on the branch's wall clock and
+	// within its opening hours
+	branch := home
+	if branchID != 0 && (home == nil || home.ID != branchID) {
+		if branch, err = u.repo.GetBranch(ctx, branchID); err != nil {
+			return nil, err
 		}
-
-		// Check overlap with appointments
-		for _, app := range appointments {
-			if t.Before(app.EndTime) && slot.EndTime.After(app.StartTime) {
-				slot.IsFree = false
-				break
-			}
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, branchLocation(branch, startOfDay.Location()))
+	workingStart, workingEnd := atClock(day, startTime), atClock(day, endTime)
+	opens, closes, isOpen := openingHours(branch, day)
+	if !isOpen {
+		return nil, nil
+	}
+	if opens != "" {
+		if t := atClock(day, opens); t.After(workingStart) {
+			workingStart = t
+		}
+		if t := atClock(day, closes); t.Before(workingEnd) {
+			workingEnd = t
 		}
-		slots = append(slots, slot)
 	}
 
-	return slots, nil
+	// 4. Get existing appointments for this day
+	appointments, err := u.repo.GetAppointmentsByEmployee(ctx, employeeID, workingStart, workingEnd)
+	if err != nil {
+		return nil, err
+	}
+	return &workday{start: workingStart, end: workingEnd, branchID: branchID, appointments: appointments}, nil
 }
 
 func (u *bookingUsecase) CreateBooking(ctx context.Context, appointment *domain.Appointment) error {
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
 
-	// TODO: Add concurrency check (transactions)
-	// 1. Double check availability
-	slots, err := u.GetAvailableSlots(ctx, appointment.EmployeeID, appointment.ServiceID, appointment.StartTime)
+	// The length, price and resources follow from the service, variant and add-ons.
+	plan, err := u.quote(ctx, appointment.EmployeeID, domain.ServiceChoice{
+		ServiceID: appointment.ServiceID,
+		VariantID: appointment.VariantID,
+		AddOnIDs:  appointment.AddOnIDs,
+	})
 	if err != nil {
+		bookingsRejected.WithLabelValues(rejectError).Inc()
 		return err
 	}
+	ctx = plan.scope(ctx)
+	appointment.EndTime = appointment.StartTime.Add(plan.duration)
+	appointment.Price = plan.price
 
+	// 1. Double check availability. The date at the branch can differ by a
+	// day from the one in the client's offset, so check the neighbours too.
+	// Requests for the same slot can pass this check together; the
+	// database then refuses all but the first (see CreateAppointment).
 	available := false
-	for _, s := range slots {
-		if s.StartTime.Equal(appointment.StartTime) && s.IsFree {
-			available = true
+	for _, offset := range []int{0, -1, 1} {
+		slots, err := u.availableSlots(ctx, appointment.EmployeeID, plan, appointment.StartTime.AddDate(0, 0, offset))
+		if err != nil {
+			bookingsRejected.WithLabelValues(rejectError).Inc()
+			return err
+		}
+		for _, s := range slots {
+			if s.StartTime.Equal(appointment.StartTime) && s.IsFree {
+				available = true
+				break
+			}
+		}
+		if available {
 			break
 		}
 	}
 
 	if !available {
-		return fmt.Errorf("slot is already taken or out of working hours")
+		bookingsRejected.WithLabelValues(rejectSlotUnavailable).Inc()
+		return domain.ErrSlotUnavailable
+	}
+	held, err := u.reservations(ctx, plan.needs, appointment.StartTime, appointment.EndTime)
+	if err != nil {
+		bookingsRejected.WithLabelValues(rejectError).Inc()
+		return err
 	}
+	reservations, ok := pickResources(plan.needs, held, appointment.StartTime, appointment.EndTime)
+	if !ok {
+		bookingsRejected.WithLabelValues(rejectSlotUnavailable).Inc()
+		return domain.ErrSlotUnavailable
+	}
+	appointment.Reservations = reservations
 
 	appointment.Status = domain.StatusConfirmed
-	return u.repo.CreateAppointment(ctx, appointment)
+	// The event is stored with the appointment and published by the outbox relay.
+	err = u.repo.Transaction(ctx, func(repo domain.BookingRepository) error {
+		if err := repo.CreateAppointment(ctx, appointment); err != nil {
+			return err
+		}
+		env, err := events.New(appointment.CompanyID, bookingCreated(appointment))
+		if err != nil {
+			return err
+		}
+		return repo.AddEvents(ctx, env)
+	})
+	if err != nil {
+		bookingsRejected.WithLabelValues(rejectReason(err)).Inc()
+		return err
+	}
+	bookingsCreated.Inc()
+	return nil
+}
+
+func bookingCreated(a *domain.Appointment) events.BookingCreated {
+	return events.BookingCreated{
+		AppointmentID: a.ID,
+		EmployeeID:    a.EmployeeID,
+		ServiceID:     a.ServiceID,
+		ClientID:      a.ClientID,
+		StartTime:     a.StartTime,
+		EndTime:       a.EndTime,
+		Price:         a.Price,
+		Status:        string(a.Status),
+		BundleID:      a.BundleID,
+		GroupID:       a.GroupID,
+	}
 }
 
 func (u *bookingUsecase) GetEmployeeSchedule(ctx context.Context, employeeID uint) ([]domain.Schedule, error) {
@@ -142,25 +363,111 @@ func (u *bookingUsecase) SetEmployeeSchedule(ctx context.Context, employeeID uin
 	for i := range schedules {
 		schedules[i].EmployeeID = employeeID
 	}
+	placement, err := u.repo.GetEmployeeBranch(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+	var fields []erru.FieldError
+	for i, s := range schedules {
+		if s.BranchID != 0 && placement != nil && !placement.WorksAt(s.BranchID) {
+			fields = append(fields, notAssigned(fmt.Sprintf("[%d].branch_id", i)))
+		}
+	}
+	if len(fields) > 0 {
+		return erru.Validation(fields...)
+	}
 
 	return u.repo.UpdateSchedule(ctx, schedules)
 }
 
-func (u *bookingUsecase) GetShifts(ctx context.Context, employeeID uint, branchID uint, month time.Time) ([]domain.WorkShift, error) {
+func (u *bookingUsecase) GetShifts(ctx context.Context, employeeID uint, branchID uint, month time.Time, page pagination.Request) (pagination.Page[domain.WorkShift], error) {
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
 
 	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
 	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
 
-	if branchID > 0 {
-		return u.repo.GetShiftsByBranch(ctx, branchID, start, end)
+	// A branch query lists every employee's shifts; employee_id then narrows it.
+	filter := domain.ShiftFilter{BranchID: branchID, From: start, To: end}
+	if branchID == 0 {
+		filter.EmployeeID = employeeID
 	}
-	return u.repo.GetShiftsByEmployee(ctx, employeeID, start, end)
+	return u.repo.ListShifts(ctx, filter, page)
 }
 
 func (u *bookingUsecase) SaveShifts(ctx context.Context, shifts []domain.WorkShift) error {
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
+
+	placements := make(map[uint]*domain.EmployeeBranch)
+	var fields []erru.FieldError
+	for i, s := range shifts {
+		placement, ok := placements[s.EmployeeID]
+		if !ok {
+			var err error
+			if placement, err = u.repo.GetEmployeeBranch(ctx, s.EmployeeID); err != nil {
+				return err
+			}
+			placements[s.EmployeeID] = placement
+		}
+		if placement != nil && !placement.WorksAt(s.BranchID) {
+			fields = append(fields, notAssigned(fmt.Sprintf("[%d].branch_id", i)))
+		}
+	}
+	if len(fields) > 0 {
+		return erru.Validation(fields...)
+	}
 	return u.repo.UpsertShifts(ctx, shifts)
 }
+
+func notAssigned(field string) erru.FieldError {
+	return erru.FieldError{Field: field, Rule: "oneof", Message: "is not a branch the employee works at"}
+}
+
+// suspended reports whether the employee, branch or company was deleted in
+// company-service. Zero IDs are not checked.
+func (u *bookingUsecase) suspended(ctx context.Context, employeeID, branchID, companyID uint) (bool, error) {
+	refs := []struct {
+		kind domain.SuspensionKind
+		id   uint
+	}{
+		{domain.SuspendEmployee, employeeID},
+		{domain.SuspendBranch, branchID},
+		{domain.SuspendCompany, companyID},
+	}
+	for _, ref := range refs {
+		if ref.id == 0 {
+			continue
+		}
+		suspended, err := u.repo.IsSuspended(ctx, ref.kind, ref.id)
+		if err != nil || suspended {
+			return suspended, err
+		}
+	}
+	return false, nil
+}
+
+func (u *bookingUsecase) Suspend(ctx context.Context, kind domain.SuspensionKind, id, companyID uint, suspended bool, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	return u.repo.SaveSuspension(ctx, &domain.Suspension{
+		Kind:      kind,
+		RefID:     id,
+		CompanyID: companyID,
+		Suspended: suspended,
+		ChangedAt: at,
+	})
+}
+
+func (u *bookingUsecase) SyncBranch(ctx context.Context, branch *domain.Branch) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.SaveBranch(ctx, branch)
+}
+
+func (u *bookingUsecase) SyncEmployee(ctx context.Context, employee *domain.EmployeeBranch) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.SaveEmployeeBranch(ctx, employee)
+}
@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// bookingRepo keeps schedules, shifts, appointments and their reservations
+// in memory. CreateAppointment refuses overlaps as the database does.
+type bookingRepo struct {
+	domain.BookingRepository
+	schedules    map[uint][]domain.Schedule
+	shifts       []domain.WorkShift
+	branches     map[uint]*domain.Branch
+	placements   map[uint]*domain.EmployeeBranch
+	appointments []domain.Appointment
+	events       []events.Envelope
+	saved        int
+}
+
+// newBookingRepo has employees working 09:00-18:00 every day.
+func newBookingRepo(employeeIDs ...uint) *bookingRepo {
+	r := &bookingRepo{
+		schedules:  make(map[uint][]domain.Schedule),
+		branches:   make(map[uint]*domain.Branch),
+		placements: make(map[uint]*domain.EmployeeBranch),
+	}
+	for _, id := range employeeIDs {
+		for day := range 7 {
+			r.schedules[id] = append(r.schedules[id], domain.Schedule{EmployeeID: id, DayOfWeek: day, StartTime: "09:00", EndTime: "18:00"})
+		}
+	}
+	return r
+}
+
+func (r *bookingRepo) Transaction(_ context.Context, fn func(repo domain.BookingRepository) error) error {
+	return fn(r)
+}
+
+func (r *bookingRepo) AddEvents(_ context.Context, envs ...events.Envelope) error {
+	r.events = append(r.events, envs...)
+	return nil
+}
+
+func (r *bookingRepo) GetScheduleByEmployee(_ context.Context, employeeID uint) ([]domain.Schedule, error) {
+	return r.schedules[employeeID], nil
+}
+
+func (r *bookingRepo) UpdateSchedule(context.Context, []domain.Schedule) error {
+	r.saved++
+	return nil
+}
+
+// GetShiftsByEmployee compares calendar days, as the repository does.
+func (r *bookingRepo) GetShiftsByEmployee(_ context.Context, employeeID uint, start, end time.Time) ([]domain.WorkShift, error) {
+	var shifts []domain.WorkShift
+	for _, s := range r.shifts {
+		day := s.Date.Format(time.DateOnly)
+		if s.EmployeeID == employeeID && day >= start.Format(time.DateOnly) && day <= end.Format(time.DateOnly) {
+			shifts = append(shifts, s)
+		}
+	}
+	return shifts, nil
+}
+
+func (r *bookingRepo) UpsertShifts(context.Context, []domain.WorkShift) error {
+	r.saved++
+	return nil
+}
+
+func (r *bookingRepo) IsSuspended(context.Context, domain.SuspensionKind, uint) (bool, error) {
+	return false, nil
+}
+
+func (r *bookingRepo) GetBranch(_ context.Context, id uint) (*domain.Branch, error) {
+	return r.branches[id], nil
+}
+
+func (r *bookingRepo) GetEmployeeBranch(_ context.Context, employeeID uint) (*domain.EmployeeBranch, error) {
+	return r.placements[employeeID], nil
+}
+
+func (r *bookingRepo) GetAppointmentsByEmployee(_ context.Context, employeeID uint, start, end time.Time) ([]domain.Appointment, error) {
+	var found []domain.Appointment
+	for _, a := range r.appointments {
+		if a.EmployeeID == employeeID && a.Status != domain.StatusCancelled && !a.StartTime.Before(start) && a.StartTime.Before(end) {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+func (r *bookingRepo) GetReservations(_ context.Context, resourceIDs []uint, start, end time.Time) ([]domain.Reservation, error) {
+	var found []domain.Reservation
+	for _, a := range r.appointments {
+		if a.Status == domain.StatusCancelled {
+			continue
+		}
+		for _, res := range a.Reservations {
+			if slices.Contains(resourceIDs, res.ResourceID) && res.StartTime.Before(end) && res.EndTime.After(start) {
+				found = append(found, res)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *bookingRepo) CreateAppointment(_ context.Context, appointment *domain.Appointment) error {
+	for _, a := range r.appointments {
+		if a.Status == domain.StatusCancelled || !appointment.StartTime.Before(a.EndTime) || !appointment.EndTime.After(a.StartTime) {
+			continue
+		}
+		if a.EmployeeID == appointment.EmployeeID {
+			return domain.ErrSlotUnavailable
+		}
+		for _, res := range appointment.Reservations {
+			if busy(res.ResourceID, a.Reservations, res.StartTime, res.EndTime) {
+				return domain.ErrSlotUnavailable
+			}
+		}
+	}
+	appointment.ID = uint(len(r.appointments) + 1)
+	r.appointments = append(r.appointments, *appointment)
+	return nil
+}
+
+// raceRepo is an employee working 09:00-18:00 every day. Transaction holds
+// every booking until all of them have passed the availability check, and
+// CreateAppointment refuses overlaps as the database's exclusion constraint does.
+type raceRepo struct {
+	domain.BookingRepository
+	checked *sync.WaitGroup
+
+	mu           sync.Mutex
+	appointments []domain.Appointment
+}
+
+func (r *raceRepo) GetScheduleByEmployee(_ context.Context, employeeID uint) ([]domain.Schedule, error) {
+	schedules := make([]domain.Schedule, 7)
+	for day := range schedules {
+		schedules[day] = domain.Schedule{EmployeeID: employeeID, DayOfWeek: day, StartTime: "09:00", EndTime: "18:00"}
+	}
+	return schedules, nil
+}
+
+func (r *raceRepo) GetShiftsByEmployee(context.Context, uint, time.Time, time.Time) ([]domain.WorkShift, error) {
+	return nil, nil
+}
+
+func (r *raceRepo) GetEmployeeBranch(context.Context, uint) (*domain.EmployeeBranch, error) {
+	return nil, nil
+}
+
+func (r *raceRepo) IsSuspended(context.Context, domain.SuspensionKind, uint) (bool, error) {
+	return false, nil
+}
+
+func (r *raceRepo) GetAppointmentsByEmployee(_ context.Context, employeeID uint, start, end time.Time) ([]domain.Appointment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []domain.Appointment
+	for _, a := range r.appointments {
+		if a.EmployeeID == employeeID && !a.StartTime.Before(start) && a.StartTime.Before(end) {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+func (r *raceRepo) Transaction(_ context.Context, fn func(repo domain.BookingRepository) error) error {
+	r.checked.Done()
+	r.checked.Wait()
+	return fn(r)
+}
+
+func (r *raceRepo) CreateAppointment(_ context.Context, appointment *domain.Appointment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.appointments {
+		if a.EmployeeID == appointment.EmployeeID && appointment.StartTime.Before(a.EndTime) && appointment.EndTime.After(a.StartTime) {
+			return domain.ErrSlotUnavailable
+		}
+	}
+	appointment.ID = uint(len(r.appointments) + 1)
+	r.appointments = append(r.appointments, *appointment)
+	return nil
+}
+
+func (r *raceRepo) AddEvents(context.Context, ...events.Envelope) error {
+	return nil
+}
+
+func TestCreateBookingConcurrently(t *testing.T) {
+	const clients = 8
+	var checked sync.WaitGroup
+	checked.Add(clients)
+	repo := &raceRepo{checked: &checked}
+	u := NewBookingUsecase(repo, time.Second, nil)
+	start := time.Date(2026, 1, 20, 10, 0, 0, 0, time.UTC)
+
+	errs := make([]error, clients)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = u.CreateBooking(context.Background(), &domain.Appointment{
+				EmployeeID: 1, ServiceID: 5, ClientID: uint(100 + i), StartTime: start,
+			})
+		}()
+	}
+	wg.Wait()
+
+	booked := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			booked++
+		case !errors.Is(err, domain.ErrSlotUnavailable):
+			t.Errorf("client %d: err = %v, want %v", i, err, domain.ErrSlotUnavailable)
+		}
+	}
+	if booked != 1 || len(repo.appointments) != 1 {
+		t.Fatalf("%d bookings succeeded and %d stored, want exactly one", booked, len(repo.appointments))
+	}
+}
+
+// assignedRepo has employee 10 assigned to branches 1 (primary) and 2, and
+// employee 11 to branch 3 only.
+func assignedRepo() *bookingRepo {
+	repo := newBookingRepo(10, 11)
+	for id := uint(1); id <= 3; id++ {
+		repo.branches[id] = &domain.Branch{ID: id, CompanyID: 1}
+	}
+	repo.placements[10] = &domain.EmployeeBranch{EmployeeID: 10, CompanyID: 1, BranchID: 1, BranchIDs: []uint{1, 2}}
+	repo.placements[11] = &domain.EmployeeBranch{EmployeeID: 11, CompanyID: 1, BranchID: 3, BranchIDs: []uint{3}}
+	return repo
+}
+
+// notAssignedAt checks err is a validation error on the branches of the
+// items at indexes.
+func notAssignedAt(t *testing.T, err error, indexes ...int) {
+	t.Helper()
+	if len(indexes) == 0 {
+		if err != nil {
+			t.Fatalf("err = %v", err)
+		}
+		return
+	}
+	var appErr *erru.AppError
+	if !errors.As(err, &appErr) || len(appErr.Fields) != len(indexes) {
+		t.Fatalf("err = %v, want branch errors at %v", err, indexes)
+	}
+	for i, f := range appErr.Fields {
+		if want := fmt.Sprintf("[%d].branch_id", indexes[i]); f.Field != want || f.Rule != "oneof" {
+			t.Errorf("field %d = %+v, want %s", i, f, want)
+		}
+	}
+}
+
+func TestSetEmployeeScheduleBranches(t *testing.T) {
+	tests := []struct {
+		name       string
+		employeeID uint
+		branches   []uint
+		wantErrAt  []int
+	}{
+		{name: "assigned branches", employeeID: 10, branches: []uint{1, 2, 0}},
+		{name: "unassigned branch", employeeID: 10, branches: []uint{1, 3, 2, 4}, wantErrAt: []int{1, 3}},
+		{name: "primary branch of another employee", employeeID: 11, branches: []uint{1}, wantErrAt: []int{0}},
+		{name: "employee without a copy", employeeID: 12, branches: []uint{3}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := assignedRepo()
+			u := NewBookingUsecase(repo, time.Second, nil)
+			schedules := make([]domain.Schedule, len(tc.branches))
+			for i, b := range tc.branches {
+				schedules[i] = domain.Schedule{BranchID: b, DayOfWeek: i, StartTime: "09:00", EndTime: "18:00"}
+			}
+
+			notAssignedAt(t, u.SetEmployeeSchedule(context.Background(), tc.employeeID, schedules), tc.wantErrAt...)
+			if saved := len(tc.wantErrAt) == 0; (repo.saved == 1) != saved {
+				t.Errorf("saved %d times, want saved = %v", repo.saved, saved)
+			}
+		})
+	}
+}
+
+func TestSaveShiftsBranches(t *testing.T) {
+	shift := func(employeeID, branchID uint) domain.WorkShift {
+		return domain.WorkShift{EmployeeID: employeeID, BranchID: branchID, Date: at("00:00"), StartTime: "09:00", EndTime: "18:00"}
+	}
+	tests := []struct {
+		name      string
+		shifts    []domain.WorkShift
+		wantErrAt []int
+	}{
+		{name: "assigned branches", shifts: []domain.WorkShift{shift(10, 1), shift(10, 2), shift(11, 3)}},
+		{name: "unassigned branch", shifts: []domain.WorkShift{shift(10, 2), shift(10, 3)}, wantErrAt: []int{1}},
+		{name: "each employee's own branches", shifts: []domain.WorkShift{shift(10, 3), shift(11, 3), shift(11, 1)}, wantErrAt: []int{0, 2}},
+		{name: "employee without a copy", shifts: []domain.WorkShift{shift(12, 3)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := assignedRepo()
+			u := NewBookingUsecase(repo, time.Second, nil)
+
+			notAssignedAt(t, u.SaveShifts(context.Background(), tc.shifts), tc.wantErrAt...)
+			if saved := len(tc.wantErrAt) == 0; (repo.saved == 1) != saved {
+				t.Errorf("saved %d times, want saved = %v", repo.saved, saved)
+			}
+		})
+	}
+}
+
+func TestSlotsAtAssignedBranches(t *testing.T) {
+	tests := []struct {
+		name       string
+		scheduleAt uint
+		shiftAt    *uint
+		serviceAt  uint
+		wantSlots  bool
+	}{
+		{name: "primary branch", scheduleAt: 1, serviceAt: 1, wantSlots: true},
+		{name: "second branch", scheduleAt: 2, serviceAt: 2, wantSlots: true},
+		{name: "schedule without a branch is at the primary", scheduleAt: 0, serviceAt: 1, wantSlots: true},
+		{name: "service at another branch that day", scheduleAt: 2, serviceAt: 1},
+		{name: "schedule without a branch, service at the second", scheduleAt: 0, serviceAt: 2},
+		{name: "schedule at an unassigned branch", scheduleAt: 3, serviceAt: 3},
+		{name: "shift at an unassigned branch", scheduleAt: 1, shiftAt: ptr(3), serviceAt: 3},
+		{name: "shift moves the day to the second branch", scheduleAt: 1, shiftAt: ptr(2), serviceAt: 2, wantSlots: true},
+		{name: "shift away from the service's branch", scheduleAt: 1, shiftAt: ptr(2), serviceAt: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := assignedRepo()
+			for i := range repo.schedules[10] {
+				repo.schedules[10][i].BranchID = tc.scheduleAt
+			}
+			if tc.shiftAt != nil {
+				repo.shifts = []domain.WorkShift{{EmployeeID: 10, BranchID: *tc.shiftAt, Date: at("00:00"), StartTime: "09:00", EndTime: "18:00"}}
+			}
+			company := &fake.Company{Services: map[uint][]clients.Service{1: {
+				{ID: 1, CompanyID: 1, BranchID: tc.serviceAt, Name: "Cut", Price: 50, DurationMinutes: 60},
+			}}}
+			u := NewBookingUsecase(repo, time.Second, company)
+
+			slots, err := u.GetAvailableSlots(context.Background(), 10, domain.ServiceChoice{ServiceID: 1}, at("00:00"))
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if got := len(slots) > 0; got != tc.wantSlots {
+				t.Fatalf("slots = %d, want any = %v", len(slots), tc.wantSlots)
+			}
+
+			err = u.CreateBooking(context.Background(), &domain.Appointment{EmployeeID: 10, ServiceID: 1, ClientID: 5, StartTime: at("10:00")})
+			if tc.wantSlots != (err == nil) || (err != nil && !errors.Is(err, domain.ErrSlotUnavailable)) {
+				t.Errorf("booking: err = %v, want booked = %v", err, tc.wantSlots)
+			}
+		})
+	}
+}
+
+func ptr(v uint) *uint { return &v }
@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// bundleStep is one item of a bundle laid out from the bundle's start.
+type bundleStep struct {
+	servicePlan
+	employeeID uint
+	service    domain.ServiceChoice
+	offset     time.Duration
+}
+
+// planBundle quotes each item of the bundle with its employee and lays the
+// items out back to back, each followed by its gap. It also returns the
+// time from the start of the first item to the end of the last. Callers run
+// the rest of the request in the bundle's company.
+func (u *bookingUsecase) planBundle(ctx context.Context, choice domain.BundleChoice) (*clients.Bundle, []bundleStep, time.Duration, error) {
+	if u.company == nil {
+		return nil, nil, 0, erru.E(erru.CodeInternal, "Bundles need company-service")
+	}
+	bundle, err := u.company.GetBundle(ctx, choice.BundleID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	items := bundle.Items
+	if n := len(choice.EmployeeIDs); n != 1 && n != len(items) {
+		return nil, nil, 0, erru.Validation(erru.FieldError{
+			Field:   "employee_ids",
+			Rule:    "len",
+			Message: fmt.Sprintf("needs one employee for all items or one per item (%d)", len(items)),
+		})
+	}
+
+	steps := make([]bundleStep, len(items))
+	var offset time.Duration
+	for i, item := range items {
+		employeeID := choice.EmployeeIDs[0]
+		if len(choice.EmployeeIDs) > 1 {
+			employeeID = choice.EmployeeIDs[i]
+		}
+		service := domain.ServiceChoice{ServiceID: item.ServiceID, VariantID: item.VariantID}
+		plan, err := u.quote(ctx, employeeID, service)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if plan.companyID != bundle.CompanyID {
+			// The employee works for another company than the one selling the bundle.
+			return nil, nil, 0, erru.ErrNotFound
+		}
+		steps[i] = bundleStep{servicePlan: plan, employeeID: employeeID, service: service, offset: offset}
+		offset += plan.duration
+		if i < len(items)-1 {
+			offset += time.Duration(item.GapMinutes) * time.Minute
+		}
+	}
+	return bundle, steps, offset, nil
+}
+
+func (u *bookingUsecase) GetBundleSlots(ctx context.Context, choice domain.BundleChoice, date time.Time) ([]domain.Slot, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	bundle, steps, total, err := u.planBundle(ctx, choice)
+	if err != nil {
+		return nil, err
+	}
+	ctx = tenant.WithCompany(ctx, bundle.CompanyID)
+	start := time.Now()
+	slots, err := u.bundleSlots(ctx, steps, total, date)
+	slotCalculationDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	slotsComputed.Add(float64(len(slots)))
+	return slots, nil
+}
+
+// bundleSlots lists the bundle's possible starts within the workday of the
+// first item's employee; a slot is free when every item fits its employee
+// and finds its resources.
+func (u *bookingUsecase) bundleSlots(ctx context.Context, steps []bundleStep, total time.Duration, date time.Time) ([]domain.Slot, error) {
+	if len(steps) == 0 {
+		return []domain.Slot{}, nil
+	}
+	days := make(map[uint]*workday, len(steps))
+	for _, step := range steps {
+		if _, ok := days[step.employeeID]; ok {
+			continue
+		}
+		day, err := u.workday(ctx, step.employeeID, date)
+		if err != nil {
+			return nil, err
+		}
+		days[step.employeeID] = day
+	}
+	// Every item is performed at its service's branch.
+	for _, step := range steps {
+		if day := days[step.employeeID]; day != nil && !day.at(step.branchID) {
+			return []domain.Slot{}, nil
+		}
+	}
+	first := days[steps[0].employeeID]
+	if first == nil {
+		return []domain.Slot{}, nil
+	}
+	held, err := u.reservations(ctx, bundleNeeds(steps), first.start, first.end.Add(total))
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []domain.Slot
+	for t := first.start; !t.Add(steps[0].duration).After(first.end); t = t.Add(slotStep) {
+		free := true
+		for _, step := range steps {
+			day, at := days[step.employeeID], t.Add(step.offset)
+			if day == nil || !day.free(at, at.Add(step.duration)) {
+				free = false
+				break
+			}
+		}
+		if free {
+			_, free = holdBundle(steps, held, t)
+		}
+		slots = append(slots, domain.Slot{StartTime: t, EndTime: t.Add(total), IsFree: free})
+	}
+	return slots, nil
+}
+
+func (u *bookingUsecase) CreateBundleBooking(ctx context.Context, choice domain.BundleChoice, clientID uint, start time.Time, comment string) (*domain.BundleBooking, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	bundle, steps, total, err := u.planBundle(ctx, choice)
+	if err != nil {
+		bookingsRejected.WithLabelValues(rejectError).Inc()
+		return nil, err
+	}
+	ctx = tenant.WithCompany(ctx, bundle.CompanyID)
+
+	// As for single appointments, the date at the branch may differ by a day.
+	available := false
+	for _, offset := range []int{0, -1, 1} {
+		slots, err := u.bundleSlots(ctx, steps, total, start.AddDate(0, 0, offset))
+		if err != nil {
+			bookingsRejected.WithLabelValues(rejectError).Inc()
+			return nil, err
+		}
+		for _, s := range slots {
+			if s.StartTime.Equal(start) && s.IsFree {
+				available = true
+				break
+			}
+		}
+		if available {
+			break
+		}
+	}
+	if !available {
+		bookingsRejected.WithLabelValues(rejectSlotUnavailable).Inc()
+		return nil, domain.ErrSlotUnavailable
+	}
+	held, err := u.reservations(ctx, bundleNeeds(steps), start, start.Add(total))
+	if err != nil {
+		bookingsRejected.WithLabelValues(rejectError).Inc()
+		return nil, err
+	}
+	reservations, ok := holdBundle(steps, held, start)
+	if !ok {
+		bookingsRejected.WithLabelValues(rejectSlotUnavailable).Inc()
+		return nil, domain.ErrSlotUnavailable
+	}
+
+	booking := &domain.BundleBooking{
+		GroupID:   uuid.NewString(),
+		BundleID:  bundle.ID,
+		Price:     bundle.Price,
+		StartTime: start,
+		EndTime:   start.Add(total),
+	}
+	prices := splitPrice(bundle.Price, steps)
+	for i, step := range steps {
+		booking.Appointments = append(booking.Appointments, domain.Appointment{
+			EmployeeID:   step.employeeID,
+			ServiceID:    step.service.ServiceID,
+			VariantID:    step.service.VariantID,
+			ClientID:     clientID,
+			StartTime:    start.Add(step.offset),
+			EndTime:      start.Add(step.offset + step.duration),
+			Price:        prices[i],
+			BundleID:     &booking.BundleID,
+			GroupID:      booking.GroupID,
+			Status:       domain.StatusConfirmed,
+			Comment:      comment,
+			Reservations: reservations[i],
+		})
+	}
+
+	err = u.repo.Transaction(ctx, func(repo domain.BookingRepository) error {
+		for i := range booking.Appointments {
+			appointment := &booking.Appointments[i]
+			if err := repo.CreateAppointment(ctx, appointment); err != nil {
+				return err
+			}
+			env, err := events.New(appointment.CompanyID, bookingCreated(appointment))
+			if err != nil {
+				return err
+			}
+			if err := repo.AddEvents(ctx, env); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		bookingsRejected.WithLabelValues(rejectReason(err)).Inc()
+		return nil, err
+	}
+	bookingsCreated.Add(float64(len(booking.Appointments)))
+	return booking, nil
+}
+
+func bundleNeeds(steps []bundleStep) []clients.ResourceNeed {
+	var needs []clients.ResourceNeed
+	for _, step := range steps {
+		needs = append(needs, step.needs...)
+	}
+	return needs
+}
+
+// holdBundle picks the resources of every item when the bundle starts at
+// start, so that items never share a resource at the same time. ok is false
+// when an item cannot get its resources.
+func holdBundle(steps []bundleStep, held []domain.Reservation, start time.Time) (picks [][]domain.Reservation, ok bool) {
+	held = append([]domain.Reservation(nil), held...)
+	picks = make([][]domain.Reservation, len(steps))
+	for i, step := range steps {
+		at := start.Add(step.offset)
+		picked, ok := pickResources(step.needs, held, at, at.Add(step.duration))
+		if !ok {
+			return nil, false
+		}
+		picks[i] = picked
+		held = append(held, picked...)
+	}
+	return picks, true
+}
+
+// splitPrice shares the bundle price out over its items in proportion to
+// their own prices, or evenly when they have none. The shares are rounded to
+// cents and the last one takes the remainder, so they add up to price.
+func splitPrice(price float64, steps []bundleStep) []float64 {
+	shares := make([]float64, len(steps))
+	if len(steps) == 0 {
+		return shares
+	}
+	var sum float64
+	for _, step := range steps {
+		sum += step.price
+	}
+	rest := price
+	for i, step := range steps[:len(steps)-1] {
+		weight := 1 / float64(len(steps))
+		if sum > 0 {
+			weight = step.price / sum
+		}
+		shares[i] = math.Round(price*weight*100) / 100
+		rest -= shares[i]
+	}
+	shares[len(steps)-1] = math.Round(rest*100) / 100
+	return shares
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// bundleCompany sells bundle 5 of company 1 for 70: a 60-minute cut (50)
+// followed by 15 minutes of pause, then a 30-minute wash (30) followed by 10.
+func bundleCompany() *fake.Company {
+	return &fake.Company{
+		Services: map[uint][]clients.Service{1: {
+			{ID: 1, CompanyID: 1, BranchID: 1, Name: "Cut", Price: 50, DurationMinutes: 60},
+			{ID: 2, CompanyID: 1, BranchID: 1, Name: "Wash", Price: 30, DurationMinutes: 30},
+		}},
+		Bundles: map[uint]*clients.Bundle{5: {
+			ID: 5, CompanyID: 1, BranchID: 1, Price: 70,
+			Items: []clients.BundleItem{{Position: 1, ServiceID: 1, GapMinutes: 15}, {Position: 2, ServiceID: 2, GapMinutes: 10}},
+		}},
+	}
+}
+
+func at(clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(2026, 3, 10, t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+func TestGetBundleSlots(t *testing.T) {
+	tests := []struct {
+		name      string
+		employees []uint
+		busy      []domain.Appointment
+		wantFree  map[string]bool
+	}{
+		{
+			// The slot spans both items but not the gap after the last.
+			name: "one employee", employees: []uint{10},
+			wantFree: map[string]bool{"09:00": true, "16:00": true, "16:30": false},
+		},
+		{
+			name: "one employee per item", employees: []uint{10, 11},
+			busy:     []domain.Appointment{{EmployeeID: 11, StartTime: at("10:15"), EndTime: at("10:45")}},
+			wantFree: map[string]bool{"09:00": false, "09:30": true},
+		},
+		{
+			name: "first employee busy", employees: []uint{10, 11},
+			busy:     []domain.Appointment{{EmployeeID: 10, StartTime: at("09:30"), EndTime: at("10:00")}},
+			wantFree: map[string]bool{"09:00": false, "10:00": true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newBookingRepo(10, 11)
+			repo.appointments = tc.busy
+			u := NewBookingUsecase(repo, time.Second, bundleCompany())
+
+			slots, err := u.GetBundleSlots(context.Background(), domain.BundleChoice{BundleID: 5, EmployeeIDs: tc.employees}, at("00:00"))
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if len(slots) == 0 || !slots[0].StartTime.Equal(at("09:00")) || !slots[len(slots)-1].StartTime.Equal(at("17:00")) {
+				t.Fatalf("slots from %v to %v, want 09:00 to 17:00", slots[0].StartTime, slots[len(slots)-1].StartTime)
+			}
+			for _, s := range slots {
+				if d := s.EndTime.Sub(s.StartTime); d != 105*time.Minute {
+					t.Fatalf("slot at %s lasts %v, want 1h45m", s.StartTime.Format("15:04"), d)
+				}
+				if want, ok := tc.wantFree[s.StartTime.Format("15:04")]; ok && s.IsFree != want {
+					t.Errorf("slot at %s free = %v, want %v", s.StartTime.Format("15:04"), s.IsFree, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanBundleErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		employees []uint
+		company   uint
+		wantErr   error
+		wantField string
+	}{
+		{name: "employee per item mismatch", employees: []uint{10, 11, 12}, company: 1, wantField: "employee_ids"},
+		{name: "no employee", company: 1, wantField: "employee_ids"},
+		{name: "service of another company", employees: []uint{10}, company: 2, wantErr: erru.ErrNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			company := bundleCompany()
+			company.Bundles[5].CompanyID = tc.company
+			u := NewBookingUsecase(newBookingRepo(10, 11), time.Second, company)
+
+			_, err := u.GetBundleSlots(context.Background(), domain.BundleChoice{BundleID: 5, EmployeeIDs: tc.employees}, at("00:00"))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || len(appErr.Fields) != 1 || appErr.Fields[0].Field != tc.wantField {
+				t.Fatalf("err = %v, want a validation error on %s", err, tc.wantField)
+			}
+		})
+	}
+}
+
+func TestCreateBundleBooking(t *testing.T) {
+	repo := newBookingRepo(10, 11)
+	company := bundleCompany()
+	// Both items need one of rooms 7 and 8; room 7 is taken until 11:00.
+	rooms := []clients.ResourceNeed{{TypeID: 3, Quantity: 1, ResourceIDs: []uint{7, 8}}}
+	company.Requirements = map[uint][]clients.ResourceNeed{1: rooms, 2: rooms}
+	repo.appointments = []domain.Appointment{{
+		ID: 99, EmployeeID: 12, StartTime: at("09:00"), EndTime: at("11:00"),
+		Reservations: []domain.Reservation{{ResourceID: 7, TypeID: 3, StartTime: at("09:00"), EndTime: at("11:00")}},
+	}}
+	u := NewBookingUsecase(repo, time.Second, company)
+	choice := domain.BundleChoice{BundleID: 5, EmployeeIDs: []uint{10, 11}}
+
+	booking, err := u.CreateBundleBooking(context.Background(), choice, 42, at("10:00"), "first visit")
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if !booking.EndTime.Equal(at("11:45")) || booking.Price != 70 || len(booking.Appointments) != 2 {
+		t.Fatalf("booking = %+v, want 10:00-11:45 for 70 in two appointments", booking)
+	}
+	want := []struct {
+		employee    uint
+		start, end  string
+		price       float64
+		resourceIDs []uint
+	}{
+		{employee: 10, start: "10:00", end: "11:00", price: 43.75, resourceIDs: []uint{8}},
+		{employee: 11, start: "11:15", end: "11:45", price: 26.25, resourceIDs: []uint{7}},
+	}
+	for i, a := range booking.Appointments {
+		w := want[i]
+		if a.EmployeeID != w.employee || !a.StartTime.Equal(at(w.start)) || !a.EndTime.Equal(at(w.end)) || a.Price != w.price {
+			t.Errorf("appointment %d = %d %s-%s for %v, want %d %s-%s for %v", i,
+				a.EmployeeID, a.StartTime.Format("15:04"), a.EndTime.Format("15:04"), a.Price, w.employee, w.start, w.end, w.price)
+		}
+		if a.GroupID != booking.GroupID || a.BundleID == nil || *a.BundleID != 5 || a.ClientID != 42 || a.Status != domain.StatusConfirmed {
+			t.Errorf("appointment %d = %+v, want it in the bundle's group", i, a)
+		}
+		if len(a.Reservations) != len(w.resourceIDs) || a.Reservations[0].ResourceID != w.resourceIDs[0] {
+			t.Errorf("appointment %d holds %+v, want rooms %v", i, a.Reservations, w.resourceIDs)
+		}
+	}
+	if len(repo.events) != 2 {
+		t.Errorf("events = %d, want one per appointment", len(repo.events))
+	}
+
+	// Employee 10 is now busy from 10:00 to 11:00.
+	_, err = u.CreateBundleBooking(context.Background(), choice, 43, at("10:30"), "")
+	if !errors.Is(err, domain.ErrSlotUnavailable) {
+		t.Errorf("overlapping bundle: err = %v, want %v", err, domain.ErrSlotUnavailable)
+	}
+}
+
+func TestHoldBundle(t *testing.T) {
+	room := clients.ResourceNeed{TypeID: 3, Quantity: 1, ResourceIDs: []uint{7, 8}}
+	step := func(offset, duration time.Duration) bundleStep {
+		return bundleStep{servicePlan: servicePlan{duration: duration, needs: []clients.ResourceNeed{room}}, offset: offset}
+	}
+	tests := []struct {
+		name    string
+		steps   []bundleStep
+		held    []domain.Reservation
+		want    []uint
+		wantOK  bool
+		wantNil bool
+	}{
+		{name: "back to back reuse the room", steps: []bundleStep{step(0, time.Hour), step(time.Hour, time.Hour)}, want: []uint{7, 7}, wantOK: true},
+		{name: "overlapping items never share", steps: []bundleStep{step(0, time.Hour), step(30*time.Minute, time.Hour)}, want: []uint{7, 8}, wantOK: true},
+		{
+			name:  "room taken during the second item",
+			steps: []bundleStep{step(0, time.Hour), step(30*time.Minute, time.Hour)},
+			held:  []domain.Reservation{{ResourceID: 8, StartTime: at("10:45"), EndTime: at("11:00")}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			picks, ok := holdBundle(tc.steps, tc.held, at("10:00"))
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !ok {
+				if picks != nil {
+					t.Errorf("picks = %+v, want none", picks)
+				}
+				return
+			}
+			for i, p := range picks {
+				if len(p) != 1 || p[0].ResourceID != tc.want[i] {
+					t.Errorf("item %d holds %+v, want room %d", i, p, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitPrice(t *testing.T) {
+	priced := func(prices ...float64) []bundleStep {
+		steps := make([]bundleStep, len(prices))
+		for i, p := range prices {
+			steps[i].price = p
+		}
+		return steps
+	}
+	tests := []struct {
+		name  string
+		price float64
+		steps []bundleStep
+		want  []float64
+	}{
+		{name: "proportional", price: 70, steps: priced(50, 30), want: []float64{43.75, 26.25}},
+		{name: "even without prices", price: 100, steps: priced(0, 0, 0), want: []float64{33.33, 33.33, 33.34}},
+		{name: "rounded to cents", price: 10, steps: priced(1, 1, 1), want: []float64{3.33, 3.33, 3.34}},
+		{name: "single item", price: 19.99, steps: priced(25), want: []float64{19.99}},
+		{name: "no items", price: 10, want: []float64{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := splitPrice(tc.price, tc.steps)
+			if len(got) != len(tc.want) {
+				t.Fatalf("shares = %v, want %v", got, tc.want)
+			}
+			var sum float64
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("shares = %v, want %v", got, tc.want)
+				}
+				sum += got[i]
+			}
+			if len(got) > 0 && int(sum*100+0.5) != int(tc.price*100+0.5) {
+				t.Errorf("shares add up to %v, want %v", sum, tc.price)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// branchLocation is the branch timezone, or fallback for branches that
+// booking-service has not heard of yet.
+func branchLocation(branch *domain.Branch, fallback *time.Location) *time.Location {
+	if branch == nil {
+		return fallback
+	}
+	loc, err := time.LoadLocation(branch.Timezone)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// openingHours returns the "HH:MM" hours of branch on day. Empty hours with
+// isOpen mean the branch does not restrict that day.
+func openingHours(branch *domain.Branch, day time.Time) (opens, closes string, isOpen bool) {
+	if branch == nil {
+		return "", "", true
+	}
+	date := day.Format(time.DateOnly)
+	for _, x := range branch.Exceptions {
+		if x.Date == date {
+			return x.Open, x.Close, !x.Closed
+		}
+	}
+	if len(branch.OpeningHours) == 0 {
+		return "", "", true
+	}
+	for _, h := range branch.OpeningHours {
+		if h.DayOfWeek == int(day.Weekday()) {
+			return h.Open, h.Close, true
+		}
+	}
+	return "", "", false
+}
+
+// atClock is the "HH:MM" wall-clock time on day, in day's location.
+func atClock(day time.Time, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
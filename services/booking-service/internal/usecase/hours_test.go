@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// Employee 10 works at branch 1 in Vladivostok (UTC+10), employee 20 at
+// branch 2 in Kaliningrad (UTC+2), both 09:00-18:00 every day.
+func zonedRepo() *bookingRepo {
+	repo := newBookingRepo(10, 20)
+	repo.branches[1] = &domain.Branch{ID: 1, CompanyID: 1, Timezone: "Asia/Vladivostok"}
+	repo.branches[2] = &domain.Branch{ID: 2, CompanyID: 1, Timezone: "Europe/Kaliningrad"}
+	repo.placements[10] = &domain.EmployeeBranch{EmployeeID: 10, CompanyID: 1, BranchID: 1}
+	repo.placements[20] = &domain.EmployeeBranch{EmployeeID: 20, CompanyID: 1, BranchID: 2}
+	return repo
+}
+
+func TestSlotsFollowBranchTimezone(t *testing.T) {
+	// Tuesday 10 March 2026 as a client in New York sends it.
+	date := time.Date(2026, 3, 10, 0, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	tests := []struct {
+		name       string
+		employeeID uint
+		setup      func(repo *bookingRepo)
+		wantFirst  time.Time
+		wantLast   time.Time
+		wantNone   bool
+	}{
+		{
+			name: "Vladivostok", employeeID: 10,
+			wantFirst: time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC),
+			wantLast:  time.Date(2026, 3, 10, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "Kaliningrad", employeeID: 20,
+			wantFirst: time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC),
+			wantLast:  time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "shift clipped to opening hours", employeeID: 10,
+			setup: func(repo *bookingRepo) {
+				repo.shifts = []domain.WorkShift{{
+					EmployeeID: 10, BranchID: 1, Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+					StartTime: "08:00", EndTime: "20:00",
+				}}
+				repo.branches[1].OpeningHours = []events.OpeningHours{{DayOfWeek: 2, Open: "10:00", Close: "16:00"}}
+			},
+			wantFirst: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+			wantLast:  time.Date(2026, 3, 10, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "closed by an exception", employeeID: 20,
+			setup: func(repo *bookingRepo) {
+				repo.branches[2].Exceptions = []events.HoursException{{Date: "2026-03-10", Closed: true}}
+			},
+			wantNone: true,
+		},
+		{
+			name: "shorter hours by an exception", employeeID: 20,
+			setup: func(repo *bookingRepo) {
+				repo.branches[2].Exceptions = []events.HoursException{{Date: "2026-03-10", Open: "12:00", Close: "14:00"}}
+			},
+			wantFirst: time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+			wantLast:  time.Date(2026, 3, 10, 11, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "closed on the weekday", employeeID: 10,
+			setup: func(repo *bookingRepo) {
+				repo.branches[1].OpeningHours = []events.OpeningHours{{DayOfWeek: 1, Open: "10:00", Close: "16:00"}}
+			},
+			wantNone: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := zonedRepo()
+			if tc.setup != nil {
+				tc.setup(repo)
+			}
+			u := NewBookingUsecase(repo, time.Second, nil)
+
+			slots, err := u.GetAvailableSlots(context.Background(), tc.employeeID, domain.ServiceChoice{ServiceID: 1}, date)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if tc.wantNone {
+				if len(slots) != 0 {
+					t.Fatalf("slots = %d from %v, want none", len(slots), slots[0].StartTime)
+				}
+				return
+			}
+			if len(slots) == 0 {
+				t.Fatal("no slots")
+			}
+			first, last := slots[0].StartTime, slots[len(slots)-1].StartTime
+			if !first.Equal(tc.wantFirst) || !last.Equal(tc.wantLast) {
+				t.Errorf("slots from %v to %v, want %v to %v", first.UTC(), last.UTC(), tc.wantFirst, tc.wantLast)
+			}
+		})
+	}
+}
+
+func TestCreateBookingOnBranchDay(t *testing.T) {
+	vladivostok, _ := time.LoadLocation("Asia/Vladivostok")
+	tests := []struct {
+		name       string
+		employeeID uint
+		start      time.Time
+		wantErr    error
+	}{
+		// 09:00 on 11 March in Vladivostok is still 10 March in UTC.
+		{name: "next day at the branch", employeeID: 10, start: time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC)},
+		// 17:30 on 10 March in Kaliningrad is already 11 March in Vladivostok.
+		{name: "previous day at the branch", employeeID: 20, start: time.Date(2026, 3, 11, 1, 30, 0, 0, vladivostok)},
+		{name: "after hours", employeeID: 20, start: time.Date(2026, 3, 10, 16, 30, 0, 0, time.UTC), wantErr: domain.ErrSlotUnavailable},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := zonedRepo()
+			u := NewBookingUsecase(repo, time.Second, nil)
+
+			err := u.CreateBooking(context.Background(), &domain.Appointment{EmployeeID: tc.employeeID, ServiceID: 1, ClientID: 5, StartTime: tc.start})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && len(repo.appointments) != 1 {
+				t.Errorf("appointments = %d, want 1", len(repo.appointments))
+			}
+		})
+	}
+}
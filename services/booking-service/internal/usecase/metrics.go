@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/vipos89/timehub/pkg/metrics"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// Reasons a booking request is rejected.
+const (
+	rejectSlotUnavailable = "slot_unavailable"
+	rejectError           = "error"
+)
+
+// rejectReason labels a failed insert: a booking that lost a race for its
+// slot is refused by the database with domain.ErrSlotUnavailable.
+func rejectReason(err error) string {
+	if errors.Is(err, domain.ErrSlotUnavailable) {
+		return rejectSlotUnavailable
+	}
+	return rejectError
+}
+
+var (
+	bookingsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "bookings_created_total",
+		Help:      "Appointments booked successfully.",
+	})
+
+	bookingsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "bookings_rejected_total",
+		Help:      "Booking attempts that were refused, by reason.",
+	}, []string{"reason"})
+
+	slotsComputed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "slots_computed_total",
+		Help:      "Slots generated by availability lookups.",
+	})
+
+	slotCalculationDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "slot_calculation_duration_seconds",
+		Help:      "Time spent calculating available slots per request.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	})
+)
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+// reservations loads what already holds the resources of needs during
+// [start, end). Without needs there is nothing to load.
+func (u *bookingUsecase) reservations(ctx context.Context, needs []clients.ResourceNeed, start, end time.Time) ([]domain.Reservation, error) {
+	var ids []uint
+	for _, need := range needs {
+		ids = append(ids, need.ResourceIDs...)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return u.repo.GetReservations(ctx, ids, start, end)
+}
+
+// pickResources chooses, for every need, the first of its resources that
+// nothing in held uses during [start, end). ok is false when a need cannot
+// be met, e.g. the only laser room is taken.
+func pickResources(needs []clients.ResourceNeed, held []domain.Reservation, start, end time.Time) (picked []domain.Reservation, ok bool) {
+	for _, need := range needs {
+		count := 0
+		for _, id := range need.ResourceIDs {
+			if count == need.Quantity {
+				break
+			}
+			if busy(id, held, start, end) || busy(id, picked, start, end) {
+				continue
+			}
+			picked = append(picked, domain.Reservation{ResourceID: id, TypeID: need.TypeID, StartTime: start, EndTime: end})
+			count++
+		}
+		if count < need.Quantity {
+			return nil, false
+		}
+	}
+	return picked, true
+}
+
+func busy(resourceID uint, reservations []domain.Reservation, start, end time.Time) bool {
+	for _, r := range reservations {
+		if r.ResourceID == resourceID && start.Before(r.EndTime) && end.After(r.StartTime) {
+			return true
+		}
+	}
+	return false
+}
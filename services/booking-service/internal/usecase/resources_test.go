@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/services/booking-service/internal/domain"
+)
+
+func TestPickResources(t *testing.T) {
+	chairs := func(quantity int) clients.ResourceNeed {
+		return clients.ResourceNeed{TypeID: 3, Quantity: quantity, ResourceIDs: []uint{7, 8, 9}}
+	}
+	tests := []struct {
+		name   string
+		needs  []clients.ResourceNeed
+		held   []domain.Reservation
+		want   []uint
+		wantOK bool
+	}{
+		{name: "no needs", wantOK: true},
+		{name: "first free", needs: []clients.ResourceNeed{chairs(1)}, want: []uint{7}, wantOK: true},
+		{name: "quantity above one", needs: []clients.ResourceNeed{chairs(2)}, want: []uint{7, 8}, wantOK: true},
+		{
+			name: "busy in held", needs: []clients.ResourceNeed{chairs(2)},
+			held: []domain.Reservation{{ResourceID: 7, StartTime: at("09:30"), EndTime: at("10:30")}},
+			want: []uint{8, 9}, wantOK: true,
+		},
+		{
+			name: "held before the slot", needs: []clients.ResourceNeed{chairs(1)},
+			held: []domain.Reservation{{ResourceID: 7, StartTime: at("09:00"), EndTime: at("10:00")}},
+			want: []uint{7}, wantOK: true,
+		},
+		{name: "already picked for another need", needs: []clients.ResourceNeed{chairs(1), chairs(2)}, want: []uint{7, 8, 9}, wantOK: true},
+		{name: "need not met", needs: []clients.ResourceNeed{chairs(2), chairs(2)}},
+		{
+			name: "need not met for held", needs: []clients.ResourceNeed{chairs(2)},
+			held: []domain.Reservation{{ResourceID: 8, StartTime: at("10:00"), EndTime: at("11:00")}, {ResourceID: 9, StartTime: at("10:45"), EndTime: at("12:00")}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			picked, ok := pickResources(tc.needs, tc.held, at("10:00"), at("11:00"))
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !ok && picked != nil {
+				t.Fatalf("picked = %+v, want none", picked)
+			}
+			if len(picked) != len(tc.want) {
+				t.Fatalf("picked = %+v, want resources %v", picked, tc.want)
+			}
+			for i, p := range picked {
+				if p.ResourceID != tc.want[i] || p.TypeID != 3 || !p.StartTime.Equal(at("10:00")) || !p.EndTime.Equal(at("11:00")) {
+					t.Errorf("picked[%d] = %+v, want resource %d for 10:00-11:00", i, p, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateBookingReservesResources(t *testing.T) {
+	repo := newBookingRepo(10, 11, 12)
+	company := &fake.Company{
+		Services: map[uint][]clients.Service{1: {{ID: 1, CompanyID: 1, Name: "Pedicure", Price: 40, DurationMinutes: 60}}},
+		// Every pedicure needs two of chairs 7, 8 and 9.
+		Requirements: map[uint][]clients.ResourceNeed{1: {{TypeID: 3, Quantity: 2, ResourceIDs: []uint{7, 8, 9}}}},
+	}
+	u := NewBookingUsecase(repo, time.Second, company)
+	book := func(employeeID uint, start string) (*domain.Appointment, error) {
+		a := &domain.Appointment{EmployeeID: employeeID, ServiceID: 1, ClientID: 5, StartTime: at(start)}
+		return a, u.CreateBooking(context.Background(), a)
+	}
+
+	first, err := book(10, "10:00")
+	if err != nil {
+		t.Fatalf("first booking: %v", err)
+	}
+	if len(first.Reservations) != 2 || first.Reservations[0].ResourceID != 7 || first.Reservations[1].ResourceID != 8 {
+		t.Fatalf("first booking holds %+v, want chairs 7 and 8", first.Reservations)
+	}
+
+	// Only chair 9 is free until 11:00.
+	if _, err := book(11, "10:30"); !errors.Is(err, domain.ErrSlotUnavailable) {
+		t.Fatalf("second booking: err = %v, want %v", err, domain.ErrSlotUnavailable)
+	}
+	if len(repo.appointments) != 1 {
+		t.Errorf("appointments = %d, want 1", len(repo.appointments))
+	}
+
+	second, err := book(11, "11:00")
+	if err != nil {
+		t.Fatalf("booking after the first: %v", err)
+	}
+	if len(second.Reservations) != 2 || second.Reservations[0].ResourceID != 7 {
+		t.Errorf("booking after the first holds %+v, want chairs 7 and 8", second.Reservations)
+	}
+
+	// A cancelled booking no longer holds its chairs.
+	if _, err := book(12, "09:30"); !errors.Is(err, domain.ErrSlotUnavailable) {
+		t.Fatalf("booking over the first: err = %v, want %v", err, domain.ErrSlotUnavailable)
+	}
+	repo.appointments[0].Status = domain.StatusCancelled
+	if _, err := book(12, "09:30"); err != nil {
+		t.Errorf("booking over a cancelled one: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+-- +goose Up
+-- Written with IF NOT EXISTS so databases previously created by GORM AutoMigrate are adopted as-is.
+CREATE TABLE IF NOT EXISTS schedules (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT NOT NULL,
+    day_of_week BIGINT NOT NULL, -- 0 = Sunday, 1 = Monday, ...
+    start_time TEXT NOT NULL,    -- e.g. "09:00"
+    end_time TEXT NOT NULL,      -- e.g. "18:00"
+    is_day_off BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_schedules_employee_id ON schedules (employee_id);
+
+CREATE TABLE IF NOT EXISTS work_shifts (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT NOT NULL,
+    branch_id BIGINT NOT NULL,
+    date DATE NOT NULL,
+    start_time TEXT NOT NULL,
+    end_time TEXT NOT NULL,
+    is_day_off BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_work_shifts_employee_id ON work_shifts (employee_id);
+CREATE INDEX IF NOT EXISTS idx_work_shifts_branch_id ON work_shifts (branch_id);
+CREATE INDEX IF NOT EXISTS idx_work_shifts_date ON work_shifts (date);
+
+CREATE TABLE IF NOT EXISTS appointments (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id BIGINT NOT NULL,
+    service_id BIGINT NOT NULL,
+    client_id BIGINT NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    status TEXT DEFAULT 'pending',
+    comment TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_appointments_employee_id ON appointments (employee_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_client_id ON appointments (client_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_start_time ON appointments (start_time);
+
+-- +goose Down
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS work_shifts;
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Rows written before tenants were tracked keep company_id 0 and are only
+-- visible to the system role.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS company_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE work_shifts ADD COLUMN IF NOT EXISTS company_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS company_id BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_schedules_company_id ON schedules (company_id);
+CREATE INDEX IF NOT EXISTS idx_work_shifts_company_id ON work_shifts (company_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_company_id ON appointments (company_id);
+
+-- Row-level security: a session sees only the rows of the company in
+-- app.company_id and none while it is unset. Roles with BYPASSRLS (the
+-- database.system_role used for migrations and cross-company jobs, or a
+-- superuser) see everything; with database.row_level_security off the
+-- service must connect as such a role.
+ALTER TABLE schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE schedules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON schedules;
+CREATE POLICY tenant_isolation ON schedules USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE work_shifts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE work_shifts FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON work_shifts;
+CREATE POLICY tenant_isolation ON work_shifts USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE appointments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE appointments FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON appointments;
+CREATE POLICY tenant_isolation ON appointments USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP POLICY IF EXISTS tenant_isolation ON appointments;
+DROP POLICY IF EXISTS tenant_isolation ON work_shifts;
+DROP POLICY IF EXISTS tenant_isolation ON schedules;
+ALTER TABLE appointments DISABLE ROW LEVEL SECURITY;
+ALTER TABLE work_shifts DISABLE ROW LEVEL SECURITY;
+ALTER TABLE schedules DISABLE ROW LEVEL SECURITY;
+ALTER TABLE appointments DROP COLUMN IF EXISTS company_id;
+ALTER TABLE work_shifts DROP COLUMN IF EXISTS company_id;
+ALTER TABLE schedules DROP COLUMN IF EXISTS company_id;
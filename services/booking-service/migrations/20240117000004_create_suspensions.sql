@@ -0,0 +1,15 @@
+-- +goose Up
+-- Companies, branches and employees soft-deleted in company-service; their
+-- slots are hidden until a restore event arrives.
+CREATE TABLE IF NOT EXISTS suspensions (
+    kind TEXT NOT NULL,
+    ref_id BIGINT NOT NULL,
+    company_id BIGINT NOT NULL DEFAULT 0,
+    suspended BOOLEAN NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (kind, ref_id)
+);
+CREATE INDEX IF NOT EXISTS idx_suspensions_company_id ON suspensions (company_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS suspensions;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Copies of company-service branches and employee placements, fed by its
+-- events, so slots are built on the branch's wall clock and within its hours.
+CREATE TABLE IF NOT EXISTS branches (
+    id BIGINT PRIMARY KEY,
+    company_id BIGINT NOT NULL DEFAULT 0,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    opening_hours JSONB,
+    exceptions JSONB,
+    changed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_branches_company_id ON branches (company_id);
+
+CREATE TABLE IF NOT EXISTS employee_branches (
+    employee_id BIGINT PRIMARY KEY,
+    company_id BIGINT NOT NULL DEFAULT 0,
+    branch_id BIGINT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_employee_branches_company_id ON employee_branches (company_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS employee_branches;
+DROP TABLE IF EXISTS branches;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Appointments remember the chosen variant and add-ons and the quoted price;
+-- the end time follows from the quoted duration.
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS variant_id BIGINT;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS addon_ids JSONB;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS price NUMERIC NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE appointments DROP COLUMN IF EXISTS price;
+ALTER TABLE appointments DROP COLUMN IF EXISTS addon_ids;
+ALTER TABLE appointments DROP COLUMN IF EXISTS variant_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- The appointments of a bundle booked as one share a group_id.
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS bundle_id BIGINT;
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS group_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_appointments_group_id ON appointments (group_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_appointments_group_id;
+ALTER TABLE appointments DROP COLUMN IF EXISTS group_id;
+ALTER TABLE appointments DROP COLUMN IF EXISTS bundle_id;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Resources (rooms, chairs, devices) held by appointments, copied from the
+-- appointment's times so overlaps are found without reading appointments.
+CREATE TABLE IF NOT EXISTS reservations (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL DEFAULT 0,
+    appointment_id BIGINT NOT NULL REFERENCES appointments (id),
+    resource_id BIGINT NOT NULL,
+    type_id BIGINT NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_company_id ON reservations (company_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_appointment_id ON reservations (appointment_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_resource_id ON reservations (resource_id, start_time);
+
+ALTER TABLE reservations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reservations FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON reservations;
+CREATE POLICY tenant_isolation ON reservations USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP TABLE IF EXISTS reservations;
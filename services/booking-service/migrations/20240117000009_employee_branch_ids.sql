@@ -0,0 +1,9 @@
+-- +goose Up
+-- Employees may work at several branches: every branch they are assigned to,
+-- and the branch a weekly schedule day is spent at (0 = the primary one).
+ALTER TABLE employee_branches ADD COLUMN IF NOT EXISTS branch_ids JSONB;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS branch_id BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE schedules DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE employee_branches DROP COLUMN IF EXISTS branch_ids;
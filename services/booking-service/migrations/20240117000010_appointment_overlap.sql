@@ -0,0 +1,13 @@
+-- +goose Up
+-- btree_gist lets an exclusion constraint compare plain IDs with =.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- An employee cannot be booked twice at once, even by requests that passed
+-- the availability check together. Cancelled appointments free their time.
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
+ALTER TABLE appointments ADD CONSTRAINT appointments_no_overlap
+    EXCLUDE USING gist (employee_id WITH =, tstzrange(start_time, end_time) WITH &&)
+    WHERE (status <> 'cancelled');
+
+-- +goose Down
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
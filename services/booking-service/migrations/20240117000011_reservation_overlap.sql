@@ -0,0 +1,10 @@
+-- +goose Up
+-- Two bookings racing for one resource cannot both hold it: the second
+-- insert fails however the availability checks interleaved. A cancelled
+-- appointment releases its reservations by deleting them.
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_no_overlap;
+ALTER TABLE reservations ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (resource_id WITH =, tstzrange(start_time, end_time) WITH &&);
+
+-- +goose Down
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_no_overlap;
@@ -0,0 +1,31 @@
+// Package app assembles company-service from its layers, so it can run as
+// its own binary or be mounted into the all-in-one timehub binary.
+package app
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/services/company-service/internal/delivery/http"
+	"github.com/vipos89/timehub/services/company-service/internal/repository/postgres"
+	"github.com/vipos89/timehub/services/company-service/internal/usecase"
+	"github.com/vipos89/timehub/services/company-service/migrations"
+)
+
+// Migrations is the service's versioned SQL schema.
+var Migrations = migrations.FS
+
+// Mount registers the company, branch, catalog, employee and search routes
+// on e. Employees with an email are registered through auth; search asks
+// booking for free slots.
+func Mount(e *echo.Echo, database *gorm.DB, auth clients.AuthClient, booking clients.BookingClient, timeout time.Duration) {
+	companyRepo := postgres.NewCompanyRepository(database)
+	companyUsecase := usecase.NewCompanyUsecase(companyRepo, timeout, auth)
+	accessUsecase := usecase.NewAccessUsecase(postgres.NewAccessRepository(database), timeout)
+	http.NewCompanyHandler(e, companyUsecase, accessUsecase)
+	searchUsecase := usecase.NewSearchUsecase(postgres.NewSearchRepository(database), booking, timeout)
+	http.NewSearchHandler(e, searchUsecase)
+}
@@ -1,22 +1,29 @@
 package main
 
 import (
+	"context"
 	"log"
-	"time"
+	"os"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
 
+	"github.com/vipos89/timehub/pkg/clients"
 	"github.com/vipos89/timehub/pkg/config"
 	"github.com/vipos89/timehub/pkg/db"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/health"
+	"github.com/vipos89/timehub/pkg/identity"
 	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/metrics"
 	customMiddleware "github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/outbox"
+	"github.com/vipos89/timehub/pkg/server"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/pkg/tracing"
 
-	"github.com/vipos89/timehub/services/company-service/internal/delivery/http"
-	"github.com/vipos89/timehub/services/company-service/internal/domain"
-	"github.com/vipos89/timehub/services/company-service/internal/repository/postgres"
-	"github.com/vipos89/timehub/services/company-service/internal/usecase"
+	"github.com/vipos89/timehub/services/company-service/app"
 
 	_ "github.com/vipos89/timehub/services/company-service/docs" // for swagger docs
 )
@@ -27,63 +34,110 @@ import (
 // @host localhost:8082
 // @BasePath /
 func main() {
-	logger.Init()
-	cfg := config.Load()
+	cfg := config.MustLoad("company-service")
+	logger.Init(cfg.Log)
+	logger.Info("Configuration loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to init tracing", "error", err)
+		log.Fatalf("Failed to init tracing: %v", err)
+	}
+
+	srv := server.New(cfg.Service, cfg.Timeouts.Shutdown, cfg.Timeouts.ShutdownDelay)
+	srv.OnShutdown("tracing", shutdownTracing)
 
 	// Initialize DB (GORM)
-	database, err := db.ConnectDSN(cfg.DBUrl)
+	database, err := db.Open(cfg.Database)
 	if err != nil {
 		logger.Error("Failed to connect to database", "error", err)
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Auto Migrate
-	err = database.AutoMigrate(
-		&domain.Company{},
-		&domain.Branch{},
-		&domain.Category{},
-		&domain.Service{},
-		&domain.Employee{},
-		&domain.EmployeeService{},
-	)
+	sqlDB, _ := database.DB()
+	srv.OnShutdown("postgres", func(context.Context) error { return sqlDB.Close() })
+	if err := metrics.RegisterDB(sqlDB, cfg.Service); err != nil {
+		logger.Error("Failed to register DB metrics", "error", err)
+	}
+
+	// Migrations
+	migrator, err := db.NewMigrator(sqlDB, app.Migrations, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to load migrations", "error", err)
+		log.Fatal(err)
+	}
+	migrator.WithRole(cfg.Database.SystemRole)
+	if len(cfg.Args) > 0 && cfg.Args[0] == "migrate" {
+		err := db.RunMigrateCommand(context.Background(), migrator, cfg.Args[1:], os.Stdout)
+		if cerr := srv.Close(); cerr != nil {
+			logger.Error("Failed to release resources", "error", cerr)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
 		logger.Error("Failed to migrate database", "error", err)
 		log.Fatal(err)
 	}
-
-	sqlDB, _ := database.DB()
-	defer sqlDB.Close()
+	logger.Info("Database schema is up to date", "applied", len(applied))
 
 	logger.Info("Connected to database")
 
+	// Event Bus
+	bus, err := events.Open(cfg.Broker)
+	if err != nil {
+		logger.Error("Failed to connect to broker", "error", err)
+		log.Fatalf("Failed to connect to broker: %v", err)
+	}
+	srv.OnShutdown("broker", func(context.Context) error { return bus.Close() })
+	srv.Worker("outbox", outbox.NewRelay(database, bus, cfg.Service, cfg.Outbox).Run)
+
 	// Init Layers
-	companyRepo := postgres.NewCompanyRepository(database)
-	timeout := time.Duration(2) * time.Second
-	companyUsecase := usecase.NewCompanyUsecase(companyRepo, timeout, cfg.AuthServiceURL)
+	authClient := clients.NewAuth(cfg.Upstreams.AuthServiceURL, clients.OptionsFrom(cfg.Upstreams))
+	bookingClient := clients.NewBooking(cfg.Upstreams.BookingServiceURL, clients.OptionsFrom(cfg.Upstreams))
 
 	e := echo.New()
+	e.HTTPErrorHandler = customMiddleware.ErrorHandler
+	e.Validator = customMiddleware.NewValidator()
 
 	// Middleware
+	e.Use(tracing.Middleware(cfg.Service))
+	e.Use(middleware.RequestID())
+	e.Use(metrics.Middleware(cfg.Service))
 	e.Use(middleware.Recover())
 	e.Use(customMiddleware.RequestLogger)
+	// Only identity headers signed by the gateway (or a peer service) are trusted.
+	e.Use(identity.Trust(cfg.Auth.IdentitySecret.Reveal()))
+	e.Use(tenant.Middleware)
+	e.Use(clients.ForwardHeaders)
 	e.Use(customMiddleware.PanicRecovery)
 
 	// Handlers
-	http.NewCompanyHandler(e, companyUsecase)
+	app.Mount(e, database, authClient, bookingClient, cfg.Timeouts.Context)
 
 	// Swagger
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	// Health Check
-	e.GET("/health", func(c echo.Context) error {
-		if err := sqlDB.Ping(); err != nil {
-			return c.String(500, "Database unreachable")
-		}
-		return c.String(200, "Company Service is healthy")
-	})
+	// Metrics
+	e.GET("/metrics", metrics.Handler())
+
+	// Health Checks
+	checks := health.NewRegistry(cfg.Service, cfg.Timeouts.HealthCheck)
+	checks.Register("postgres", health.DB(sqlDB))
+	checks.RegisterOptional("auth-service", health.Service(nil, cfg.Upstreams.AuthServiceURL))
+	checks.RegisterOptional("booking-service", health.Service(nil, cfg.Upstreams.BookingServiceURL))
+	if cfg.Broker.URL != "" {
+		checks.RegisterOptional("broker", health.TCP(cfg.Broker.URL.Reveal()))
+	}
+	checks.Mount(e)
+
+	srv.HTTP(e, ":"+cfg.HTTP.Port).Health(checks)
 
-	logger.Info("Starting Company Service", "port", cfg.HTTPPort)
-	if err := e.Start(":" + cfg.HTTPPort); err != nil {
+	logger.Info("Starting Company Service", "port", cfg.HTTP.Port)
+	if err := srv.Run(); err != nil {
 		logger.Error("Server failed", "error", err)
 	}
 }
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+type bundleRequest struct {
+	Name        string              `json:"name" validate:"required,max=255"`
+	Description string              `json:"description"`
+	Price       float64             `json:"price" validate:"required,positive_money"`
+	Items       []bundleItemRequest `json:"items" validate:"required,min=2,max=20,dive"`
+}
+
+type bundleItemRequest struct {
+	ServiceID  uint  `json:"service_id" validate:"required"`
+	VariantID  *uint `json:"variant_id" validate:"omitempty,gt=0"`
+	GapMinutes int   `json:"gap_minutes" validate:"gte=0,lte=240"`
+}
+
+func (r bundleRequest) items() []domain.BundleItem {
+	items := make([]domain.BundleItem, len(r.Items))
+	for i, it := range r.Items {
+		items[i] = domain.BundleItem{ServiceID: it.ServiceID, VariantID: it.VariantID, GapMinutes: it.GapMinutes}
+	}
+	return items
+}
+
+// AddBundle godoc
+// @Summary Add a bundle to a branch
+// @Description A bundle sells services of the branch together at one price. Items are performed in the given order, each followed by its gap.
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Param input body bundleRequest true "Bundle Input"
+// @Success 201 {object} domain.Bundle
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/bundles [post]
+func (h *CompanyHandler) AddBundle(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req bundleRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	bundle, err := h.Usecase.AddBundle(c.Request().Context(), branchID, req.Name, req.Description, req.Price, req.items())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, bundle)
+}
+
+// GetBundles godoc
+// @Summary Get all bundles of a branch
+// @Tags companies
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Bundle]
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/bundles [get]
+func (h *CompanyHandler) GetBundles(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBranch, branchID); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, bundleList)
+	if err != nil {
+		return err
+	}
+	bundles, err := h.Usecase.GetBranchBundles(c.Request().Context(), branchID, page)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, bundles)
+}
+
+// GetBundle godoc
+// @Summary Get a bundle with its items in order
+// @Tags companies
+// @Produce json
+// @Param id path int true "Bundle ID"
+// @Success 200 {object} domain.Bundle
+// @Failure 404 {object} erru.Problem
+// @Router /bundles/{id} [get]
+func (h *CompanyHandler) GetBundle(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBundle, id); err != nil {
+		return err
+	}
+	bundle, err := h.Usecase.GetBundle(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, bundle)
+}
+
+// UpdateBundle godoc
+// @Summary Update a bundle
+// @Description Replaces the name, description, price and items.
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Bundle ID"
+// @Param input body bundleRequest true "Bundle Input"
+// @Success 200 {object} domain.Bundle
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /bundles/{id} [put]
+func (h *CompanyHandler) UpdateBundle(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req bundleRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBundle, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	bundle := &domain.Bundle{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Items:       req.items(),
+	}
+	if err := h.Usecase.UpdateBundle(c.Request().Context(), bundle); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, bundle)
+}
+
+// DeleteBundle godoc
+// @Summary Delete a bundle
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Bundle ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /bundles/{id} [delete]
+func (h *CompanyHandler) DeleteBundle(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBundle, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	if err := h.Usecase.DeleteBundle(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,365 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// maxCatalogRows caps each section of an imported catalog.
+const maxCatalogRows = 1000
+
+// catalogColumns is the CSV header. Each row is one category, service,
+// employee or price, as named by its type; columns that do not apply to it
+// stay empty.
+var catalogColumns = []string{"type", "key", "name", "category_key", "description", "position", "employee_key", "service_key", "price", "duration_minutes"}
+
+type catalogRequest struct {
+	Categories []catalogCategoryRequest `json:"categories" validate:"max=1000,dive"`
+	Services   []catalogServiceRequest  `json:"services" validate:"max=1000,dive"`
+	Employees  []catalogEmployeeRequest `json:"employees" validate:"max=1000,dive"`
+	Prices     []catalogPriceRequest    `json:"prices" validate:"max=1000,dive"`
+}
+
+type catalogCategoryRequest struct {
+	Key  string `json:"key" validate:"required,max=64"`
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type catalogServiceRequest struct {
+	Key             string  `json:"key" validate:"required,max=64"`
+	CategoryKey     string  `json:"category_key" validate:"max=64"`
+	Name            string  `json:"name" validate:"required,max=255"`
+	Description     string  `json:"description"`
+	Price           float64 `json:"price" validate:"omitempty,positive_money"`
+	DurationMinutes int     `json:"duration_minutes" validate:"gte=0,lte=1440"`
+}
+
+type catalogEmployeeRequest struct {
+	Key      string `json:"key" validate:"required,max=64"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Position string `json:"position" validate:"max=255"`
+}
+
+type catalogPriceRequest struct {
+	EmployeeKey     string  `json:"employee_key" validate:"required,max=64"`
+	ServiceKey      string  `json:"service_key" validate:"required,max=64"`
+	Price           float64 `json:"price" validate:"required,positive_money"`
+	DurationMinutes int     `json:"duration_minutes" validate:"required,gt=0,lte=1440"`
+}
+
+// ExportCatalog godoc
+// @Summary Export the catalog of a branch
+// @Description Categories, services, staff and the staff's prices. Rows refer to each other by key: the row's external key, or id:<id> for rows created without one. The CSV has one row per item with the columns type, key, name, category_key, description, position, employee_key, service_key, price and duration_minutes.
+// @Tags companies
+// @Produce json
+// @Produce text/csv
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} domain.Catalog
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/catalog [get]
+func (h *CompanyHandler) ExportCatalog(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return erru.Validation(erru.FieldError{Field: "format", Rule: "oneof", Message: "must be json or csv"})
+	}
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	catalog, err := h.Usecase.ExportCatalog(c.Request().Context(), branchID)
+	if err != nil {
+		return err
+	}
+	if format != "csv" {
+		return c.JSON(http.StatusOK, catalog)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="catalog-%d.csv"`, branchID))
+	res.WriteHeader(http.StatusOK)
+	return writeCatalogCSV(res, catalog)
+}
+
+// ImportCatalog godoc
+// @Summary Import a catalog into a branch
+// @Description Takes the format of the export, as JSON or as CSV (Content-Type text/csv). Rows are matched by key and created or updated, all in one transaction: nothing is written when any row is invalid. New employees and those of other branches with a listed key work at the branch afterwards. Keys of another branch's rows without an external key (id:<id>) create rows that keep the key, so exports of one branch import into another. Blank columns keep the current values. Errors name JSON rows as services[2].price and CSV rows by line, as lines[3].price.
+// @Tags companies
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Param dry_run query bool false "Only validate and report what would change"
+// @Param input body catalogRequest true "Catalog"
+// @Success 200 {object} domain.ImportReport
+// @Failure 400 {object} erru.Problem
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/catalog [post]
+func (h *CompanyHandler) ImportCatalog(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	dryRun := false
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			return erru.Validation(erru.FieldError{Field: "dry_run", Rule: "boolean", Message: "must be true or false"})
+		}
+	}
+
+	var catalog *domain.Catalog
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		catalog, err = readCatalogCSV(c, c.Request().Body)
+	} else {
+		var req catalogRequest
+		if err = middleware.BindAndValidate(c, &req); err == nil {
+			catalog = req.catalog()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	// The catalog holds the staff and their prices as well.
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageCatalog, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+
+	report, err := h.Usecase.ImportCatalog(c.Request().Context(), branchID, catalog, dryRun)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (r catalogRequest) catalog() *domain.Catalog {
+	catalog := &domain.Catalog{
+		Categories: make([]domain.CatalogCategory, len(r.Categories)),
+		Services:   make([]domain.CatalogService, len(r.Services)),
+		Employees:  make([]domain.CatalogEmployee, len(r.Employees)),
+		Prices:     make([]domain.CatalogPrice, len(r.Prices)),
+	}
+	for i, c := range r.Categories {
+		catalog.Categories[i] = c.row(0)
+	}
+	for i, s := range r.Services {
+		catalog.Services[i] = s.row(0)
+	}
+	for i, e := range r.Employees {
+		catalog.Employees[i] = e.row(0)
+	}
+	for i, p := range r.Prices {
+		catalog.Prices[i] = p.row(0)
+	}
+	return catalog
+}
+
+func (r catalogCategoryRequest) row(line int) domain.CatalogCategory {
+	return domain.CatalogCategory{Key: r.Key, Name: r.Name, Line: line}
+}
+
+func (r catalogServiceRequest) row(line int) domain.CatalogService {
+	return domain.CatalogService{
+		Key:             r.Key,
+		CategoryKey:     r.CategoryKey,
+		Name:            r.Name,
+		Description:     r.Description,
+		Price:           r.Price,
+		DurationMinutes: r.DurationMinutes,
+		Line:            line,
+	}
+}
+
+func (r catalogEmployeeRequest) row(line int) domain.CatalogEmployee {
+	return domain.CatalogEmployee{Key: r.Key, Name: r.Name, Position: r.Position, Line: line}
+}
+
+func (r catalogPriceRequest) row(line int) domain.CatalogPrice {
+	return domain.CatalogPrice{
+		EmployeeKey:     r.EmployeeKey,
+		ServiceKey:      r.ServiceKey,
+		Price:           r.Price,
+		DurationMinutes: r.DurationMinutes,
+		Line:            line,
+	}
+}
+
+func writeCatalogCSV(w io.Writer, catalog *domain.Catalog) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(catalogColumns); err != nil {
+		return err
+	}
+	row := func(values map[string]string) error {
+		record := make([]string, len(catalogColumns))
+		for i, column := range catalogColumns {
+			record[i] = values[column]
+		}
+		return out.Write(record)
+	}
+	for _, c := range catalog.Categories {
+		if err := row(map[string]string{"type": "category", "key": c.Key, "name": c.Name}); err != nil {
+			return err
+		}
+	}
+	for _, s := range catalog.Services {
+		err := row(map[string]string{
+			"type":             "service",
+			"key":              s.Key,
+			"name":             s.Name,
+			"category_key":     s.CategoryKey,
+			"description":      s.Description,
+			"price":            strconv.FormatFloat(s.Price, 'f', -1, 64),
+			"duration_minutes": strconv.Itoa(s.DurationMinutes),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range catalog.Employees {
+		if err := row(map[string]string{"type": "employee", "key": e.Key, "name": e.Name, "position": e.Position}); err != nil {
+			return err
+		}
+	}
+	for _, p := range catalog.Prices {
+		err := row(map[string]string{
+			"type":             "price",
+			"employee_key":     p.EmployeeKey,
+			"service_key":      p.ServiceKey,
+			"price":            strconv.FormatFloat(p.Price, 'f', -1, 64),
+			"duration_minutes": strconv.Itoa(p.DurationMinutes),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// readCatalogCSV reads the rows of the export's CSV, in any column order,
+// and validates each like the JSON rows. Errors name the line.
+func readCatalogCSV(c echo.Context, r io.Reader) (*domain.Catalog, error) {
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+	header, err := in.Read()
+	if err != nil {
+		return nil, erru.Wrap(err, erru.CodeBadRequest, "Invalid CSV")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["type"]; !ok {
+		return nil, erru.Validation(erru.FieldError{Field: "lines[1].type", Rule: "required", Message: "the header has no type column"})
+	}
+
+	catalog := &domain.Catalog{}
+	var fields []erru.FieldError
+	for {
+		record, err := in.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, erru.Wrap(err, erru.CodeBadRequest, "Invalid CSV")
+		}
+		line, _ := in.FieldPos(0)
+		if len(catalog.Categories)+len(catalog.Services)+len(catalog.Employees)+len(catalog.Prices) >= 4*maxCatalogRows {
+			return nil, erru.Validation(erru.FieldError{Field: fmt.Sprintf("lines[%d]", line), Rule: "max", Message: "the catalog has too many rows"})
+		}
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		fail := func(column, rule, message string) {
+			fields = append(fields, erru.FieldError{Field: fmt.Sprintf("lines[%d].%s", line, column), Rule: rule, Message: message})
+		}
+		number := func(column string) float64 {
+			raw := value(column)
+			if raw == "" {
+				return 0
+			}
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				fail(column, "numeric", "must be a number")
+			}
+			return n
+		}
+		// validate checks row like a JSON row, naming fields by line.
+		validate := func(row any) bool {
+			var appErr *erru.AppError
+			if err := c.Validate(row); errors.As(err, &appErr) {
+				for _, f := range appErr.Fields {
+					fail(f.Field, f.Rule, f.Message)
+				}
+				return false
+			}
+			return true
+		}
+
+		switch value("type") {
+		case "category":
+			row := catalogCategoryRequest{Key: value("key"), Name: value("name")}
+			if validate(&row) {
+				catalog.Categories = append(catalog.Categories, row.row(line))
+			}
+		case "service":
+			row := catalogServiceRequest{
+				Key:             value("key"),
+				CategoryKey:     value("category_key"),
+				Name:            value("name"),
+				Description:     value("description"),
+				Price:           number("price"),
+				DurationMinutes: int(number("duration_minutes")),
+			}
+			if validate(&row) {
+				catalog.Services = append(catalog.Services, row.row(line))
+			}
+		case "employee":
+			row := catalogEmployeeRequest{Key: value("key"), Name: value("name"), Position: value("position")}
+			if validate(&row) {
+				catalog.Employees = append(catalog.Employees, row.row(line))
+			}
+		case "price":
+			row := catalogPriceRequest{
+				EmployeeKey:     value("employee_key"),
+				ServiceKey:      value("service_key"),
+				Price:           number("price"),
+				DurationMinutes: int(number("duration_minutes")),
+			}
+			if validate(&row) {
+				catalog.Prices = append(catalog.Prices, row.row(line))
+			}
+		default:
+			fail("type", "oneof", "must be category, service, employee or price")
+		}
+	}
+	if len(fields) > 0 {
+		return nil, erru.Validation(fields...)
+	}
+	return catalog, nil
+}
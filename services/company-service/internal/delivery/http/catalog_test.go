@@ -0,0 +1,150 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+	"github.com/vipos89/timehub/services/company-service/internal/usecase"
+)
+
+// catalogUsecase exports a fixed catalog and keeps the one imported.
+type catalogUsecase struct {
+	companyUsecase
+	imported *domain.Catalog
+}
+
+func (u *catalogUsecase) ExportCatalog(context.Context, uint) (*domain.Catalog, error) {
+	return &domain.Catalog{
+		Categories: []domain.CatalogCategory{{Key: "hair", Name: "Hair"}},
+		Services: []domain.CatalogService{
+			{Key: "cut", CategoryKey: "hair", Name: "Cut, wash", Description: `Incl. "styling"`, Price: 25.5, DurationMinutes: 45},
+		},
+		Employees: []domain.CatalogEmployee{{Key: "id:7", Name: "Ann", Position: "Stylist"}},
+		Prices:    []domain.CatalogPrice{{EmployeeKey: "id:7", ServiceKey: "cut", Price: 30, DurationMinutes: 40}},
+	}, nil
+}
+
+func (u *catalogUsecase) ImportCatalog(_ context.Context, _ uint, catalog *domain.Catalog, dryRun bool) (*domain.ImportReport, error) {
+	u.imported = catalog
+	return &domain.ImportReport{DryRun: dryRun}, nil
+}
+
+func newCatalogServer(us domain.CompanyUsecase) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = middleware.ErrorHandler
+	e.Validator = middleware.NewValidator()
+	e.Use(tenant.Middleware)
+	NewCompanyHandler(e, us, usecase.NewAccessUsecase(newAccessRepo(), time.Second))
+	return e
+}
+
+func TestCatalogCSVRoundTrip(t *testing.T) {
+	us := &catalogUsecase{}
+	e := newCatalogServer(us)
+
+	req := httptest.NewRequest(http.MethodGet, "/branches/1/catalog?format=csv", nil)
+	req.Header.Set(middleware.HeaderUserID, owner)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("content type = %s, want text/csv", ct)
+	}
+	exported := rec.Body.Bytes()
+
+	req = httptest.NewRequest(http.MethodPost, "/branches/1/catalog?dry_run=1", bytes.NewReader(exported))
+	req.Header.Set(echo.HeaderContentType, "text/csv")
+	req.Header.Set(middleware.HeaderUserID, owner)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var report domain.ImportReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || !report.DryRun {
+		t.Fatalf("report = %s, want a dry run", rec.Body.String())
+	}
+
+	want, _ := us.ExportCatalog(context.Background(), 1)
+	got := us.imported
+	if len(got.Categories) != 1 || got.Categories[0].Key != "hair" || got.Categories[0].Line != 2 {
+		t.Errorf("categories = %+v", got.Categories)
+	}
+	if len(got.Services) != 1 {
+		t.Fatalf("services = %+v", got.Services)
+	}
+	service := got.Services[0]
+	service.Line = 0
+	if service != want.Services[0] {
+		t.Errorf("service = %+v, want %+v", service, want.Services[0])
+	}
+	if len(got.Employees) != 1 || got.Employees[0].Key != "id:7" || got.Employees[0].Position != "Stylist" {
+		t.Errorf("employees = %+v", got.Employees)
+	}
+	if len(got.Prices) != 1 || got.Prices[0].Line != 5 {
+		t.Fatalf("prices = %+v", got.Prices)
+	}
+	price := got.Prices[0]
+	price.Line = 0
+	if price != want.Prices[0] {
+		t.Errorf("price = %+v, want %+v", price, want.Prices[0])
+	}
+}
+
+func TestImportCatalogCSVErrors(t *testing.T) {
+	body := strings.Join([]string{
+		"key,type,name,price,duration_minutes",
+		"hair,category,Hair,,",
+		"cut,service,,ten,30",
+		"x,product,Shampoo,,",
+		"color,service,Color,-5,2000",
+	}, "\n")
+	us := &catalogUsecase{}
+	e := newCatalogServer(us)
+
+	req := httptest.NewRequest(http.MethodPost, "/branches/1/catalog", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	req.Header.Set(middleware.HeaderUserID, owner)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body.String())
+	}
+	if us.imported != nil {
+		t.Fatal("the usecase was called with an invalid catalog")
+	}
+
+	var problem erru.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{}
+	for _, f := range problem.Errors {
+		fields[f.Field] = f.Rule
+	}
+	for field, rule := range map[string]string{
+		"lines[3].price":            "numeric",
+		"lines[3].name":             "required",
+		"lines[4].type":             "oneof",
+		"lines[5].price":            "positive_money",
+		"lines[5].duration_minutes": "lte",
+	} {
+		if fields[field] != rule {
+			t.Errorf("%s = %q, want %q (errors %+v)", field, fields[field], rule, problem.Errors)
+		}
+	}
+}
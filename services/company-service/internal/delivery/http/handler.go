@@ -3,88 +3,194 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
 	"github.com/vipos89/timehub/services/company-service/internal/domain"
 )
 
 type CompanyHandler struct {
 	Usecase domain.CompanyUsecase
+	Access  domain.AccessUsecase
 }
 
-func NewCompanyHandler(e *echo.Echo, us domain.CompanyUsecase) {
+// NewCompanyHandler registers the routes. Changes require the caller in the
+// signed X-User-ID header to hold a permission in the company that owns the
+// target; reads stay public because clients browse the catalog before booking.
+func NewCompanyHandler(e *echo.Echo, us domain.CompanyUsecase, access domain.AccessUsecase) {
 	handler := &CompanyHandler{
 		Usecase: us,
+		Access:  access,
 	}
 
 	// Company Routes
 	e.POST("/companies", handler.CreateCompany)
 	e.GET("/companies", handler.GetCompanies)
 	e.GET("/companies/:id", handler.GetCompanyByID)
+	e.PUT("/companies/:id", handler.UpdateCompany)
+	e.DELETE("/companies/:id", handler.DeleteCompany)
+	e.POST("/companies/:id/restore", handler.RestoreCompany)
+	e.GET("/companies/:id/membership", handler.GetMembership)
 	e.POST("/companies/:id/branches", handler.AddBranch)
 	e.GET("/companies/:id/branches", handler.GetBranches)
 
+	// Branch Routes
+	e.GET("/branches/:id", handler.GetBranch)
+	e.PUT("/branches/:id", handler.UpdateBranch)
+	e.PUT("/branches/:id/hours", handler.SetBranchHours)
+	e.DELETE("/branches/:id", handler.DeleteBranch)
+	e.POST("/branches/:id/restore", handler.RestoreBranch)
+
 	// Branch-Specific Service/Category Routes
 	e.POST("/branches/:id/categories", handler.AddCategory)
 	e.GET("/branches/:id/categories", handler.GetCategories)
 	e.POST("/branches/:id/services", handler.AddService)
 	e.GET("/branches/:id/services", handler.GetServices)
 	e.PUT("/services/:id", handler.UpdateService)
+	e.POST("/services/:id/options", handler.AddServiceOption)
+	e.PUT("/options/:id", handler.UpdateServiceOption)
+	e.DELETE("/options/:id", handler.DeleteServiceOption)
+	e.POST("/branches/:id/bundles", handler.AddBundle)
+	e.GET("/branches/:id/bundles", handler.GetBundles)
+	e.GET("/bundles/:id", handler.GetBundle)
+	e.PUT("/bundles/:id", handler.UpdateBundle)
+	e.DELETE("/bundles/:id", handler.DeleteBundle)
+	e.POST("/branches/:id/resource-types", handler.AddResourceType)
+	e.GET("/branches/:id/resource-types", handler.GetResourceTypes)
+	e.PUT("/resource-types/:id", handler.UpdateResourceType)
+	e.DELETE("/resource-types/:id", handler.DeleteResourceType)
+	e.POST("/resource-types/:id/resources", handler.AddResource)
+	e.PUT("/resources/:id", handler.UpdateResource)
+	e.DELETE("/resources/:id", handler.DeleteResource)
+	e.PUT("/services/:id/requirements", handler.SetServiceRequirements)
+	e.PUT("/categories/:id", handler.UpdateCategory)
+	e.DELETE("/categories/:id", handler.DeleteCategory)
+	e.POST("/categories/:id/restore", handler.RestoreCategory)
+	e.GET("/branches/:id/catalog", handler.ExportCatalog)
+	e.POST("/branches/:id/catalog", handler.ImportCatalog)
 
 	// Employee Routes
 	e.GET("/employees", handler.GetEmployees) // Query param company_id
 	e.POST("/employees", handler.AddEmployee)
 	e.POST("/branches/:id/employees", handler.AddEmployee) // Keep for backward compatibility
+	e.GET("/branches/:id/employees", handler.GetBranchEmployees)
+	e.PUT("/employees/:id", handler.UpdateEmployee)
+	e.PUT("/employees/:id/branches", handler.SetEmployeeBranches)
+	e.DELETE("/employees/:id", handler.DeleteEmployee)
+	e.POST("/employees/:id/restore", handler.RestoreEmployee)
 	e.POST("/employees/:id/services", handler.AssignService)
 	e.DELETE("/employees/:id/services/:serviceId", handler.RemoveService)
 	e.GET("/employees/:id/services", handler.GetEmployeeMenu)
+	e.GET("/employees/:id/services/:serviceId/quote", handler.Quote)
+	e.PUT("/employees/:id/options/:optionId", handler.SetEmployeeOption)
+	e.DELETE("/employees/:id/options/:optionId", handler.RemoveEmployeeOption)
 }
 
 // Request Structs
 
 type createCompanyRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type updateCompanyRequest struct {
+	Name  string `json:"name" validate:"max=255"`
+	TaxID string `json:"tax_id" validate:"max=64"`
 }
 
 type addBranchRequest struct {
-	Name    string `json:"name" validate:"required"`
+	Name    string `json:"name" validate:"required,max=255"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+	locationRequest
+}
+
+type updateBranchRequest struct {
+	Name    string `json:"name" validate:"max=255"`
 	Address string `json:"address"`
 	Phone   string `json:"phone"`
+	locationRequest
+}
+
+type locationRequest struct {
+	Timezone  string   `json:"timezone" validate:"omitempty,timezone" example:"Asia/Vladivostok"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+}
+
+func (r locationRequest) location() domain.Location {
+	return domain.Location{Timezone: r.Timezone, Latitude: r.Latitude, Longitude: r.Longitude}
+}
+
+type openingHoursRequest struct {
+	DayOfWeek int    `json:"day_of_week" validate:"gte=0,lte=6"` // 0 = Sunday
+	OpenTime  string `json:"open_time" validate:"required,hhmm"`
+	CloseTime string `json:"close_time" validate:"required,hhmm"`
+}
+
+type hoursExceptionRequest struct {
+	Date      string `json:"date" validate:"required,datetime=2006-01-02" example:"2026-12-31"`
+	IsClosed  bool   `json:"is_closed"`
+	OpenTime  string `json:"open_time" validate:"omitempty,hhmm"`
+	CloseTime string `json:"close_time" validate:"omitempty,hhmm"`
+}
+
+type setBranchHoursRequest struct {
+	OpeningHours    []openingHoursRequest   `json:"opening_hours" validate:"dive"`
+	HoursExceptions []hoursExceptionRequest `json:"hours_exceptions" validate:"dive"`
 }
 
 type addCategoryRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type updateCategoryRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
 }
 
 type addServiceRequest struct {
-	CompanyID       uint    `json:"company_id"` // Optional or from path
 	CategoryID      *uint   `json:"category_id"`
-	Name            string  `json:"name" validate:"required"`
+	Name            string  `json:"name" validate:"required,max=255"`
 	Description     string  `json:"description"`
-	Price           float64 `json:"price"`
-	DurationMinutes int     `json:"duration_minutes"`
+	Price           float64 `json:"price" validate:"omitempty,positive_money"`
+	DurationMinutes int     `json:"duration_minutes" validate:"gte=0,lte=1440"`
 }
 
 type updateServiceRequest struct {
 	CategoryID      *uint   `json:"category_id"`
-	Name            string  `json:"name"`
+	Name            string  `json:"name" validate:"max=255"`
 	Description     string  `json:"description"`
-	Price           float64 `json:"price"`
-	DurationMinutes int     `json:"duration_minutes"`
+	Price           float64 `json:"price" validate:"omitempty,positive_money"`
+	DurationMinutes int     `json:"duration_minutes" validate:"gte=0,lte=1440"`
 }
 
 type addEmployeeRequest struct {
 	BranchID uint   `json:"branch_id"`
-	Name     string `json:"name" validate:"required"`
+	Name     string `json:"name" validate:"required,max=255"`
 	Position string `json:"position"`
-	Email    string `json:"email"` // For Auth Service registration
+	Email    string `json:"email" validate:"omitempty,email"` // For Auth Service registration
+	Role     string `json:"role" validate:"omitempty,oneof=admin master"`
+}
+
+type employeeBranchesRequest struct {
+	PrimaryBranchID uint   `json:"primary_branch_id" validate:"required"`
+	BranchIDs       []uint `json:"branch_ids" validate:"max=50,dive,required"`
+}
+
+type updateEmployeeRequest struct {
+	Name      string `json:"name" validate:"max=255"`
+	Position  string `json:"position"`
+	AvatarURL string `json:"avatar_url" validate:"omitempty,url"`
+	Role      string `json:"role" validate:"omitempty,oneof=admin master"`
 }
 
 type assignServiceRequest struct {
 	ServiceID uint    `json:"service_id" validate:"required"`
-	Price     float64 `json:"price" validate:"required"`
-	Duration  int     `json:"duration_minutes" validate:"required"`
+	Price     float64 `json:"price" validate:"required,positive_money"`
+	Duration  int     `json:"duration_minutes" validate:"required,gt=0,lte=1440"`
 }
 
 // Handlers
@@ -95,23 +201,26 @@ type assignServiceRequest struct {
 // @Tags companies
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller, becomes the owner"
 // @Param input body createCompanyRequest true "Company Input"
 // @Success 201 {object} domain.Company
-// @Failure 400 {object} erru.AppError
+// @Failure 400 {object} erru.Problem
+// @Failure 401 {object} erru.Problem
 // @Router /companies [post]
 func (h *CompanyHandler) CreateCompany(c echo.Context) error {
 	var req createCompanyRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	// Mock OwnerID from JWT (middleware should set this)
-	// ownerID := c.Get("user_id").(uint)
-	ownerID := uint(1) // Placeholder
+	ownerID, err := callerID(c)
+	if err != nil {
+		return err
+	}
 
 	company, err := h.Usecase.CreateCompany(c.Request().Context(), req.Name, ownerID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusCreated, company)
 }
@@ -120,15 +229,27 @@ func (h *CompanyHandler) CreateCompany(c echo.Context) error {
 // @Summary Get all companies owned by user
 // @Tags companies
 // @Produce json
-// @Success 200 {array} domain.Company
+// @Param X-User-ID header int true "Caller"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Company]
+// @Failure 401 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
 // @Router /companies [get]
 func (h *CompanyHandler) GetCompanies(c echo.Context) error {
-	// ownerID := c.Get("user_id").(uint)
-	ownerID := uint(1) // Placeholder
-
-	companies, err := h.Usecase.GetMyCompanies(c.Request().Context(), ownerID)
+	ownerID, err := callerID(c)
+	if err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, companyList)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+	companies, err := h.Usecase.GetMyCompanies(c.Request().Context(), ownerID, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, companies)
 }
@@ -141,36 +262,79 @@ func (h *CompanyHandler) GetCompanies(c echo.Context) error {
 // @Success 200 {object} domain.Company
 // @Router /companies/{id} [get]
 func (h *CompanyHandler) GetCompanyByID(c echo.Context) error {
-	id, _ := strconv.Atoi(c.Param("id"))
-	company, err := h.Usecase.GetCompanyByID(c.Request().Context(), uint(id))
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceCompany, id); err != nil {
+		return err
+	}
+	company, err := h.Usecase.GetCompanyByID(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	if company == nil {
-		return c.JSON(http.StatusNotFound, erru.ErrNotFound)
+		return erru.ErrNotFound
 	}
 	return c.JSON(http.StatusOK, company)
 }
 
+// GetMembership godoc
+// @Summary Get the caller's role in a company
+// @Description Used by auth-service to check a user may sign in to a company.
+// @Tags companies
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Company ID"
+// @Success 200 {object} domain.Membership
+// @Failure 401 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /companies/{id}/membership [get]
+func (h *CompanyHandler) GetMembership(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	userID, err := callerID(c)
+	if err != nil {
+		return err
+	}
+	member, err := h.Access.Membership(c.Request().Context(), id, userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, member)
+}
+
 // AddBranch godoc
 // @Summary Add a branch to a company
 // @Tags companies
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller"
 // @Param id path int true "Company ID"
 // @Param input body addBranchRequest true "Branch Input"
 // @Success 201 {object} domain.Branch
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
 // @Router /companies/{id}/branches [post]
 func (h *CompanyHandler) AddBranch(c echo.Context) error {
-	companyID, _ := strconv.Atoi(c.Param("id"))
+	companyID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
 	var req addBranchRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCompany, companyID, domain.PermissionManageBranches); err != nil {
+		return err
 	}
 
-	branch, err := h.Usecase.AddBranch(c.Request().Context(), uint(companyID), req.Name, req.Address, req.Phone)
+	branch, err := h.Usecase.AddBranch(c.Request().Context(), companyID, req.Name, req.Address, req.Phone, req.location())
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusCreated, branch)
 }
@@ -180,13 +344,28 @@ func (h *CompanyHandler) AddBranch(c echo.Context) error {
 // @Tags companies
 // @Produce json
 // @Param id path int true "Company ID"
-// @Success 200 {array} domain.Branch
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Branch]
+// @Failure 422 {object} erru.Problem
 // @Router /companies/{id}/branches [get]
 func (h *CompanyHandler) GetBranches(c echo.Context) error {
-	companyID, _ := strconv.Atoi(c.Param("id"))
-	branches, err := h.Usecase.GetCompanyBranches(c.Request().Context(), uint(companyID))
+	companyID, err := middleware.ParamID(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceCompany, companyID); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, branchList)
+	if err != nil {
+		return err
+	}
+	branches, err := h.Usecase.GetCompanyBranches(c.Request().Context(), companyID, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, branches)
 }
@@ -196,24 +375,30 @@ func (h *CompanyHandler) GetBranches(c echo.Context) error {
 // @Tags companies
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller"
 // @Param id path int true "Branch ID"
 // @Param input body addCategoryRequest true "Category Input"
 // @Success 201 {object} domain.Category
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
 // @Router /branches/{id}/categories [post]
 func (h *CompanyHandler) AddCategory(c echo.Context) error {
-	branchID, _ := strconv.Atoi(c.Param("id"))
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
 	var req addCategoryRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageCatalog); err != nil {
+		return err
 	}
 
-	// In a real app, we'd get CompanyID from the branch itself or JWT
-	// For now, let's assume companyID 1 or fetch it
-	companyID := uint(1)
-
-	cat, err := h.Usecase.AddCategory(c.Request().Context(), companyID, uint(branchID), req.Name)
+	cat, err := h.Usecase.AddCategory(c.Request().Context(), branchID, req.Name)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusCreated, cat)
 }
@@ -223,13 +408,28 @@ func (h *CompanyHandler) AddCategory(c echo.Context) error {
 // @Tags companies
 // @Produce json
 // @Param id path int true "Branch ID"
-// @Success 200 {array} domain.Category
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Category]
+// @Failure 422 {object} erru.Problem
 // @Router /branches/{id}/categories [get]
 func (h *CompanyHandler) GetCategories(c echo.Context) error {
-	branchID, _ := strconv.Atoi(c.Param("id"))
-	cats, err := h.Usecase.GetBranchCategories(c.Request().Context(), uint(branchID))
+	branchID, err := middleware.ParamID(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBranch, branchID); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, categoryList)
+	if err != nil {
+		return err
+	}
+	cats, err := h.Usecase.GetBranchCategories(c.Request().Context(), branchID, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, cats)
 }
@@ -239,25 +439,30 @@ func (h *CompanyHandler) GetCategories(c echo.Context) error {
 // @Tags companies
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller"
 // @Param id path int true "Branch ID"
 // @Param input body addServiceRequest true "Service Input"
 // @Success 201 {object} domain.Service
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
 // @Router /branches/{id}/services [post]
 func (h *CompanyHandler) AddService(c echo.Context) error {
-	branchID, _ := strconv.Atoi(c.Param("id"))
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
 	var req addServiceRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
 	}
-
-	companyID := req.CompanyID
-	if companyID == 0 {
-		companyID = uint(1)
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageCatalog); err != nil {
+		return err
 	}
 
-	svc, err := h.Usecase.AddService(c.Request().Context(), companyID, uint(branchID), req.CategoryID, req.Name, req.Description, req.Price, req.DurationMinutes)
+	svc, err := h.Usecase.AddService(c.Request().Context(), branchID, req.CategoryID, req.Name, req.Description, req.Price, req.DurationMinutes)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusCreated, svc)
 }
@@ -267,21 +472,29 @@ func (h *CompanyHandler) AddService(c echo.Context) error {
 // @Tags companies
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller"
 // @Param id path int true "Service ID"
 // @Param input body updateServiceRequest true "Service Update Input"
 // @Success 200 {object} domain.Service
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
 // @Router /services/{id} [put]
 func (h *CompanyHandler) UpdateService(c echo.Context) error {
-	id, _ := strconv.Atoi(c.Param("id"))
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
 	var req updateServiceRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceService, id, domain.PermissionManageCatalog); err != nil {
+		return err
 	}
 
-	// For simplicity, we just pass the object to usecase
-	// In a real app, we'd fetch it first to ensure it exists and belongs to the company
 	svc := &domain.Service{
-		ID:              uint(id),
+		ID:              id,
 		CategoryID:      req.CategoryID,
 		Name:            req.Name,
 		Description:     req.Description,
@@ -289,20 +502,40 @@ func (h *CompanyHandler) UpdateService(c echo.Context) error {
 		DurationMinutes: req.DurationMinutes,
 	}
 
-	err := h.Usecase.UpdateService(c.Request().Context(), svc)
+	err = h.Usecase.UpdateService(c.Request().Context(), svc)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusOK, svc)
 }
 
+// RemoveService godoc
+// @Summary Remove a service from an employee's menu
+// @Tags employees
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Param serviceId path int true "Service ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /employees/{id}/services/{serviceId} [delete]
 func (h *CompanyHandler) RemoveService(c echo.Context) error {
-	employeeID, _ := strconv.Atoi(c.Param("id"))
-	serviceID, _ := strconv.Atoi(c.Param("serviceId"))
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	serviceID, err := middleware.ParamID(c, "serviceId")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, employeeID, domain.PermissionManageStaff); err != nil {
+		return err
+	}
 
-	err := h.Usecase.RemoveService(c.Request().Context(), uint(employeeID), uint(serviceID))
+	err = h.Usecase.RemoveService(c.Request().Context(), employeeID, serviceID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.NoContent(http.StatusNoContent)
 }
@@ -312,46 +545,72 @@ func (h *CompanyHandler) RemoveService(c echo.Context) error {
 // @Tags companies
 // @Produce json
 // @Param id path int true "Branch ID"
-// @Success 200 {array} domain.Service
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Service]
+// @Failure 422 {object} erru.Problem
 // @Router /branches/{id}/services [get]
 func (h *CompanyHandler) GetServices(c echo.Context) error {
-	branchID, _ := strconv.Atoi(c.Param("id"))
-	svcs, err := h.Usecase.GetBranchServices(c.Request().Context(), uint(branchID))
+	branchID, err := middleware.ParamID(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBranch, branchID); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, serviceList)
+	if err != nil {
+		return err
+	}
+	svcs, err := h.Usecase.GetBranchServices(c.Request().Context(), branchID, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, svcs)
 }
 
 // AddEmployee godoc
 // @Summary Add an employee to a branch
+// @Description With an email the employee gets an account; the response carries its one-time password in password, which is not shown again.
 // @Tags employees
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller"
 // @Param id path int true "Branch ID"
 // @Param input body addEmployeeRequest true "Employee Input"
 // @Success 201 {object} domain.Employee
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
 // @Router /branches/{id}/employees [post]
 func (h *CompanyHandler) AddEmployee(c echo.Context) error {
 	var req addEmployeeRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	// Support both path param and body param for branchID
 	branchID := req.BranchID
-	if branchID == 0 {
-		id, _ := strconv.Atoi(c.Param("id"))
-		branchID = uint(id)
+	if branchID == 0 && c.Param("id") != "" {
+		id, err := middleware.ParamID(c, "id")
+		if err != nil {
+			return err
+		}
+		branchID = id
 	}
 
 	if branchID == 0 {
-		return c.JSON(http.StatusBadRequest, erru.New(http.StatusBadRequest, "branch_id is required"))
+		return erru.Validation(erru.FieldError{Field: "branch_id", Rule: "required", Message: "branch_id is required"})
+	}
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageStaff); err != nil {
+		return err
 	}
 
-	emp, err := h.Usecase.AddEmployee(c.Request().Context(), branchID, req.Name, req.Position, req.Email)
+	emp, err := h.Usecase.AddEmployee(c.Request().Context(), branchID, req.Name, req.Position, req.Email, domain.Role(req.Role))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusCreated, emp)
 }
@@ -361,36 +620,134 @@ func (h *CompanyHandler) AddEmployee(c echo.Context) error {
 // @Tags employees
 // @Produce json
 // @Param company_id query int true "Company ID"
-// @Success 200 {array} domain.Employee
+// @Param branch_id query int false "Only employees working at this branch"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Employee]
+// @Failure 422 {object} erru.Problem
 // @Router /employees [get]
 func (h *CompanyHandler) GetEmployees(c echo.Context) error {
-	companyID, _ := strconv.Atoi(c.QueryParam("company_id"))
-	emps, err := h.Usecase.GetCompanyEmployees(c.Request().Context(), uint(companyID))
+	companyID, err := middleware.QueryID(c, "company_id", true)
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceCompany, companyID); err != nil {
+		return err
+	}
+	branchID, err := middleware.QueryID(c, "branch_id", false)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+	page, err := pagination.Parse(c, employeeList)
+	if err != nil {
+		return err
+	}
+	emps, err := h.Usecase.GetCompanyEmployees(c.Request().Context(), companyID, branchID, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, emps)
 }
 
+// GetBranchEmployees godoc
+// @Summary Get employees working at a branch
+// @Description Includes employees whose primary branch is another one.
+// @Tags employees
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.Employee]
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/employees [get]
+func (h *CompanyHandler) GetBranchEmployees(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBranch, branchID); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, employeeList)
+	if err != nil {
+		return err
+	}
+	emps, err := h.Usecase.GetBranchEmployees(c.Request().Context(), branchID, page)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, emps)
+}
+
+// SetEmployeeBranches godoc
+// @Summary Set the branches an employee works at
+// @Description Replaces the assignments. The primary branch is always one of them; the shifts decide where the employee works on a given day.
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Param input body employeeBranchesRequest true "Branches"
+// @Success 200 {object} domain.Employee
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /employees/{id}/branches [put]
+func (h *CompanyHandler) SetEmployeeBranches(c echo.Context) error {
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req employeeBranchesRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, employeeID, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+
+	employee, err := h.Usecase.SetEmployeeBranches(c.Request().Context(), employeeID, req.PrimaryBranchID, req.BranchIDs)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, employee)
+}
+
 // AssignService godoc
 // @Summary Assign service to employee with price
 // @Tags employees
 // @Accept json
 // @Produce json
+// @Param X-User-ID header int true "Caller"
 // @Param id path int true "Employee ID"
 // @Param input body assignServiceRequest true "Assignment Input"
 // @Success 200 {string} string "Assigned"
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
 // @Router /employees/{id}/services [post]
 func (h *CompanyHandler) AssignService(c echo.Context) error {
-	employeeID, _ := strconv.Atoi(c.Param("id"))
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
 	var req assignServiceRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, erru.ErrBadRequest)
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, employeeID, domain.PermissionManageStaff); err != nil {
+		return err
 	}
 
-	err := h.Usecase.AssignService(c.Request().Context(), uint(employeeID), req.ServiceID, req.Price, req.Duration)
+	err = h.Usecase.AssignService(c.Request().Context(), employeeID, req.ServiceID, req.Price, req.Duration)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
 	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "assigned"})
 }
@@ -400,14 +757,507 @@ func (h *CompanyHandler) AssignService(c echo.Context) error {
 // @Tags employees
 // @Produce json
 // @Param id path int true "Employee ID"
-// @Success 200 {array} domain.EmployeeService
+// @Param branch_id query int false "Only services of this branch"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.EmployeeService]
+// @Failure 422 {object} erru.Problem
 // @Router /employees/{id}/services [get]
 func (h *CompanyHandler) GetEmployeeMenu(c echo.Context) error {
-	employeeID, _ := strconv.Atoi(c.Param("id"))
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceEmployee, employeeID); err != nil {
+		return err
+	}
+	branchID, err := middleware.QueryID(c, "branch_id", false)
+	if err != nil {
+		return err
+	}
 
-	menu, err := h.Usecase.GetEmployeeMenu(c.Request().Context(), uint(employeeID))
+	page, err := pagination.Parse(c, menuList)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, erru.New(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+	menu, err := h.Usecase.GetEmployeeMenu(c.Request().Context(), employeeID, branchID, page)
+	if err != nil {
+		return err
 	}
 	return c.JSON(http.StatusOK, menu)
 }
+
+// UpdateCompany godoc
+// @Summary Update company details
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Company ID"
+// @Param input body updateCompanyRequest true "Company Update Input"
+// @Success 200 {object} domain.Company
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /companies/{id} [put]
+func (h *CompanyHandler) UpdateCompany(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req updateCompanyRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCompany, id, domain.PermissionManageCompany); err != nil {
+		return err
+	}
+
+	company := &domain.Company{
+		ID:    id,
+		Name:  req.Name,
+		TaxID: req.TaxID,
+	}
+	if err := h.Usecase.UpdateCompany(c.Request().Context(), company); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, company)
+}
+
+// DeleteCompany godoc
+// @Summary Delete a company
+// @Description Soft-deletes the company with its branches, staff and catalog; booking stops offering its slots
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Company ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /companies/{id} [delete]
+func (h *CompanyHandler) DeleteCompany(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCompany, id, domain.PermissionManageCompany); err != nil {
+		return err
+	}
+	if err := h.Usecase.DeleteCompany(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreCompany godoc
+// @Summary Restore a deleted company
+// @Description Restores the company with the branches, staff and catalog deleted along with it
+// @Tags companies
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Company ID"
+// @Success 200 {object} domain.Company
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /companies/{id}/restore [post]
+func (h *CompanyHandler) RestoreCompany(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCompany, id, domain.PermissionManageCompany); err != nil {
+		return err
+	}
+	company, err := h.Usecase.RestoreCompany(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, company)
+}
+
+// GetBranch godoc
+// @Summary Get branch details by ID
+// @Description Includes the branch timezone, location and opening hours
+// @Tags companies
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {object} domain.Branch
+// @Failure 404 {object} erru.Problem
+// @Router /branches/{id} [get]
+func (h *CompanyHandler) GetBranch(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBranch, id); err != nil {
+		return err
+	}
+	branch, err := h.Usecase.GetBranch(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, branch)
+}
+
+// SetBranchHours godoc
+// @Summary Replace branch opening hours
+// @Description Sets the weekly hours and date exceptions, in the branch timezone. Weekdays without hours are closed; empty lists remove all restrictions.
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Param input body setBranchHoursRequest true "Opening hours"
+// @Success 200 {object} domain.Branch
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/hours [put]
+func (h *CompanyHandler) SetBranchHours(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req setBranchHoursRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, id, domain.PermissionManageBranches); err != nil {
+		return err
+	}
+
+	hours := make([]domain.OpeningHours, len(req.OpeningHours))
+	for i, r := range req.OpeningHours {
+		hours[i] = domain.OpeningHours{DayOfWeek: r.DayOfWeek, OpenTime: r.OpenTime, CloseTime: r.CloseTime}
+	}
+	exceptions := make([]domain.HoursException, len(req.HoursExceptions))
+	for i, r := range req.HoursExceptions {
+		// Already checked by the datetime rule.
+		date, _ := time.Parse(time.DateOnly, r.Date)
+		exceptions[i] = domain.HoursException{Date: date, IsClosed: r.IsClosed, OpenTime: r.OpenTime, CloseTime: r.CloseTime}
+	}
+	branch, err := h.Usecase.SetBranchHours(c.Request().Context(), id, hours, exceptions)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, branch)
+}
+
+// UpdateBranch godoc
+// @Summary Update branch details
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Param input body updateBranchRequest true "Branch Update Input"
+// @Success 200 {object} domain.Branch
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /branches/{id} [put]
+func (h *CompanyHandler) UpdateBranch(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req updateBranchRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, id, domain.PermissionManageBranches); err != nil {
+		return err
+	}
+
+	branch := &domain.Branch{
+		ID:       id,
+		Name:     req.Name,
+		Address:  req.Address,
+		Phone:    req.Phone,
+		Location: req.location(),
+	}
+	if err := h.Usecase.UpdateBranch(c.Request().Context(), branch); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, branch)
+}
+
+// DeleteBranch godoc
+// @Summary Delete a branch
+// @Description Soft-deletes the branch; the main branch cannot be deleted
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 409 {object} erru.Problem
+// @Router /branches/{id} [delete]
+func (h *CompanyHandler) DeleteBranch(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, id, domain.PermissionManageBranches); err != nil {
+		return err
+	}
+	if err := h.Usecase.DeleteBranch(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreBranch godoc
+// @Summary Restore a deleted branch
+// @Tags companies
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Success 200 {object} domain.Branch
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /branches/{id}/restore [post]
+func (h *CompanyHandler) RestoreBranch(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, id, domain.PermissionManageBranches); err != nil {
+		return err
+	}
+	branch, err := h.Usecase.RestoreBranch(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, branch)
+}
+
+// UpdateCategory godoc
+// @Summary Rename a category
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Category ID"
+// @Param input body updateCategoryRequest true "Category Update Input"
+// @Success 200 {object} domain.Category
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /categories/{id} [put]
+func (h *CompanyHandler) UpdateCategory(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req updateCategoryRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCategory, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	category := &domain.Category{
+		ID:   id,
+		Name: req.Name,
+	}
+	if err := h.Usecase.UpdateCategory(c.Request().Context(), category); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Soft-deletes the category; its services stay in the catalog without a category
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Category ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /categories/{id} [delete]
+func (h *CompanyHandler) DeleteCategory(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCategory, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+	if err := h.Usecase.DeleteCategory(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreCategory godoc
+// @Summary Restore a deleted category
+// @Tags companies
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Category ID"
+// @Success 200 {object} domain.Category
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /categories/{id}/restore [post]
+func (h *CompanyHandler) RestoreCategory(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceCategory, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+	category, err := h.Usecase.RestoreCategory(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, category)
+}
+
+// UpdateEmployee godoc
+// @Summary Update employee details
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Param input body updateEmployeeRequest true "Employee Update Input"
+// @Success 200 {object} domain.Employee
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /employees/{id} [put]
+func (h *CompanyHandler) UpdateEmployee(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req updateEmployeeRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, id, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+
+	employee := &domain.Employee{
+		ID:        id,
+		Name:      req.Name,
+		Position:  req.Position,
+		AvatarURL: req.AvatarURL,
+		Role:      domain.Role(req.Role),
+	}
+	if err := h.Usecase.UpdateEmployee(c.Request().Context(), employee); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, employee)
+}
+
+// DeleteEmployee godoc
+// @Summary Delete an employee
+// @Description Soft-deletes the employee; booking stops offering their slots
+// @Tags employees
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /employees/{id} [delete]
+func (h *CompanyHandler) DeleteEmployee(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, id, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+	if err := h.Usecase.DeleteEmployee(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreEmployee godoc
+// @Summary Restore a deleted employee
+// @Tags employees
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Success 200 {object} domain.Employee
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /employees/{id}/restore [post]
+func (h *CompanyHandler) RestoreEmployee(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, id, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+	employee, err := h.Usecase.RestoreEmployee(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, employee)
+}
+
+// callerID is the authenticated user set by the gateway in X-User-ID;
+// identity.Trust has already rejected the header unless it was signed.
+func callerID(c echo.Context) (uint, error) {
+	raw := c.Request().Header.Get(middleware.HeaderUserID)
+	if raw == "" {
+		return 0, erru.E(erru.CodeUnauthorized, "Missing "+middleware.HeaderUserID+" header")
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return 0, erru.E(erru.CodeBadRequest, "Invalid "+middleware.HeaderUserID+" header")
+	}
+	return uint(id), nil
+}
+
+// authorize checks that the caller holds the permissions in the company
+// owning the resource and scopes the rest of the request to that company, so
+// IDs in the body that belong to another company are not found.
+func (h *CompanyHandler) authorize(c echo.Context, resource domain.Resource, id uint, permissions ...domain.Permission) error {
+	userID, err := callerID(c)
+	if err != nil {
+		return err
+	}
+	req := c.Request()
+	companyID, err := h.Access.Authorize(req.Context(), userID, resource, id, permissions...)
+	if err != nil {
+		return err
+	}
+	ctx, err := tenant.Enter(req.Context(), string(domain.ResourceCompany), companyID)
+	if err != nil {
+		return err
+	}
+	c.SetRequest(req.WithContext(ctx))
+	return nil
+}
+
+// scopeTo scopes a public read to the company owning the resource. Anyone
+// may read it, but its queries still run inside that one company.
+func (h *CompanyHandler) scopeTo(c echo.Context, resource domain.Resource, id uint) error {
+	req := c.Request()
+	companyID, err := h.Access.CompanyOf(req.Context(), resource, id)
+	if err != nil {
+		return err
+	}
+	c.SetRequest(req.WithContext(tenant.WithCompany(req.Context(), companyID)))
+	return nil
+}
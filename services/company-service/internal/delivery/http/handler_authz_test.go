@@ -0,0 +1,897 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+	"github.com/vipos89/timehub/services/company-service/internal/usecase"
+)
+
+// Users of the fixture: company 1 has an owner, an admin and a master;
+// company 2 belongs to someone else.
+const (
+	owner    = "10"
+	admin    = "11"
+	master   = "12"
+	outsider = "20"
+)
+
+// accessRepo is an in-memory domain.AccessRepository.
+type accessRepo struct {
+	companies map[domain.Resource]map[uint]uint
+	members   map[uint]map[uint]domain.Role
+}
+
+func newAccessRepo() *accessRepo {
+	return &accessRepo{
+		companies: map[domain.Resource]map[uint]uint{
+			domain.ResourceCompany:        {1: 1, 2: 2},
+			domain.ResourceBranch:         {1: 1, 2: 2},
+			domain.ResourceCategory:       {300: 1, 400: 2},
+			domain.ResourceService:        {100: 1, 200: 2},
+			domain.ResourceEmployee:       {101: 1, 102: 1, 201: 2},
+			domain.ResourceOption:         {500: 1, 600: 2},
+			domain.ResourceBundle:         {700: 1, 800: 2},
+			domain.ResourceResourceType:   {900: 1, 901: 2},
+			domain.ResourceBranchResource: {910: 1, 911: 2},
+		},
+		members: map[uint]map[uint]domain.Role{
+			1: {10: domain.RoleOwner, 11: domain.RoleAdmin, 12: domain.RoleMaster},
+			2: {20: domain.RoleOwner},
+		},
+	}
+}
+
+func (r *accessRepo) CompanyOf(_ context.Context, resource domain.Resource, id uint) (uint, error) {
+	companyID, ok := r.companies[resource][id]
+	if !ok {
+		return 0, erru.ErrNotFound
+	}
+	return companyID, nil
+}
+
+func (r *accessRepo) Membership(_ context.Context, companyID, userID uint) (*domain.Membership, error) {
+	role, ok := r.members[companyID][userID]
+	if !ok {
+		return nil, nil
+	}
+	return &domain.Membership{CompanyID: companyID, UserID: userID, Role: role}, nil
+}
+
+// companyUsecase succeeds for every change and records the tenant the
+// handler scoped the call to. Reads are not stubbed: they are public.
+type companyUsecase struct {
+	domain.CompanyUsecase
+	tenant uint
+}
+
+func (u *companyUsecase) seen(ctx context.Context) { u.tenant = tenant.ID(ctx) }
+
+func (u *companyUsecase) CreateCompany(ctx context.Context, name string, ownerID uint) (*domain.Company, error) {
+	u.seen(ctx)
+	return &domain.Company{ID: 3, Name: name, OwnerID: ownerID}, nil
+}
+
+func (u *companyUsecase) GetMyCompanies(ctx context.Context, ownerID uint, page pagination.Request) (pagination.Page[domain.Company], error) {
+	u.seen(ctx)
+	return pagination.Page[domain.Company]{Items: []domain.Company{}}, nil
+}
+
+func (u *companyUsecase) AddBranch(ctx context.Context, companyID uint, name, address, phone string, location domain.Location) (*domain.Branch, error) {
+	u.seen(ctx)
+	return &domain.Branch{CompanyID: companyID, Name: name}, nil
+}
+
+func (u *companyUsecase) AddCategory(ctx context.Context, branchID uint, name string) (*domain.Category, error) {
+	u.seen(ctx)
+	return &domain.Category{BranchID: branchID, Name: name}, nil
+}
+
+func (u *companyUsecase) AddService(ctx context.Context, branchID uint, categoryID *uint, name, description string, price float64, duration int) (*domain.Service, error) {
+	u.seen(ctx)
+	return &domain.Service{BranchID: branchID, Name: name}, nil
+}
+
+func (u *companyUsecase) UpdateService(ctx context.Context, service *domain.Service) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) AddEmployee(ctx context.Context, branchID uint, name, position, email string, role domain.Role) (*domain.Employee, error) {
+	u.seen(ctx)
+	return &domain.Employee{BranchID: branchID, Name: name, Role: role}, nil
+}
+
+func (u *companyUsecase) AssignService(ctx context.Context, employeeID, serviceID uint, price float64, duration int) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) RemoveService(ctx context.Context, employeeID, serviceID uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) UpdateCompany(ctx context.Context, company *domain.Company) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) DeleteCompany(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) RestoreCompany(ctx context.Context, id uint) (*domain.Company, error) {
+	u.seen(ctx)
+	return &domain.Company{ID: id}, nil
+}
+
+func (u *companyUsecase) UpdateBranch(ctx context.Context, branch *domain.Branch) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) SetBranchHours(ctx context.Context, branchID uint, hours []domain.OpeningHours, exceptions []domain.HoursException) (*domain.Branch, error) {
+	u.seen(ctx)
+	return &domain.Branch{ID: branchID}, nil
+}
+
+func (u *companyUsecase) DeleteBranch(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) RestoreBranch(ctx context.Context, id uint) (*domain.Branch, error) {
+	u.seen(ctx)
+	return &domain.Branch{ID: id}, nil
+}
+
+func (u *companyUsecase) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) DeleteCategory(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) RestoreCategory(ctx context.Context, id uint) (*domain.Category, error) {
+	u.seen(ctx)
+	return &domain.Category{ID: id}, nil
+}
+
+func (u *companyUsecase) UpdateEmployee(ctx context.Context, employee *domain.Employee) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) DeleteEmployee(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) RestoreEmployee(ctx context.Context, id uint) (*domain.Employee, error) {
+	u.seen(ctx)
+	return &domain.Employee{ID: id}, nil
+}
+
+func (u *companyUsecase) SetEmployeeBranches(ctx context.Context, employeeID, primaryID uint, branchIDs []uint) (*domain.Employee, error) {
+	u.seen(ctx)
+	return &domain.Employee{ID: employeeID, BranchID: primaryID}, nil
+}
+
+func (u *companyUsecase) AddServiceOption(ctx context.Context, serviceID uint, kind domain.OptionKind, name string, priceDelta float64, durationDelta int) (*domain.ServiceOption, error) {
+	u.seen(ctx)
+	return &domain.ServiceOption{ServiceID: serviceID, Kind: kind, Name: name}, nil
+}
+
+func (u *companyUsecase) UpdateServiceOption(ctx context.Context, option *domain.ServiceOption) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) DeleteServiceOption(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) SetEmployeeOption(ctx context.Context, employeeID, optionID uint, priceDelta float64, durationDelta int) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) RemoveEmployeeOption(ctx context.Context, employeeID, optionID uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) AddBundle(ctx context.Context, branchID uint, name, description string, price float64, items []domain.BundleItem) (*domain.Bundle, error) {
+	u.seen(ctx)
+	return &domain.Bundle{BranchID: branchID, Name: name, Price: price, Items: items}, nil
+}
+
+func (u *companyUsecase) UpdateBundle(ctx context.Context, bundle *domain.Bundle) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) DeleteBundle(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) AddResourceType(ctx context.Context, branchID uint, name string) (*domain.ResourceType, error) {
+	u.seen(ctx)
+	return &domain.ResourceType{BranchID: branchID, Name: name}, nil
+}
+
+func (u *companyUsecase) UpdateResourceType(ctx context.Context, resourceType *domain.ResourceType) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) DeleteResourceType(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) AddResource(ctx context.Context, typeID uint, name string) (*domain.BranchResource, error) {
+	u.seen(ctx)
+	return &domain.BranchResource{TypeID: typeID, Name: name}, nil
+}
+
+func (u *companyUsecase) UpdateResource(ctx context.Context, resource *domain.BranchResource) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) ExportCatalog(ctx context.Context, branchID uint) (*domain.Catalog, error) {
+	u.seen(ctx)
+	return &domain.Catalog{}, nil
+}
+
+func (u *companyUsecase) ImportCatalog(ctx context.Context, branchID uint, catalog *domain.Catalog, dryRun bool) (*domain.ImportReport, error) {
+	u.seen(ctx)
+	return &domain.ImportReport{DryRun: dryRun}, nil
+}
+
+func (u *companyUsecase) DeleteResource(ctx context.Context, id uint) error {
+	u.seen(ctx)
+	return nil
+}
+
+func (u *companyUsecase) SetServiceRequirements(ctx context.Context, serviceID uint, requirements []domain.ServiceRequirement) ([]domain.ServiceRequirement, error) {
+	u.seen(ctx)
+	return requirements, nil
+}
+
+type authzCase struct {
+	name string
+	user string
+	// companyHeader is sent as X-Company-ID when set.
+	companyHeader string
+	path          string // overrides the route's path
+	want          int
+	// wantTenant is the company the usecase must have been scoped to.
+	wantTenant uint
+}
+
+func TestAuthorization(t *testing.T) {
+	routes := []struct {
+		method string
+		path   string
+		body   string
+		cases  []authzCase
+	}{
+		{
+			method: http.MethodPost, path: "/companies", body: `{"name":"Salon"}`,
+			cases: []authzCase{
+				{name: "any user", user: outsider, want: http.StatusCreated},
+				{name: "anonymous", want: http.StatusUnauthorized},
+				{name: "invalid user", user: "abc", want: http.StatusBadRequest},
+			},
+		},
+		{
+			method: http.MethodGet, path: "/companies",
+			cases: []authzCase{
+				{name: "any user", user: master, want: http.StatusOK},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodGet, path: "/companies/1/membership",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK},
+				{name: "master", user: master, want: http.StatusOK},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/companies/1/branches", body: `{"name":"North"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "unknown company", user: owner, path: "/companies/9/branches", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+				{name: "foreign tenant header", user: owner, companyHeader: "2", want: http.StatusNotFound},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/categories", body: `{"name":"Hair"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "unknown branch", user: owner, path: "/branches/9/categories", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/services", body: `{"name":"Haircut","price":30,"duration_minutes":45}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "owner of other branch", user: owner, path: "/branches/2/services", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/services/100", body: `{"name":"Haircut","price":35}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's service", user: owner, path: "/services/200", want: http.StatusNotFound},
+				{name: "unknown service", user: owner, path: "/services/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/employees", body: `{"branch_id":1,"name":"Anna"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/employees", body: `{"name":"Anna","role":"admin"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "unknown branch", user: owner, path: "/branches/9/employees", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/employees/102/services", body: `{"service_id":100,"price":30,"duration_minutes":45}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's employee", user: owner, path: "/employees/201/services", want: http.StatusNotFound},
+				{name: "unknown employee", user: owner, path: "/employees/9/services", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/employees/102/services/100",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "unknown employee", user: owner, path: "/employees/9/services/100", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/services/100/options", body: `{"kind":"addon","name":"Wash","price_delta":5,"duration_delta_minutes":15}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's service", user: owner, path: "/services/200/options", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/options/500", body: `{"name":"Long hair","price_delta":10}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/options/600", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/options/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/options/500",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/options/600", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/employees/102/options/500", body: `{"price_delta":8,"duration_delta_minutes":20}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's employee", user: owner, path: "/employees/201/options/500", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/employees/102/options/500",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/bundles", body: `{"name":"Cut and beard","price":45,"items":[{"service_id":100,"gap_minutes":10},{"service_id":101}]}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "owner of other branch", user: owner, path: "/branches/2/bundles", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/bundles/700", body: `{"name":"Cut and beard","price":45,"items":[{"service_id":100,"gap_minutes":10},{"service_id":101}]}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/bundles/800", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/bundles/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/bundles/700",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/bundles/800", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/resource-types", body: `{"name":"Massage room"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "owner of other branch", user: owner, path: "/branches/2/resource-types", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/resource-types/900", body: `{"name":"Laser room"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/resource-types/901", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/resource-types/900",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/resource-types/901", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/resource-types/900/resources", body: `{"name":"Room 1"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusCreated, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusCreated, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's type", user: owner, path: "/resource-types/901/resources", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/resources/910", body: `{"name":"Room 2"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/resources/911", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/resources/910",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's row", user: owner, path: "/resources/911", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/services/100/requirements", body: `{"requirements":[{"type_id":900,"quantity":1}]}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company's service", user: owner, path: "/services/200/requirements", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodGet, path: "/branches/1/catalog?format=csv",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "owner of other branch", user: owner, path: "/branches/2/catalog", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/catalog?dry_run=true", body: `{"categories":[{"key":"hair","name":"Hair"}]}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "owner of other branch", user: owner, path: "/branches/2/catalog", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/companies/1", body: `{"name":"Salon 2"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/companies/2", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/companies/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/companies/1",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/companies/2", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/companies/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/companies/1/restore",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/companies/2/restore", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/companies/9/restore", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/branches/1", body: `{"name":"North"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/branches/2", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/branches/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/branches/1/hours", body: `{"opening_hours":[{"day_of_week":1,"open_time":"09:00","close_time":"21:00"}]}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/branches/2/hours", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/branches/9/hours", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/branches/1",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/branches/2", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/branches/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/branches/1/restore",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusForbidden},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/branches/2/restore", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/branches/9/restore", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/categories/300", body: `{"name":"Nails"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/categories/400", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/categories/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/categories/300",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/categories/400", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/categories/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/categories/300/restore",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/categories/400/restore", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/categories/9/restore", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/employees/102", body: `{"name":"Anna"}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/employees/201", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/employees/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/employees/102",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusNoContent, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusNoContent, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/employees/201", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/employees/9", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPut, path: "/employees/102/branches", body: `{"primary_branch_id":1,"branch_ids":[1]}`,
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/employees/201/branches", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/employees/9/branches", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+		{
+			method: http.MethodPost, path: "/employees/102/restore",
+			cases: []authzCase{
+				{name: "owner", user: owner, want: http.StatusOK, wantTenant: 1},
+				{name: "admin", user: admin, want: http.StatusOK, wantTenant: 1},
+				{name: "master", user: master, want: http.StatusForbidden},
+				{name: "other company", user: outsider, want: http.StatusNotFound},
+				{name: "other company's row", user: owner, path: "/employees/201/restore", want: http.StatusNotFound},
+				{name: "unknown", user: owner, path: "/employees/9/restore", want: http.StatusNotFound},
+				{name: "anonymous", want: http.StatusUnauthorized},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		for _, tc := range route.cases {
+			t.Run(route.method+" "+route.path+"/"+tc.name, func(t *testing.T) {
+				us := &companyUsecase{}
+				e := echo.New()
+				e.HTTPErrorHandler = middleware.ErrorHandler
+				e.Validator = middleware.NewValidator()
+				e.Use(tenant.Middleware)
+				NewCompanyHandler(e, us, usecase.NewAccessUsecase(newAccessRepo(), time.Second))
+
+				path := route.path
+				if tc.path != "" {
+					path = tc.path
+				}
+				req := httptest.NewRequest(route.method, path, strings.NewReader(route.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				if tc.user != "" {
+					req.Header.Set(middleware.HeaderUserID, tc.user)
+				}
+				if tc.companyHeader != "" {
+					req.Header.Set(middleware.HeaderCompanyID, tc.companyHeader)
+				}
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+
+				if rec.Code != tc.want {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+				}
+				if us.tenant != tc.wantTenant {
+					t.Errorf("usecase scoped to company %d, want %d", us.tenant, tc.wantTenant)
+				}
+			})
+		}
+	}
+}
+
+// Reads stay open to anonymous callers such as the booking widget.
+func TestPublicReadsNeedNoCaller(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = middleware.ErrorHandler
+	e.Validator = middleware.NewValidator()
+	var scoped uint
+	e.Use(tenant.Middleware, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			scoped = tenant.ID(c.Request().Context())
+			return err
+		}
+	})
+	NewCompanyHandler(e, &readUsecase{}, usecase.NewAccessUsecase(newAccessRepo(), time.Second))
+
+	for _, path := range []string{
+		"/companies/1",
+		"/companies/1/branches",
+		"/branches/1",
+		"/branches/1/categories",
+		"/branches/1/services",
+		"/branches/1/bundles",
+		"/bundles/700",
+		"/branches/1/resource-types",
+		"/branches/1/employees",
+		"/employees?company_id=1",
+		"/employees?company_id=1&branch_id=2",
+		"/employees/101/services",
+		"/employees/101/services?branch_id=1",
+		"/employees/101/services/100/quote?variant_id=500&addon_ids=501&addon_ids=502",
+	} {
+		t.Run(path, func(t *testing.T) {
+			// Staff of another company read public data like anyone else.
+			for _, header := range []string{"", "2"} {
+				scoped = 0
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if header != "" {
+					req.Header.Set(tenant.Header, header)
+				}
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("X-Company-ID %q: status = %d, want 200: %s", header, rec.Code, rec.Body.String())
+				}
+				if scoped != 1 {
+					t.Errorf("X-Company-ID %q: scoped to company %d, want the owner 1", header, scoped)
+				}
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/branches/9/services", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown branch: status = %d, want 404", rec.Code)
+	}
+}
+
+// readUsecase answers the public read routes with empty results.
+type readUsecase struct {
+	domain.CompanyUsecase
+}
+
+func (readUsecase) GetCompanyByID(context.Context, uint) (*domain.Company, error) {
+	return &domain.Company{ID: 1}, nil
+}
+
+func (readUsecase) GetCompanyBranches(context.Context, uint, pagination.Request) (pagination.Page[domain.Branch], error) {
+	return pagination.Page[domain.Branch]{Items: []domain.Branch{}}, nil
+}
+
+func (readUsecase) GetBranch(context.Context, uint) (*domain.Branch, error) {
+	return &domain.Branch{ID: 1}, nil
+}
+
+func (readUsecase) GetBranchCategories(context.Context, uint, pagination.Request) (pagination.Page[domain.Category], error) {
+	return pagination.Page[domain.Category]{Items: []domain.Category{}}, nil
+}
+
+func (readUsecase) GetBranchServices(context.Context, uint, pagination.Request) (pagination.Page[domain.Service], error) {
+	return pagination.Page[domain.Service]{Items: []domain.Service{}}, nil
+}
+
+func (readUsecase) GetBranchBundles(context.Context, uint, pagination.Request) (pagination.Page[domain.Bundle], error) {
+	return pagination.Page[domain.Bundle]{Items: []domain.Bundle{}}, nil
+}
+
+func (readUsecase) GetBundle(_ context.Context, id uint) (*domain.Bundle, error) {
+	return &domain.Bundle{ID: id}, nil
+}
+
+func (readUsecase) GetBranchResourceTypes(context.Context, uint, pagination.Request) (pagination.Page[domain.ResourceType], error) {
+	return pagination.Page[domain.ResourceType]{Items: []domain.ResourceType{}}, nil
+}
+
+func (readUsecase) GetCompanyEmployees(context.Context, uint, uint, pagination.Request) (pagination.Page[domain.Employee], error) {
+	return pagination.Page[domain.Employee]{Items: []domain.Employee{}}, nil
+}
+
+func (readUsecase) GetBranchEmployees(context.Context, uint, pagination.Request) (pagination.Page[domain.Employee], error) {
+	return pagination.Page[domain.Employee]{Items: []domain.Employee{}}, nil
+}
+
+func (readUsecase) GetEmployeeMenu(context.Context, uint, uint, pagination.Request) (pagination.Page[domain.EmployeeService], error) {
+	return pagination.Page[domain.EmployeeService]{Items: []domain.EmployeeService{}}, nil
+}
+
+func (readUsecase) Quote(_ context.Context, employeeID, serviceID uint, variantID *uint, addOnIDs []uint) (*domain.Quote, error) {
+	if variantID == nil || *variantID != 500 || len(addOnIDs) != 2 {
+		return nil, erru.E(erru.CodeBadRequest, "options not bound")
+	}
+	return &domain.Quote{EmployeeID: employeeID, ServiceID: serviceID, VariantID: variantID, AddOnIDs: addOnIDs}, nil
+}
@@ -0,0 +1,110 @@
+package http
+
+import "github.com/vipos89/timehub/pkg/pagination"
+
+// Sort and filter fields accepted by the list endpoints. Columns without a
+// NOT NULL constraint are marked Nullable so keyset pages include NULL rows.
+
+var (
+	textOps   = []pagination.Op{pagination.OpEq, pagination.OpNe, pagination.OpLike, pagination.OpIn}
+	numberOps = []pagination.Op{pagination.OpEq, pagination.OpNe, pagination.OpLt, pagination.OpLte, pagination.OpGt, pagination.OpGte, pagination.OpIn}
+	timeOps   = []pagination.Op{pagination.OpLt, pagination.OpLte, pagination.OpGt, pagination.OpGte}
+	idOps     = []pagination.Op{pagination.OpEq, pagination.OpIn}
+)
+
+var companyList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "id", Ops: idOps},
+		"name":       {Column: "name", Ops: textOps},
+		"created_at": {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "name",
+}
+
+var branchList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "id", Ops: idOps},
+		"name":       {Column: "name", Ops: textOps},
+		"address":    {Column: "address", Ops: textOps, NoSort: true},
+		"is_main":    {Column: "is_main", Ops: []pagination.Op{pagination.OpEq}, Nullable: true},
+		"created_at": {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "-is_main,name",
+}
+
+// branchSearchList pages are small: each result may cost booking-service calls.
+var branchSearchList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":           {Column: "id", Ops: idOps},
+		"distance":     {Column: "distance_km"},
+		"name":         {Column: "name", Ops: textOps},
+		"company_name": {Column: "company_name", Ops: textOps},
+	},
+	DefaultSort:  "distance",
+	DefaultLimit: 20,
+	MaxLimit:     50,
+}
+
+var categoryList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "id", Ops: idOps},
+		"name":       {Column: "name", Ops: textOps},
+		"created_at": {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "name",
+}
+
+var serviceList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":               {Column: "id", Ops: idOps},
+		"name":             {Column: "name", Ops: textOps},
+		"category_id":      {Column: "category_id", Ops: idOps, NoSort: true},
+		"price":            {Column: "price", Ops: numberOps},
+		"duration_minutes": {Column: "duration_minutes", Ops: numberOps},
+		"created_at":       {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "name",
+}
+
+var bundleList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "id", Ops: idOps},
+		"name":       {Column: "name", Ops: textOps},
+		"price":      {Column: "price", Ops: numberOps},
+		"created_at": {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "name",
+}
+
+var resourceTypeList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "id", Ops: idOps},
+		"name":       {Column: "name", Ops: textOps},
+		"created_at": {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "name",
+}
+
+// branch_id is the primary branch; the branch_id query parameter keeps
+// everyone working at a branch.
+var employeeList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "id", Ops: idOps},
+		"name":       {Column: "name", Ops: textOps},
+		"position":   {Column: "position", Ops: textOps, Nullable: true},
+		"branch_id":  {Column: "branch_id", Ops: idOps},
+		"created_at": {Column: "created_at", Ops: timeOps, Nullable: true},
+	},
+	DefaultSort: "name",
+}
+
+// The menu is keyed by service; an employee has each service at most once.
+var menuList = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"service_id":       {Column: "service_id", Ops: idOps},
+		"price":            {Column: "price", Ops: numberOps},
+		"duration_minutes": {Column: "duration_minutes", Ops: numberOps},
+	},
+	DefaultSort: "service_id",
+	Key:         "service_id",
+}
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+type addServiceOptionRequest struct {
+	Kind          string  `json:"kind" validate:"required,oneof=variant addon"`
+	Name          string  `json:"name" validate:"required,max=255"`
+	PriceDelta    float64 `json:"price_delta" validate:"gte=-1000000,lte=1000000"`
+	DurationDelta int     `json:"duration_delta_minutes" validate:"gte=-1440,lte=1440"`
+}
+
+type updateServiceOptionRequest struct {
+	Name          string  `json:"name" validate:"required,max=255"`
+	PriceDelta    float64 `json:"price_delta" validate:"gte=-1000000,lte=1000000"`
+	DurationDelta int     `json:"duration_delta_minutes" validate:"gte=-1440,lte=1440"`
+}
+
+type employeeOptionRequest struct {
+	PriceDelta    float64 `json:"price_delta" validate:"gte=-1000000,lte=1000000"`
+	DurationDelta int     `json:"duration_delta_minutes" validate:"gte=-1440,lte=1440"`
+}
+
+type quoteRequest struct {
+	VariantID *uint  `query:"variant_id" validate:"omitempty,gt=0"`
+	AddOnIDs  []uint `query:"addon_ids" validate:"max=20,dive,gt=0"`
+}
+
+// AddServiceOption godoc
+// @Summary Add a variant or add-on to a service
+// @Description Variants are mutually exclusive and one must be chosen once a service has any; add-ons are optional extras. Deltas are added to the price and duration of the service.
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Service ID"
+// @Param input body addServiceOptionRequest true "Option Input"
+// @Success 201 {object} domain.ServiceOption
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /services/{id}/options [post]
+func (h *CompanyHandler) AddServiceOption(c echo.Context) error {
+	serviceID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req addServiceOptionRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceService, serviceID, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	option, err := h.Usecase.AddServiceOption(c.Request().Context(), serviceID, domain.OptionKind(req.Kind), req.Name, req.PriceDelta, req.DurationDelta)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, option)
+}
+
+// UpdateServiceOption godoc
+// @Summary Update a variant or add-on
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Option ID"
+// @Param input body updateServiceOptionRequest true "Option Update Input"
+// @Success 200 {object} domain.ServiceOption
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /options/{id} [put]
+func (h *CompanyHandler) UpdateServiceOption(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req updateServiceOptionRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceOption, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	option := &domain.ServiceOption{
+		ID:            id,
+		Name:          req.Name,
+		PriceDelta:    req.PriceDelta,
+		DurationDelta: req.DurationDelta,
+	}
+	if err := h.Usecase.UpdateServiceOption(c.Request().Context(), option); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, option)
+}
+
+// DeleteServiceOption godoc
+// @Summary Delete a variant or add-on
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Option ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /options/{id} [delete]
+func (h *CompanyHandler) DeleteServiceOption(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceOption, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	if err := h.Usecase.DeleteServiceOption(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetEmployeeOption godoc
+// @Summary Override a variant or add-on for an employee
+// @Description The deltas replace the option's own ones when the employee performs it.
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Param optionId path int true "Option ID"
+// @Param input body employeeOptionRequest true "Override Input"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /employees/{id}/options/{optionId} [put]
+func (h *CompanyHandler) SetEmployeeOption(c echo.Context) error {
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	optionID, err := middleware.ParamID(c, "optionId")
+	if err != nil {
+		return err
+	}
+	var req employeeOptionRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, employeeID, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+
+	if err := h.Usecase.SetEmployeeOption(c.Request().Context(), employeeID, optionID, req.PriceDelta, req.DurationDelta); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveEmployeeOption godoc
+// @Summary Remove an employee's override of a variant or add-on
+// @Tags employees
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Employee ID"
+// @Param optionId path int true "Option ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /employees/{id}/options/{optionId} [delete]
+func (h *CompanyHandler) RemoveEmployeeOption(c echo.Context) error {
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	optionID, err := middleware.ParamID(c, "optionId")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceEmployee, employeeID, domain.PermissionManageStaff); err != nil {
+		return err
+	}
+
+	if err := h.Usecase.RemoveEmployeeOption(c.Request().Context(), employeeID, optionID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Quote godoc
+// @Summary Price and duration of a service with an employee
+// @Description Applies the employee's price matrix and the chosen variant and add-ons. A variant is required when the service has any.
+// @Tags employees
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param serviceId path int true "Service ID"
+// @Param variant_id query int false "Variant ID"
+// @Param addon_ids query []int false "Add-on IDs" collectionFormat(multi)
+// @Success 200 {object} domain.Quote
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /employees/{id}/services/{serviceId}/quote [get]
+func (h *CompanyHandler) Quote(c echo.Context) error {
+	employeeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceEmployee, employeeID); err != nil {
+		return err
+	}
+	serviceID, err := middleware.ParamID(c, "serviceId")
+	if err != nil {
+		return err
+	}
+	var req quoteRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	quote, err := h.Usecase.Quote(c.Request().Context(), employeeID, serviceID, req.VariantID, req.AddOnIDs)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, quote)
+}
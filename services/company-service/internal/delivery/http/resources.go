@@ -0,0 +1,284 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+type resourceRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type requirementsRequest struct {
+	Requirements []requirementRequest `json:"requirements" validate:"max=10,dive"`
+}
+
+type requirementRequest struct {
+	TypeID   uint `json:"type_id" validate:"required"`
+	Quantity int  `json:"quantity" validate:"gte=1,lte=10"`
+}
+
+// AddResourceType godoc
+// @Summary Add a resource type to a branch
+// @Description A resource type is a kind of room, chair or device that services can require, e.g. "Massage room".
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Branch ID"
+// @Param input body resourceRequest true "Resource Type Input"
+// @Success 201 {object} domain.ResourceType
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/resource-types [post]
+func (h *CompanyHandler) AddResourceType(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req resourceRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranch, branchID, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	resourceType, err := h.Usecase.AddResourceType(c.Request().Context(), branchID, req.Name)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, resourceType)
+}
+
+// GetResourceTypes godoc
+// @Summary Get the resource types of a branch with their resources
+// @Tags companies
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Param filter query []string false "field:op:value (ops: eq, ne, lt, lte, gt, gte, like, in)" collectionFormat(multi)
+// @Success 200 {object} pagination.Page[domain.ResourceType]
+// @Failure 422 {object} erru.Problem
+// @Router /branches/{id}/resource-types [get]
+func (h *CompanyHandler) GetResourceTypes(c echo.Context) error {
+	branchID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.scopeTo(c, domain.ResourceBranch, branchID); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, resourceTypeList)
+	if err != nil {
+		return err
+	}
+	types, err := h.Usecase.GetBranchResourceTypes(c.Request().Context(), branchID, page)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, types)
+}
+
+// UpdateResourceType godoc
+// @Summary Rename a resource type
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Resource Type ID"
+// @Param input body resourceRequest true "Resource Type Input"
+// @Success 200 {object} domain.ResourceType
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /resource-types/{id} [put]
+func (h *CompanyHandler) UpdateResourceType(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req resourceRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceResourceType, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	resourceType := &domain.ResourceType{ID: id, Name: req.Name}
+	if err := h.Usecase.UpdateResourceType(c.Request().Context(), resourceType); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resourceType)
+}
+
+// DeleteResourceType godoc
+// @Summary Delete a resource type
+// @Description Only types without resources that no service requires can be deleted.
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Resource Type ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 409 {object} erru.Problem
+// @Router /resource-types/{id} [delete]
+func (h *CompanyHandler) DeleteResourceType(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceResourceType, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	if err := h.Usecase.DeleteResourceType(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AddResource godoc
+// @Summary Add a resource of a type
+// @Description The resource belongs to the branch of its type.
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Resource Type ID"
+// @Param input body resourceRequest true "Resource Input"
+// @Success 201 {object} domain.BranchResource
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /resource-types/{id}/resources [post]
+func (h *CompanyHandler) AddResource(c echo.Context) error {
+	typeID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req resourceRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceResourceType, typeID, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	resource, err := h.Usecase.AddResource(c.Request().Context(), typeID, req.Name)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, resource)
+}
+
+// UpdateResource godoc
+// @Summary Rename a resource
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Resource ID"
+// @Param input body resourceRequest true "Resource Input"
+// @Success 200 {object} domain.BranchResource
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /resources/{id} [put]
+func (h *CompanyHandler) UpdateResource(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req resourceRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranchResource, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	resource := &domain.BranchResource{ID: id, Name: req.Name}
+	if err := h.Usecase.UpdateResource(c.Request().Context(), resource); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, resource)
+}
+
+// DeleteResource godoc
+// @Summary Delete a resource
+// @Description Existing bookings keep it; new ones no longer use it.
+// @Tags companies
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Resource ID"
+// @Success 204
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Router /resources/{id} [delete]
+func (h *CompanyHandler) DeleteResource(c echo.Context) error {
+	id, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceBranchResource, id, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	if err := h.Usecase.DeleteResource(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetServiceRequirements godoc
+// @Summary Set the resources a service needs
+// @Description Replaces the requirements, e.g. 1 × "Massage room". A booking holds that many resources of each type for the whole appointment; an empty list removes them.
+// @Tags companies
+// @Accept json
+// @Produce json
+// @Param X-User-ID header int true "Caller"
+// @Param id path int true "Service ID"
+// @Param input body requirementsRequest true "Requirements"
+// @Success 200 {array} domain.ServiceRequirement
+// @Failure 401 {object} erru.Problem
+// @Failure 403 {object} erru.Problem
+// @Failure 404 {object} erru.Problem
+// @Failure 422 {object} erru.Problem
+// @Router /services/{id}/requirements [put]
+func (h *CompanyHandler) SetServiceRequirements(c echo.Context) error {
+	serviceID, err := middleware.ParamID(c, "id")
+	if err != nil {
+		return err
+	}
+	var req requirementsRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.authorize(c, domain.ResourceService, serviceID, domain.PermissionManageCatalog); err != nil {
+		return err
+	}
+
+	requirements := make([]domain.ServiceRequirement, len(req.Requirements))
+	for i, r := range req.Requirements {
+		requirements[i] = domain.ServiceRequirement{TypeID: r.TypeID, Quantity: r.Quantity}
+	}
+	requirements, err = h.Usecase.SetServiceRequirements(c.Request().Context(), serviceID, requirements)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, requirements)
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// defaultRadiusKm is the search radius when the request names none.
+const defaultRadiusKm = 10
+
+type SearchHandler struct {
+	Usecase domain.SearchUsecase
+}
+
+// NewSearchHandler registers the public search routes; they need no caller.
+func NewSearchHandler(e *echo.Echo, us domain.SearchUsecase) {
+	handler := &SearchHandler{Usecase: us}
+	e.GET("/public/branches/search", handler.SearchBranches)
+}
+
+type searchBranchesRequest struct {
+	Latitude  *float64 `query:"lat" validate:"required,gte=-90,lte=90"`
+	Longitude *float64 `query:"lng" validate:"required,gte=-180,lte=180"`
+	RadiusKm  float64  `query:"radius" validate:"omitempty,gt=0,lte=100"`
+	Service   string   `query:"service" validate:"max=255"`
+	Category  string   `query:"category" validate:"max=255"`
+	Date      string   `query:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// SearchBranches godoc
+// @Summary Search branches near a point
+// @Description Finds branches within radius km that offer a matching service, nearest first. With a date, each result says whether any matching employee has a free slot that day; has_free_slots is omitted when that is unknown, e.g. a branch with more than 10 matching employee/service pairs where none of the first 10 is free.
+// @Tags search
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius query number false "Radius in km (default 10, max 100)"
+// @Param service query string false "Part of a service name"
+// @Param category query string false "Part of a category name"
+// @Param date query string false "Date (2006-01-02) to check for free slots"
+// @Param limit query int false "Page size (default 20, max 50)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma-separated fields, prefix with - for descending"
+// @Success 200 {object} pagination.Page[domain.BranchMatch]
+// @Failure 422 {object} erru.Problem
+// @Router /public/branches/search [get]
+func (h *SearchHandler) SearchBranches(c echo.Context) error {
+	var req searchBranchesRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	page, err := pagination.Parse(c, branchSearchList)
+	if err != nil {
+		return err
+	}
+
+	search := domain.BranchSearch{
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		RadiusKm:  req.RadiusKm,
+		Service:   req.Service,
+		Category:  req.Category,
+	}
+	if search.RadiusKm == 0 {
+		search.RadiusKm = defaultRadiusKm
+	}
+	var date time.Time
+	if req.Date != "" {
+		// Already checked by the datetime rule.
+		date, _ = time.Parse(time.DateOnly, req.Date)
+	}
+
+	branches, err := h.Usecase.SearchBranches(c.Request().Context(), search, date, page)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, branches)
+}
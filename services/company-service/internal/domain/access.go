@@ -0,0 +1,67 @@
+package domain
+
+import "context"
+
+// Role is what a user is in a company: its owner or an employee with a role.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMaster Role = "master"
+)
+
+// Permission is an action on a company's data.
+type Permission string
+
+const (
+	PermissionManageCompany  Permission = "company:manage"
+	PermissionManageBranches Permission = "branches:manage"
+	PermissionManageCatalog  Permission = "catalog:manage"
+	PermissionManageStaff    Permission = "staff:manage"
+)
+
+// Resource is a kind of row the caller can target by ID.
+type Resource string
+
+const (
+	ResourceCompany  Resource = "companies"
+	ResourceBranch   Resource = "branches"
+	ResourceCategory Resource = "categories"
+	ResourceService  Resource = "services"
+	ResourceOption   Resource = "service_options"
+	ResourceBundle   Resource = "bundles"
+	// Bookable resources, not to be confused with this type.
+	ResourceResourceType   Resource = "resource_types"
+	ResourceBranchResource Resource = "branch_resources"
+	ResourceEmployee       Resource = "employees"
+)
+
+// Membership links a user to a company.
+type Membership struct {
+	CompanyID uint `json:"company_id"`
+	UserID    uint `json:"user_id"`
+	Role      Role `json:"role"`
+}
+
+type AccessRepository interface {
+	// CompanyOf returns the company owning the resource, even if it is
+	// soft-deleted, or erru.ErrNotFound. Employees are resolved through their branch.
+	CompanyOf(ctx context.Context, resource Resource, id uint) (uint, error)
+	// Membership returns the user's role in the company or nil if they have none.
+	Membership(ctx context.Context, companyID, userID uint) (*Membership, error)
+}
+
+type AccessUsecase interface {
+	// Authorize checks that userID holds every permission on the resource
+	// and returns its company. Unknown resources and companies the user is
+	// not a member of are reported as not found, so their existence does not
+	// leak; members lacking a permission get erru.ErrForbidden.
+	Authorize(ctx context.Context, userID uint, resource Resource, id uint, permissions ...Permission) (uint, error)
+	// CompanyOf returns the company owning the resource, for public reads
+	// that must still run inside one company.
+	CompanyOf(ctx context.Context, resource Resource, id uint) (uint, error)
+	// Membership returns the user's role in the company or erru.ErrNotFound
+	// when they are not a member.
+	Membership(ctx context.Context, companyID, userID uint) (*Membership, error)
+}
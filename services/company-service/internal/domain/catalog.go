@@ -0,0 +1,70 @@
+package domain
+
+// Catalog is a branch's categories, services and staff with the staff's
+// prices, as exported and imported. Rows refer to each other by key: the
+// row's external key, or "id:<id>" for rows stored without one.
+type Catalog struct {
+	Categories []CatalogCategory `json:"categories"`
+	Services   []CatalogService  `json:"services"`
+	Employees  []CatalogEmployee `json:"employees"`
+	Prices     []CatalogPrice    `json:"prices"`
+}
+
+// CatalogCategory is a category row. Line, on every row, is the CSV line it
+// was read from, 0 for JSON; errors name it.
+type CatalogCategory struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Line int    `json:"-"`
+}
+
+type CatalogService struct {
+	Key             string  `json:"key"`
+	CategoryKey     string  `json:"category_key,omitempty"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description,omitempty"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+	Line            int     `json:"-"`
+}
+
+type CatalogEmployee struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Position string `json:"position,omitempty"`
+	Line     int    `json:"-"`
+}
+
+// CatalogPrice is an EmployeeService: the employee's price and duration for
+// a service.
+type CatalogPrice struct {
+	EmployeeKey     string  `json:"employee_key"`
+	ServiceKey      string  `json:"service_key"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+	Line            int     `json:"-"`
+}
+
+// BranchCatalog is what a branch's catalog holds as stored.
+type BranchCatalog struct {
+	Categories []Category
+	Services   []Service
+	Employees  []Employee
+	Prices     []EmployeeService
+}
+
+// ImportReport counts the rows an import created, updated and left as they
+// were, or would have on a dry run.
+type ImportReport struct {
+	DryRun     bool        `json:"dry_run"`
+	Categories ImportCount `json:"categories"`
+	Services   ImportCount `json:"services"`
+	Employees  ImportCount `json:"employees"`
+	Prices     ImportCount `json:"prices"`
+}
+
+type ImportCount struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
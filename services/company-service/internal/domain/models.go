@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -23,31 +25,68 @@ type Company struct {
 	Services   []Service  `json:"services,omitempty"`
 }
 
+// DefaultTimezone is used for branches created without one.
+const DefaultTimezone = "UTC"
+
 // Branch represents a physical location of the company.
 type Branch struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	CompanyID uint           `json:"company_id" gorm:"not null"`
-	Name      string         `json:"name" gorm:"not null"`
-	Address   string         `json:"address"`
-	Phone     string         `json:"phone"`
-	IsMain    bool           `json:"is_main" gorm:"default:false"`
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	CompanyID uint   `json:"company_id" gorm:"not null"`
+	Name      string `json:"name" gorm:"not null"`
+	Address   string `json:"address"`
+	Phone     string `json:"phone"`
+	IsMain    bool   `json:"is_main" gorm:"default:false"`
+	Location
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations
-	Employees []Employee `json:"employees,omitempty"`
+	Employees       []Employee       `json:"employees,omitempty"`
+	OpeningHours    []OpeningHours   `json:"opening_hours,omitempty"`
+	HoursExceptions []HoursException `json:"hours_exceptions,omitempty"`
+}
+
+// Location places a branch on the map and in time.
+type Location struct {
+	Timezone  string   `json:"timezone" gorm:"not null;default:UTC"` // IANA name, e.g. "Asia/Vladivostok"
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// OpeningHours are a branch's regular hours on one weekday, on the wall clock
+// of the branch timezone. A weekday without hours is closed, unless the branch
+// has no hours at all.
+type OpeningHours struct {
+	ID        uint   `json:"-" gorm:"primaryKey"`
+	CompanyID uint   `json:"-" gorm:"not null;index"`
+	BranchID  uint   `json:"-" gorm:"not null;uniqueIndex:idx_opening_hours_branch_day"`
+	DayOfWeek int    `json:"day_of_week" gorm:"not null;uniqueIndex:idx_opening_hours_branch_day"` // 0 = Sunday
+	OpenTime  string `json:"open_time" gorm:"not null"`                                            // e.g. "09:00"
+	CloseTime string `json:"close_time" gorm:"not null"`                                           // e.g. "21:00"
+}
+
+// HoursException replaces the weekly hours on one date, e.g. a holiday.
+type HoursException struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	CompanyID uint      `json:"-" gorm:"not null;index"`
+	BranchID  uint      `json:"-" gorm:"not null;uniqueIndex:idx_hours_exceptions_branch_date"`
+	Date      time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_hours_exceptions_branch_date"`
+	IsClosed  bool      `json:"is_closed" gorm:"not null;default:false"`
+	OpenTime  string    `json:"open_time"`
+	CloseTime string    `json:"close_time"`
 }
 
 // Category groups services (e.g., "Hair", "Nails").
 type Category struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	CompanyID uint           `json:"company_id" gorm:"not null"`
-	BranchID  uint           `json:"branch_id" gorm:"not null;index"`
-	Name      string         `json:"name" gorm:"not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	CompanyID   uint           `json:"company_id" gorm:"not null"`
+	BranchID    uint           `json:"branch_id" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"not null"`
+	ExternalKey *string        `json:"external_key,omitempty"` // Identifies the category in catalog imports
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations
 	Services []Service `json:"services,omitempty" gorm:"foreignKey:CategoryID"`
@@ -63,25 +102,104 @@ type Service struct {
 	Description     string         `json:"description"`
 	Price           float64        `json:"price" gorm:"not null;default:0"`
 	DurationMinutes int            `json:"duration_minutes" gorm:"not null;default:0"`
+	ExternalKey     *string        `json:"external_key,omitempty"` // Identifies the service in catalog imports
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relations
+	Options      []ServiceOption      `json:"options,omitempty" gorm:"foreignKey:ServiceID"`
+	Requirements []ServiceRequirement `json:"requirements,omitempty" gorm:"foreignKey:ServiceID"`
+}
+
+// OptionKind says how a ServiceOption is chosen.
+type OptionKind string
+
+const (
+	OptionVariant OptionKind = "variant"
+	OptionAddOn   OptionKind = "addon"
+)
+
+// ServiceOption is a variant of a service (short, medium or long hair), of
+// which a booking picks exactly one when the service has any, or an add-on
+// (wash), of which it picks any. Both add to the service's price and duration.
+type ServiceOption struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CompanyID     uint           `json:"company_id" gorm:"not null;index"`
+	ServiceID     uint           `json:"service_id" gorm:"not null;index"`
+	Kind          OptionKind     `json:"kind" gorm:"type:text;not null"`
+	Name          string         `json:"name" gorm:"not null"`
+	PriceDelta    float64        `json:"price_delta" gorm:"not null;default:0"`
+	DurationDelta int            `json:"duration_delta_minutes" gorm:"not null;default:0"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Quote is what a service with the chosen options costs and takes when
+// performed by an employee.
+type Quote struct {
+	EmployeeID      uint    `json:"employee_id"`
+	ServiceID       uint    `json:"service_id"`
+	VariantID       *uint   `json:"variant_id,omitempty"`
+	AddOnIDs        []uint  `json:"addon_ids,omitempty"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+	// BranchID is the service's branch, where the appointment takes place.
+	BranchID uint `json:"branch_id"`
+	// CompanyID owns the service; bookings of it belong to this tenant.
+	CompanyID uint `json:"company_id"`
+	// Requirements are the resources a booking of the service must hold.
+	Requirements []ResourceNeed `json:"requirements,omitempty"`
 }
 
-// Employee represents a staff member (Master).
+// ResourceNeed is a requirement of a quoted service together with the
+// branch resources that can meet it.
+type ResourceNeed struct {
+	TypeID      uint   `json:"type_id"`
+	Quantity    int    `json:"quantity"`
+	ResourceIDs []uint `json:"resource_ids"`
+}
+
+// Employee represents a staff member (Master). BranchID is their primary
+// branch; Branches lists every branch they work at, the primary included.
 type Employee struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	BranchID  uint           `json:"branch_id" gorm:"not null"`
-	UserID    *uint          `json:"user_id"` // Optional link to Auth Service
-	Name      string         `json:"name" gorm:"not null"`
-	Position  string         `json:"position"`
-	AvatarURL string         `json:"avatar_url"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	CompanyID   uint           `json:"company_id" gorm:"not null;index"`
+	BranchID    uint           `json:"branch_id" gorm:"not null"`
+	UserID      *uint          `json:"user_id"` // Optional link to Auth Service
+	Name        string         `json:"name" gorm:"not null"`
+	Position    string         `json:"position"`
+	Role        Role           `json:"role" gorm:"not null;default:master"`
+	AvatarURL   string         `json:"avatar_url"`
+	ExternalKey *string        `json:"external_key,omitempty"`      // Identifies the employee in catalog imports, company-wide
+	Password    string         `json:"password,omitempty" gorm:"-"` // One-time password of the account AddEmployee registered; never stored
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations
 	Services []EmployeeService `json:"services,omitempty" gorm:"foreignKey:EmployeeID"`
+	Branches []EmployeeBranch  `json:"branches,omitempty" gorm:"foreignKey:EmployeeID"`
+}
+
+// WorksAt reports whether the employee is assigned to the branch. It needs
+// Branches loaded.
+func (e *Employee) WorksAt(branchID uint) bool {
+	for _, b := range e.Branches {
+		if b.BranchID == branchID {
+			return true
+		}
+	}
+	return false
+}
+
+// EmployeeBranch assigns an employee to a branch. Their shifts there and the
+// branch's services on their menu are bookable.
+type EmployeeBranch struct {
+	EmployeeID uint `json:"employee_id" gorm:"primaryKey;autoIncrement:false"`
+	BranchID   uint `json:"branch_id" gorm:"primaryKey;autoIncrement:false"`
+	CompanyID  uint `json:"company_id" gorm:"not null"`
 }
 
 // EmployeeService is the pricing matrix (Junction Table).
@@ -89,6 +207,7 @@ type Employee struct {
 type EmployeeService struct {
 	EmployeeID      uint    `json:"employee_id" gorm:"primaryKey"`
 	ServiceID       uint    `json:"service_id" gorm:"primaryKey"`
+	CompanyID       uint    `json:"company_id" gorm:"not null"`
 	Price           float64 `json:"price" gorm:"not null"`
 	DurationMinutes int     `json:"duration_minutes" gorm:"not null"`
 
@@ -96,10 +215,94 @@ type EmployeeService struct {
 	Service *Service `json:"service,omitempty" gorm:"foreignKey:ServiceID"`
 }
 
+// EmployeeServiceOption replaces an option's price and duration changes for
+// one employee, e.g. a senior stylist charging more for long hair.
+type EmployeeServiceOption struct {
+	EmployeeID    uint    `json:"employee_id" gorm:"primaryKey"`
+	OptionID      uint    `json:"option_id" gorm:"primaryKey"`
+	CompanyID     uint    `json:"company_id" gorm:"not null"`
+	PriceDelta    float64 `json:"price_delta" gorm:"not null"`
+	DurationDelta int     `json:"duration_delta_minutes" gorm:"not null"`
+}
+
+// Bundle sells several services together at one price ("haircut + beard").
+// Its items are performed in order, back to back apart from each item's gap,
+// by one or several employees.
+type Bundle struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	CompanyID   uint           `json:"company_id" gorm:"not null;index"`
+	BranchID    uint           `json:"branch_id" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description"`
+	Price       float64        `json:"price" gorm:"not null;default:0"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relations
+	Items []BundleItem `json:"items" gorm:"foreignKey:BundleID"`
+}
+
+// BundleItem is one service of a bundle. GapMinutes is the pause after it,
+// before the next item starts.
+type BundleItem struct {
+	ID         uint  `json:"id" gorm:"primaryKey"`
+	CompanyID  uint  `json:"company_id" gorm:"not null;index"`
+	BundleID   uint  `json:"bundle_id" gorm:"not null;index"`
+	Position   int   `json:"position" gorm:"not null"`
+	ServiceID  uint  `json:"service_id" gorm:"not null"`
+	VariantID  *uint `json:"variant_id,omitempty"`
+	GapMinutes int   `json:"gap_minutes" gorm:"not null;default:0"`
+
+	// Relations for Preloading
+	Service *Service `json:"service,omitempty" gorm:"foreignKey:ServiceID"`
+}
+
+// ResourceType is a kind of thing a branch books besides its staff: a
+// massage room, a pedicure chair, a laser device.
+type ResourceType struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CompanyID uint           `json:"company_id" gorm:"not null;index"`
+	BranchID  uint           `json:"branch_id" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relations
+	Resources []BranchResource `json:"resources,omitempty" gorm:"foreignKey:TypeID"`
+}
+
+// BranchResource is one room, chair or device of a branch. An appointment
+// holds it from start to end, so no other booking can use it meanwhile.
+type BranchResource struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CompanyID uint           `json:"company_id" gorm:"not null;index"`
+	BranchID  uint           `json:"branch_id" gorm:"not null;index"`
+	TypeID    uint           `json:"type_id" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ServiceRequirement says a service needs Quantity resources of a type for
+// its whole length, e.g. "1 × massage room".
+type ServiceRequirement struct {
+	ServiceID uint `json:"service_id" gorm:"primaryKey"`
+	TypeID    uint `json:"type_id" gorm:"primaryKey"`
+	CompanyID uint `json:"company_id" gorm:"not null"`
+	Quantity  int  `json:"quantity" gorm:"not null;default:1"`
+}
+
 // Interfaces
 
 type CompanyRepository interface {
 	// Transactions
+	// Transaction runs fn with a repository bound to a single database transaction.
+	Transaction(ctx context.Context, fn func(repo CompanyRepository) error) error
+	// AddEvents stores domain events in the outbox; use it inside Transaction.
+	AddEvents(ctx context.Context, envs ...events.Envelope) error
 	CreateCompanyWithBranch(ctx context.Context, company *Company, branch *Branch) error
 
 	// CRUD
@@ -109,39 +312,193 @@ type CompanyRepository interface {
 	UpdateService(ctx context.Context, service *Service) error
 	CreateEmployee(ctx context.Context, employee *Employee) error
 
+	// Updates change the non-zero fields; parent IDs cannot be changed.
+	UpdateCompany(ctx context.Context, company *Company) error
+	UpdateBranch(ctx context.Context, branch *Branch) error
+	UpdateCategory(ctx context.Context, category *Category) error
+	UpdateEmployee(ctx context.Context, employee *Employee) error
+	// SetEmployeeBranches sets the primary branch of employee and replaces
+	// their assignments with employee.Branches.
+	SetEmployeeBranches(ctx context.Context, employee *Employee) error
+	// SetBranchHours replaces the opening hours and exceptions of a branch.
+	SetBranchHours(ctx context.Context, branchID uint, hours []OpeningHours, exceptions []HoursException) error
+
+	// Lifecycle: Delete* soft-delete a row, Restore* bring it back.
+	// Both return erru.ErrNotFound when there is nothing to change.
+	// DeleteCompany and RestoreCompany also cover the company's branches,
+	// staff and catalog, except rows deleted before the company was.
+	DeleteCompany(ctx context.Context, id uint) error
+	RestoreCompany(ctx context.Context, id uint) error
+	DeleteBranch(ctx context.Context, id uint) error
+	RestoreBranch(ctx context.Context, id uint) error
+	DeleteCategory(ctx context.Context, id uint) error
+	RestoreCategory(ctx context.Context, id uint) error
+	DeleteEmployee(ctx context.Context, id uint) error
+	RestoreEmployee(ctx context.Context, id uint) error
+	// DetachServices removes the services of a category from it and returns them.
+	DetachServices(ctx context.Context, categoryID uint) ([]Service, error)
+
 	// Queries
 	GetCompanyByID(ctx context.Context, id uint) (*Company, error)
-	GetCompaniesByOwnerID(ctx context.Context, ownerID uint) ([]Company, error)
-	GetBranchesByCompanyID(ctx context.Context, companyID uint) ([]Branch, error)
-	GetCategoriesByBranchID(ctx context.Context, branchID uint) ([]Category, error)
-	GetServicesByBranchID(ctx context.Context, branchID uint) ([]Service, error)
-	GetEmployeesByCompanyID(ctx context.Context, companyID uint) ([]Employee, error)
-	GetServicesByEmployeeID(ctx context.Context, employeeID uint) ([]EmployeeService, error)
+	// GetBranchByID and GetBranchesByCompanyID include the opening hours;
+	// GetEmployeeByID and GetEmployeesByCompanyID include the assignments.
+	GetBranchByID(ctx context.Context, id uint) (*Branch, error)
+	GetServiceByID(ctx context.Context, id uint) (*Service, error)
+	GetCategoryByID(ctx context.Context, id uint) (*Category, error)
+	GetEmployeeByID(ctx context.Context, id uint) (*Employee, error)
+	GetCompaniesByOwnerID(ctx context.Context, ownerID uint, page pagination.Request) (pagination.Page[Company], error)
+	GetBranchesByCompanyID(ctx context.Context, companyID uint, page pagination.Request) (pagination.Page[Branch], error)
+	GetCategoriesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Category], error)
+	GetServicesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Service], error)
+	// GetEmployeesByCompanyID and GetServicesByEmployeeID keep the employees
+	// assigned to, and the services of, branchID unless it is 0.
+	GetEmployeesByCompanyID(ctx context.Context, companyID, branchID uint, page pagination.Request) (pagination.Page[Employee], error)
+	GetServicesByEmployeeID(ctx context.Context, employeeID, branchID uint, page pagination.Request) (pagination.Page[EmployeeService], error)
 
 	// Pricing Matrix
 	AssignServiceToEmployee(ctx context.Context, relation *EmployeeService) error
 	RemoveServiceFromEmployee(ctx context.Context, employeeID, serviceID uint) error
+	// GetEmployeeService returns nil when the employee has no own terms for the service.
+	GetEmployeeService(ctx context.Context, employeeID, serviceID uint) (*EmployeeService, error)
+
+	// Service Options
+	CreateServiceOption(ctx context.Context, option *ServiceOption) error
+	// UpdateServiceOption sets the name and changes; kind and service stay.
+	UpdateServiceOption(ctx context.Context, option *ServiceOption) error
+	DeleteServiceOption(ctx context.Context, id uint) error
+	GetServiceOptionByID(ctx context.Context, id uint) (*ServiceOption, error)
+	GetServiceOptions(ctx context.Context, serviceID uint) ([]ServiceOption, error)
+	SetEmployeeOption(ctx context.Context, override *EmployeeServiceOption) error
+	RemoveEmployeeOption(ctx context.Context, employeeID, optionID uint) error
+	GetEmployeeOptions(ctx context.Context, employeeID uint, optionIDs []uint) ([]EmployeeServiceOption, error)
+
+	// Bundles
+	CreateBundle(ctx context.Context, bundle *Bundle) error
+	// UpdateBundle sets the name, description and price and replaces the items.
+	UpdateBundle(ctx context.Context, bundle *Bundle) error
+	DeleteBundle(ctx context.Context, id uint) error
+	// GetBundleByID and GetBundlesByBranchID include the items in order.
+	GetBundleByID(ctx context.Context, id uint) (*Bundle, error)
+	GetBundlesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Bundle], error)
+
+	// Resources
+	CreateResourceType(ctx context.Context, resourceType *ResourceType) error
+	UpdateResourceType(ctx context.Context, resourceType *ResourceType) error
+	DeleteResourceType(ctx context.Context, id uint) error
+	// GetResourceTypeByID and GetResourceTypesByBranchID include the resources.
+	GetResourceTypeByID(ctx context.Context, id uint) (*ResourceType, error)
+	GetResourceTypesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[ResourceType], error)
+	CreateResource(ctx context.Context, resource *BranchResource) error
+	UpdateResource(ctx context.Context, resource *BranchResource) error
+	DeleteResource(ctx context.Context, id uint) error
+	GetResourceByID(ctx context.Context, id uint) (*BranchResource, error)
+	// GetResourcesByType returns the resources of the types, by ID.
+	GetResourcesByType(ctx context.Context, typeIDs []uint) ([]BranchResource, error)
+	// SetServiceRequirements replaces the requirements of a service.
+	SetServiceRequirements(ctx context.Context, serviceID uint, requirements []ServiceRequirement) error
+	GetServiceRequirements(ctx context.Context, serviceID uint) ([]ServiceRequirement, error)
+	// CountRequirements counts the live services that need a resource type.
+	CountRequirements(ctx context.Context, typeID uint) (int64, error)
+
+	// GetBranchCatalog loads the live categories, services and staff of a
+	// branch and the staff's prices for its services.
+	GetBranchCatalog(ctx context.Context, branchID uint) (*BranchCatalog, error)
+	// GetEmployeesByExternalKey finds employees of a company by external key,
+	// with their assignments.
+	GetEmployeesByExternalKey(ctx context.Context, companyID uint, keys []string) ([]Employee, error)
+	// GetEmployeesByIDs finds employees of a company by ID, with their
+	// assignments. IDs of other companies are skipped.
+	GetEmployeesByIDs(ctx context.Context, companyID uint, ids []uint) ([]Employee, error)
 }
 
 type CompanyUsecase interface {
 	CreateCompany(ctx context.Context, name string, ownerID uint) (*Company, error)
-	GetMyCompanies(ctx context.Context, ownerID uint) ([]Company, error)
+	GetMyCompanies(ctx context.Context, ownerID uint, page pagination.Request) (pagination.Page[Company], error)
 	GetCompanyByID(ctx context.Context, id uint) (*Company, error)
+	UpdateCompany(ctx context.Context, company *Company) error
+	DeleteCompany(ctx context.Context, id uint) error
+	RestoreCompany(ctx context.Context, id uint) (*Company, error)
 
-	AddBranch(ctx context.Context, companyID uint, name, address, phone string) (*Branch, error)
-	GetCompanyBranches(ctx context.Context, companyID uint) ([]Branch, error)
+	AddBranch(ctx context.Context, companyID uint, name, address, phone string, location Location) (*Branch, error)
+	GetBranch(ctx context.Context, id uint) (*Branch, error)
+	GetCompanyBranches(ctx context.Context, companyID uint, page pagination.Request) (pagination.Page[Branch], error)
+	UpdateBranch(ctx context.Context, branch *Branch) error
+	// SetBranchHours replaces the weekly hours and exceptions of a branch;
+	// empty lists leave the branch unrestricted.
+	SetBranchHours(ctx context.Context, branchID uint, hours []OpeningHours, exceptions []HoursException) (*Branch, error)
+	// DeleteBranch refuses to delete the company's main branch.
+	DeleteBranch(ctx context.Context, id uint) error
+	RestoreBranch(ctx context.Context, id uint) (*Branch, error)
 
-	AddCategory(ctx context.Context, companyID uint, branchID uint, name string) (*Category, error)
-	GetBranchCategories(ctx context.Context, branchID uint) ([]Category, error)
+	AddCategory(ctx context.Context, branchID uint, name string) (*Category, error)
+	GetBranchCategories(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Category], error)
+	UpdateCategory(ctx context.Context, category *Category) error
+	// DeleteCategory leaves the category's services in the catalog without a category.
+	DeleteCategory(ctx context.Context, id uint) error
+	RestoreCategory(ctx context.Context, id uint) (*Category, error)
 
-	AddService(ctx context.Context, companyID uint, branchID uint, categoryID *uint, name, description string, price float64, duration int) (*Service, error)
+	AddService(ctx context.Context, branchID uint, categoryID *uint, name, description string, price float64, duration int) (*Service, error)
 	UpdateService(ctx context.Context, service *Service) error
-	GetBranchServices(ctx context.Context, branchID uint) ([]Service, error)
+	GetBranchServices(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Service], error)
 
-	AddEmployee(ctx context.Context, branchID uint, name, position, email string) (*Employee, error)
-	GetCompanyEmployees(ctx context.Context, companyID uint) ([]Employee, error)
+	// AddEmployee adds a master, or an employee with the given role. With
+	// an email it registers their account and returns its one-time password
+	// in Password; the account is removed again if the employee is not added.
+	AddEmployee(ctx context.Context, branchID uint, name, position, email string, role Role) (*Employee, error)
+	// GetCompanyEmployees lists the staff of a company, or of one of its
+	// branches when branchID is not 0.
+	GetCompanyEmployees(ctx context.Context, companyID, branchID uint, page pagination.Request) (pagination.Page[Employee], error)
+	GetBranchEmployees(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Employee], error)
+	UpdateEmployee(ctx context.Context, employee *Employee) error
+	DeleteEmployee(ctx context.Context, id uint) error
+	RestoreEmployee(ctx context.Context, id uint) (*Employee, error)
+	// SetEmployeeBranches assigns an employee to branches of their company,
+	// one of which is primary.
+	SetEmployeeBranches(ctx context.Context, employeeID, primaryID uint, branchIDs []uint) (*Employee, error)
 
+	// AssignService puts a service of one of the employee's branches on their menu.
 	AssignService(ctx context.Context, employeeID, serviceID uint, price float64, duration int) error
 	RemoveService(ctx context.Context, employeeID, serviceID uint) error
-	GetEmployeeMenu(ctx context.Context, employeeID uint) ([]EmployeeService, error)
+	// GetEmployeeMenu lists the employee's services, at one branch when branchID is not 0.
+	GetEmployeeMenu(ctx context.Context, employeeID, branchID uint, page pagination.Request) (pagination.Page[EmployeeService], error)
+
+	AddServiceOption(ctx context.Context, serviceID uint, kind OptionKind, name string, priceDelta float64, durationDelta int) (*ServiceOption, error)
+	UpdateServiceOption(ctx context.Context, option *ServiceOption) error
+	DeleteServiceOption(ctx context.Context, id uint) error
+	// SetEmployeeOption overrides an option's changes for an employee.
+	SetEmployeeOption(ctx context.Context, employeeID, optionID uint, priceDelta float64, durationDelta int) error
+	RemoveEmployeeOption(ctx context.Context, employeeID, optionID uint) error
+	// Quote prices a service for an employee: their own terms, or the
+	// service's, plus the variant and add-ons. A service with variants needs one.
+	// The employee must work at the service's branch. The quote also lists the
+	// branch resources that can meet its requirements.
+	Quote(ctx context.Context, employeeID, serviceID uint, variantID *uint, addOnIDs []uint) (*Quote, error)
+
+	// AddBundle and UpdateBundle take the items in order; their services
+	// must be in the bundle's branch.
+	AddBundle(ctx context.Context, branchID uint, name, description string, price float64, items []BundleItem) (*Bundle, error)
+	UpdateBundle(ctx context.Context, bundle *Bundle) error
+	DeleteBundle(ctx context.Context, id uint) error
+	GetBundle(ctx context.Context, id uint) (*Bundle, error)
+	GetBranchBundles(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[Bundle], error)
+
+	AddResourceType(ctx context.Context, branchID uint, name string) (*ResourceType, error)
+	UpdateResourceType(ctx context.Context, resourceType *ResourceType) error
+	// DeleteResourceType refuses while the type has resources or services need it.
+	DeleteResourceType(ctx context.Context, id uint) error
+	GetBranchResourceTypes(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[ResourceType], error)
+	// AddResource adds a resource of the type to the type's branch.
+	AddResource(ctx context.Context, typeID uint, name string) (*BranchResource, error)
+	UpdateResource(ctx context.Context, resource *BranchResource) error
+	DeleteResource(ctx context.Context, id uint) error
+	// SetServiceRequirements replaces what a service needs; the types must
+	// be of the service's branch.
+	SetServiceRequirements(ctx context.Context, serviceID uint, requirements []ServiceRequirement) ([]ServiceRequirement, error)
+
+	ExportCatalog(ctx context.Context, branchID uint) (*Catalog, error)
+	// ImportCatalog upserts the catalog into a branch by key in one
+	// transaction. Nothing is written when a row is invalid or on a dry run.
+	// An id:<id> key of another branch creates a row that keeps the key,
+	// unless it names an employee of the company, who joins the branch.
+	ImportCatalog(ctx context.Context, branchID uint, catalog *Catalog, dryRun bool) (*ImportReport, error)
 }
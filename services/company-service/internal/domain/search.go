@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/pagination"
+)
+
+// BranchSearch finds open-for-business branches near a point. Service and
+// Category match service and category names case-insensitively.
+type BranchSearch struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	Service   string
+	Category  string
+}
+
+// BranchMatch is a branch found by a BranchSearch.
+type BranchMatch struct {
+	ID          uint   `json:"id"`
+	CompanyID   uint   `json:"company_id"`
+	CompanyName string `json:"company_name"`
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	Phone       string `json:"phone"`
+	Location
+	DistanceKm float64 `json:"distance_km"`
+	// HasFreeSlots is set when the search names a date: whether any employee
+	// of the branch offering a matching service has a free slot that day.
+	// It is left unset when that is unknown: booking-service failed, or only
+	// some of the branch's employees and services were checked and none had
+	// a free slot.
+	HasFreeSlots *bool `json:"has_free_slots,omitempty" gorm:"-"`
+}
+
+// Offer is an employee performing a service, the pair booking-service
+// computes slots for.
+type Offer struct {
+	EmployeeID uint
+	ServiceID  uint
+	// VariantID is the service's first variant, if it has any.
+	VariantID *uint
+}
+
+type SearchRepository interface {
+	SearchBranches(ctx context.Context, search BranchSearch, page pagination.Request) (pagination.Page[BranchMatch], error)
+	// BranchOffers lists up to limit employee/service pairs of a branch that
+	// match the search's service and category.
+	BranchOffers(ctx context.Context, branchID uint, search BranchSearch, limit int) ([]Offer, error)
+}
+
+type SearchUsecase interface {
+	// SearchBranches sorts by distance unless page says otherwise. A non-zero
+	// date fills in BranchMatch.HasFreeSlots.
+	SearchBranches(ctx context.Context, search BranchSearch, date time.Time, page pagination.Request) (pagination.Page[BranchMatch], error)
+}
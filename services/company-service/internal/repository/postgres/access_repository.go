@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+// accessRepository answers authorization lookups. It reads across tenants:
+// whether the caller may see a row is decided by their membership. Deleted
+// resources and companies still resolve, so their owners can restore them;
+// deleted employees lose their membership.
+type accessRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessRepository(db *gorm.DB) domain.AccessRepository {
+	return &accessRepository{db: db}
+}
+
+func (r *accessRepository) CompanyOf(ctx context.Context, resource domain.Resource, id uint) (uint, error) {
+	query := r.db.WithContext(tenant.System(ctx)).Unscoped()
+	column := "company_id"
+	switch resource {
+	case domain.ResourceCompany:
+		query, column = query.Model(&domain.Company{}).Where("id = ?", id), "id"
+	case domain.ResourceBranch:
+		query = query.Model(&domain.Branch{}).Where("id = ?", id)
+	case domain.ResourceCategory:
+		query = query.Model(&domain.Category{}).Where("id = ?", id)
+	case domain.ResourceService:
+		query = query.Model(&domain.Service{}).Where("id = ?", id)
+	case domain.ResourceOption:
+		query = query.Model(&domain.ServiceOption{}).Where("id = ?", id)
+	case domain.ResourceBundle:
+		query = query.Model(&domain.Bundle{}).Where("id = ?", id)
+	case domain.ResourceResourceType:
+		query = query.Model(&domain.ResourceType{}).Where("id = ?", id)
+	case domain.ResourceBranchResource:
+		query = query.Model(&domain.BranchResource{}).Where("id = ?", id)
+	case domain.ResourceEmployee:
+		query = query.Model(&domain.Employee{}).
+			Joins("JOIN branches ON branches.id = employees.branch_id").
+			Where("employees.id = ?", id)
+		column = "branches.company_id"
+	default:
+		return 0, erru.E(erru.CodeInternal, "Unknown resource "+string(resource))
+	}
+
+	var companyIDs []uint
+	if err := query.Limit(1).Pluck(column, &companyIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(companyIDs) == 0 {
+		return 0, erru.ErrNotFound
+	}
+	return companyIDs[0], nil
+}
+
+func (r *accessRepository) Membership(ctx context.Context, companyID, userID uint) (*domain.Membership, error) {
+	db := r.db.WithContext(tenant.System(ctx))
+
+	var owners int64
+	if err := db.Unscoped().Model(&domain.Company{}).Where("id = ? AND owner_id = ?", companyID, userID).Count(&owners).Error; err != nil {
+		return nil, err
+	}
+	if owners > 0 {
+		return &domain.Membership{CompanyID: companyID, UserID: userID, Role: domain.RoleOwner}, nil
+	}
+
+	var roles []domain.Role
+	err := db.Model(&domain.Employee{}).
+		Where("company_id = ? AND user_id = ?", companyID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	return &domain.Membership{CompanyID: companyID, UserID: userID, Role: roles[0]}, nil
+}
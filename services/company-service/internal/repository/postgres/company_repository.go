@@ -3,11 +3,20 @@ package postgres
 import (
 	"context"
 	"errors"
+	"sort"
+	"time"
 
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/outbox"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
 	"github.com/vipos89/timehub/services/company-service/internal/domain"
 	"gorm.io/gorm"
 )
 
+// companyRepository restricts every query to the tenant in ctx (see pkg/tenant).
+// Rows of other companies behave as if they did not exist.
 type companyRepository struct {
 	db *gorm.DB
 }
@@ -16,6 +25,21 @@ func NewCompanyRepository(db *gorm.DB) domain.CompanyRepository {
 	return &companyRepository{db: db}
 }
 
+func (r *companyRepository) Transaction(ctx context.Context, fn func(repo domain.CompanyRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&companyRepository{db: tx})
+	})
+}
+
+func (r *companyRepository) AddEvents(ctx context.Context, envs ...events.Envelope) error {
+	return outbox.Add(r.db.WithContext(ctx), envs...)
+}
+
+// scoped starts a query on a tenant-owned table.
+func (r *companyRepository) scoped(ctx context.Context, column string) *gorm.DB {
+	return r.db.WithContext(ctx).Scopes(tenant.Scope(ctx, column))
+}
+
 // CreateCompanyWithBranch implements Transactional creation
 func (r *companyRepository) CreateCompanyWithBranch(ctx context.Context, company *domain.Company, branch *domain.Branch) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -28,8 +52,8 @@ func (r *companyRepository) CreateCompanyWithBranch(ctx context.Context, company
 		branch.CompanyID = company.ID
 		branch.IsMain = true // Enforce main branch on creation
 
-		// 3. Create Branch
-		if err := tx.Create(branch).Error; err != nil {
+		// 3. Create Branch, inside the new company
+		if err := tx.WithContext(tenant.WithCompany(ctx, company.ID)).Create(branch).Error; err != nil {
 			return err
 		}
 
@@ -38,26 +62,256 @@ func (r *companyRepository) CreateCompanyWithBranch(ctx context.Context, company
 }
 
 func (r *companyRepository) CreateBranch(ctx context.Context, branch *domain.Branch) error {
+	if err := tenant.Authorize(ctx, "companies", branch.CompanyID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(branch).Error
 }
 
 func (r *companyRepository) CreateCategory(ctx context.Context, category *domain.Category) error {
+	if err := tenant.Authorize(ctx, "companies", category.CompanyID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(category).Error
 }
 
 func (r *companyRepository) CreateService(ctx context.Context, service *domain.Service) error {
+	if err := tenant.Authorize(ctx, "companies", service.CompanyID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(service).Error
 }
 
 func (r *companyRepository) UpdateService(ctx context.Context, service *domain.Service) error {
-	return r.db.WithContext(ctx).Model(&domain.Service{ID: service.ID}).Updates(service).Error
+	res := r.scoped(ctx, "company_id").Model(&domain.Service{ID: service.ID}).Omit("company_id", "branch_id").Updates(service)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return tenant.Guard(ctx, r.db, "services", service.ID)
+	}
+	return nil
+}
+
+func (r *companyRepository) UpdateCompany(ctx context.Context, company *domain.Company) error {
+	return r.update(ctx, "companies", "id", company.ID, &domain.Company{ID: company.ID}, company, "owner_id")
+}
+
+func (r *companyRepository) UpdateBranch(ctx context.Context, branch *domain.Branch) error {
+	return r.update(ctx, "branches", "company_id", branch.ID, &domain.Branch{ID: branch.ID}, branch, "company_id", "is_main")
+}
+
+func (r *companyRepository) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	return r.update(ctx, "categories", "company_id", category.ID, &domain.Category{ID: category.ID}, category, "company_id", "branch_id")
+}
+
+func (r *companyRepository) UpdateEmployee(ctx context.Context, employee *domain.Employee) error {
+	return r.update(ctx, "employees", "company_id", employee.ID, &domain.Employee{ID: employee.ID}, employee, "company_id", "branch_id", "user_id")
+}
+
+func (r *companyRepository) SetBranchHours(ctx context.Context, branchID uint, hours []domain.OpeningHours, exceptions []domain.HoursException) error {
+	branch, err := r.GetBranchByID(ctx, branchID)
+	if err != nil {
+		return err
+	}
+	if branch == nil {
+		return erru.ErrNotFound
+	}
+	db := r.db.WithContext(ctx)
+	if err := db.Where("branch_id = ?", branchID).Delete(&domain.OpeningHours{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("branch_id = ?", branchID).Delete(&domain.HoursException{}).Error; err != nil {
+		return err
+	}
+	for i := range hours {
+		hours[i].ID, hours[i].BranchID, hours[i].CompanyID = 0, branchID, branch.CompanyID
+	}
+	for i := range exceptions {
+		exceptions[i].ID, exceptions[i].BranchID, exceptions[i].CompanyID = 0, branchID, branch.CompanyID
+	}
+	if len(hours) > 0 {
+		if err := db.Create(&hours).Error; err != nil {
+			return err
+		}
+	}
+	if len(exceptions) > 0 {
+		return db.Create(&exceptions).Error
+	}
+	return nil
+}
+
+// companyData are the soft-deletable tables holding a company's branches,
+// staff and catalog, deleted and restored along with the company.
+var companyData = []any{
+	&domain.Branch{}, &domain.Employee{}, &domain.Category{}, &domain.Service{},
+	&domain.ServiceOption{}, &domain.Bundle{}, &domain.ResourceType{}, &domain.BranchResource{},
+}
+
+// DeleteCompany stamps the company and every row of its data with the same
+// deleted_at, so RestoreCompany can tell them from rows deleted earlier on
+// their own. Run it in a transaction.
+func (r *companyRepository) DeleteCompany(ctx context.Context, id uint) error {
+	deletedAt := time.Now()
+	res := r.scoped(ctx, "id").Model(&domain.Company{}).Where("id = ?", id).Update("deleted_at", deletedAt)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, "companies", id)
+	}
+	for _, model := range companyData {
+		err := r.scoped(ctx, "company_id").Model(model).
+			Where("company_id = ?", id).
+			Update("deleted_at", deletedAt).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreCompany brings back the company and the data deleted with it.
+// Run it in a transaction.
+func (r *companyRepository) RestoreCompany(ctx context.Context, id uint) error {
+	var company domain.Company
+	err := r.scoped(ctx, "id").Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Select("id", "deleted_at").
+		Take(&company).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.notFound(ctx, "companies", id)
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.restore(ctx, "companies", "id", &domain.Company{}, id); err != nil {
+		return err
+	}
+	for _, model := range companyData {
+		err := r.scoped(ctx, "company_id").Unscoped().Model(model).
+			Where("company_id = ? AND deleted_at = ?", id, company.DeletedAt.Time).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *companyRepository) DeleteBranch(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "branches", "company_id", &domain.Branch{}, id)
+}
+
+func (r *companyRepository) RestoreBranch(ctx context.Context, id uint) error {
+	return r.restore(ctx, "branches", "company_id", &domain.Branch{}, id)
+}
+
+func (r *companyRepository) DeleteCategory(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "categories", "company_id", &domain.Category{}, id)
+}
+
+func (r *companyRepository) RestoreCategory(ctx context.Context, id uint) error {
+	return r.restore(ctx, "categories", "company_id", &domain.Category{}, id)
+}
+
+func (r *companyRepository) DeleteEmployee(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "employees", "company_id", &domain.Employee{}, id)
+}
+
+func (r *companyRepository) RestoreEmployee(ctx context.Context, id uint) error {
+	return r.restore(ctx, "employees", "company_id", &domain.Employee{}, id)
+}
+
+func (r *companyRepository) DetachServices(ctx context.Context, categoryID uint) ([]domain.Service, error) {
+	var services []domain.Service
+	if err := r.scoped(ctx, "company_id").Where("category_id = ?", categoryID).Find(&services).Error; err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return services, nil
+	}
+	err := r.scoped(ctx, "company_id").Model(&domain.Service{}).
+		Where("category_id = ?", categoryID).
+		Update("category_id", nil).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range services {
+		services[i].CategoryID = nil
+	}
+	return services, nil
+}
+
+// update saves the non-zero fields of values to row id of table, never
+// touching the omitted columns.
+func (r *companyRepository) update(ctx context.Context, table, column string, id uint, model, values any, omit ...string) error {
+	res := r.scoped(ctx, column).Model(model).Omit(omit...).Updates(values)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, table, id)
+	}
+	return nil
+}
+
+func (r *companyRepository) softDelete(ctx context.Context, table, column string, model any, id uint) error {
+	res := r.scoped(ctx, column).Delete(model, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, table, id)
+	}
+	return nil
+}
+
+func (r *companyRepository) restore(ctx context.Context, table, column string, model any, id uint) error {
+	res := r.scoped(ctx, column).Unscoped().Model(model).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, table, id)
+	}
+	return nil
 }
 
 func (r *companyRepository) CreateEmployee(ctx context.Context, employee *domain.Employee) error {
+	if err := tenant.Authorize(ctx, "companies", employee.CompanyID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(employee).Error
 }
 
+func (r *companyRepository) SetEmployeeBranches(ctx context.Context, employee *domain.Employee) error {
+	res := r.scoped(ctx, "company_id").Model(&domain.Employee{ID: employee.ID}).Update("branch_id", employee.BranchID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, "employees", employee.ID)
+	}
+	db := r.db.WithContext(ctx)
+	if err := db.Where("employee_id = ?", employee.ID).Delete(&domain.EmployeeBranch{}).Error; err != nil {
+		return err
+	}
+	for i := range employee.Branches {
+		employee.Branches[i].EmployeeID, employee.Branches[i].CompanyID = employee.ID, employee.CompanyID
+	}
+	if len(employee.Branches) == 0 {
+		return nil
+	}
+	return db.Create(&employee.Branches).Error
+}
+
 func (r *companyRepository) GetCompanyByID(ctx context.Context, id uint) (*domain.Company, error) {
+	if err := tenant.Authorize(ctx, "companies", id); err != nil {
+		return nil, err
+	}
 	var company domain.Company
 	err := r.db.WithContext(ctx).
 		Preload("Branches.Employees").
@@ -72,61 +326,442 @@ func (r *companyRepository) GetCompanyByID(ctx context.Context, id uint) (*domai
 	return &company, nil
 }
 
-func (r *companyRepository) GetCompaniesByOwnerID(ctx context.Context, ownerID uint) ([]domain.Company, error) {
-	var companies []domain.Company
-	err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).Find(&companies).Error
-	return companies, err
+func (r *companyRepository) GetBranchByID(ctx context.Context, id uint) (*domain.Branch, error) {
+	var branch domain.Branch
+	err := r.scoped(ctx, "company_id").
+		Preload("OpeningHours", func(db *gorm.DB) *gorm.DB { return db.Order("day_of_week ASC") }).
+		Preload("HoursExceptions", func(db *gorm.DB) *gorm.DB { return db.Order("date ASC") }).
+		First(&branch, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "branches", id)
+		}
+		return nil, err
+	}
+	return &branch, nil
 }
 
-func (r *companyRepository) GetBranchesByCompanyID(ctx context.Context, companyID uint) ([]domain.Branch, error) {
-	var branches []domain.Branch
-	err := r.db.WithContext(ctx).Where("company_id = ?", companyID).Find(&branches).Error
-	return branches, err
+func (r *companyRepository) GetServiceByID(ctx context.Context, id uint) (*domain.Service, error) {
+	var service domain.Service
+	err := r.scoped(ctx, "company_id").First(&service, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "services", id)
+		}
+		return nil, err
+	}
+	return &service, nil
 }
 
-func (r *companyRepository) GetCategoriesByBranchID(ctx context.Context, branchID uint) ([]domain.Category, error) {
-	var categories []domain.Category
-	err := r.db.WithContext(ctx).
-		Preload("Services").
-		Where("branch_id = ?", branchID).
-		Find(&categories).Error
-	return categories, err
+func (r *companyRepository) GetCategoryByID(ctx context.Context, id uint) (*domain.Category, error) {
+	var category domain.Category
+	err := r.scoped(ctx, "company_id").First(&category, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "categories", id)
+		}
+		return nil, err
+	}
+	return &category, nil
 }
 
-func (r *companyRepository) GetServicesByBranchID(ctx context.Context, branchID uint) ([]domain.Service, error) {
-	var services []domain.Service
-	err := r.db.WithContext(ctx).
-		Where("branch_id = ?", branchID).
-		Find(&services).Error
-	return services, err
+func (r *companyRepository) GetEmployeeByID(ctx context.Context, id uint) (*domain.Employee, error) {
+	var employee domain.Employee
+	err := r.scoped(ctx, "company_id").Preload("Branches").First(&employee, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "employees", id)
+		}
+		return nil, err
+	}
+	return &employee, nil
 }
 
-func (r *companyRepository) GetEmployeesByCompanyID(ctx context.Context, companyID uint) ([]domain.Employee, error) {
-	var employees []domain.Employee
-	// Find all employees belonging to any branch of the company
-	err := r.db.WithContext(ctx).
-		Joins("JOIN branches ON branches.id = employees.branch_id").
-		Where("branches.company_id = ?", companyID).
-		Preload("Services.Service").
-		Find(&employees).Error
-	return employees, err
+func (r *companyRepository) GetCompaniesByOwnerID(ctx context.Context, ownerID uint, page pagination.Request) (pagination.Page[domain.Company], error) {
+	// Owners list their companies before any of them is the request's tenant.
+	query := r.db.WithContext(tenant.System(ctx)).Where("owner_id = ?", ownerID)
+	return pagination.Find[domain.Company](query, page)
+}
+
+func (r *companyRepository) GetBranchesByCompanyID(ctx context.Context, companyID uint, page pagination.Request) (pagination.Page[domain.Branch], error) {
+	if err := tenant.Authorize(ctx, "companies", companyID); err != nil {
+		return pagination.Page[domain.Branch]{}, err
+	}
+	query := r.db.WithContext(ctx).Where("company_id = ?", companyID)
+	return pagination.Find[domain.Branch](query, page, "OpeningHours", "HoursExceptions")
+}
+
+func (r *companyRepository) GetCategoriesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Category], error) {
+	if err := tenant.Guard(ctx, r.db, "branches", branchID); err != nil {
+		return pagination.Page[domain.Category]{}, err
+	}
+	query := r.scoped(ctx, "company_id").Where("branch_id = ?", branchID)
+	return pagination.Find[domain.Category](query, page, "Services")
+}
+
+func (r *companyRepository) GetServicesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Service], error) {
+	if err := tenant.Guard(ctx, r.db, "branches", branchID); err != nil {
+		return pagination.Page[domain.Service]{}, err
+	}
+	query := r.scoped(ctx, "company_id").Where("branch_id = ?", branchID)
+	return pagination.Find[domain.Service](query, page, "Options", "Requirements")
+}
+
+func (r *companyRepository) GetEmployeesByCompanyID(ctx context.Context, companyID, branchID uint, page pagination.Request) (pagination.Page[domain.Employee], error) {
+	if err := tenant.Authorize(ctx, "companies", companyID); err != nil {
+		return pagination.Page[domain.Employee]{}, err
+	}
+	query := r.db.WithContext(ctx).Where("company_id = ?", companyID)
+	if branchID != 0 {
+		query = query.Where("id IN (?)", r.db.Model(&domain.EmployeeBranch{}).Where("branch_id = ?", branchID).Select("employee_id"))
+	}
+	return pagination.Find[domain.Employee](query, page, "Services.Service", "Branches")
 }
 
 func (r *companyRepository) AssignServiceToEmployee(ctx context.Context, relation *domain.EmployeeService) error {
+	// Both sides must belong to the caller's company; the relation inherits it.
+	var employee domain.Employee
+	if err := r.scoped(ctx, "company_id").Select("id", "company_id").First(&employee, relation.EmployeeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return r.notFound(ctx, "employees", relation.EmployeeID)
+		}
+		return err
+	}
+	var service domain.Service
+	if err := r.scoped(ctx, "company_id").Select("id", "company_id").First(&service, relation.ServiceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return r.notFound(ctx, "services", relation.ServiceID)
+		}
+		return err
+	}
+	if service.CompanyID != employee.CompanyID {
+		return tenant.Deny(ctx, "services", relation.ServiceID)
+	}
+	relation.CompanyID = employee.CompanyID
+
 	// Upsert: On conflict update price/duration
 	return r.db.WithContext(ctx).Save(relation).Error
 }
 
 func (r *companyRepository) RemoveServiceFromEmployee(ctx context.Context, employeeID, serviceID uint) error {
-	return r.db.WithContext(ctx).Delete(&domain.EmployeeService{}, "employee_id = ? AND service_id = ?", employeeID, serviceID).Error
+	if err := tenant.Guard(ctx, r.db, "employees", employeeID); err != nil {
+		return err
+	}
+	return r.scoped(ctx, "company_id").Delete(&domain.EmployeeService{}, "employee_id = ? AND service_id = ?", employeeID, serviceID).Error
 }
 
-func (r *companyRepository) GetServicesByEmployeeID(ctx context.Context, employeeID uint) ([]domain.EmployeeService, error) {
-	var results []domain.EmployeeService
-	// Join with Service table to get names
-	err := r.db.WithContext(ctx).
-		Preload("Service").
-		Where("employee_id = ?", employeeID).
-		Find(&results).Error
-	return results, err
+func (r *companyRepository) GetServicesByEmployeeID(ctx context.Context, employeeID, branchID uint, page pagination.Request) (pagination.Page[domain.EmployeeService], error) {
+	if err := tenant.Guard(ctx, r.db, "employees", employeeID); err != nil {
+		return pagination.Page[domain.EmployeeService]{}, err
+	}
+	query := r.scoped(ctx, "company_id").Where("employee_id = ?", employeeID)
+	if branchID != 0 {
+		query = query.Where("service_id IN (?)", r.db.Model(&domain.Service{}).Where("branch_id = ?", branchID).Select("id"))
+	}
+	return pagination.Find[domain.EmployeeService](query, page, "Service", "Service.Options")
+}
+
+func (r *companyRepository) GetEmployeeService(ctx context.Context, employeeID, serviceID uint) (*domain.EmployeeService, error) {
+	var relation domain.EmployeeService
+	err := r.scoped(ctx, "company_id").Where("employee_id = ? AND service_id = ?", employeeID, serviceID).First(&relation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &relation, nil
+}
+
+func (r *companyRepository) CreateServiceOption(ctx context.Context, option *domain.ServiceOption) error {
+	if err := tenant.Authorize(ctx, "companies", option.CompanyID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(option).Error
+}
+
+func (r *companyRepository) UpdateServiceOption(ctx context.Context, option *domain.ServiceOption) error {
+	res := r.scoped(ctx, "company_id").Model(&domain.ServiceOption{ID: option.ID}).
+		Select("name", "price_delta", "duration_delta").
+		Updates(option)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, "service_options", option.ID)
+	}
+	return nil
+}
+
+func (r *companyRepository) DeleteServiceOption(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "service_options", "company_id", &domain.ServiceOption{}, id)
+}
+
+func (r *companyRepository) GetServiceOptionByID(ctx context.Context, id uint) (*domain.ServiceOption, error) {
+	var option domain.ServiceOption
+	err := r.scoped(ctx, "company_id").First(&option, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "service_options", id)
+		}
+		return nil, err
+	}
+	return &option, nil
+}
+
+func (r *companyRepository) GetServiceOptions(ctx context.Context, serviceID uint) ([]domain.ServiceOption, error) {
+	var options []domain.ServiceOption
+	err := r.scoped(ctx, "company_id").Where("service_id = ?", serviceID).Order("id ASC").Find(&options).Error
+	return options, err
+}
+
+func (r *companyRepository) SetEmployeeOption(ctx context.Context, override *domain.EmployeeServiceOption) error {
+	// Both sides must belong to the caller's company; the override inherits it.
+	var employee domain.Employee
+	if err := r.scoped(ctx, "company_id").Select("id", "company_id").First(&employee, override.EmployeeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return r.notFound(ctx, "employees", override.EmployeeID)
+		}
+		return err
+	}
+	option, err := r.GetServiceOptionByID(ctx, override.OptionID)
+	if err != nil {
+		return err
+	}
+	if option == nil || option.CompanyID != employee.CompanyID {
+		return erru.ErrNotFound
+	}
+	override.CompanyID = employee.CompanyID
+	return r.db.WithContext(ctx).Save(override).Error
+}
+
+func (r *companyRepository) RemoveEmployeeOption(ctx context.Context, employeeID, optionID uint) error {
+	if err := tenant.Guard(ctx, r.db, "employees", employeeID); err != nil {
+		return err
+	}
+	return r.scoped(ctx, "company_id").Delete(&domain.EmployeeServiceOption{}, "employee_id = ? AND option_id = ?", employeeID, optionID).Error
+}
+
+func (r *companyRepository) GetEmployeeOptions(ctx context.Context, employeeID uint, optionIDs []uint) ([]domain.EmployeeServiceOption, error) {
+	var overrides []domain.EmployeeServiceOption
+	if len(optionIDs) == 0 {
+		return overrides, nil
+	}
+	err := r.scoped(ctx, "company_id").Where("employee_id = ? AND option_id IN ?", employeeID, optionIDs).Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *companyRepository) CreateBundle(ctx context.Context, bundle *domain.Bundle) error {
+	if err := tenant.Authorize(ctx, "companies", bundle.CompanyID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(bundle).Error
+}
+
+func (r *companyRepository) UpdateBundle(ctx context.Context, bundle *domain.Bundle) error {
+	res := r.scoped(ctx, "company_id").Model(&domain.Bundle{ID: bundle.ID}).
+		Select("name", "description", "price").
+		Updates(bundle)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.notFound(ctx, "bundles", bundle.ID)
+	}
+	db := r.db.WithContext(ctx)
+	if err := db.Where("bundle_id = ?", bundle.ID).Delete(&domain.BundleItem{}).Error; err != nil {
+		return err
+	}
+	for i := range bundle.Items {
+		bundle.Items[i].ID, bundle.Items[i].BundleID = 0, bundle.ID
+	}
+	if len(bundle.Items) == 0 {
+		return nil
+	}
+	return db.Create(&bundle.Items).Error
+}
+
+func (r *companyRepository) DeleteBundle(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "bundles", "company_id", &domain.Bundle{}, id)
+}
+
+func (r *companyRepository) GetBundleByID(ctx context.Context, id uint) (*domain.Bundle, error) {
+	var bundle domain.Bundle
+	err := r.scoped(ctx, "company_id").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Items.Service").
+		First(&bundle, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "bundles", id)
+		}
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (r *companyRepository) GetBundlesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Bundle], error) {
+	if err := tenant.Guard(ctx, r.db, "branches", branchID); err != nil {
+		return pagination.Page[domain.Bundle]{}, err
+	}
+	query := r.scoped(ctx, "company_id").Where("branch_id = ?", branchID)
+	bundles, err := pagination.Find[domain.Bundle](query, page, "Items", "Items.Service")
+	for _, b := range bundles.Items {
+		sort.Slice(b.Items, func(i, j int) bool { return b.Items[i].Position < b.Items[j].Position })
+	}
+	return bundles, err
+}
+
+func (r *companyRepository) CreateResourceType(ctx context.Context, resourceType *domain.ResourceType) error {
+	if err := tenant.Authorize(ctx, "companies", resourceType.CompanyID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(resourceType).Error
+}
+
+func (r *companyRepository) UpdateResourceType(ctx context.Context, resourceType *domain.ResourceType) error {
+	return r.update(ctx, "resource_types", "company_id", resourceType.ID, &domain.ResourceType{ID: resourceType.ID}, resourceType, "company_id", "branch_id")
+}
+
+func (r *companyRepository) DeleteResourceType(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "resource_types", "company_id", &domain.ResourceType{}, id)
+}
+
+func (r *companyRepository) GetResourceTypeByID(ctx context.Context, id uint) (*domain.ResourceType, error) {
+	var resourceType domain.ResourceType
+	err := r.scoped(ctx, "company_id").
+		Preload("Resources", func(db *gorm.DB) *gorm.DB { return db.Order("name ASC") }).
+		First(&resourceType, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "resource_types", id)
+		}
+		return nil, err
+	}
+	return &resourceType, nil
+}
+
+func (r *companyRepository) GetResourceTypesByBranchID(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.ResourceType], error) {
+	if err := tenant.Guard(ctx, r.db, "branches", branchID); err != nil {
+		return pagination.Page[domain.ResourceType]{}, err
+	}
+	query := r.scoped(ctx, "company_id").Where("branch_id = ?", branchID)
+	types, err := pagination.Find[domain.ResourceType](query, page, "Resources")
+	for _, t := range types.Items {
+		sort.Slice(t.Resources, func(i, j int) bool { return t.Resources[i].Name < t.Resources[j].Name })
+	}
+	return types, err
+}
+
+func (r *companyRepository) CreateResource(ctx context.Context, resource *domain.BranchResource) error {
+	if err := tenant.Authorize(ctx, "companies", resource.CompanyID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(resource).Error
+}
+
+func (r *companyRepository) UpdateResource(ctx context.Context, resource *domain.BranchResource) error {
+	return r.update(ctx, "branch_resources", "company_id", resource.ID, &domain.BranchResource{ID: resource.ID}, resource, "company_id", "branch_id", "type_id")
+}
+
+func (r *companyRepository) DeleteResource(ctx context.Context, id uint) error {
+	return r.softDelete(ctx, "branch_resources", "company_id", &domain.BranchResource{}, id)
+}
+
+func (r *companyRepository) GetResourceByID(ctx context.Context, id uint) (*domain.BranchResource, error) {
+	var resource domain.BranchResource
+	err := r.scoped(ctx, "company_id").First(&resource, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, tenant.Guard(ctx, r.db, "branch_resources", id)
+		}
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (r *companyRepository) GetResourcesByType(ctx context.Context, typeIDs []uint) ([]domain.BranchResource, error) {
+	var resources []domain.BranchResource
+	if len(typeIDs) == 0 {
+		return resources, nil
+	}
+	err := r.scoped(ctx, "company_id").Where("type_id IN ?", typeIDs).Order("id ASC").Find(&resources).Error
+	return resources, err
+}
+
+func (r *companyRepository) SetServiceRequirements(ctx context.Context, serviceID uint, requirements []domain.ServiceRequirement) error {
+	db := r.scoped(ctx, "company_id")
+	if err := db.Where("service_id = ?", serviceID).Delete(&domain.ServiceRequirement{}).Error; err != nil {
+		return err
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&requirements).Error
+}
+
+func (r *companyRepository) GetServiceRequirements(ctx context.Context, serviceID uint) ([]domain.ServiceRequirement, error) {
+	var requirements []domain.ServiceRequirement
+	err := r.scoped(ctx, "company_id").Where("service_id = ?", serviceID).Order("type_id ASC").Find(&requirements).Error
+	return requirements, err
+}
+
+func (r *companyRepository) CountRequirements(ctx context.Context, typeID uint) (int64, error) {
+	var count int64
+	err := r.scoped(ctx, "service_requirements.company_id").Model(&domain.ServiceRequirement{}).
+		Joins("JOIN services ON services.id = service_requirements.service_id AND services.deleted_at IS NULL").
+		Where("service_requirements.type_id = ?", typeID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *companyRepository) GetBranchCatalog(ctx context.Context, branchID uint) (*domain.BranchCatalog, error) {
+	var catalog domain.BranchCatalog
+	if err := r.scoped(ctx, "company_id").Where("branch_id = ?", branchID).Order("id ASC").Find(&catalog.Categories).Error; err != nil {
+		return nil, err
+	}
+	if err := r.scoped(ctx, "company_id").Where("branch_id = ?", branchID).Order("id ASC").Find(&catalog.Services).Error; err != nil {
+		return nil, err
+	}
+	err := r.scoped(ctx, "company_id").
+		Where("id IN (?)", r.db.Model(&domain.EmployeeBranch{}).Where("branch_id = ?", branchID).Select("employee_id")).
+		Preload("Branches").Order("id ASC").Find(&catalog.Employees).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.scoped(ctx, "company_id").
+		Where("service_id IN (?)", r.db.Model(&domain.Service{}).Where("branch_id = ?", branchID).Select("id")).
+		Order("employee_id ASC, service_id ASC").Find(&catalog.Prices).Error
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func (r *companyRepository) GetEmployeesByExternalKey(ctx context.Context, companyID uint, keys []string) ([]domain.Employee, error) {
+	var employees []domain.Employee
+	if len(keys) == 0 {
+		return employees, nil
+	}
+	err := r.scoped(ctx, "company_id").Where("company_id = ? AND external_key IN ?", companyID, keys).
+		Preload("Branches").Order("id ASC").Find(&employees).Error
+	return employees, err
+}
+
+func (r *companyRepository) GetEmployeesByIDs(ctx context.Context, companyID uint, ids []uint) ([]domain.Employee, error) {
+	var employees []domain.Employee
+	if len(ids) == 0 {
+		return employees, nil
+	}
+	err := r.scoped(ctx, "company_id").Where("company_id = ? AND id IN ?", companyID, ids).
+		Preload("Branches").Order("id ASC").Find(&employees).Error
+	return employees, err
+}
+
+// notFound reports a scoped miss, logging it when the row belongs to another company.
+func (r *companyRepository) notFound(ctx context.Context, table string, id uint) error {
+	if err := tenant.Guard(ctx, r.db, table, id); err != nil {
+		return err
+	}
+	return erru.ErrNotFound
 }
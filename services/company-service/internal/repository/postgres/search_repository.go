@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+	"gorm.io/gorm"
+)
+
+// earthRadiusKm is the mean radius used by the haversine formula.
+const earthRadiusKm = 6371.0
+
+// haversine is the great-circle distance in km from a branch to the point
+// given as (lat, lat, lng) arguments.
+const haversine = `2 * 6371.0 * asin(sqrt(
+	power(sin(radians(branches.latitude - ?) / 2), 2) +
+	cos(radians(?)) * cos(radians(branches.latitude)) *
+	power(sin(radians(branches.longitude - ?) / 2), 2)))`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// contains is an ILIKE pattern matching s anywhere.
+func contains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// searchRepository reads the public catalog of every company.
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) domain.SearchRepository {
+	return &searchRepository{db: db}
+}
+
+func (r *searchRepository) SearchBranches(ctx context.Context, search domain.BranchSearch, page pagination.Request) (pagination.Page[domain.BranchMatch], error) {
+	// A bounding box on the raw coordinates lets the index cut the candidates
+	// before the exact distance is computed.
+	dLat := search.RadiusKm / (earthRadiusKm * math.Pi / 180)
+	dLng := 180.0
+	if cos := math.Cos(search.Latitude * math.Pi / 180); cos > 0.01 {
+		dLng = math.Min(dLng, dLat/cos)
+	}
+	nearby := r.db.WithContext(tenant.System(ctx)).
+		Table("branches").
+		Select(`branches.id, branches.company_id, companies.name AS company_name,
+			branches.name, branches.address, branches.phone,
+			branches.timezone, branches.latitude, branches.longitude,
+			`+haversine+` AS distance_km`, search.Latitude, search.Latitude, search.Longitude).
+		Joins("JOIN companies ON companies.id = branches.company_id AND companies.deleted_at IS NULL").
+		Where("branches.deleted_at IS NULL AND branches.latitude IS NOT NULL AND branches.longitude IS NOT NULL").
+		Where("branches.latitude BETWEEN ? AND ?", search.Latitude-dLat, search.Latitude+dLat)
+	// Near the antimeridian the box wraps around to the other side of ±180.
+	switch minLng, maxLng := search.Longitude-dLng, search.Longitude+dLng; {
+	case dLng >= 180:
+		// The box spans every longitude.
+	case minLng < -180:
+		nearby = nearby.Where("(branches.longitude >= ? OR branches.longitude <= ?)", minLng+360, maxLng)
+	case maxLng > 180:
+		nearby = nearby.Where("(branches.longitude >= ? OR branches.longitude <= ?)", minLng, maxLng-360)
+	default:
+		nearby = nearby.Where("branches.longitude BETWEEN ? AND ?", minLng, maxLng)
+	}
+	if search.Service != "" || search.Category != "" {
+		nearby = nearby.Where("EXISTS (?)", r.matchingServices(search).Where("services.branch_id = branches.id").Select("1"))
+	}
+
+	query := r.db.WithContext(tenant.System(ctx)).
+		Table("(?) AS nearby", nearby).
+		Where("distance_km <= ?", search.RadiusKm)
+	return pagination.Find[domain.BranchMatch](query, page)
+}
+
+func (r *searchRepository) BranchOffers(ctx context.Context, branchID uint, search domain.BranchSearch, limit int) ([]domain.Offer, error) {
+	var offers []domain.Offer
+	err := r.matchingServices(search).WithContext(tenant.System(ctx)).
+		Joins("JOIN employee_services ON employee_services.service_id = services.id").
+		Joins("JOIN employees ON employees.id = employee_services.employee_id AND employees.deleted_at IS NULL").
+		Joins("JOIN employee_branches ON employee_branches.employee_id = employees.id AND employee_branches.branch_id = services.branch_id").
+		Where("services.branch_id = ?", branchID).
+		Order("employees.id, services.id").
+		Limit(limit).
+		Select("employee_services.employee_id, employee_services.service_id, (?) AS variant_id",
+			r.db.Table("service_options").
+				Where("service_options.service_id = services.id AND service_options.kind = ? AND service_options.deleted_at IS NULL", domain.OptionVariant).
+				Select("MIN(service_options.id)")).
+		Scan(&offers).Error
+	return offers, err
+}
+
+// matchingServices selects the live services matching search by service
+// and category name.
+func (r *searchRepository) matchingServices(search domain.BranchSearch) *gorm.DB {
+	query := r.db.Table("services").Where("services.deleted_at IS NULL")
+	if search.Service != "" {
+		query = query.Where("services.name ILIKE ?", contains(search.Service))
+	}
+	if search.Category != "" {
+		query = query.
+			Joins("JOIN categories ON categories.id = services.category_id AND categories.deleted_at IS NULL").
+			Where("categories.name ILIKE ?", contains(search.Category))
+	}
+	return query
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// rolePermissions lists what each role may do besides reading. Owners may
+// do everything; masters only work with what others set up for them.
+var rolePermissions = map[domain.Role][]domain.Permission{
+	domain.RoleOwner: {domain.PermissionManageCompany, domain.PermissionManageBranches, domain.PermissionManageCatalog, domain.PermissionManageStaff},
+	domain.RoleAdmin: {domain.PermissionManageCatalog, domain.PermissionManageStaff},
+}
+
+type accessUsecase struct {
+	repo           domain.AccessRepository
+	contextTimeout time.Duration
+}
+
+func NewAccessUsecase(repo domain.AccessRepository, timeout time.Duration) domain.AccessUsecase {
+	return &accessUsecase{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *accessUsecase) Authorize(ctx context.Context, userID uint, resource domain.Resource, id uint, permissions ...domain.Permission) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	companyID, err := u.repo.CompanyOf(ctx, resource, id)
+	if err != nil {
+		return 0, err
+	}
+	member, err := u.repo.Membership(ctx, companyID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if member == nil {
+		logger.FromContext(ctx).Warn("Access denied to non-member",
+			"user_id", userID, "resource", resource, "resource_id", id, "company_id", companyID)
+		return 0, erru.ErrNotFound
+	}
+	for _, permission := range permissions {
+		if !allowed(member.Role, permission) {
+			logger.FromContext(ctx).Warn("Access denied by role",
+				"user_id", userID, "role", member.Role, "permission", permission, "company_id", companyID)
+			return 0, erru.ErrForbidden
+		}
+	}
+	return companyID, nil
+}
+
+func (u *accessUsecase) CompanyOf(ctx context.Context, resource domain.Resource, id uint) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.CompanyOf(ctx, resource, id)
+}
+
+func (u *accessUsecase) Membership(ctx context.Context, companyID, userID uint) (*domain.Membership, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	member, err := u.repo.Membership(ctx, companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, erru.ErrNotFound
+	}
+	return member, nil
+}
+
+func allowed(role domain.Role, permission domain.Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
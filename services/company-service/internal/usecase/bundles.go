@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+func (u *companyUsecase) AddBundle(ctx context.Context, branchID uint, name, description string, price float64, items []domain.BundleItem) (*domain.Bundle, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	bundle := &domain.Bundle{
+		BranchID:    branchID,
+		Name:        name,
+		Description: description,
+		Price:       price,
+		Items:       items,
+	}
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		branch, err := branchOf(ctx, repo, branchID)
+		if err != nil {
+			return err
+		}
+		bundle.CompanyID = branch.CompanyID
+		if err := checkBundleItems(ctx, repo, bundle); err != nil {
+			return err
+		}
+		return repo.CreateBundle(ctx, bundle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+func (u *companyUsecase) UpdateBundle(ctx context.Context, bundle *domain.Bundle) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		current, err := bundleOf(ctx, repo, bundle.ID)
+		if err != nil {
+			return err
+		}
+		bundle.CompanyID, bundle.BranchID = current.CompanyID, current.BranchID
+		if err := checkBundleItems(ctx, repo, bundle); err != nil {
+			return err
+		}
+		if err := repo.UpdateBundle(ctx, bundle); err != nil {
+			return err
+		}
+		updated, err := bundleOf(ctx, repo, bundle.ID)
+		if err != nil {
+			return err
+		}
+		*bundle = *updated
+		return nil
+	})
+}
+
+func (u *companyUsecase) DeleteBundle(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.DeleteBundle(ctx, id)
+}
+
+func (u *companyUsecase) GetBundle(ctx context.Context, id uint) (*domain.Bundle, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return bundleOf(ctx, u.repo, id)
+}
+
+func (u *companyUsecase) GetBranchBundles(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Bundle], error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.GetBundlesByBranchID(ctx, branchID, page)
+}
+
+// checkBundleItems numbers the items of bundle in order and checks that each
+// names a service of the bundle's branch and, optionally, one of its variants.
+func checkBundleItems(ctx context.Context, repo domain.CompanyRepository, bundle *domain.Bundle) error {
+	var fields []erru.FieldError
+	for i := range bundle.Items {
+		item := &bundle.Items[i]
+		item.ID, item.Position, item.CompanyID = 0, i, bundle.CompanyID
+		item.Service = nil
+
+		field := fmt.Sprintf("items[%d]", i)
+		service, err := repo.GetServiceByID(ctx, item.ServiceID)
+		if err != nil {
+			return err
+		}
+		if service == nil || service.BranchID != bundle.BranchID {
+			fields = append(fields, erru.FieldError{Field: field + ".service_id", Rule: "oneof", Message: "is not a service of the branch"})
+			continue
+		}
+		if item.VariantID == nil {
+			continue
+		}
+		option, err := repo.GetServiceOptionByID(ctx, *item.VariantID)
+		if err != nil {
+			return err
+		}
+		if option == nil || option.ServiceID != service.ID || option.Kind != domain.OptionVariant {
+			fields = append(fields, erru.FieldError{Field: field + ".variant_id", Rule: "oneof", Message: "is not a variant of the service"})
+		}
+	}
+	if len(fields) > 0 {
+		return erru.Validation(fields...)
+	}
+	return nil
+}
+
+func bundleOf(ctx context.Context, repo domain.CompanyRepository, bundleID uint) (*domain.Bundle, error) {
+	bundle, err := repo.GetBundleByID(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if bundle == nil {
+		return nil, erru.ErrNotFound
+	}
+	return bundle, nil
+}
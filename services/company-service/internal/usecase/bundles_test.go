@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// bundleRepo has branch 1 with services 100 and 101, where 101 has variant
+// 5 and add-on 6, and branch 2 with service 200.
+type bundleRepo struct {
+	domain.CompanyRepository
+	created *domain.Bundle
+}
+
+func (r *bundleRepo) Transaction(ctx context.Context, fn func(repo domain.CompanyRepository) error) error {
+	return fn(r)
+}
+
+func (r *bundleRepo) GetBranchByID(_ context.Context, id uint) (*domain.Branch, error) {
+	if id != 1 {
+		return nil, nil
+	}
+	return &domain.Branch{ID: 1, CompanyID: 1}, nil
+}
+
+func (r *bundleRepo) GetServiceByID(_ context.Context, id uint) (*domain.Service, error) {
+	switch id {
+	case 100, 101:
+		return &domain.Service{ID: id, CompanyID: 1, BranchID: 1}, nil
+	case 200:
+		return &domain.Service{ID: id, CompanyID: 1, BranchID: 2}, nil
+	}
+	return nil, nil
+}
+
+func (r *bundleRepo) GetServiceOptionByID(_ context.Context, id uint) (*domain.ServiceOption, error) {
+	switch id {
+	case 5:
+		return &domain.ServiceOption{ID: 5, ServiceID: 101, Kind: domain.OptionVariant}, nil
+	case 6:
+		return &domain.ServiceOption{ID: 6, ServiceID: 101, Kind: domain.OptionAddOn}, nil
+	}
+	return nil, nil
+}
+
+func (r *bundleRepo) CreateBundle(_ context.Context, bundle *domain.Bundle) error {
+	r.created = bundle
+	return nil
+}
+
+func TestAddBundle(t *testing.T) {
+	variant, addOn := uint(5), uint(6)
+	tests := []struct {
+		name       string
+		items      []domain.BundleItem
+		wantFields []string
+	}{
+		{
+			name:  "services of the branch",
+			items: []domain.BundleItem{{ServiceID: 100, GapMinutes: 10}, {ServiceID: 101, VariantID: &variant}},
+		},
+		{
+			name:       "service of another branch",
+			items:      []domain.BundleItem{{ServiceID: 100}, {ServiceID: 200}},
+			wantFields: []string{"items[1].service_id"},
+		},
+		{
+			name:       "unknown service and add-on as variant",
+			items:      []domain.BundleItem{{ServiceID: 9}, {ServiceID: 101, VariantID: &addOn}},
+			wantFields: []string{"items[0].service_id", "items[1].variant_id"},
+		},
+		{
+			name:       "variant of another service",
+			items:      []domain.BundleItem{{ServiceID: 100, VariantID: &variant}, {ServiceID: 101}},
+			wantFields: []string{"items[0].variant_id"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &bundleRepo{}
+			u := NewCompanyUsecase(repo, time.Second, nil)
+			bundle, err := u.AddBundle(context.Background(), 1, "Cut and beard", "", 45, tc.items)
+			if len(tc.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				if repo.created != bundle || bundle.CompanyID != 1 {
+					t.Fatalf("created %+v, want bundle of company 1", repo.created)
+				}
+				for i, item := range bundle.Items {
+					if item.Position != i || item.CompanyID != 1 {
+						t.Errorf("items[%d] = %+v, want position %d of company 1", i, item, i)
+					}
+				}
+				return
+			}
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || appErr.Code != erru.CodeValidation {
+				t.Fatalf("err = %v, want %s", err, erru.CodeValidation)
+			}
+			if repo.created != nil {
+				t.Errorf("bundle was created")
+			}
+			if len(appErr.Fields) != len(tc.wantFields) {
+				t.Fatalf("fields = %+v, want %v", appErr.Fields, tc.wantFields)
+			}
+			for i, f := range appErr.Fields {
+				if f.Field != tc.wantFields[i] {
+					t.Errorf("fields[%d] = %s, want %s", i, f.Field, tc.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAddBundleUnknownBranch(t *testing.T) {
+	u := NewCompanyUsecase(&bundleRepo{}, time.Second, nil)
+	_, err := u.AddBundle(context.Background(), 9, "Cut and beard", "", 45, []domain.BundleItem{{ServiceID: 100}, {ServiceID: 101}})
+	if !errors.Is(err, erru.ErrNotFound) {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
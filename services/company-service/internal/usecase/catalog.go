@@ -0,0 +1,449 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+func (u *companyUsecase) ExportCatalog(ctx context.Context, branchID uint) (*domain.Catalog, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if _, err := branchOf(ctx, u.repo, branchID); err != nil {
+		return nil, err
+	}
+	stored, err := u.repo.GetBranchCatalog(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &domain.Catalog{
+		Categories: make([]domain.CatalogCategory, 0, len(stored.Categories)),
+		Services:   make([]domain.CatalogService, 0, len(stored.Services)),
+		Employees:  make([]domain.CatalogEmployee, 0, len(stored.Employees)),
+		Prices:     make([]domain.CatalogPrice, 0, len(stored.Prices)),
+	}
+	categoryKeys := make(map[uint]string, len(stored.Categories))
+	for _, c := range stored.Categories {
+		categoryKeys[c.ID] = catalogKey(c.ExternalKey, c.ID)
+		catalog.Categories = append(catalog.Categories, domain.CatalogCategory{Key: categoryKeys[c.ID], Name: c.Name})
+	}
+	serviceKeys := make(map[uint]string, len(stored.Services))
+	for _, s := range stored.Services {
+		serviceKeys[s.ID] = catalogKey(s.ExternalKey, s.ID)
+		row := domain.CatalogService{
+			Key:             serviceKeys[s.ID],
+			Name:            s.Name,
+			Description:     s.Description,
+			Price:           s.Price,
+			DurationMinutes: s.DurationMinutes,
+		}
+		if s.CategoryID != nil {
+			row.CategoryKey = categoryKeys[*s.CategoryID]
+		}
+		catalog.Services = append(catalog.Services, row)
+	}
+	employeeKeys := make(map[uint]string, len(stored.Employees))
+	for _, e := range stored.Employees {
+		employeeKeys[e.ID] = catalogKey(e.ExternalKey, e.ID)
+		catalog.Employees = append(catalog.Employees, domain.CatalogEmployee{Key: employeeKeys[e.ID], Name: e.Name, Position: e.Position})
+	}
+	for _, p := range stored.Prices {
+		// Prices of employees who left the branch stay behind.
+		if _, ok := employeeKeys[p.EmployeeID]; !ok {
+			continue
+		}
+		catalog.Prices = append(catalog.Prices, domain.CatalogPrice{
+			EmployeeKey:     employeeKeys[p.EmployeeID],
+			ServiceKey:      serviceKeys[p.ServiceID],
+			Price:           p.Price,
+			DurationMinutes: p.DurationMinutes,
+		})
+	}
+	return catalog, nil
+}
+
+func (u *companyUsecase) ImportCatalog(ctx context.Context, branchID uint, catalog *domain.Catalog, dryRun bool) (*domain.ImportReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var report *domain.ImportReport
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		branch, err := branchOf(ctx, repo, branchID)
+		if err != nil {
+			return err
+		}
+		stored, err := repo.GetBranchCatalog(ctx, branchID)
+		if err != nil {
+			return err
+		}
+		imp := newCatalogImport(branch, stored)
+
+		// Employees of the company's other branches join this one, found by
+		// their external key or, when exported without one, by their ID.
+		keys := make([]string, 0, len(catalog.Employees))
+		var ids []uint
+		for _, e := range catalog.Employees {
+			if _, ok := imp.employees[e.Key]; ok {
+				continue
+			}
+			keys = append(keys, e.Key)
+			if id, ok := keyID(e.Key); ok {
+				ids = append(ids, id)
+			}
+		}
+		others, err := repo.GetEmployeesByExternalKey(ctx, branch.CompanyID, keys)
+		if err != nil {
+			return err
+		}
+		for i := range others {
+			imp.employees[*others[i].ExternalKey] = &others[i]
+		}
+		others, err = repo.GetEmployeesByIDs(ctx, branch.CompanyID, ids)
+		if err != nil {
+			return err
+		}
+		for i := range others {
+			if key := catalogKey(nil, others[i].ID); imp.employees[key] == nil {
+				imp.employees[key] = &others[i]
+			}
+		}
+
+		if fields := imp.check(catalog); len(fields) > 0 {
+			return erru.Validation(fields...)
+		}
+		var write domain.CompanyRepository
+		if !dryRun {
+			write = repo
+		}
+		report, err = imp.apply(ctx, write, catalog)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = dryRun
+	return report, nil
+}
+
+// idKeyPrefix starts the keys of rows stored without an external key. Rows
+// of other branches or companies keep such a key when they are imported.
+const idKeyPrefix = "id:"
+
+func catalogKey(externalKey *string, id uint) string {
+	if externalKey != nil && *externalKey != "" {
+		return *externalKey
+	}
+	return fmt.Sprintf("%s%d", idKeyPrefix, id)
+}
+
+// keyID returns the ID named by an id:<id> key.
+func keyID(key string) (uint, bool) {
+	raw, ok := strings.CutPrefix(key, idKeyPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// catalogImport matches the rows of an imported catalog with what the
+// branch holds, by key.
+type catalogImport struct {
+	branch     *domain.Branch
+	categories map[string]*domain.Category
+	services   map[string]*domain.Service
+	employees  map[string]*domain.Employee
+	prices     map[[2]uint]domain.EmployeeService
+}
+
+func newCatalogImport(branch *domain.Branch, stored *domain.BranchCatalog) *catalogImport {
+	imp := &catalogImport{
+		branch:     branch,
+		categories: make(map[string]*domain.Category),
+		services:   make(map[string]*domain.Service),
+		employees:  make(map[string]*domain.Employee),
+		prices:     make(map[[2]uint]domain.EmployeeService, len(stored.Prices)),
+	}
+	// A stored row is found by its external key and by its ID.
+	for i := range stored.Categories {
+		c := &stored.Categories[i]
+		imp.categories[catalogKey(c.ExternalKey, c.ID)] = c
+		imp.categories[catalogKey(nil, c.ID)] = c
+	}
+	for i := range stored.Services {
+		s := &stored.Services[i]
+		imp.services[catalogKey(s.ExternalKey, s.ID)] = s
+		imp.services[catalogKey(nil, s.ID)] = s
+	}
+	for i := range stored.Employees {
+		e := &stored.Employees[i]
+		imp.employees[catalogKey(e.ExternalKey, e.ID)] = e
+		imp.employees[catalogKey(nil, e.ID)] = e
+	}
+	for _, p := range stored.Prices {
+		imp.prices[[2]uint{p.EmployeeID, p.ServiceID}] = p
+	}
+	return imp
+}
+
+// check validates the keys of the rows and what they refer to.
+func (imp *catalogImport) check(catalog *domain.Catalog) []erru.FieldError {
+	var fields []erru.FieldError
+	fail := func(section string, i, line int, field, rule, message string) {
+		fields = append(fields, erru.FieldError{Field: rowField(section, i, line, field), Rule: rule, Message: message})
+	}
+	categories := make(map[string]bool, len(catalog.Categories))
+	for i, c := range catalog.Categories {
+		if categories[c.Key] {
+			fail("categories", i, c.Line, "key", "unique", "is listed twice")
+		}
+		categories[c.Key] = true
+	}
+	services := make(map[string]bool, len(catalog.Services))
+	for i, s := range catalog.Services {
+		if services[s.Key] {
+			fail("services", i, s.Line, "key", "unique", "is listed twice")
+		}
+		services[s.Key] = true
+		if s.CategoryKey != "" && !categories[s.CategoryKey] && imp.categories[s.CategoryKey] == nil {
+			fail("services", i, s.Line, "category_key", "oneof", "is not a category of the catalog or the branch")
+		}
+	}
+	employees := make(map[string]bool, len(catalog.Employees))
+	for i, e := range catalog.Employees {
+		if employees[e.Key] {
+			fail("employees", i, e.Line, "key", "unique", "is listed twice")
+		}
+		employees[e.Key] = true
+	}
+	pairs := make(map[[2]string]bool, len(catalog.Prices))
+	for i, p := range catalog.Prices {
+		// Employees of other branches are known only when listed.
+		staff := imp.employees[p.EmployeeKey]
+		if !employees[p.EmployeeKey] && (staff == nil || !staff.WorksAt(imp.branch.ID)) {
+			fail("prices", i, p.Line, "employee_key", "oneof", "is not an employee of the catalog or the branch")
+		}
+		if !services[p.ServiceKey] && imp.services[p.ServiceKey] == nil {
+			fail("prices", i, p.Line, "service_key", "oneof", "is not a service of the catalog or the branch")
+		}
+		pair := [2]string{p.EmployeeKey, p.ServiceKey}
+		if pairs[pair] {
+			fail("prices", i, p.Line, "service_key", "unique", "is priced twice for the employee")
+		}
+		pairs[pair] = true
+	}
+	return fields
+}
+
+// rowField names a field of the i-th row of a section, or of the CSV line
+// the row was read from.
+func rowField(section string, i, line int, field string) string {
+	if line > 0 {
+		return fmt.Sprintf("lines[%d].%s", line, field)
+	}
+	return fmt.Sprintf("%s[%d].%s", section, i, field)
+}
+
+// apply upserts the checked catalog and counts the changes. Without a
+// repository nothing is written: new rows stay without IDs.
+func (imp *catalogImport) apply(ctx context.Context, repo domain.CompanyRepository, catalog *domain.Catalog) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{}
+	companyID, branchID := imp.branch.CompanyID, imp.branch.ID
+	// Blank columns keep the current values.
+	changed := func(value, current string) bool { return value != "" && value != current }
+
+	for _, row := range catalog.Categories {
+		category := imp.categories[row.Key]
+		switch {
+		case category == nil:
+			report.Categories.Created++
+			key := row.Key
+			category = &domain.Category{CompanyID: companyID, BranchID: branchID, Name: row.Name, ExternalKey: &key}
+			imp.categories[row.Key] = category
+			if repo == nil {
+				continue
+			}
+			if err := repo.CreateCategory(ctx, category); err != nil {
+				return nil, err
+			}
+			if err := record(ctx, repo, companyID, categoryCreated(category)); err != nil {
+				return nil, err
+			}
+		case changed(row.Name, category.Name):
+			report.Categories.Updated++
+			category.Name = row.Name
+			if repo == nil {
+				continue
+			}
+			if err := repo.UpdateCategory(ctx, &domain.Category{ID: category.ID, Name: row.Name}); err != nil {
+				return nil, err
+			}
+			if err := record(ctx, repo, companyID, events.CategoryUpdated(categoryCreated(category))); err != nil {
+				return nil, err
+			}
+		default:
+			report.Categories.Unchanged++
+		}
+	}
+
+	for _, row := range catalog.Services {
+		var categoryID *uint
+		if row.CategoryKey != "" {
+			id := imp.categories[row.CategoryKey].ID
+			categoryID = &id
+		}
+		service := imp.services[row.Key]
+		switch {
+		case service == nil:
+			report.Services.Created++
+			key := row.Key
+			service = &domain.Service{
+				CompanyID:       companyID,
+				BranchID:        branchID,
+				CategoryID:      categoryID,
+				Name:            row.Name,
+				Description:     row.Description,
+				Price:           row.Price,
+				DurationMinutes: row.DurationMinutes,
+				ExternalKey:     &key,
+			}
+			imp.services[row.Key] = service
+			if repo == nil {
+				continue
+			}
+			if err := repo.CreateService(ctx, service); err != nil {
+				return nil, err
+			}
+			if err := record(ctx, repo, companyID, events.ServiceCreated{ServiceSnapshot: serviceSnapshot(service)}); err != nil {
+				return nil, err
+			}
+		case changed(row.Name, service.Name) || changed(row.Description, service.Description) ||
+			(row.Price != 0 && row.Price != service.Price) ||
+			(row.DurationMinutes != 0 && row.DurationMinutes != service.DurationMinutes) ||
+			(categoryID != nil && (service.CategoryID == nil || *categoryID != *service.CategoryID)):
+			report.Services.Updated++
+			update := &domain.Service{
+				ID:              service.ID,
+				CategoryID:      categoryID,
+				Name:            row.Name,
+				Description:     row.Description,
+				Price:           row.Price,
+				DurationMinutes: row.DurationMinutes,
+			}
+			if repo == nil {
+				continue
+			}
+			if err := repo.UpdateService(ctx, update); err != nil {
+				return nil, err
+			}
+			updated, err := repo.GetServiceByID(ctx, service.ID)
+			if err != nil {
+				return nil, err
+			}
+			*service = *updated
+			if err := record(ctx, repo, companyID, events.ServiceUpdated{ServiceSnapshot: serviceSnapshot(service)}); err != nil {
+				return nil, err
+			}
+		default:
+			report.Services.Unchanged++
+		}
+	}
+
+	for _, row := range catalog.Employees {
+		employee := imp.employees[row.Key]
+		if employee == nil {
+			report.Employees.Created++
+			key := row.Key
+			employee = &domain.Employee{
+				CompanyID:   companyID,
+				BranchID:    branchID,
+				Name:        row.Name,
+				Position:    row.Position,
+				Role:        domain.RoleMaster,
+				ExternalKey: &key,
+				Branches:    []domain.EmployeeBranch{{BranchID: branchID, CompanyID: companyID}},
+			}
+			imp.employees[row.Key] = employee
+			if repo == nil {
+				continue
+			}
+			if err := repo.CreateEmployee(ctx, employee); err != nil {
+				return nil, err
+			}
+			if err := record(ctx, repo, companyID, employeeCreated(employee)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		edited := changed(row.Name, employee.Name) || changed(row.Position, employee.Position)
+		joining := !employee.WorksAt(branchID)
+		if !edited && !joining {
+			report.Employees.Unchanged++
+			continue
+		}
+		report.Employees.Updated++
+		if edited {
+			employee.Name = row.Name
+			if row.Position != "" {
+				employee.Position = row.Position
+			}
+		}
+		if joining {
+			employee.Branches = append(employee.Branches, domain.EmployeeBranch{BranchID: branchID, CompanyID: companyID})
+		}
+		if repo == nil {
+			continue
+		}
+		if edited {
+			if err := repo.UpdateEmployee(ctx, &domain.Employee{ID: employee.ID, Name: row.Name, Position: row.Position}); err != nil {
+				return nil, err
+			}
+		}
+		if joining {
+			if err := repo.SetEmployeeBranches(ctx, employee); err != nil {
+				return nil, err
+			}
+		}
+		if err := record(ctx, repo, companyID, employeeUpdated(employee)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range catalog.Prices {
+		employeeID, serviceID := imp.employees[row.EmployeeKey].ID, imp.services[row.ServiceKey].ID
+		current, ok := imp.prices[[2]uint{employeeID, serviceID}]
+		switch {
+		case !ok:
+			report.Prices.Created++
+		case current.Price != row.Price || current.DurationMinutes != row.DurationMinutes:
+			report.Prices.Updated++
+		default:
+			report.Prices.Unchanged++
+			continue
+		}
+		if repo == nil {
+			continue
+		}
+		relation := &domain.EmployeeService{
+			EmployeeID:      employeeID,
+			ServiceID:       serviceID,
+			Price:           row.Price,
+			DurationMinutes: row.DurationMinutes,
+		}
+		if err := repo.AssignServiceToEmployee(ctx, relation); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
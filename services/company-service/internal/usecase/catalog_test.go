@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// catalogRepo holds branch 1 of company 1 with category "hair" (1),
+// service 10 stored without an external key and Ann ("ann", 100) pricing
+// it. Bob ("bob", 200) works at branch 2 only.
+type catalogRepo struct {
+	domain.CompanyRepository
+	nextID     uint
+	categories map[uint]domain.Category
+	services   map[uint]domain.Service
+	employees  map[uint]domain.Employee
+	prices     map[[2]uint]domain.EmployeeService
+	events     []events.Envelope
+}
+
+func newCatalogRepo() *catalogRepo {
+	key := func(k string) *string { return &k }
+	category := uint(1)
+	return &catalogRepo{
+		nextID:     1000,
+		categories: map[uint]domain.Category{1: {ID: 1, CompanyID: 1, BranchID: 1, Name: "Hair", ExternalKey: key("hair")}},
+		services: map[uint]domain.Service{
+			10: {ID: 10, CompanyID: 1, BranchID: 1, CategoryID: &category, Name: "Cut", Price: 20, DurationMinutes: 30},
+		},
+		employees: map[uint]domain.Employee{
+			100: {
+				ID: 100, CompanyID: 1, BranchID: 1, Name: "Ann", ExternalKey: key("ann"),
+				Branches: []domain.EmployeeBranch{{EmployeeID: 100, BranchID: 1, CompanyID: 1}},
+			},
+			200: {
+				ID: 200, CompanyID: 1, BranchID: 2, Name: "Bob", ExternalKey: key("bob"),
+				Branches: []domain.EmployeeBranch{{EmployeeID: 200, BranchID: 2, CompanyID: 1}},
+			},
+		},
+		prices: map[[2]uint]domain.EmployeeService{
+			{100, 10}: {EmployeeID: 100, ServiceID: 10, Price: 25, DurationMinutes: 30},
+		},
+	}
+}
+
+func (r *catalogRepo) id() uint {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *catalogRepo) Transaction(ctx context.Context, fn func(repo domain.CompanyRepository) error) error {
+	return fn(r)
+}
+
+func (r *catalogRepo) AddEvents(_ context.Context, envs ...events.Envelope) error {
+	r.events = append(r.events, envs...)
+	return nil
+}
+
+func (r *catalogRepo) GetBranchByID(_ context.Context, id uint) (*domain.Branch, error) {
+	if id != 1 && id != 2 {
+		return nil, nil
+	}
+	return &domain.Branch{ID: id, CompanyID: 1}, nil
+}
+
+func (r *catalogRepo) GetBranchCatalog(_ context.Context, branchID uint) (*domain.BranchCatalog, error) {
+	catalog := &domain.BranchCatalog{}
+	for _, c := range r.categories {
+		if c.BranchID == branchID {
+			catalog.Categories = append(catalog.Categories, c)
+		}
+	}
+	for _, s := range r.services {
+		if s.BranchID == branchID {
+			catalog.Services = append(catalog.Services, s)
+		}
+	}
+	for _, e := range r.employees {
+		if e.WorksAt(branchID) {
+			e.Branches = append([]domain.EmployeeBranch(nil), e.Branches...)
+			catalog.Employees = append(catalog.Employees, e)
+		}
+	}
+	for _, p := range r.prices {
+		if r.services[p.ServiceID].BranchID == branchID {
+			catalog.Prices = append(catalog.Prices, p)
+		}
+	}
+	return catalog, nil
+}
+
+func (r *catalogRepo) GetEmployeesByExternalKey(_ context.Context, companyID uint, keys []string) ([]domain.Employee, error) {
+	var found []domain.Employee
+	for _, e := range r.employees {
+		for _, k := range keys {
+			if e.CompanyID == companyID && e.ExternalKey != nil && *e.ExternalKey == k {
+				e.Branches = append([]domain.EmployeeBranch(nil), e.Branches...)
+				found = append(found, e)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *catalogRepo) GetEmployeesByIDs(_ context.Context, companyID uint, ids []uint) ([]domain.Employee, error) {
+	var found []domain.Employee
+	for _, id := range ids {
+		if e, ok := r.employees[id]; ok && e.CompanyID == companyID {
+			e.Branches = append([]domain.EmployeeBranch(nil), e.Branches...)
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
+
+func (r *catalogRepo) CreateCategory(_ context.Context, category *domain.Category) error {
+	category.ID = r.id()
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *catalogRepo) UpdateCategory(_ context.Context, category *domain.Category) error {
+	stored := r.categories[category.ID]
+	stored.Name = category.Name
+	r.categories[category.ID] = stored
+	return nil
+}
+
+func (r *catalogRepo) CreateService(_ context.Context, service *domain.Service) error {
+	service.ID = r.id()
+	r.services[service.ID] = *service
+	return nil
+}
+
+// UpdateService skips zero values, as the repository's Updates does.
+func (r *catalogRepo) UpdateService(_ context.Context, service *domain.Service) error {
+	stored := r.services[service.ID]
+	if service.CategoryID != nil {
+		stored.CategoryID = service.CategoryID
+	}
+	if service.Name != "" {
+		stored.Name = service.Name
+	}
+	if service.Description != "" {
+		stored.Description = service.Description
+	}
+	if service.Price != 0 {
+		stored.Price = service.Price
+	}
+	if service.DurationMinutes != 0 {
+		stored.DurationMinutes = service.DurationMinutes
+	}
+	r.services[service.ID] = stored
+	return nil
+}
+
+func (r *catalogRepo) GetServiceByID(_ context.Context, id uint) (*domain.Service, error) {
+	service, ok := r.services[id]
+	if !ok {
+		return nil, nil
+	}
+	return &service, nil
+}
+
+func (r *catalogRepo) CreateEmployee(_ context.Context, employee *domain.Employee) error {
+	employee.ID = r.id()
+	r.employees[employee.ID] = *employee
+	return nil
+}
+
+func (r *catalogRepo) UpdateEmployee(_ context.Context, employee *domain.Employee) error {
+	stored := r.employees[employee.ID]
+	stored.Name = employee.Name
+	if employee.Position != "" {
+		stored.Position = employee.Position
+	}
+	r.employees[employee.ID] = stored
+	return nil
+}
+
+func (r *catalogRepo) SetEmployeeBranches(_ context.Context, employee *domain.Employee) error {
+	stored := r.employees[employee.ID]
+	stored.Branches = employee.Branches
+	r.employees[employee.ID] = stored
+	return nil
+}
+
+func (r *catalogRepo) AssignServiceToEmployee(_ context.Context, relation *domain.EmployeeService) error {
+	r.prices[[2]uint{relation.EmployeeID, relation.ServiceID}] = *relation
+	return nil
+}
+
+func TestExportCatalog(t *testing.T) {
+	u := NewCompanyUsecase(newCatalogRepo(), time.Second, nil)
+
+	catalog, err := u.ExportCatalog(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(catalog.Categories) != 1 || catalog.Categories[0].Key != "hair" {
+		t.Errorf("categories = %+v", catalog.Categories)
+	}
+	if len(catalog.Services) != 1 || catalog.Services[0].Key != "id:10" || catalog.Services[0].CategoryKey != "hair" {
+		t.Errorf("services = %+v, want id:10 in hair", catalog.Services)
+	}
+	if len(catalog.Employees) != 1 || catalog.Employees[0].Key != "ann" {
+		t.Errorf("employees = %+v, want ann only", catalog.Employees)
+	}
+	want := domain.CatalogPrice{EmployeeKey: "ann", ServiceKey: "id:10", Price: 25, DurationMinutes: 30}
+	if len(catalog.Prices) != 1 || catalog.Prices[0] != want {
+		t.Errorf("prices = %+v, want %+v", catalog.Prices, want)
+	}
+
+	if _, err := u.ExportCatalog(context.Background(), 9); !errors.Is(err, erru.ErrNotFound) {
+		t.Errorf("unknown branch: err = %v, want not found", err)
+	}
+}
+
+func TestImportCatalog(t *testing.T) {
+	catalog := &domain.Catalog{
+		Categories: []domain.CatalogCategory{
+			{Key: "hair", Name: "Hair & beard"},
+			{Key: "nails", Name: "Nails"},
+		},
+		Services: []domain.CatalogService{
+			{Key: "id:10", Price: 22},
+			{Key: "mani", CategoryKey: "nails", Name: "Manicure", Price: 30, DurationMinutes: 45},
+		},
+		Employees: []domain.CatalogEmployee{
+			{Key: "ann", Name: "Ann"},
+			{Key: "bob", Name: "Bob"},
+			{Key: "cat", Name: "Cat", Position: "Nail artist"},
+		},
+		Prices: []domain.CatalogPrice{
+			{EmployeeKey: "ann", ServiceKey: "id:10", Price: 25, DurationMinutes: 30},
+			{EmployeeKey: "bob", ServiceKey: "mani", Price: 35, DurationMinutes: 50},
+			{EmployeeKey: "cat", ServiceKey: "mani", Price: 30, DurationMinutes: 45},
+		},
+	}
+	want := domain.ImportReport{
+		Categories: domain.ImportCount{Created: 1, Updated: 1},
+		Services:   domain.ImportCount{Created: 1, Updated: 1},
+		Employees:  domain.ImportCount{Created: 1, Updated: 1, Unchanged: 1},
+		Prices:     domain.ImportCount{Created: 2, Unchanged: 1},
+	}
+
+	repo := newCatalogRepo()
+	u := NewCompanyUsecase(repo, time.Second, nil)
+
+	report, err := u.ImportCatalog(context.Background(), 1, catalog, true)
+	if err != nil {
+		t.Fatalf("dry run: err = %v", err)
+	}
+	want.DryRun = true
+	if *report != want {
+		t.Errorf("dry run report = %+v, want %+v", *report, want)
+	}
+	bob := repo.employees[200]
+	if len(repo.events) != 0 || len(repo.categories) != 1 || repo.categories[1].Name != "Hair" ||
+		repo.services[10].Price != 20 || bob.WorksAt(1) {
+		t.Fatalf("the dry run wrote: events %d, categories %+v", len(repo.events), repo.categories)
+	}
+
+	report, err = u.ImportCatalog(context.Background(), 1, catalog, false)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want.DryRun = false
+	if *report != want {
+		t.Errorf("report = %+v, want %+v", *report, want)
+	}
+	if repo.categories[1].Name != "Hair & beard" {
+		t.Errorf("category 1 = %q, want renamed", repo.categories[1].Name)
+	}
+	if s := repo.services[10]; s.Price != 22 || s.Name != "Cut" || s.DurationMinutes != 30 {
+		t.Errorf("service 10 = %+v, want only the price changed", s)
+	}
+	if bob = repo.employees[200]; !bob.WorksAt(1) || bob.BranchID != 2 {
+		t.Errorf("bob = %+v, want branch 1 added to primary branch 2", bob)
+	}
+	if len(repo.events) != 6 {
+		t.Errorf("events = %d, want 6", len(repo.events))
+	}
+
+	// The new rows are known by their keys from now on.
+	report, err = u.ImportCatalog(context.Background(), 1, catalog, false)
+	if err != nil {
+		t.Fatalf("second import: err = %v", err)
+	}
+	if report.Categories.Created+report.Services.Created+report.Employees.Created+report.Prices.Created != 0 ||
+		report.Categories.Updated+report.Services.Updated+report.Employees.Updated+report.Prices.Updated != 0 {
+		t.Errorf("second import report = %+v, want everything unchanged", *report)
+	}
+}
+
+func TestImportCatalogForeignKeys(t *testing.T) {
+	// Exported from another branch: its rows were stored without keys and
+	// Bob (200) is found by ID.
+	catalog := &domain.Catalog{
+		Categories: []domain.CatalogCategory{{Key: "id:7", Name: "Brows"}},
+		Services:   []domain.CatalogService{{Key: "id:8", CategoryKey: "id:7", Name: "Tint", Price: 15, DurationMinutes: 20}},
+		Employees:  []domain.CatalogEmployee{{Key: "id:200", Name: "Bob"}, {Key: "id:900", Name: "Dan"}},
+		Prices:     []domain.CatalogPrice{{EmployeeKey: "id:900", ServiceKey: "id:8", Price: 15, DurationMinutes: 20}},
+	}
+	want := domain.ImportReport{
+		Categories: domain.ImportCount{Created: 1},
+		Services:   domain.ImportCount{Created: 1},
+		Employees:  domain.ImportCount{Created: 1, Updated: 1},
+		Prices:     domain.ImportCount{Created: 1},
+	}
+	repo := newCatalogRepo()
+	u := NewCompanyUsecase(repo, time.Second, nil)
+
+	report, err := u.ImportCatalog(context.Background(), 1, catalog, false)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if *report != want {
+		t.Errorf("report = %+v, want %+v", *report, want)
+	}
+	if bob := repo.employees[200]; !bob.WorksAt(1) || *bob.ExternalKey != "bob" {
+		t.Errorf("bob = %+v, want branch 1 added", bob)
+	}
+	created := map[string]bool{}
+	for _, c := range repo.categories {
+		created[*c.ExternalKey] = true
+	}
+	for _, s := range repo.services {
+		if s.ExternalKey != nil {
+			created[*s.ExternalKey] = true
+		}
+	}
+	for _, e := range repo.employees {
+		created[*e.ExternalKey] = true
+	}
+	if !created["id:7"] || !created["id:8"] || !created["id:900"] {
+		t.Errorf("created keys = %v, want id:7, id:8 and id:900", created)
+	}
+
+	report, err = u.ImportCatalog(context.Background(), 1, catalog, false)
+	if err != nil {
+		t.Fatalf("second import: err = %v", err)
+	}
+	if report.Categories.Created+report.Services.Created+report.Employees.Created+report.Prices.Created != 0 ||
+		report.Categories.Updated+report.Services.Updated+report.Employees.Updated+report.Prices.Updated != 0 {
+		t.Errorf("second import report = %+v, want everything unchanged", *report)
+	}
+}
+
+func TestImportCatalogErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		catalog    domain.Catalog
+		wantFields []string
+	}{
+		{
+			name: "duplicate keys",
+			catalog: domain.Catalog{
+				Categories: []domain.CatalogCategory{{Key: "a", Name: "A"}, {Key: "a", Name: "B"}},
+				Services:   []domain.CatalogService{{Key: "id:11", Name: "S"}, {Key: "id:11", Name: "T"}},
+			},
+			wantFields: []string{"categories[1].key", "services[1].key"},
+		},
+		{
+			name: "unknown references",
+			catalog: domain.Catalog{
+				Services: []domain.CatalogService{{Key: "s", Name: "S", CategoryKey: "nails"}},
+				Prices: []domain.CatalogPrice{
+					{EmployeeKey: "bob", ServiceKey: "s", Price: 10, DurationMinutes: 10},
+					{EmployeeKey: "ann", ServiceKey: "x", Price: 10, DurationMinutes: 10},
+				},
+			},
+			wantFields: []string{"services[0].category_key", "prices[0].employee_key", "prices[1].service_key"},
+		},
+		{
+			name: "CSV lines",
+			catalog: domain.Catalog{
+				Prices: []domain.CatalogPrice{
+					{EmployeeKey: "ann", ServiceKey: "id:10", Price: 10, DurationMinutes: 10, Line: 4},
+					{EmployeeKey: "ann", ServiceKey: "id:10", Price: 12, DurationMinutes: 10, Line: 7},
+				},
+			},
+			wantFields: []string{"lines[7].service_key"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newCatalogRepo()
+			u := NewCompanyUsecase(repo, time.Second, nil)
+
+			_, err := u.ImportCatalog(context.Background(), 1, &tc.catalog, false)
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || appErr.Code != erru.CodeValidation {
+				t.Fatalf("err = %v, want validation_failed", err)
+			}
+			if len(appErr.Fields) != len(tc.wantFields) {
+				t.Fatalf("fields = %+v, want %v", appErr.Fields, tc.wantFields)
+			}
+			for i, f := range appErr.Fields {
+				if f.Field != tc.wantFields[i] {
+					t.Errorf("fields[%d] = %s, want %s", i, f.Field, tc.wantFields[i])
+				}
+			}
+			if len(repo.events) != 0 {
+				t.Errorf("events = %d, want none", len(repo.events))
+			}
+		})
+	}
+}
@@ -1,26 +1,31 @@
 package usecase
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"net/http"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"time"
 
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/pagination"
 	"github.com/vipos89/timehub/services/company-service/internal/domain"
 )
 
 type companyUsecase struct {
 	repo           domain.CompanyRepository
 	contextTimeout time.Duration
-	authServiceURL string
+	auth           clients.AuthClient
 }
 
-func NewCompanyUsecase(repo domain.CompanyRepository, timeout time.Duration, authServiceURL string) domain.CompanyUsecase {
+func NewCompanyUsecase(repo domain.CompanyRepository, timeout time.Duration, auth clients.AuthClient) domain.CompanyUsecase {
 	return &companyUsecase{
 		repo:           repo,
 		contextTimeout: timeout,
-		authServiceURL: authServiceURL,
+		auth:           auth,
 	}
 }
 
@@ -35,22 +40,32 @@ func (u *companyUsecase) CreateCompany(ctx context.Context, name string, ownerID
 
 	// Create default main branch
 	branch := &domain.Branch{
-		Name:    "Main Branch",
-		Address: "Headquarters",
-		IsMain:  true,
+		Name:     "Main Branch",
+		Address:  "Headquarters",
+		IsMain:   true,
+		Location: domain.Location{Timezone: domain.DefaultTimezone},
 	}
 
-	if err := u.repo.CreateCompanyWithBranch(ctx, company, branch); err != nil {
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.CreateCompanyWithBranch(ctx, company, branch); err != nil {
+			return err
+		}
+		return record(ctx, repo, company.ID,
+			events.CompanyCreated{CompanyID: company.ID, OwnerID: company.OwnerID, Name: company.Name},
+			branchCreated(branch),
+		)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return company, nil
 }
 
-func (u *companyUsecase) GetMyCompanies(ctx context.Context, ownerID uint) ([]domain.Company, error) {
+func (u *companyUsecase) GetMyCompanies(ctx context.Context, ownerID uint, page pagination.Request) (pagination.Page[domain.Company], error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.GetCompaniesByOwnerID(ctx, ownerID)
+	return u.repo.GetCompaniesByOwnerID(ctx, ownerID, page)
 }
 
 func (u *companyUsecase) GetCompanyByID(ctx context.Context, id uint) (*domain.Company, error) {
@@ -59,53 +74,76 @@ func (u *companyUsecase) GetCompanyByID(ctx context.Context, id uint) (*domain.C
 	return u.repo.GetCompanyByID(ctx, id)
 }
 
-func (u *companyUsecase) AddBranch(ctx context.Context, companyID uint, name, address, phone string) (*domain.Branch, error) {
+func (u *companyUsecase) AddBranch(ctx context.Context, companyID uint, name, address, phone string, location domain.Location) (*domain.Branch, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
+	if location.Timezone == "" {
+		location.Timezone = domain.DefaultTimezone
+	}
 	branch := &domain.Branch{
 		CompanyID: companyID,
 		Name:      name,
 		Address:   address,
 		Phone:     phone,
 		IsMain:    false,
+		Location:  location,
 	}
 
-	err := u.repo.CreateBranch(ctx, branch)
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.CreateBranch(ctx, branch); err != nil {
+			return err
+		}
+		return record(ctx, repo, companyID, branchCreated(branch))
+	})
 	return branch, err
 }
 
-func (u *companyUsecase) GetCompanyBranches(ctx context.Context, companyID uint) ([]domain.Branch, error) {
+func (u *companyUsecase) GetBranch(ctx context.Context, id uint) (*domain.Branch, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return branchOf(ctx, u.repo, id)
+}
+
+func (u *companyUsecase) GetCompanyBranches(ctx context.Context, companyID uint, page pagination.Request) (pagination.Page[domain.Branch], error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.GetBranchesByCompanyID(ctx, companyID)
+	return u.repo.GetBranchesByCompanyID(ctx, companyID, page)
 }
 
-func (u *companyUsecase) AddCategory(ctx context.Context, companyID uint, branchID uint, name string) (*domain.Category, error) {
+func (u *companyUsecase) AddCategory(ctx context.Context, branchID uint, name string) (*domain.Category, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
 	category := &domain.Category{
-		CompanyID: companyID,
-		BranchID:  branchID,
-		Name:      name,
+		BranchID: branchID,
+		Name:     name,
 	}
-	err := u.repo.CreateCategory(ctx, category)
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		branch, err := branchOf(ctx, repo, branchID)
+		if err != nil {
+			return err
+		}
+		category.CompanyID = branch.CompanyID
+		if err := repo.CreateCategory(ctx, category); err != nil {
+			return err
+		}
+		return record(ctx, repo, category.CompanyID, categoryCreated(category))
+	})
 	return category, err
 }
 
-func (u *companyUsecase) GetBranchCategories(ctx context.Context, branchID uint) ([]domain.Category, error) {
+func (u *companyUsecase) GetBranchCategories(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Category], error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.GetCategoriesByBranchID(ctx, branchID)
+	return u.repo.GetCategoriesByBranchID(ctx, branchID, page)
 }
 
-func (u *companyUsecase) AddService(ctx context.Context, companyID uint, branchID uint, categoryID *uint, name, description string, price float64, duration int) (*domain.Service, error) {
+func (u *companyUsecase) AddService(ctx context.Context, branchID uint, categoryID *uint, name, description string, price float64, duration int) (*domain.Service, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
 	service := &domain.Service{
-		CompanyID:       companyID,
 		BranchID:        branchID,
 		CategoryID:      categoryID,
 		Name:            name,
@@ -113,68 +151,146 @@ func (u *companyUsecase) AddService(ctx context.Context, companyID uint, branchI
 		Price:           price,
 		DurationMinutes: duration,
 	}
-	err := u.repo.CreateService(ctx, service)
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		branch, err := branchOf(ctx, repo, branchID)
+		if err != nil {
+			return err
+		}
+		service.CompanyID = branch.CompanyID
+		if err := repo.CreateService(ctx, service); err != nil {
+			return err
+		}
+		return record(ctx, repo, service.CompanyID, events.ServiceCreated{ServiceSnapshot: serviceSnapshot(service)})
+	})
 	return service, err
 }
 
 func (u *companyUsecase) UpdateService(ctx context.Context, service *domain.Service) error {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.UpdateService(ctx, service)
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.UpdateService(ctx, service); err != nil {
+			return err
+		}
+		// Reload so the response and the event carry the full state.
+		updated, err := repo.GetServiceByID(ctx, service.ID)
+		if err != nil {
+			return err
+		}
+		if updated == nil {
+			return erru.ErrNotFound
+		}
+		*service = *updated
+		return record(ctx, repo, service.CompanyID, events.ServiceUpdated{ServiceSnapshot: serviceSnapshot(service)})
+	})
 }
 
-func (u *companyUsecase) GetBranchServices(ctx context.Context, branchID uint) ([]domain.Service, error) {
+func (u *companyUsecase) GetBranchServices(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Service], error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.GetServicesByBranchID(ctx, branchID)
+	return u.repo.GetServicesByBranchID(ctx, branchID, page)
 }
 
-func (u *companyUsecase) AddEmployee(ctx context.Context, branchID uint, name, position, email string) (*domain.Employee, error) {
+func (u *companyUsecase) AddEmployee(ctx context.Context, branchID uint, name, position, email string, role domain.Role) (*domain.Employee, error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
-	var userID *uint
+	if role == "" {
+		role = domain.RoleMaster
+	}
+
+	// The branch is checked first, so no account is registered for a
+	// branch the employee cannot join.
+	branch, err := branchOf(ctx, u.repo, branchID)
+	if err != nil {
+		return nil, err
+	}
+	employee := &domain.Employee{
+		CompanyID: branch.CompanyID,
+		BranchID:  branchID,
+		Name:      name,
+		Position:  position,
+		Role:      role,
+		Branches:  []domain.EmployeeBranch{{BranchID: branchID, CompanyID: branch.CompanyID}},
+	}
+
 	if email != "" {
-		// Call Auth Service to create user
-		// In a real app, this should be gRPC or more robust HTTP client
-		// Using simple http.Post for brevity as this is a demo/prototype phase
-		userData := map[string]string{
-			"email":    email,
-			"password": "temporary_password_123", // Should be random or sent via email
-			"role":     "master",
-		}
-		jsonBody, _ := json.Marshal(userData)
-		resp, err := http.Post(u.authServiceURL+"/auth/register", "application/json", bytes.NewBuffer(jsonBody))
-		if err == nil && resp.StatusCode == http.StatusCreated {
-			var result struct {
-				ID uint `json:"id"`
-			}
-			json.NewDecoder(resp.Body).Decode(&result)
-			userID = &result.ID
-			resp.Body.Close()
+		// The caller hands the one-time password to the employee.
+		password, err := randomPassword()
+		if err != nil {
+			return nil, err
 		}
+		user, err := u.auth.Register(ctx, clients.RegisterRequest{
+			Email:    email,
+			Password: password,
+			Role:     string(role),
+		})
+		if err != nil {
+			return nil, err
+		}
+		employee.UserID = &user.ID
+		employee.Password = password
 	}
 
-	employee := &domain.Employee{
-		BranchID: branchID,
-		UserID:   userID,
-		Name:     name,
-		Position: position,
+	err = u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.CreateEmployee(ctx, employee); err != nil {
+			return err
+		}
+		return record(ctx, repo, branch.CompanyID, employeeCreated(employee))
+	})
+	if err != nil {
+		if employee.UserID != nil {
+			u.unregister(ctx, email, employee.Password)
+		}
+		return nil, err
+	}
+	return employee, nil
+}
+
+// unregister removes the account of an employee that was not added, so the
+// email can be used again. It runs even when the request has timed out.
+func (u *companyUsecase) unregister(ctx context.Context, email, password string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), u.contextTimeout)
+	defer cancel()
+	if err := u.auth.Unregister(ctx, email, password); err != nil {
+		logger.FromContext(ctx).Error("Failed to remove the account of an employee that was not added", "email", email, "error", err)
 	}
-	err := u.repo.CreateEmployee(ctx, employee)
-	return employee, err
 }
 
-func (u *companyUsecase) GetCompanyEmployees(ctx context.Context, companyID uint) ([]domain.Employee, error) {
+func (u *companyUsecase) GetCompanyEmployees(ctx context.Context, companyID, branchID uint, page pagination.Request) (pagination.Page[domain.Employee], error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.GetEmployeesByCompanyID(ctx, companyID)
+	return u.repo.GetEmployeesByCompanyID(ctx, companyID, branchID, page)
+}
+
+func (u *companyUsecase) GetBranchEmployees(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.Employee], error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	branch, err := branchOf(ctx, u.repo, branchID)
+	if err != nil {
+		return pagination.Page[domain.Employee]{}, err
+	}
+	return u.repo.GetEmployeesByCompanyID(ctx, branch.CompanyID, branchID, page)
 }
 
 func (u *companyUsecase) AssignService(ctx context.Context, employeeID, serviceID uint, price float64, duration int) error {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
+	employee, err := employeeOf(ctx, u.repo, employeeID)
+	if err != nil {
+		return err
+	}
+	service, err := u.repo.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+	if service != nil && !employee.WorksAt(service.BranchID) {
+		return erru.Validation(erru.FieldError{Field: "service_id", Rule: "oneof", Message: "is not a service of the employee's branches"})
+	}
+
 	relation := &domain.EmployeeService{
 		EmployeeID:      employeeID,
 		ServiceID:       serviceID,
@@ -190,8 +306,343 @@ func (u *companyUsecase) RemoveService(ctx context.Context, employeeID, serviceI
 	return u.repo.RemoveServiceFromEmployee(ctx, employeeID, serviceID)
 }
 
-func (u *companyUsecase) GetEmployeeMenu(ctx context.Context, employeeID uint) ([]domain.EmployeeService, error) {
+func (u *companyUsecase) GetEmployeeMenu(ctx context.Context, employeeID, branchID uint, page pagination.Request) (pagination.Page[domain.EmployeeService], error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.GetServicesByEmployeeID(ctx, employeeID, branchID, page)
+}
+
+func (u *companyUsecase) UpdateCompany(ctx context.Context, company *domain.Company) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.UpdateCompany(ctx, company); err != nil {
+			return err
+		}
+		updated, err := repo.GetCompanyByID(ctx, company.ID)
+		if err != nil {
+			return err
+		}
+		if updated == nil {
+			return erru.ErrNotFound
+		}
+		*company = *updated
+		return record(ctx, repo, company.ID, events.CompanyUpdated{
+			CompanyID: company.ID,
+			Name:      company.Name,
+			TaxID:     company.TaxID,
+		})
+	})
+}
+
+func (u *companyUsecase) DeleteCompany(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.DeleteCompany(ctx, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, id, events.CompanyDeleted{CompanyID: id})
+	})
+}
+
+func (u *companyUsecase) RestoreCompany(ctx context.Context, id uint) (*domain.Company, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var company *domain.Company
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.RestoreCompany(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if company, err = repo.GetCompanyByID(ctx, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, id, events.CompanyRestored{CompanyID: id})
+	})
+	return company, err
+}
+
+func (u *companyUsecase) UpdateBranch(ctx context.Context, branch *domain.Branch) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.UpdateBranch(ctx, branch); err != nil {
+			return err
+		}
+		updated, err := branchOf(ctx, repo, branch.ID)
+		if err != nil {
+			return err
+		}
+		*branch = *updated
+		return record(ctx, repo, branch.CompanyID, branchUpdated(branch))
+	})
+}
+
+func (u *companyUsecase) SetBranchHours(ctx context.Context, branchID uint, hours []domain.OpeningHours, exceptions []domain.HoursException) (*domain.Branch, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := validateHours(hours, exceptions); err != nil {
+		return nil, err
+	}
+	var branch *domain.Branch
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.SetBranchHours(ctx, branchID, hours, exceptions); err != nil {
+			return err
+		}
+		var err error
+		if branch, err = branchOf(ctx, repo, branchID); err != nil {
+			return err
+		}
+		return record(ctx, repo, branch.CompanyID, branchUpdated(branch))
+	})
+	return branch, err
+}
+
+func (u *companyUsecase) DeleteBranch(ctx context.Context, id uint) error {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
-	return u.repo.GetServicesByEmployeeID(ctx, employeeID)
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		branch, err := branchOf(ctx, repo, id)
+		if err != nil {
+			return err
+		}
+		if branch.IsMain {
+			return erru.E(erru.CodeConflict, "The main branch cannot be deleted")
+		}
+		if err := repo.DeleteBranch(ctx, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, branch.CompanyID, events.BranchDeleted{BranchID: id, CompanyID: branch.CompanyID})
+	})
+}
+
+func (u *companyUsecase) RestoreBranch(ctx context.Context, id uint) (*domain.Branch, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var branch *domain.Branch
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.RestoreBranch(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if branch, err = branchOf(ctx, repo, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, branch.CompanyID, events.BranchRestored{BranchID: id, CompanyID: branch.CompanyID})
+	})
+	return branch, err
+}
+
+func (u *companyUsecase) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.UpdateCategory(ctx, category); err != nil {
+			return err
+		}
+		updated, err := categoryOf(ctx, repo, category.ID)
+		if err != nil {
+			return err
+		}
+		*category = *updated
+		return record(ctx, repo, category.CompanyID, events.CategoryUpdated(categoryCreated(category)))
+	})
+}
+
+func (u *companyUsecase) DeleteCategory(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		category, err := categoryOf(ctx, repo, id)
+		if err != nil {
+			return err
+		}
+		detached, err := repo.DetachServices(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteCategory(ctx, id); err != nil {
+			return err
+		}
+		evs := []events.Event{events.CategoryDeleted{CategoryID: id, CompanyID: category.CompanyID, BranchID: category.BranchID}}
+		for i := range detached {
+			evs = append(evs, events.ServiceUpdated{ServiceSnapshot: serviceSnapshot(&detached[i])})
+		}
+		return record(ctx, repo, category.CompanyID, evs...)
+	})
+}
+
+// RestoreCategory brings the category back empty: services detached on
+// deletion may have been recategorized since.
+func (u *companyUsecase) RestoreCategory(ctx context.Context, id uint) (*domain.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var category *domain.Category
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.RestoreCategory(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if category, err = categoryOf(ctx, repo, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, category.CompanyID, events.CategoryRestored{CategoryID: id, CompanyID: category.CompanyID, BranchID: category.BranchID})
+	})
+	return category, err
+}
+
+func (u *companyUsecase) UpdateEmployee(ctx context.Context, employee *domain.Employee) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.UpdateEmployee(ctx, employee); err != nil {
+			return err
+		}
+		updated, err := employeeOf(ctx, repo, employee.ID)
+		if err != nil {
+			return err
+		}
+		*employee = *updated
+		return record(ctx, repo, employee.CompanyID, employeeUpdated(employee))
+	})
+}
+
+func (u *companyUsecase) SetEmployeeBranches(ctx context.Context, employeeID, primaryID uint, branchIDs []uint) (*domain.Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var employee *domain.Employee
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		var err error
+		if employee, err = employeeOf(ctx, repo, employeeID); err != nil {
+			return err
+		}
+
+		// The primary branch is always one of them.
+		ids := append([]uint{primaryID}, branchIDs...)
+		var fields []erru.FieldError
+		seen := make(map[uint]bool, len(ids))
+		employee.Branches = nil
+		for i, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			field := "primary_branch_id"
+			if i > 0 {
+				field = fmt.Sprintf("branch_ids[%d]", i-1)
+			}
+			branch, err := repo.GetBranchByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if branch == nil || branch.CompanyID != employee.CompanyID {
+				fields = append(fields, erru.FieldError{Field: field, Rule: "oneof", Message: "is not a branch of the employee's company"})
+				continue
+			}
+			employee.Branches = append(employee.Branches, domain.EmployeeBranch{BranchID: id})
+		}
+		if len(fields) > 0 {
+			return erru.Validation(fields...)
+		}
+
+		employee.BranchID = primaryID
+		if err := repo.SetEmployeeBranches(ctx, employee); err != nil {
+			return err
+		}
+		return record(ctx, repo, employee.CompanyID, employeeUpdated(employee))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return employee, nil
+}
+
+// DeleteEmployee keeps the employee's menu so a restore brings it back.
+func (u *companyUsecase) DeleteEmployee(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		employee, err := employeeOf(ctx, repo, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteEmployee(ctx, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, employee.CompanyID, events.EmployeeDeleted{EmployeeID: id, CompanyID: employee.CompanyID, BranchID: employee.BranchID})
+	})
+}
+
+func (u *companyUsecase) RestoreEmployee(ctx context.Context, id uint) (*domain.Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var employee *domain.Employee
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		if err := repo.RestoreEmployee(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if employee, err = employeeOf(ctx, repo, id); err != nil {
+			return err
+		}
+		return record(ctx, repo, employee.CompanyID, events.EmployeeRestored{EmployeeID: id, CompanyID: employee.CompanyID, BranchID: employee.BranchID})
+	})
+	return employee, err
+}
+
+// branchOf loads the branch new catalog entries attach to; they inherit its company.
+func branchOf(ctx context.Context, repo domain.CompanyRepository, branchID uint) (*domain.Branch, error) {
+	branch, err := repo.GetBranchByID(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if branch == nil {
+		return nil, erru.ErrNotFound
+	}
+	return branch, nil
+}
+
+func categoryOf(ctx context.Context, repo domain.CompanyRepository, categoryID uint) (*domain.Category, error) {
+	category, err := repo.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, erru.ErrNotFound
+	}
+	return category, nil
+}
+
+func employeeOf(ctx context.Context, repo domain.CompanyRepository, employeeID uint) (*domain.Employee, error) {
+	employee, err := repo.GetEmployeeByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil {
+		return nil, erru.ErrNotFound
+	}
+	return employee, nil
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
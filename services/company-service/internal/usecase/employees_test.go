@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// employeeRepo has employee 10 of company 1 working at branch 1. Company 1
+// has branches 1 and 2 with services 100 and 200; branch 3 belongs to
+// company 2.
+type employeeRepo struct {
+	domain.CompanyRepository
+	employee  *domain.Employee
+	assigned  []domain.EmployeeService
+	events    []events.Envelope
+	created   []domain.Employee
+	createErr error
+}
+
+func newEmployeeRepo() *employeeRepo {
+	return &employeeRepo{employee: &domain.Employee{
+		ID: 10, CompanyID: 1, BranchID: 1,
+		Branches: []domain.EmployeeBranch{{EmployeeID: 10, BranchID: 1, CompanyID: 1}},
+	}}
+}
+
+func (r *employeeRepo) Transaction(ctx context.Context, fn func(repo domain.CompanyRepository) error) error {
+	return fn(r)
+}
+
+func (r *employeeRepo) AddEvents(_ context.Context, envs ...events.Envelope) error {
+	r.events = append(r.events, envs...)
+	return nil
+}
+
+func (r *employeeRepo) GetEmployeeByID(_ context.Context, id uint) (*domain.Employee, error) {
+	if id != r.employee.ID {
+		return nil, nil
+	}
+	employee := *r.employee
+	return &employee, nil
+}
+
+func (r *employeeRepo) GetBranchByID(_ context.Context, id uint) (*domain.Branch, error) {
+	switch id {
+	case 1, 2:
+		return &domain.Branch{ID: id, CompanyID: 1}, nil
+	case 3:
+		return &domain.Branch{ID: id, CompanyID: 2}, nil
+	}
+	return nil, nil
+}
+
+func (r *employeeRepo) GetServiceByID(_ context.Context, id uint) (*domain.Service, error) {
+	switch id {
+	case 100:
+		return &domain.Service{ID: id, CompanyID: 1, BranchID: 1}, nil
+	case 200:
+		return &domain.Service{ID: id, CompanyID: 1, BranchID: 2}, nil
+	}
+	return nil, nil
+}
+
+func (r *employeeRepo) SetEmployeeBranches(_ context.Context, employee *domain.Employee) error {
+	r.employee.BranchID = employee.BranchID
+	r.employee.Branches = employee.Branches
+	return nil
+}
+
+func (r *employeeRepo) CreateEmployee(_ context.Context, employee *domain.Employee) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	employee.ID = uint(11 + len(r.created))
+	r.created = append(r.created, *employee)
+	return nil
+}
+
+func (r *employeeRepo) AssignServiceToEmployee(_ context.Context, relation *domain.EmployeeService) error {
+	r.assigned = append(r.assigned, *relation)
+	return nil
+}
+
+func TestSetEmployeeBranches(t *testing.T) {
+	tests := []struct {
+		name         string
+		primaryID    uint
+		branchIDs    []uint
+		wantBranches []uint
+		wantFields   []string
+	}{
+		{name: "second branch", primaryID: 1, branchIDs: []uint{1, 2}, wantBranches: []uint{1, 2}},
+		{name: "primary added", primaryID: 2, branchIDs: []uint{1}, wantBranches: []uint{2, 1}},
+		{
+			name: "other company's and unknown branches", primaryID: 3, branchIDs: []uint{2, 9},
+			wantFields: []string{"primary_branch_id", "branch_ids[1]"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newEmployeeRepo()
+			u := NewCompanyUsecase(repo, time.Second, nil)
+			employee, err := u.SetEmployeeBranches(context.Background(), 10, tc.primaryID, tc.branchIDs)
+			if tc.wantFields != nil {
+				var appErr *erru.AppError
+				if !errors.As(err, &appErr) || appErr.Code != erru.CodeValidation {
+					t.Fatalf("err = %v, want validation_failed", err)
+				}
+				if len(appErr.Fields) != len(tc.wantFields) {
+					t.Fatalf("fields = %+v, want %v", appErr.Fields, tc.wantFields)
+				}
+				for i, f := range appErr.Fields {
+					if f.Field != tc.wantFields[i] {
+						t.Errorf("fields[%d] = %s, want %s", i, f.Field, tc.wantFields[i])
+					}
+				}
+				if len(repo.events) != 0 {
+					t.Errorf("events = %d, want none", len(repo.events))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if employee.BranchID != tc.primaryID {
+				t.Errorf("primary = %d, want %d", employee.BranchID, tc.primaryID)
+			}
+			got := branchIDs(employee)
+			if len(got) != len(tc.wantBranches) {
+				t.Fatalf("branches = %v, want %v", got, tc.wantBranches)
+			}
+			for i := range got {
+				if got[i] != tc.wantBranches[i] {
+					t.Fatalf("branches = %v, want %v", got, tc.wantBranches)
+				}
+			}
+			if len(repo.events) != 1 || repo.events[0].Type != events.TypeEmployeeUpdated {
+				t.Errorf("events = %+v, want one %s", repo.events, events.TypeEmployeeUpdated)
+			}
+		})
+	}
+}
+
+func TestAssignServiceOfOtherBranch(t *testing.T) {
+	repo := newEmployeeRepo()
+	u := NewCompanyUsecase(repo, time.Second, nil)
+
+	err := u.AssignService(context.Background(), 10, 200, 30, 45)
+	var appErr *erru.AppError
+	if !errors.As(err, &appErr) || appErr.Code != erru.CodeValidation || appErr.Fields[0].Field != "service_id" {
+		t.Fatalf("err = %v, want validation_failed on service_id", err)
+	}
+
+	repo.employee.Branches = append(repo.employee.Branches, domain.EmployeeBranch{EmployeeID: 10, BranchID: 2, CompanyID: 1})
+	if err := u.AssignService(context.Background(), 10, 200, 30, 45); err != nil {
+		t.Fatalf("err = %v after assigning branch 2", err)
+	}
+	if len(repo.assigned) != 1 || repo.assigned[0].ServiceID != 200 {
+		t.Errorf("assigned = %+v, want service 200", repo.assigned)
+	}
+}
+
+func TestAddEmployee(t *testing.T) {
+	const email = "ann@example.com"
+	insertFailed := errors.New("insert failed")
+	tests := []struct {
+		name      string
+		branchID  uint
+		email     string
+		createErr error
+		wantErr   error
+	}{
+		{name: "with an account", branchID: 2, email: email},
+		{name: "without an account", branchID: 1},
+		{name: "unknown branch", branchID: 9, email: email, wantErr: erru.ErrNotFound},
+		{name: "insert fails", branchID: 1, email: email, createErr: insertFailed, wantErr: insertFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newEmployeeRepo()
+			repo.createErr = tc.createErr
+			auth := &fake.Auth{}
+			u := NewCompanyUsecase(repo, time.Second, auth)
+
+			employee, err := u.AddEmployee(context.Background(), tc.branchID, "Ann", "Stylist", tc.email, "")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+				if len(auth.Users) != 0 || len(repo.events) != 0 {
+					t.Errorf("users %v and events %d left behind, want none", auth.Users, len(repo.events))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if employee.CompanyID != 1 || !employee.WorksAt(tc.branchID) || employee.Role != domain.RoleMaster {
+				t.Errorf("employee = %+v, want a master of company 1 at branch %d", employee, tc.branchID)
+			}
+			if tc.email == "" {
+				if employee.UserID != nil || employee.Password != "" || len(auth.Users) != 0 {
+					t.Errorf("employee = %+v, want no account", employee)
+				}
+				return
+			}
+			user := auth.Users[tc.email]
+			if user == nil || employee.UserID == nil || *employee.UserID != user.ID || employee.Password == "" {
+				t.Fatalf("employee = %+v, user = %+v, want the account linked with its password", employee, user)
+			}
+			// The password returned is the account's.
+			if err := auth.Unregister(context.Background(), tc.email, employee.Password); err != nil {
+				t.Errorf("unregister with the returned password: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddEmployeeRetry(t *testing.T) {
+	repo := newEmployeeRepo()
+	repo.createErr = errors.New("insert failed")
+	u := NewCompanyUsecase(repo, time.Second, &fake.Auth{})
+
+	if _, err := u.AddEmployee(context.Background(), 1, "Ann", "", "ann@example.com", ""); err == nil {
+		t.Fatal("err = nil, want the insert error")
+	}
+	repo.createErr = nil
+	if _, err := u.AddEmployee(context.Background(), 1, "Ann", "", "ann@example.com", ""); err != nil {
+		t.Fatalf("retry: err = %v, want the email free again", err)
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// record stores evs for companyID in the outbox of the running transaction.
+func record(ctx context.Context, repo domain.CompanyRepository, companyID uint, evs ...events.Event) error {
+	envs := make([]events.Envelope, 0, len(evs))
+	for _, ev := range evs {
+		env, err := events.New(companyID, ev)
+		if err != nil {
+			return err
+		}
+		envs = append(envs, env)
+	}
+	return repo.AddEvents(ctx, envs...)
+}
+
+func branchCreated(b *domain.Branch) events.BranchCreated {
+	return events.BranchCreated{
+		BranchID:    b.ID,
+		CompanyID:   b.CompanyID,
+		Name:        b.Name,
+		Address:     b.Address,
+		Phone:       b.Phone,
+		IsMain:      b.IsMain,
+		Latitude:    b.Latitude,
+		Longitude:   b.Longitude,
+		BranchHours: branchHours(b),
+	}
+}
+
+func branchUpdated(b *domain.Branch) events.BranchUpdated {
+	return events.BranchUpdated(branchCreated(b))
+}
+
+func branchHours(b *domain.Branch) events.BranchHours {
+	hours := events.BranchHours{
+		Timezone:     b.Timezone,
+		OpeningHours: make([]events.OpeningHours, 0, len(b.OpeningHours)),
+		Exceptions:   make([]events.HoursException, 0, len(b.HoursExceptions)),
+	}
+	for _, h := range b.OpeningHours {
+		hours.OpeningHours = append(hours.OpeningHours, events.OpeningHours{DayOfWeek: h.DayOfWeek, Open: h.OpenTime, Close: h.CloseTime})
+	}
+	for _, x := range b.HoursExceptions {
+		hours.Exceptions = append(hours.Exceptions, events.HoursException{
+			Date:   x.Date.Format(time.DateOnly),
+			Closed: x.IsClosed,
+			Open:   x.OpenTime,
+			Close:  x.CloseTime,
+		})
+	}
+	return hours
+}
+
+func categoryCreated(c *domain.Category) events.CategoryCreated {
+	return events.CategoryCreated{
+		CategoryID: c.ID,
+		CompanyID:  c.CompanyID,
+		BranchID:   c.BranchID,
+		Name:       c.Name,
+	}
+}
+
+func serviceSnapshot(s *domain.Service) events.ServiceSnapshot {
+	return events.ServiceSnapshot{
+		ServiceID:       s.ID,
+		CompanyID:       s.CompanyID,
+		BranchID:        s.BranchID,
+		CategoryID:      s.CategoryID,
+		Name:            s.Name,
+		Price:           s.Price,
+		DurationMinutes: s.DurationMinutes,
+	}
+}
+
+func employeeCreated(e *domain.Employee) events.EmployeeCreated {
+	return events.EmployeeCreated{
+		EmployeeID: e.ID,
+		CompanyID:  e.CompanyID,
+		BranchID:   e.BranchID,
+		BranchIDs:  branchIDs(e),
+		UserID:     e.UserID,
+		Name:       e.Name,
+		Position:   e.Position,
+	}
+}
+
+func employeeUpdated(e *domain.Employee) events.EmployeeUpdated {
+	return events.EmployeeUpdated{
+		EmployeeID: e.ID,
+		CompanyID:  e.CompanyID,
+		BranchID:   e.BranchID,
+		BranchIDs:  branchIDs(e),
+		UserID:     e.UserID,
+		Name:       e.Name,
+		Position:   e.Position,
+		Role:       string(e.Role),
+	}
+}
+
+// branchIDs lists the branches the employee works at.
+func branchIDs(e *domain.Employee) []uint {
+	ids := make([]uint, len(e.Branches))
+	for i, b := range e.Branches {
+		ids[i] = b.BranchID
+	}
+	return ids
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// validateHours checks what the request validator cannot: one entry per
+// weekday and per date, and opening before closing. "HH:MM" strings compare
+// in time order.
+func validateHours(hours []domain.OpeningHours, exceptions []domain.HoursException) error {
+	var fields []erru.FieldError
+	days := make(map[int]bool, len(hours))
+	for i, h := range hours {
+		field := fmt.Sprintf("opening_hours[%d]", i)
+		if days[h.DayOfWeek] {
+			fields = append(fields, erru.FieldError{Field: field + ".day_of_week", Rule: "unique", Message: "day_of_week is listed twice"})
+		}
+		days[h.DayOfWeek] = true
+		if h.OpenTime >= h.CloseTime {
+			fields = append(fields, erru.FieldError{Field: field + ".close_time", Rule: "gtfield", Message: "close_time must be after open_time"})
+		}
+	}
+	dates := make(map[time.Time]bool, len(exceptions))
+	for i, x := range exceptions {
+		field := fmt.Sprintf("hours_exceptions[%d]", i)
+		if dates[x.Date] {
+			fields = append(fields, erru.FieldError{Field: field + ".date", Rule: "unique", Message: "date is listed twice"})
+		}
+		dates[x.Date] = true
+		if !x.IsClosed && (x.OpenTime == "" || x.OpenTime >= x.CloseTime) {
+			fields = append(fields, erru.FieldError{Field: field + ".close_time", Rule: "gtfield", Message: "close_time must be after open_time unless is_closed"})
+		}
+	}
+	if len(fields) > 0 {
+		return erru.Validation(fields...)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+func TestValidateHours(t *testing.T) {
+	newYear := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		hours      []domain.OpeningHours
+		exceptions []domain.HoursException
+		wantFields []string
+	}{
+		{name: "no hours"},
+		{
+			name:       "week and holiday",
+			hours:      []domain.OpeningHours{{DayOfWeek: 1, OpenTime: "09:00", CloseTime: "21:00"}, {DayOfWeek: 2, OpenTime: "09:00", CloseTime: "21:00"}},
+			exceptions: []domain.HoursException{{Date: newYear, IsClosed: true}},
+		},
+		{
+			name:       "day twice",
+			hours:      []domain.OpeningHours{{DayOfWeek: 1, OpenTime: "09:00", CloseTime: "13:00"}, {DayOfWeek: 1, OpenTime: "14:00", CloseTime: "21:00"}},
+			wantFields: []string{"opening_hours[1].day_of_week"},
+		},
+		{
+			name:       "closes before opening",
+			hours:      []domain.OpeningHours{{DayOfWeek: 1, OpenTime: "21:00", CloseTime: "09:00"}},
+			wantFields: []string{"opening_hours[0].close_time"},
+		},
+		{
+			name:       "open exception without hours",
+			exceptions: []domain.HoursException{{Date: newYear}},
+			wantFields: []string{"hours_exceptions[0].close_time"},
+		},
+		{
+			name:       "date twice",
+			exceptions: []domain.HoursException{{Date: newYear, IsClosed: true}, {Date: newYear, OpenTime: "10:00", CloseTime: "15:00"}},
+			wantFields: []string{"hours_exceptions[1].date"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateHours(tc.hours, tc.exceptions)
+			if len(tc.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || appErr.Code != erru.CodeValidation {
+				t.Fatalf("err = %v, want %s", err, erru.CodeValidation)
+			}
+			if len(appErr.Fields) != len(tc.wantFields) {
+				t.Fatalf("fields = %v, want %v", appErr.Fields, tc.wantFields)
+			}
+			for i, field := range tc.wantFields {
+				if appErr.Fields[i].Field != field {
+					t.Errorf("field %d = %s, want %s", i, appErr.Fields[i].Field, field)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// lifecycleRepo keeps branches, categories and services in memory and
+// records deletions and outbox events.
+type lifecycleRepo struct {
+	domain.CompanyRepository
+	branches   map[uint]*domain.Branch
+	categories map[uint]*domain.Category
+	services   []domain.Service
+	deleted    []uint
+	events     []string
+}
+
+func (r *lifecycleRepo) Transaction(ctx context.Context, fn func(repo domain.CompanyRepository) error) error {
+	return fn(r)
+}
+
+func (r *lifecycleRepo) AddEvents(_ context.Context, envs ...events.Envelope) error {
+	for _, env := range envs {
+		r.events = append(r.events, env.Type)
+	}
+	return nil
+}
+
+func (r *lifecycleRepo) GetBranchByID(_ context.Context, id uint) (*domain.Branch, error) {
+	return r.branches[id], nil
+}
+
+func (r *lifecycleRepo) DeleteBranch(_ context.Context, id uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *lifecycleRepo) GetCategoryByID(_ context.Context, id uint) (*domain.Category, error) {
+	return r.categories[id], nil
+}
+
+func (r *lifecycleRepo) DeleteCategory(_ context.Context, id uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *lifecycleRepo) DetachServices(_ context.Context, categoryID uint) ([]domain.Service, error) {
+	var detached []domain.Service
+	for i, s := range r.services {
+		if s.CategoryID != nil && *s.CategoryID == categoryID {
+			r.services[i].CategoryID = nil
+			detached = append(detached, r.services[i])
+		}
+	}
+	return detached, nil
+}
+
+func newLifecycleRepo() *lifecycleRepo {
+	hair := uint(30)
+	return &lifecycleRepo{
+		branches: map[uint]*domain.Branch{
+			1: {ID: 1, CompanyID: 1, IsMain: true},
+			2: {ID: 2, CompanyID: 1},
+		},
+		categories: map[uint]*domain.Category{
+			30: {ID: 30, CompanyID: 1, BranchID: 2},
+			31: {ID: 31, CompanyID: 1, BranchID: 2},
+		},
+		services: []domain.Service{
+			{ID: 100, CompanyID: 1, BranchID: 2, CategoryID: &hair},
+			{ID: 101, CompanyID: 1, BranchID: 2, CategoryID: &hair},
+			{ID: 102, CompanyID: 1, BranchID: 2},
+		},
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         uint
+		wantCode   erru.Code
+		wantEvents []string
+	}{
+		{name: "branch", id: 2, wantEvents: []string{events.TypeBranchDeleted}},
+		{name: "main branch", id: 1, wantCode: erru.CodeConflict},
+		{name: "unknown branch", id: 9, wantCode: erru.CodeNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newLifecycleRepo()
+			u := NewCompanyUsecase(repo, time.Second, nil)
+
+			err := u.DeleteBranch(context.Background(), tc.id)
+			if tc.wantCode != "" {
+				var appErr *erru.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tc.wantCode {
+					t.Fatalf("err = %v, want %s", err, tc.wantCode)
+				}
+				if len(repo.deleted) != 0 || len(repo.events) != 0 {
+					t.Fatalf("deleted %v and recorded %v, want nothing", repo.deleted, repo.events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.deleted) != 1 || repo.deleted[0] != tc.id {
+				t.Errorf("deleted %v, want [%d]", repo.deleted, tc.id)
+			}
+			assertEvents(t, repo.events, tc.wantEvents)
+		})
+	}
+}
+
+func TestDeleteCategoryDetachesServices(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         uint
+		wantEvents []string
+	}{
+		{
+			name:       "category with services",
+			id:         30,
+			wantEvents: []string{events.TypeCategoryDeleted, events.TypeServiceUpdated, events.TypeServiceUpdated},
+		},
+		{
+			name:       "empty category",
+			id:         31,
+			wantEvents: []string{events.TypeCategoryDeleted},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newLifecycleRepo()
+			u := NewCompanyUsecase(repo, time.Second, nil)
+
+			if err := u.DeleteCategory(context.Background(), tc.id); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range repo.services {
+				if s.CategoryID != nil && *s.CategoryID == tc.id {
+					t.Errorf("service %d still in category %d", s.ID, tc.id)
+				}
+			}
+			if len(repo.deleted) != 1 || repo.deleted[0] != tc.id {
+				t.Errorf("deleted %v, want [%d]", repo.deleted, tc.id)
+			}
+			assertEvents(t, repo.events, tc.wantEvents)
+		})
+	}
+}
+
+func assertEvents(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+}
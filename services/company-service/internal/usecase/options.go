@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+func (u *companyUsecase) AddServiceOption(ctx context.Context, serviceID uint, kind domain.OptionKind, name string, priceDelta float64, durationDelta int) (*domain.ServiceOption, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	service, err := u.repo.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, erru.ErrNotFound
+	}
+	option := &domain.ServiceOption{
+		CompanyID:     service.CompanyID,
+		ServiceID:     service.ID,
+		Kind:          kind,
+		Name:          name,
+		PriceDelta:    priceDelta,
+		DurationDelta: durationDelta,
+	}
+	if err := u.repo.CreateServiceOption(ctx, option); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+func (u *companyUsecase) UpdateServiceOption(ctx context.Context, option *domain.ServiceOption) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := u.repo.UpdateServiceOption(ctx, option); err != nil {
+		return err
+	}
+	updated, err := u.repo.GetServiceOptionByID(ctx, option.ID)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return erru.ErrNotFound
+	}
+	*option = *updated
+	return nil
+}
+
+func (u *companyUsecase) DeleteServiceOption(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.DeleteServiceOption(ctx, id)
+}
+
+func (u *companyUsecase) SetEmployeeOption(ctx context.Context, employeeID, optionID uint, priceDelta float64, durationDelta int) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.SetEmployeeOption(ctx, &domain.EmployeeServiceOption{
+		EmployeeID:    employeeID,
+		OptionID:      optionID,
+		PriceDelta:    priceDelta,
+		DurationDelta: durationDelta,
+	})
+}
+
+func (u *companyUsecase) RemoveEmployeeOption(ctx context.Context, employeeID, optionID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.RemoveEmployeeOption(ctx, employeeID, optionID)
+}
+
+func (u *companyUsecase) Quote(ctx context.Context, employeeID, serviceID uint, variantID *uint, addOnIDs []uint) (*domain.Quote, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	service, err := u.repo.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	employee, err := u.repo.GetEmployeeByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil || employee == nil || !employee.WorksAt(service.BranchID) {
+		return nil, erru.ErrNotFound
+	}
+
+	quote := &domain.Quote{
+		EmployeeID:      employeeID,
+		ServiceID:       serviceID,
+		VariantID:       variantID,
+		AddOnIDs:        addOnIDs,
+		Price:           service.Price,
+		DurationMinutes: service.DurationMinutes,
+		BranchID:        service.BranchID,
+		CompanyID:       service.CompanyID,
+	}
+	terms, err := u.repo.GetEmployeeService(ctx, employeeID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if terms != nil {
+		quote.Price, quote.DurationMinutes = terms.Price, terms.DurationMinutes
+	}
+
+	options, err := u.repo.GetServiceOptions(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	chosen, err := chooseOptions(options, variantID, addOnIDs)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(chosen))
+	for i, o := range chosen {
+		ids[i] = o.ID
+	}
+	overrides, err := u.repo.GetEmployeeOptions(ctx, employeeID, ids)
+	if err != nil {
+		return nil, err
+	}
+	own := make(map[uint]domain.EmployeeServiceOption, len(overrides))
+	for _, o := range overrides {
+		own[o.OptionID] = o
+	}
+	for _, o := range chosen {
+		price, duration := o.PriceDelta, o.DurationDelta
+		if override, ok := own[o.ID]; ok {
+			price, duration = override.PriceDelta, override.DurationDelta
+		}
+		quote.Price += price
+		quote.DurationMinutes += duration
+	}
+
+	if quote.Price < 0 {
+		quote.Price = 0
+	}
+	if quote.DurationMinutes <= 0 {
+		return nil, erru.E(erru.CodeConflict, "The service has no duration with these options")
+	}
+	if quote.Requirements, err = needs(ctx, u.repo, serviceID); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// chooseOptions picks the variant and add-ons out of a service's options.
+func chooseOptions(options []domain.ServiceOption, variantID *uint, addOnIDs []uint) ([]domain.ServiceOption, error) {
+	byID := make(map[uint]domain.ServiceOption, len(options))
+	hasVariants := false
+	for _, o := range options {
+		byID[o.ID] = o
+		hasVariants = hasVariants || o.Kind == domain.OptionVariant
+	}
+
+	var chosen []domain.ServiceOption
+	var fields []erru.FieldError
+	switch {
+	case variantID != nil:
+		if o, ok := byID[*variantID]; ok && o.Kind == domain.OptionVariant {
+			chosen = append(chosen, o)
+		} else {
+			fields = append(fields, erru.FieldError{Field: "variant_id", Rule: "oneof", Message: "is not a variant of the service"})
+		}
+	case hasVariants:
+		fields = append(fields, erru.FieldError{Field: "variant_id", Rule: "required", Message: "is required: the service has variants"})
+	}
+	seen := make(map[uint]bool, len(addOnIDs))
+	for i, id := range addOnIDs {
+		field := fmt.Sprintf("addon_ids[%d]", i)
+		o, ok := byID[id]
+		switch {
+		case !ok || o.Kind != domain.OptionAddOn:
+			fields = append(fields, erru.FieldError{Field: field, Rule: "oneof", Message: "is not an add-on of the service"})
+		case seen[id]:
+			fields = append(fields, erru.FieldError{Field: field, Rule: "unique", Message: "is listed twice"})
+		default:
+			chosen = append(chosen, o)
+		}
+		seen[id] = true
+	}
+	if len(fields) > 0 {
+		return nil, erru.Validation(fields...)
+	}
+	return chosen, nil
+}
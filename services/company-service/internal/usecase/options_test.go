@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// quoteRepo holds one 60 minute service of branch 1 at 40 with two variants
+// and two add-ons, needing a room of type 7. Employee 11 has own terms for it
+// and for the long variant; employee 12 works at branch 2 only.
+type quoteRepo struct {
+	domain.CompanyRepository
+}
+
+func (quoteRepo) GetServiceByID(_ context.Context, id uint) (*domain.Service, error) {
+	if id != 100 {
+		return nil, nil
+	}
+	return &domain.Service{ID: 100, CompanyID: 1, BranchID: 1, Price: 40, DurationMinutes: 60}, nil
+}
+
+func (quoteRepo) GetEmployeeByID(_ context.Context, id uint) (*domain.Employee, error) {
+	switch id {
+	case 10, 11:
+		return &domain.Employee{ID: id, CompanyID: 1, BranchID: 2, Branches: []domain.EmployeeBranch{{BranchID: 2}, {BranchID: 1}}}, nil
+	case 12:
+		return &domain.Employee{ID: id, CompanyID: 1, BranchID: 2, Branches: []domain.EmployeeBranch{{BranchID: 2}}}, nil
+	case 20:
+		return &domain.Employee{ID: id, CompanyID: 2, BranchID: 3, Branches: []domain.EmployeeBranch{{BranchID: 3}}}, nil
+	}
+	return nil, nil
+}
+
+func (quoteRepo) GetEmployeeService(_ context.Context, employeeID, serviceID uint) (*domain.EmployeeService, error) {
+	if employeeID != 11 {
+		return nil, nil
+	}
+	return &domain.EmployeeService{EmployeeID: 11, ServiceID: serviceID, Price: 50, DurationMinutes: 45}, nil
+}
+
+func (quoteRepo) GetServiceOptions(context.Context, uint) ([]domain.ServiceOption, error) {
+	return []domain.ServiceOption{
+		{ID: 1, Kind: domain.OptionVariant, Name: "Short", PriceDelta: -10, DurationDelta: -15},
+		{ID: 2, Kind: domain.OptionVariant, Name: "Long", PriceDelta: 20, DurationDelta: 30},
+		{ID: 3, Kind: domain.OptionAddOn, Name: "Wash", PriceDelta: 5, DurationDelta: 15},
+		{ID: 4, Kind: domain.OptionAddOn, Name: "Discount", PriceDelta: -100, DurationDelta: -90},
+	}, nil
+}
+
+func (quoteRepo) GetEmployeeOptions(_ context.Context, employeeID uint, _ []uint) ([]domain.EmployeeServiceOption, error) {
+	if employeeID != 11 {
+		return nil, nil
+	}
+	return []domain.EmployeeServiceOption{{EmployeeID: 11, OptionID: 2, PriceDelta: 30, DurationDelta: 45}}, nil
+}
+
+func (quoteRepo) GetServiceRequirements(context.Context, uint) ([]domain.ServiceRequirement, error) {
+	return []domain.ServiceRequirement{{ServiceID: 100, TypeID: 7, Quantity: 1}}, nil
+}
+
+func (quoteRepo) GetResourcesByType(context.Context, []uint) ([]domain.BranchResource, error) {
+	return []domain.BranchResource{{ID: 70, TypeID: 7}, {ID: 71, TypeID: 7}}, nil
+}
+
+func TestQuote(t *testing.T) {
+	short, long, wash := uint(1), uint(2), uint(3)
+	tests := []struct {
+		name         string
+		employeeID   uint
+		serviceID    uint
+		variantID    *uint
+		addOnIDs     []uint
+		wantPrice    float64
+		wantDuration int
+		wantCode     erru.Code
+		wantFields   []string
+	}{
+		{name: "variant", employeeID: 10, serviceID: 100, variantID: &short, wantPrice: 30, wantDuration: 45},
+		{name: "variant and add-on", employeeID: 10, serviceID: 100, variantID: &long, addOnIDs: []uint{3}, wantPrice: 65, wantDuration: 105},
+		{name: "employee's own terms", employeeID: 11, serviceID: 100, variantID: &long, addOnIDs: []uint{3}, wantPrice: 85, wantDuration: 105},
+		{name: "variant missing", employeeID: 10, serviceID: 100, wantCode: erru.CodeValidation, wantFields: []string{"variant_id"}},
+		{name: "add-on as variant", employeeID: 10, serviceID: 100, variantID: &wash, wantCode: erru.CodeValidation, wantFields: []string{"variant_id"}},
+		{
+			name: "bad add-ons", employeeID: 10, serviceID: 100, variantID: &short, addOnIDs: []uint{3, 3, 1, 9},
+			wantCode: erru.CodeValidation, wantFields: []string{"addon_ids[1]", "addon_ids[2]", "addon_ids[3]"},
+		},
+		{name: "no time left", employeeID: 10, serviceID: 100, variantID: &short, addOnIDs: []uint{4}, wantCode: erru.CodeConflict},
+		{name: "employee of another branch", employeeID: 12, serviceID: 100, variantID: &short, wantCode: erru.CodeNotFound},
+		{name: "other company's employee", employeeID: 20, serviceID: 100, variantID: &short, wantCode: erru.CodeNotFound},
+		{name: "unknown service", employeeID: 10, serviceID: 9, wantCode: erru.CodeNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := NewCompanyUsecase(quoteRepo{}, time.Second, nil)
+			quote, err := u.Quote(context.Background(), tc.employeeID, tc.serviceID, tc.variantID, tc.addOnIDs)
+			if tc.wantCode != "" {
+				var appErr *erru.AppError
+				if !errors.As(err, &appErr) || appErr.Code != tc.wantCode {
+					t.Fatalf("err = %v, want %s", err, tc.wantCode)
+				}
+				if len(appErr.Fields) != len(tc.wantFields) {
+					t.Fatalf("fields = %+v, want %v", appErr.Fields, tc.wantFields)
+				}
+				for i, f := range appErr.Fields {
+					if f.Field != tc.wantFields[i] {
+						t.Errorf("fields[%d] = %s, want %s", i, f.Field, tc.wantFields[i])
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if quote.Price != tc.wantPrice || quote.DurationMinutes != tc.wantDuration {
+				t.Errorf("quote = %v for %d min, want %v for %d min", quote.Price, quote.DurationMinutes, tc.wantPrice, tc.wantDuration)
+			}
+			if quote.BranchID != 1 {
+				t.Errorf("branch = %d, want the service's branch 1", quote.BranchID)
+			}
+		})
+	}
+}
+
+func TestQuoteNeeds(t *testing.T) {
+	short := uint(1)
+	u := NewCompanyUsecase(quoteRepo{}, time.Second, nil)
+	quote, err := u.Quote(context.Background(), 10, 100, &short, nil)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(quote.Requirements) != 1 {
+		t.Fatalf("requirements = %+v, want one", quote.Requirements)
+	}
+	need := quote.Requirements[0]
+	if need.TypeID != 7 || need.Quantity != 1 || len(need.ResourceIDs) != 2 || need.ResourceIDs[0] != 70 || need.ResourceIDs[1] != 71 {
+		t.Errorf("need = %+v, want 1 of type 7 among 70 and 71", need)
+	}
+}
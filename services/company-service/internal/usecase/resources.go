@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+func (u *companyUsecase) AddResourceType(ctx context.Context, branchID uint, name string) (*domain.ResourceType, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	branch, err := branchOf(ctx, u.repo, branchID)
+	if err != nil {
+		return nil, err
+	}
+	resourceType := &domain.ResourceType{CompanyID: branch.CompanyID, BranchID: branchID, Name: name}
+	if err := u.repo.CreateResourceType(ctx, resourceType); err != nil {
+		return nil, err
+	}
+	return resourceType, nil
+}
+
+func (u *companyUsecase) UpdateResourceType(ctx context.Context, resourceType *domain.ResourceType) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := u.repo.UpdateResourceType(ctx, resourceType); err != nil {
+		return err
+	}
+	updated, err := resourceTypeOf(ctx, u.repo, resourceType.ID)
+	if err != nil {
+		return err
+	}
+	*resourceType = *updated
+	return nil
+}
+
+func (u *companyUsecase) DeleteResourceType(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		resourceType, err := resourceTypeOf(ctx, repo, id)
+		if err != nil {
+			return err
+		}
+		if len(resourceType.Resources) > 0 {
+			return erru.E(erru.CodeConflict, "Delete the resources of this type first")
+		}
+		required, err := repo.CountRequirements(ctx, id)
+		if err != nil {
+			return err
+		}
+		if required > 0 {
+			return erru.E(erru.CodeConflict, "Services still need this resource type")
+		}
+		return repo.DeleteResourceType(ctx, id)
+	})
+}
+
+func (u *companyUsecase) GetBranchResourceTypes(ctx context.Context, branchID uint, page pagination.Request) (pagination.Page[domain.ResourceType], error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.GetResourceTypesByBranchID(ctx, branchID, page)
+}
+
+func (u *companyUsecase) AddResource(ctx context.Context, typeID uint, name string) (*domain.BranchResource, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	resourceType, err := resourceTypeOf(ctx, u.repo, typeID)
+	if err != nil {
+		return nil, err
+	}
+	resource := &domain.BranchResource{
+		CompanyID: resourceType.CompanyID,
+		BranchID:  resourceType.BranchID,
+		TypeID:    typeID,
+		Name:      name,
+	}
+	if err := u.repo.CreateResource(ctx, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+func (u *companyUsecase) UpdateResource(ctx context.Context, resource *domain.BranchResource) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := u.repo.UpdateResource(ctx, resource); err != nil {
+		return err
+	}
+	updated, err := u.repo.GetResourceByID(ctx, resource.ID)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return erru.ErrNotFound
+	}
+	*resource = *updated
+	return nil
+}
+
+func (u *companyUsecase) DeleteResource(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.DeleteResource(ctx, id)
+}
+
+func (u *companyUsecase) SetServiceRequirements(ctx context.Context, serviceID uint, requirements []domain.ServiceRequirement) ([]domain.ServiceRequirement, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	err := u.repo.Transaction(ctx, func(repo domain.CompanyRepository) error {
+		service, err := repo.GetServiceByID(ctx, serviceID)
+		if err != nil {
+			return err
+		}
+		if service == nil {
+			return erru.ErrNotFound
+		}
+
+		var fields []erru.FieldError
+		seen := make(map[uint]bool, len(requirements))
+		for i := range requirements {
+			req := &requirements[i]
+			req.ServiceID, req.CompanyID = serviceID, service.CompanyID
+
+			field := fmt.Sprintf("requirements[%d].type_id", i)
+			resourceType, err := repo.GetResourceTypeByID(ctx, req.TypeID)
+			if err != nil {
+				return err
+			}
+			switch {
+			case resourceType == nil || resourceType.BranchID != service.BranchID:
+				fields = append(fields, erru.FieldError{Field: field, Rule: "oneof", Message: "is not a resource type of the branch"})
+			case seen[req.TypeID]:
+				fields = append(fields, erru.FieldError{Field: field, Rule: "unique", Message: "is listed twice"})
+			}
+			seen[req.TypeID] = true
+		}
+		if len(fields) > 0 {
+			return erru.Validation(fields...)
+		}
+		return repo.SetServiceRequirements(ctx, serviceID, requirements)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return requirements, nil
+}
+
+// needs lists what a service requires along with the resources of each
+// type, so booking-service can hold them with the employee.
+func needs(ctx context.Context, repo domain.CompanyRepository, serviceID uint) ([]domain.ResourceNeed, error) {
+	requirements, err := repo.GetServiceRequirements(ctx, serviceID)
+	if err != nil || len(requirements) == 0 {
+		return nil, err
+	}
+	typeIDs := make([]uint, len(requirements))
+	for i, req := range requirements {
+		typeIDs[i] = req.TypeID
+	}
+	resources, err := repo.GetResourcesByType(ctx, typeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	needs := make([]domain.ResourceNeed, len(requirements))
+	for i, req := range requirements {
+		needs[i] = domain.ResourceNeed{TypeID: req.TypeID, Quantity: req.Quantity, ResourceIDs: []uint{}}
+		for _, r := range resources {
+			if r.TypeID == req.TypeID {
+				needs[i].ResourceIDs = append(needs[i].ResourceIDs, r.ID)
+			}
+		}
+	}
+	return needs, nil
+}
+
+func resourceTypeOf(ctx context.Context, repo domain.CompanyRepository, typeID uint) (*domain.ResourceType, error) {
+	resourceType, err := repo.GetResourceTypeByID(ctx, typeID)
+	if err != nil {
+		return nil, err
+	}
+	if resourceType == nil {
+		return nil, erru.ErrNotFound
+	}
+	return resourceType, nil
+}
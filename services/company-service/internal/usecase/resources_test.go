@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/erru"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// resourceRepo has service 100 in branch 1, room type 7 with one room and
+// types 8 and 10 without resources in branch 1, and type 9 in branch 2.
+// Services need types 7 and 10.
+type resourceRepo struct {
+	domain.CompanyRepository
+	set     []domain.ServiceRequirement
+	deleted []uint
+}
+
+func (r *resourceRepo) Transaction(ctx context.Context, fn func(repo domain.CompanyRepository) error) error {
+	return fn(r)
+}
+
+func (r *resourceRepo) GetServiceByID(_ context.Context, id uint) (*domain.Service, error) {
+	if id != 100 {
+		return nil, nil
+	}
+	return &domain.Service{ID: 100, CompanyID: 1, BranchID: 1}, nil
+}
+
+func (r *resourceRepo) GetResourceTypeByID(_ context.Context, id uint) (*domain.ResourceType, error) {
+	switch id {
+	case 7:
+		return &domain.ResourceType{ID: 7, CompanyID: 1, BranchID: 1, Resources: []domain.BranchResource{{ID: 70, TypeID: 7}}}, nil
+	case 8:
+		return &domain.ResourceType{ID: 8, CompanyID: 1, BranchID: 1}, nil
+	case 9:
+		return &domain.ResourceType{ID: 9, CompanyID: 1, BranchID: 2}, nil
+	case 10:
+		return &domain.ResourceType{ID: 10, CompanyID: 1, BranchID: 1}, nil
+	}
+	return nil, nil
+}
+
+func (r *resourceRepo) CountRequirements(_ context.Context, typeID uint) (int64, error) {
+	if typeID == 7 || typeID == 10 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (r *resourceRepo) SetServiceRequirements(_ context.Context, _ uint, requirements []domain.ServiceRequirement) error {
+	r.set = requirements
+	return nil
+}
+
+func (r *resourceRepo) DeleteResourceType(_ context.Context, id uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestSetServiceRequirements(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements []domain.ServiceRequirement
+		wantFields   []string
+	}{
+		{name: "types of the branch", requirements: []domain.ServiceRequirement{{TypeID: 7, Quantity: 1}, {TypeID: 8, Quantity: 2}}},
+		{name: "none", requirements: []domain.ServiceRequirement{}},
+		{
+			name:         "other branch, unknown and repeated types",
+			requirements: []domain.ServiceRequirement{{TypeID: 9, Quantity: 1}, {TypeID: 6, Quantity: 1}, {TypeID: 7, Quantity: 1}, {TypeID: 7, Quantity: 1}},
+			wantFields:   []string{"requirements[0].type_id", "requirements[1].type_id", "requirements[3].type_id"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &resourceRepo{}
+			u := NewCompanyUsecase(repo, time.Second, nil)
+			got, err := u.SetServiceRequirements(context.Background(), 100, tc.requirements)
+			if len(tc.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				if len(repo.set) != len(tc.requirements) || len(got) != len(tc.requirements) {
+					t.Fatalf("set %+v, want %d requirements", repo.set, len(tc.requirements))
+				}
+				for i, req := range repo.set {
+					if req.ServiceID != 100 || req.CompanyID != 1 {
+						t.Errorf("requirements[%d] = %+v, want service 100 of company 1", i, req)
+					}
+				}
+				return
+			}
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || appErr.Code != erru.CodeValidation {
+				t.Fatalf("err = %v, want %s", err, erru.CodeValidation)
+			}
+			if repo.set != nil {
+				t.Errorf("requirements were set")
+			}
+			if len(appErr.Fields) != len(tc.wantFields) {
+				t.Fatalf("fields = %+v, want %v", appErr.Fields, tc.wantFields)
+			}
+			for i, f := range appErr.Fields {
+				if f.Field != tc.wantFields[i] {
+					t.Errorf("fields[%d] = %s, want %s", i, f.Field, tc.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetServiceRequirementsUnknownService(t *testing.T) {
+	u := NewCompanyUsecase(&resourceRepo{}, time.Second, nil)
+	_, err := u.SetServiceRequirements(context.Background(), 9, nil)
+	if !errors.Is(err, erru.ErrNotFound) {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestDeleteResourceType(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       uint
+		wantCode erru.Code
+	}{
+		{name: "unused", id: 8},
+		{name: "has resources", id: 7, wantCode: erru.CodeConflict},
+		{name: "required by a service", id: 10, wantCode: erru.CodeConflict},
+		{name: "unknown", id: 6, wantCode: erru.CodeNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &resourceRepo{}
+			u := NewCompanyUsecase(repo, time.Second, nil)
+			err := u.DeleteResourceType(context.Background(), tc.id)
+			if tc.wantCode == "" {
+				if err != nil || len(repo.deleted) != 1 {
+					t.Fatalf("err = %v, deleted %v; want type %d deleted", err, repo.deleted, tc.id)
+				}
+				return
+			}
+			var appErr *erru.AppError
+			if !errors.As(err, &appErr) || appErr.Code != tc.wantCode {
+				t.Fatalf("err = %v, want %s", err, tc.wantCode)
+			}
+			if len(repo.deleted) > 0 {
+				t.Errorf("deleted %v", repo.deleted)
+			}
+		})
+	}
+}
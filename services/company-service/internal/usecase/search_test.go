@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+// searchRepo finds branches 1 to 4. Branch 1 has employees 10 and 11,
+// branch 2 has employee 20, branch 3 has nobody and branch 4 has more
+// employees than are checked.
+type searchRepo struct{}
+
+func (searchRepo) SearchBranches(context.Context, domain.BranchSearch, pagination.Request) (pagination.Page[domain.BranchMatch], error) {
+	return pagination.Page[domain.BranchMatch]{Items: []domain.BranchMatch{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}}, nil
+}
+
+func (searchRepo) BranchOffers(_ context.Context, branchID uint, _ domain.BranchSearch, limit int) ([]domain.Offer, error) {
+	switch branchID {
+	case 1:
+		return []domain.Offer{{EmployeeID: 10, ServiceID: 5}, {EmployeeID: 11, ServiceID: 5}}, nil
+	case 2:
+		return []domain.Offer{{EmployeeID: 20, ServiceID: 6}}, nil
+	case 4:
+		offers := make([]domain.Offer, limit)
+		for i := range offers {
+			offers[i] = domain.Offer{EmployeeID: 40 + uint(i), ServiceID: 7}
+		}
+		return offers, nil
+	}
+	return nil, nil
+}
+
+func TestSearchBranchesFreeSlots(t *testing.T) {
+	day := time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+	slots := map[uint][]clients.Slot{
+		10: {{IsFree: false}},
+		11: {{IsFree: false}, {IsFree: true}},
+		20: {{IsFree: false}},
+	}
+	tests := []struct {
+		name    string
+		date    time.Time
+		booking *fake.Booking
+		want    map[uint]*bool
+	}{
+		{name: "no date", booking: &fake.Booking{Slots: slots}, want: map[uint]*bool{1: nil, 2: nil, 3: nil, 4: nil}},
+		{name: "date", date: day, booking: &fake.Booking{Slots: slots}, want: map[uint]*bool{1: ptr(true), 2: ptr(false), 3: ptr(false), 4: nil}},
+		{name: "booking down", date: day, booking: &fake.Booking{Err: errors.New("down")}, want: map[uint]*bool{1: nil, 2: nil, 3: ptr(false), 4: nil}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := NewSearchUsecase(searchRepo{}, tc.booking, time.Second)
+
+			page, err := u.SearchBranches(context.Background(), domain.BranchSearch{}, tc.date, pagination.Request{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, match := range page.Items {
+				got, want := match.HasFreeSlots, tc.want[match.ID]
+				if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+					t.Errorf("branch %d: has_free_slots = %v, want %v", match.ID, show(got), show(want))
+				}
+			}
+		})
+	}
+}
+
+func ptr(b bool) *bool { return &b }
+
+func show(b *bool) any {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/pagination"
+	"github.com/vipos89/timehub/services/company-service/internal/domain"
+)
+
+const (
+	// offersPerBranch bounds the booking-service calls made for one branch
+	// when looking for free slots.
+	offersPerBranch = 10
+	// searchConcurrency is how many branches are checked at once.
+	searchConcurrency = 4
+	// slotCallBudget bounds the booking-service calls made for one search.
+	slotCallBudget = 50
+)
+
+type searchUsecase struct {
+	repo           domain.SearchRepository
+	booking        clients.BookingClient
+	contextTimeout time.Duration
+}
+
+func NewSearchUsecase(repo domain.SearchRepository, booking clients.BookingClient, timeout time.Duration) domain.SearchUsecase {
+	return &searchUsecase{
+		repo:           repo,
+		booking:        booking,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *searchUsecase) SearchBranches(ctx context.Context, search domain.BranchSearch, date time.Time, page pagination.Request) (pagination.Page[domain.BranchMatch], error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	result, err := u.repo.SearchBranches(ctx, search, page)
+	if err != nil || date.IsZero() {
+		return result, err
+	}
+
+	// Branches are checked in parallel, sharing one budget of calls; one that
+	// cannot be checked is returned without HasFreeSlots rather than failing
+	// the search.
+	var budget atomic.Int64
+	budget.Store(slotCallBudget)
+	var g errgroup.Group
+	g.SetLimit(searchConcurrency)
+	for i := range result.Items {
+		match := &result.Items[i]
+		g.Go(func() error {
+			free, err := u.hasFreeSlots(ctx, match.ID, search, date, &budget)
+			if err != nil {
+				logger.FromContext(ctx).Warn("Free slot check failed", "branch_id", match.ID, "error", err)
+				return nil
+			}
+			match.HasFreeSlots = free
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return result, nil
+}
+
+// hasFreeSlots returns nil when it cannot tell: the branch has more offers
+// than are checked, or the budget ran out before a free slot was found.
+func (u *searchUsecase) hasFreeSlots(ctx context.Context, branchID uint, search domain.BranchSearch, date time.Time, budget *atomic.Int64) (*bool, error) {
+	offers, err := u.repo.BranchOffers(ctx, branchID, search, offersPerBranch+1)
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(offers) > offersPerBranch
+	if truncated {
+		offers = offers[:offersPerBranch]
+	}
+	for _, offer := range offers {
+		if budget.Add(-1) < 0 {
+			return nil, nil
+		}
+		choice := clients.ServiceChoice{ServiceID: offer.ServiceID, VariantID: offer.VariantID}
+		slots, err := u.booking.GetSlots(ctx, offer.EmployeeID, choice, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			if slot.IsFree {
+				free := true
+				return &free, nil
+			}
+		}
+	}
+	if truncated {
+		return nil, nil
+	}
+	free := false
+	return &free, nil
+}
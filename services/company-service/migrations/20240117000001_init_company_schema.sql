@@ -1,19 +1,85 @@
 -- +goose Up
-CREATE TABLE companies (
-    id SERIAL PRIMARY KEY,
-    name VARCHAR(255) NOT NULL,
-    owner_id INT NOT NULL, -- references users(id) in auth service (logical link)
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+-- Written with IF NOT EXISTS so databases previously created by GORM AutoMigrate are adopted as-is.
+CREATE TABLE IF NOT EXISTS companies (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    tax_id TEXT,
+    owner_id BIGINT NOT NULL, -- references users(id) in auth service (logical link)
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
 );
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS tax_id TEXT;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_companies_deleted_at ON companies (deleted_at);
 
-CREATE TABLE branches (
-    id SERIAL PRIMARY KEY,
-    company_id INT NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
-    name VARCHAR(255) NOT NULL,
+CREATE TABLE IF NOT EXISTS branches (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id),
+    name TEXT NOT NULL,
     address TEXT,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    phone TEXT,
+    is_main BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_branches_deleted_at ON branches (deleted_at);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id),
+    branch_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_categories_branch_id ON categories (branch_id);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS services (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL REFERENCES companies(id),
+    branch_id BIGINT NOT NULL,
+    category_id BIGINT REFERENCES categories(id),
+    name TEXT NOT NULL,
+    description TEXT,
+    price NUMERIC NOT NULL DEFAULT 0,
+    duration_minutes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_services_branch_id ON services (branch_id);
+CREATE INDEX IF NOT EXISTS idx_services_deleted_at ON services (deleted_at);
+
+CREATE TABLE IF NOT EXISTS employees (
+    id BIGSERIAL PRIMARY KEY,
+    branch_id BIGINT NOT NULL REFERENCES branches(id),
+    user_id BIGINT, -- references users(id) in auth service (logical link)
+    name TEXT NOT NULL,
+    position TEXT,
+    avatar_url TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_employees_deleted_at ON employees (deleted_at);
+
+CREATE TABLE IF NOT EXISTS employee_services (
+    employee_id BIGINT NOT NULL REFERENCES employees(id),
+    service_id BIGINT NOT NULL REFERENCES services(id),
+    price NUMERIC NOT NULL,
+    duration_minutes BIGINT NOT NULL,
+    PRIMARY KEY (employee_id, service_id)
 );
 
 -- +goose Down
-DROP TABLE branches;
-DROP TABLE companies;
+DROP TABLE IF EXISTS employee_services;
+DROP TABLE IF EXISTS employees;
+DROP TABLE IF EXISTS services;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS branches;
+DROP TABLE IF EXISTS companies;
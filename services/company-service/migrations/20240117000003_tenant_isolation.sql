@@ -0,0 +1,64 @@
+-- +goose Up
+-- Every tenant-owned table carries company_id so queries can be scoped without joins.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS company_id BIGINT NOT NULL DEFAULT 0;
+UPDATE employees e SET company_id = b.company_id FROM branches b WHERE e.branch_id = b.id AND e.company_id = 0;
+CREATE INDEX IF NOT EXISTS idx_employees_company_id ON employees (company_id);
+
+ALTER TABLE employee_services ADD COLUMN IF NOT EXISTS company_id BIGINT NOT NULL DEFAULT 0;
+UPDATE employee_services es SET company_id = e.company_id FROM employees e WHERE es.employee_id = e.id AND es.company_id = 0;
+
+CREATE INDEX IF NOT EXISTS idx_branches_company_id ON branches (company_id);
+CREATE INDEX IF NOT EXISTS idx_categories_company_id ON categories (company_id);
+CREATE INDEX IF NOT EXISTS idx_services_company_id ON services (company_id);
+
+-- Row-level security: a session sees only the rows of the company in
+-- app.company_id and none while it is unset. Roles with BYPASSRLS (the
+-- database.system_role used for migrations and cross-company jobs, or a
+-- superuser) see everything; with database.row_level_security off the
+-- service must connect as such a role.
+ALTER TABLE branches ENABLE ROW LEVEL SECURITY;
+ALTER TABLE branches FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON branches;
+CREATE POLICY tenant_isolation ON branches USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE categories ENABLE ROW LEVEL SECURITY;
+ALTER TABLE categories FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON categories;
+CREATE POLICY tenant_isolation ON categories USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE services ENABLE ROW LEVEL SECURITY;
+ALTER TABLE services FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON services;
+CREATE POLICY tenant_isolation ON services USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE employees ENABLE ROW LEVEL SECURITY;
+ALTER TABLE employees FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON employees;
+CREATE POLICY tenant_isolation ON employees USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE employee_services ENABLE ROW LEVEL SECURITY;
+ALTER TABLE employee_services FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON employee_services;
+CREATE POLICY tenant_isolation ON employee_services USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP POLICY IF EXISTS tenant_isolation ON employee_services;
+DROP POLICY IF EXISTS tenant_isolation ON employees;
+DROP POLICY IF EXISTS tenant_isolation ON services;
+DROP POLICY IF EXISTS tenant_isolation ON categories;
+DROP POLICY IF EXISTS tenant_isolation ON branches;
+ALTER TABLE employee_services DISABLE ROW LEVEL SECURITY;
+ALTER TABLE employees DISABLE ROW LEVEL SECURITY;
+ALTER TABLE services DISABLE ROW LEVEL SECURITY;
+ALTER TABLE categories DISABLE ROW LEVEL SECURITY;
+ALTER TABLE branches DISABLE ROW LEVEL SECURITY;
+DROP INDEX IF EXISTS idx_services_company_id;
+DROP INDEX IF EXISTS idx_categories_company_id;
+DROP INDEX IF EXISTS idx_branches_company_id;
+ALTER TABLE employee_services DROP COLUMN IF EXISTS company_id;
+ALTER TABLE employees DROP COLUMN IF EXISTS company_id;
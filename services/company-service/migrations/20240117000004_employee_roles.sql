@@ -0,0 +1,11 @@
+-- +goose Up
+-- An employee's role decides what they may change in their company; the
+-- company owner is not an employee and may change everything.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'master';
+CREATE INDEX IF NOT EXISTS idx_employees_user_id ON employees (user_id);
+CREATE INDEX IF NOT EXISTS idx_companies_owner_id ON companies (owner_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_companies_owner_id;
+DROP INDEX IF EXISTS idx_employees_user_id;
+ALTER TABLE employees DROP COLUMN IF EXISTS role;
@@ -0,0 +1,48 @@
+-- +goose Up
+-- Branches keep their wall clock: slots are built in the branch timezone and
+-- only while the branch is open.
+ALTER TABLE branches ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE branches ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE branches ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS opening_hours (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    branch_id BIGINT NOT NULL REFERENCES branches (id),
+    day_of_week INT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    open_time TEXT NOT NULL,
+    close_time TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_opening_hours_branch_day ON opening_hours (branch_id, day_of_week);
+CREATE INDEX IF NOT EXISTS idx_opening_hours_company_id ON opening_hours (company_id);
+
+CREATE TABLE IF NOT EXISTS hours_exceptions (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    branch_id BIGINT NOT NULL REFERENCES branches (id),
+    date DATE NOT NULL,
+    is_closed BOOLEAN NOT NULL DEFAULT false,
+    open_time TEXT,
+    close_time TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hours_exceptions_branch_date ON hours_exceptions (branch_id, date);
+CREATE INDEX IF NOT EXISTS idx_hours_exceptions_company_id ON hours_exceptions (company_id);
+
+ALTER TABLE opening_hours ENABLE ROW LEVEL SECURITY;
+ALTER TABLE opening_hours FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON opening_hours;
+CREATE POLICY tenant_isolation ON opening_hours USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE hours_exceptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE hours_exceptions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON hours_exceptions;
+CREATE POLICY tenant_isolation ON hours_exceptions USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP TABLE IF EXISTS hours_exceptions;
+DROP TABLE IF EXISTS opening_hours;
+ALTER TABLE branches DROP COLUMN IF EXISTS longitude;
+ALTER TABLE branches DROP COLUMN IF EXISTS latitude;
+ALTER TABLE branches DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Nearby search narrows candidates with a bounding box on the coordinates.
+CREATE INDEX IF NOT EXISTS idx_branches_location ON branches (latitude, longitude)
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_branches_location;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- Variants (one is chosen) and add-ons (any number) change the price and
+-- duration of a service; employees may charge their own deltas.
+CREATE TABLE IF NOT EXISTS service_options (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    service_id BIGINT NOT NULL REFERENCES services (id),
+    kind TEXT NOT NULL CHECK (kind IN ('variant', 'addon')),
+    name TEXT NOT NULL,
+    price_delta NUMERIC NOT NULL DEFAULT 0,
+    duration_delta INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_service_options_service_id ON service_options (service_id);
+CREATE INDEX IF NOT EXISTS idx_service_options_company_id ON service_options (company_id);
+CREATE INDEX IF NOT EXISTS idx_service_options_deleted_at ON service_options (deleted_at);
+
+CREATE TABLE IF NOT EXISTS employee_service_options (
+    employee_id BIGINT NOT NULL REFERENCES employees (id),
+    option_id BIGINT NOT NULL REFERENCES service_options (id),
+    company_id BIGINT NOT NULL,
+    price_delta NUMERIC NOT NULL,
+    duration_delta INT NOT NULL,
+    PRIMARY KEY (employee_id, option_id)
+);
+CREATE INDEX IF NOT EXISTS idx_employee_service_options_company_id ON employee_service_options (company_id);
+
+ALTER TABLE service_options ENABLE ROW LEVEL SECURITY;
+ALTER TABLE service_options FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON service_options;
+CREATE POLICY tenant_isolation ON service_options USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE employee_service_options ENABLE ROW LEVEL SECURITY;
+ALTER TABLE employee_service_options FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON employee_service_options;
+CREATE POLICY tenant_isolation ON employee_service_options USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP TABLE IF EXISTS employee_service_options;
+DROP TABLE IF EXISTS service_options;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Bundles sell services of a branch together at one price; their items are
+-- booked back to back in position order.
+CREATE TABLE IF NOT EXISTS bundles (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    branch_id BIGINT NOT NULL REFERENCES branches (id),
+    name TEXT NOT NULL,
+    description TEXT,
+    price NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_bundles_company_id ON bundles (company_id);
+CREATE INDEX IF NOT EXISTS idx_bundles_branch_id ON bundles (branch_id);
+CREATE INDEX IF NOT EXISTS idx_bundles_deleted_at ON bundles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bundle_items (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    bundle_id BIGINT NOT NULL REFERENCES bundles (id),
+    position INT NOT NULL,
+    service_id BIGINT NOT NULL REFERENCES services (id),
+    variant_id BIGINT REFERENCES service_options (id),
+    gap_minutes INT NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bundle_items_bundle_position ON bundle_items (bundle_id, position);
+CREATE INDEX IF NOT EXISTS idx_bundle_items_company_id ON bundle_items (company_id);
+
+ALTER TABLE bundles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bundles FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON bundles;
+CREATE POLICY tenant_isolation ON bundles USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE bundle_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bundle_items FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON bundle_items;
+CREATE POLICY tenant_isolation ON bundle_items USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP TABLE IF EXISTS bundle_items;
+DROP TABLE IF EXISTS bundles;
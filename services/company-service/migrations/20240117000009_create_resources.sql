@@ -0,0 +1,62 @@
+-- +goose Up
+-- Rooms, chairs and devices a branch books besides its staff. Services
+-- require a number of resources of a type for their whole length.
+CREATE TABLE IF NOT EXISTS resource_types (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    branch_id BIGINT NOT NULL REFERENCES branches (id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_resource_types_company_id ON resource_types (company_id);
+CREATE INDEX IF NOT EXISTS idx_resource_types_branch_id ON resource_types (branch_id);
+CREATE INDEX IF NOT EXISTS idx_resource_types_deleted_at ON resource_types (deleted_at);
+
+CREATE TABLE IF NOT EXISTS branch_resources (
+    id BIGSERIAL PRIMARY KEY,
+    company_id BIGINT NOT NULL,
+    branch_id BIGINT NOT NULL REFERENCES branches (id),
+    type_id BIGINT NOT NULL REFERENCES resource_types (id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_branch_resources_company_id ON branch_resources (company_id);
+CREATE INDEX IF NOT EXISTS idx_branch_resources_branch_id ON branch_resources (branch_id);
+CREATE INDEX IF NOT EXISTS idx_branch_resources_type_id ON branch_resources (type_id);
+CREATE INDEX IF NOT EXISTS idx_branch_resources_deleted_at ON branch_resources (deleted_at);
+
+CREATE TABLE IF NOT EXISTS service_requirements (
+    service_id BIGINT NOT NULL REFERENCES services (id),
+    type_id BIGINT NOT NULL REFERENCES resource_types (id),
+    company_id BIGINT NOT NULL,
+    quantity INT NOT NULL DEFAULT 1,
+    PRIMARY KEY (service_id, type_id)
+);
+CREATE INDEX IF NOT EXISTS idx_service_requirements_type_id ON service_requirements (type_id);
+
+ALTER TABLE resource_types ENABLE ROW LEVEL SECURITY;
+ALTER TABLE resource_types FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON resource_types;
+CREATE POLICY tenant_isolation ON resource_types USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE branch_resources ENABLE ROW LEVEL SECURITY;
+ALTER TABLE branch_resources FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON branch_resources;
+CREATE POLICY tenant_isolation ON branch_resources USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+ALTER TABLE service_requirements ENABLE ROW LEVEL SECURITY;
+ALTER TABLE service_requirements FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON service_requirements;
+CREATE POLICY tenant_isolation ON service_requirements USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP TABLE IF EXISTS service_requirements;
+DROP TABLE IF EXISTS branch_resources;
+DROP TABLE IF EXISTS resource_types;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Employees may work at several branches of their company; employees.branch_id
+-- stays their primary branch and is always assigned too.
+CREATE TABLE IF NOT EXISTS employee_branches (
+    employee_id BIGINT NOT NULL REFERENCES employees (id),
+    branch_id BIGINT NOT NULL REFERENCES branches (id),
+    company_id BIGINT NOT NULL,
+    PRIMARY KEY (employee_id, branch_id)
+);
+CREATE INDEX IF NOT EXISTS idx_employee_branches_branch_id ON employee_branches (branch_id);
+CREATE INDEX IF NOT EXISTS idx_employee_branches_company_id ON employee_branches (company_id);
+
+INSERT INTO employee_branches (employee_id, branch_id, company_id)
+SELECT id, branch_id, company_id FROM employees
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE employee_branches ENABLE ROW LEVEL SECURITY;
+ALTER TABLE employee_branches FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON employee_branches;
+CREATE POLICY tenant_isolation ON employee_branches USING (
+    company_id = nullif(current_setting('app.company_id', true), '')::bigint);
+
+-- +goose Down
+DROP TABLE IF EXISTS employee_branches;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Catalog imports match rows by an external key: categories and services
+-- within their branch, employees within their company.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS external_key TEXT;
+ALTER TABLE services ADD COLUMN IF NOT EXISTS external_key TEXT;
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS external_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_external_key ON categories (branch_id, external_key)
+    WHERE external_key IS NOT NULL AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_services_external_key ON services (branch_id, external_key)
+    WHERE external_key IS NOT NULL AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_external_key ON employees (company_id, external_key)
+    WHERE external_key IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_employees_external_key;
+DROP INDEX IF EXISTS idx_services_external_key;
+DROP INDEX IF EXISTS idx_categories_external_key;
+ALTER TABLE employees DROP COLUMN IF EXISTS external_key;
+ALTER TABLE services DROP COLUMN IF EXISTS external_key;
+ALTER TABLE categories DROP COLUMN IF EXISTS external_key;
@@ -0,0 +1,25 @@
+// Package app holds the crm-service event consumers, so they can run in
+// their own binary or inside the all-in-one timehub binary.
+package app
+
+import (
+	"context"
+
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/logger"
+)
+
+// ConsumeBookings handles booking events under the consumer group
+// "<service>.bookings" until ctx is cancelled.
+func ConsumeBookings(bus events.Subscriber, service string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return bus.Subscribe(ctx, service+".bookings", func(ctx context.Context, env events.Envelope) error {
+			var booking events.BookingCreated
+			if err := env.Decode(&booking); err != nil {
+				return err
+			}
+			logger.Info("Booking received", "event_id", env.ID, "appointment_id", booking.AppointmentID, "client_id", booking.ClientID)
+			return nil
+		}, "booking.*")
+	}
+}
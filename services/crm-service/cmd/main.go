@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/labstack/echo/v4"
@@ -8,7 +9,14 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 
 	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/health"
 	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/metrics"
+	customMiddleware "github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/server"
+	"github.com/vipos89/timehub/pkg/tracing"
+	"github.com/vipos89/timehub/services/crm-service/app"
 	_ "github.com/vipos89/timehub/services/crm-service/docs" // for swagger docs
 )
 
@@ -18,25 +26,56 @@ import (
 // @host localhost:8084
 // @BasePath /
 func main() {
-	logger.Init()
-	cfg := config.Load()
+	cfg := config.MustLoad("crm-service")
+	logger.Init(cfg.Log)
+	logger.Info("Configuration loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to init tracing", "error", err)
+		log.Fatalf("Failed to init tracing: %v", err)
+	}
+
+	srv := server.New(cfg.Service, cfg.Timeouts.Shutdown, cfg.Timeouts.ShutdownDelay)
+	srv.OnShutdown("tracing", shutdownTracing)
+
+	// Event Bus
+	bus, err := events.Open(cfg.Broker)
+	if err != nil {
+		logger.Error("Failed to connect to broker", "error", err)
+		log.Fatalf("Failed to connect to broker: %v", err)
+	}
+	srv.OnShutdown("broker", func(context.Context) error { return bus.Close() })
+	srv.Worker("booking-events", app.ConsumeBookings(bus, cfg.Service))
 
 	e := echo.New()
+	e.HTTPErrorHandler = customMiddleware.ErrorHandler
+	e.Validator = customMiddleware.NewValidator()
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Use(tracing.Middleware(cfg.Service))
+	e.Use(middleware.RequestID())
+	e.Use(metrics.Middleware(cfg.Service))
 	e.Use(middleware.Recover())
+	e.Use(customMiddleware.RequestLogger)
 
 	// Swagger
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	// Health Check
-	e.GET("/health", func(c echo.Context) error {
-		return c.String(200, "CRM Service is health")
-	})
+	// Metrics
+	e.GET("/metrics", metrics.Handler())
+
+	// Health Checks
+	checks := health.NewRegistry(cfg.Service, cfg.Timeouts.HealthCheck)
+	if cfg.Broker.URL != "" {
+		checks.RegisterOptional("broker", health.TCP(cfg.Broker.URL.Reveal()))
+	}
+	checks.Mount(e)
+
+	srv.HTTP(e, ":"+cfg.HTTP.Port).Health(checks)
 
-	logger.Info("Starting CRM Service", "port", cfg.HTTPPort)
-	if err := e.Start(":" + cfg.HTTPPort); err != nil {
+	logger.Info("Starting CRM Service", "port", cfg.HTTP.Port)
+	if err := srv.Run(); err != nil {
 		logger.Error("Server failed", "error", err)
 		log.Fatalf("Server failed: %v", err)
 	}
@@ -0,0 +1,160 @@
+// Command timehub runs every TimeHub service in one process for small
+// deployments: auth, company, booking, CRM and report share one HTTP server
+// (the gateway's routes map one-to-one onto it), one Postgres database with a
+// schema per service and the in-memory event bus. Services still call each
+// other through pkg/clients, served in-process instead of over the network.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/db"
+	"github.com/vipos89/timehub/pkg/events"
+	"github.com/vipos89/timehub/pkg/health"
+	"github.com/vipos89/timehub/pkg/identity"
+	"github.com/vipos89/timehub/pkg/logger"
+	"github.com/vipos89/timehub/pkg/metrics"
+	customMiddleware "github.com/vipos89/timehub/pkg/middleware"
+	"github.com/vipos89/timehub/pkg/outbox"
+	"github.com/vipos89/timehub/pkg/server"
+	"github.com/vipos89/timehub/pkg/tenant"
+	"github.com/vipos89/timehub/pkg/tracing"
+
+	auth "github.com/vipos89/timehub/services/auth-service/app"
+	booking "github.com/vipos89/timehub/services/booking-service/app"
+	company "github.com/vipos89/timehub/services/company-service/app"
+	crm "github.com/vipos89/timehub/services/crm-service/app"
+	report "github.com/vipos89/timehub/services/report-service/app"
+	"github.com/vipos89/timehub/services/timehub/storage"
+)
+
+// inProcessURL is the base URL services use to reach each other; the
+// in-process transport ignores the host.
+const inProcessURL = "http://timehub"
+
+func main() {
+	cfg := config.MustLoad("timehub")
+	logger.Init(cfg.Log)
+	logger.Info("Configuration loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Service)
+	if err != nil {
+		logger.Error("Failed to init tracing", "error", err)
+		log.Fatalf("Failed to init tracing: %v", err)
+	}
+
+	srv := server.New(cfg.Service, cfg.Timeouts.Shutdown, cfg.Timeouts.ShutdownDelay)
+	srv.OnShutdown("tracing", shutdownTracing)
+
+	checks := health.NewRegistry(cfg.Service, cfg.Timeouts.HealthCheck)
+
+	// One schema per service keeps table names and migration history apart.
+	migrateOnly := len(cfg.Args) > 0 && cfg.Args[0] == "migrate"
+	authDB := openSchema(cfg, srv, checks, storage.Auth, migrateOnly)
+	companyDB := openSchema(cfg, srv, checks, storage.Company, migrateOnly)
+	bookingDB := openSchema(cfg, srv, checks, storage.Booking, migrateOnly)
+	if migrateOnly {
+		if err := srv.Close(); err != nil {
+			logger.Error("Failed to release resources", "error", err)
+		}
+		return
+	}
+	logger.Info("Connected to database")
+
+	// Event Bus: everything runs in this process, so no broker is needed.
+	bus := events.NewMemory(events.Options{
+		MaxAttempts: cfg.Broker.MaxAttempts,
+		RetryDelay:  cfg.Broker.RetryDelay,
+	})
+	srv.OnShutdown("broker", func(context.Context) error { return bus.Close() })
+	srv.Worker("company-outbox", outbox.NewRelay(companyDB, bus, "company-service", cfg.Outbox).Run)
+	srv.Worker("booking-outbox", outbox.NewRelay(bookingDB, bus, "booking-service", cfg.Outbox).Run)
+	srv.Worker("crm-booking-events", crm.ConsumeBookings(bus, "crm-service"))
+	srv.Worker("report-booking-events", report.ConsumeBookings(bus, "report-service"))
+	srv.Worker("booking-company-events", booking.ConsumeCompanyEvents(bus, bookingDB, "booking-service", cfg.Timeouts.Context))
+
+	e := echo.New()
+	e.HTTPErrorHandler = customMiddleware.ErrorHandler
+	e.Validator = customMiddleware.NewValidator()
+
+	// Middleware
+	e.Use(tracing.Middleware(cfg.Service))
+	e.Use(middleware.RequestID())
+	e.Use(metrics.Middleware(cfg.Service))
+	e.Use(middleware.Recover())
+	e.Use(customMiddleware.RequestLogger)
+	e.Use(middleware.CORS())
+	// There is no gateway in front, so tokens are verified here; calls
+	// between the services keep the identity headers they signed.
+	e.Use(identity.Authenticate(auth.Verifier(authDB, cfg.Auth, cfg.Timeouts.Context), cfg.Auth.IdentitySecret.Reveal()))
+	e.Use(tenant.Middleware)
+	e.Use(clients.ForwardHeaders)
+	e.Use(customMiddleware.PanicRecovery)
+
+	// Handlers
+	opts := clients.OptionsFrom(cfg.Upstreams)
+	opts.Transport = clients.InProcess(e)
+	auth.Mount(e, authDB, cfg.Auth, clients.NewCompany(inProcessURL, opts), cfg.Timeouts.Context)
+	company.Mount(e, companyDB, clients.NewAuth(inProcessURL, opts), clients.NewBooking(inProcessURL, opts), cfg.Timeouts.Context)
+	booking.Mount(e, bookingDB, clients.NewCompany(inProcessURL, opts), cfg.Timeouts.Context)
+
+	// Metrics
+	e.GET("/metrics", metrics.Handler())
+
+	// Health Checks; /health/all keeps the gateway's aggregated endpoint.
+	checks.Mount(e)
+	e.GET("/health/all", checks.Aggregate)
+
+	srv.HTTP(e, ":"+cfg.HTTP.Port).Health(checks)
+
+	logger.Info("Starting TimeHub", "port", cfg.HTTP.Port)
+	if err := srv.Run(); err != nil {
+		logger.Error("Server failed", "error", err)
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// openSchema connects to a service's schema and brings it up to date, or
+// runs the migrate command given on the command line against it.
+func openSchema(cfg *config.Config, srv *server.Runner, checks *health.Registry, schema storage.Schema, migrateOnly bool) *gorm.DB {
+	database, err := schema.Open(cfg.Database)
+	if err != nil {
+		logger.Error("Failed to connect to database", "schema", schema.Name, "error", err)
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	sqlDB, _ := database.DB()
+	srv.OnShutdown("postgres-"+schema.Name, func(context.Context) error { return sqlDB.Close() })
+	if err := metrics.RegisterDB(sqlDB, schema.Service); err != nil {
+		logger.Error("Failed to register DB metrics", "schema", schema.Name, "error", err)
+	}
+	checks.Register("postgres-"+schema.Name, health.DB(sqlDB))
+
+	migrator, err := schema.Migrator(sqlDB, cfg.Database)
+	if err != nil {
+		logger.Error("Failed to load migrations", "schema", schema.Name, "error", err)
+		log.Fatal(err)
+	}
+	if migrateOnly {
+		if err := db.RunMigrateCommand(context.Background(), migrator, cfg.Args[1:], os.Stdout); err != nil {
+			srv.Close()
+			log.Fatalf("Migration of %s failed: %v", schema.Name, err)
+		}
+		return database
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		logger.Error("Failed to migrate database", "schema", schema.Name, "error", err)
+		log.Fatal(err)
+	}
+	logger.Info("Database schema is up to date", "schema", schema.Name, "applied", len(applied))
+	return database
+}
@@ -0,0 +1,23 @@
+module github.com/vipos89/timehub/services/timehub
+
+go 1.24.0
+
+require (
+	github.com/labstack/echo/v4 v4.15.0
+	github.com/vipos89/timehub/pkg v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/auth-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/booking-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/company-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/crm-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/report-service v0.0.0-00010101000000-000000000000
+	gorm.io/gorm v1.31.1
+)
+
+replace (
+	github.com/vipos89/timehub/pkg => ../../pkg
+	github.com/vipos89/timehub/services/auth-service => ../auth-service
+	github.com/vipos89/timehub/services/booking-service => ../booking-service
+	github.com/vipos89/timehub/services/company-service => ../company-service
+	github.com/vipos89/timehub/services/crm-service => ../crm-service
+	github.com/vipos89/timehub/services/report-service => ../report-service
+)
@@ -0,0 +1,51 @@
+// Package storage describes how the all-in-one deployment lays the services
+// out in one Postgres database: each service keeps its tables, outbox and
+// migration history in its own schema.
+package storage
+
+import (
+	"database/sql"
+	"io/fs"
+
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/db"
+
+	auth "github.com/vipos89/timehub/services/auth-service/app"
+	booking "github.com/vipos89/timehub/services/booking-service/app"
+	company "github.com/vipos89/timehub/services/company-service/app"
+)
+
+// Schema is one service's part of the shared database.
+type Schema struct {
+	// Service names the migration lock and DB metrics, as in the standalone service.
+	Service    string
+	Name       string
+	Migrations fs.FS
+}
+
+var (
+	Auth    = Schema{Service: "auth-service", Name: "auth", Migrations: auth.Migrations}
+	Company = Schema{Service: "company-service", Name: "company", Migrations: company.Migrations}
+	Booking = Schema{Service: "booking-service", Name: "booking", Migrations: booking.Migrations}
+)
+
+// Schemas lists every schema in migration order.
+var Schemas = []Schema{Auth, Company, Booking}
+
+// Open connects to the schema, creating it if needed.
+func (s Schema) Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	cfg.Schema = s.Name
+	return db.Open(cfg)
+}
+
+// Migrator returns the schema's migrator on a connection made by Open,
+// running as the configured system role.
+func (s Schema) Migrator(sqlDB *sql.DB, cfg config.DatabaseConfig) (*db.Migrator, error) {
+	m, err := db.NewMigrator(sqlDB, s.Migrations, s.Service)
+	if err != nil {
+		return nil, err
+	}
+	return m.WithRole(cfg.SystemRole), nil
+}
@@ -0,0 +1,35 @@
+// Command timehubctl runs routine operations against a TimeHub deployment:
+// creating companies, rotating signing keys, running migrations, checking
+// availability and importing catalogs.
+//
+//	timehubctl --upstreams.auth=http://auth:8080 --upstreams.company=http://company:8080 \
+//		company create --name "Salon" --owner-email owner@example.com
+//	timehubctl --database.url=$DATABASE_URL migrate --all-in-one --dry-run up
+//
+// Global flags are the usual config flags (see --help); command flags follow
+// the command name.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/services/timehubctl/internal/cli"
+)
+
+func main() {
+	cfg := config.MustLoad("timehubctl")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cli.NewEnv(cfg, os.Stdout).Run(ctx, cfg.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "timehubctl: %v\n", err)
+		stop()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/vipos89/timehub/services/timehubctl
+
+go 1.24.0
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/vipos89/timehub/pkg v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/auth-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/booking-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/company-service v0.0.0-00010101000000-000000000000
+	github.com/vipos89/timehub/services/timehub v0.0.0-00010101000000-000000000000
+	gorm.io/driver/postgres v1.6.0
+	gorm.io/gorm v1.31.1
+)
+
+replace (
+	github.com/vipos89/timehub/pkg => ../../pkg
+	github.com/vipos89/timehub/services/auth-service => ../auth-service
+	github.com/vipos89/timehub/services/booking-service => ../booking-service
+	github.com/vipos89/timehub/services/company-service => ../company-service
+	github.com/vipos89/timehub/services/crm-service => ../crm-service
+	github.com/vipos89/timehub/services/report-service => ../report-service
+	github.com/vipos89/timehub/services/timehub => ../timehub
+)
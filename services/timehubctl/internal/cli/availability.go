@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/clients"
+)
+
+const dateLayout = "2006-01-02"
+
+// availabilityRow is the free capacity of one employee and service on a day.
+type availabilityRow struct {
+	EmployeeID uint   `json:"employee_id"`
+	Employee   string `json:"employee"`
+	ServiceID  uint   `json:"service_id"`
+	Date       string `json:"date"`
+	FreeSlots  int    `json:"free_slots"`
+	TotalSlots int    `json:"total_slots"`
+}
+
+// availabilityReindex recomputes the slots of every employee of a company
+// for each service on their menu and day in range. Booking-service derives
+// slots from shifts and appointments on every read rather than storing
+// them, so there is no index to rebuild: the command recomputes it on
+// demand to check a company's availability after bulk changes (imported
+// shifts, edited menus) and reports the result.
+func availabilityReindex(ctx context.Context, env *Env, args []string) error {
+	f := newFlags("availability reindex", false)
+	companyID := f.Uint("company-id", 0, "company whose employees are checked (required)")
+	today := time.Now().Format(dateLayout)
+	from := f.String("from", today, "first day, YYYY-MM-DD")
+	to := f.String("to", "", "last day, YYYY-MM-DD; defaults to a week after --from")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if *companyID == 0 {
+		return errors.New("--company-id is required")
+	}
+	start, err := time.Parse(dateLayout, *from)
+	if err != nil {
+		return errors.New("invalid --from: want YYYY-MM-DD")
+	}
+	end := start.AddDate(0, 0, 6)
+	if *to != "" {
+		if end, err = time.Parse(dateLayout, *to); err != nil {
+			return errors.New("invalid --to: want YYYY-MM-DD")
+		}
+	}
+	if end.Before(start) {
+		return errors.New("--to is before --from")
+	}
+
+	employees, err := env.Company.GetEmployees(ctx, uint(*companyID))
+	if err != nil {
+		return err
+	}
+	rows := []availabilityRow{}
+	for _, e := range employees {
+		menu, err := env.Company.GetEmployeeMenu(ctx, e.ID)
+		if err != nil {
+			return err
+		}
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			for _, item := range menu {
+				choice := clients.ServiceChoice{ServiceID: item.ServiceID}
+				if item.Service != nil {
+					choice = item.Service.FirstChoice()
+				}
+				slots, err := env.Booking.GetSlots(ctx, e.ID, choice, day)
+				if err != nil {
+					return err
+				}
+				row := availabilityRow{EmployeeID: e.ID, Employee: e.Name, ServiceID: item.ServiceID, Date: day.Format(dateLayout), TotalSlots: len(slots)}
+				for _, s := range slots {
+					if s.IsFree {
+						row.FreeSlots++
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	table := make([][]string, len(rows))
+	for i, r := range rows {
+		table[i] = []string{id(r.EmployeeID), r.Employee, id(r.ServiceID), r.Date, strconv.Itoa(r.FreeSlots), strconv.Itoa(r.TotalSlots)}
+	}
+	return f.print(env.Out, rows, []string{"EMPLOYEE ID", "EMPLOYEE", "SERVICE ID", "DATE", "FREE", "TOTAL"}, table)
+}
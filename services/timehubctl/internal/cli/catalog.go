@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/middleware"
+)
+
+// catalogFile is the JSON accepted by "catalog import":
+//
+//	{"categories": [{"name": "Hair", "services": [{"name": "Haircut", "price": 30, "duration_minutes": 45}]}],
+//	 "services": [{"name": "Consultation", "price": 0, "duration_minutes": 15}]}
+//
+// Top-level services have no category.
+type catalogFile struct {
+	Categories []struct {
+		Name     string           `json:"name"`
+		Services []catalogService `json:"services"`
+	} `json:"categories"`
+	Services []catalogService `json:"services"`
+}
+
+type catalogService struct {
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	Price           float64 `json:"price"`
+	DurationMinutes int     `json:"duration_minutes"`
+}
+
+// catalogRow reports what happened to one category or service.
+type catalogRow struct {
+	Kind     string `json:"kind"` // category or service
+	ID       uint   `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Action   string `json:"action"` // created, exists or create (dry run)
+}
+
+// catalogImport adds the categories and services of a file to a branch.
+// Entries whose name already exists in the branch are left as they are, so
+// the import can be repeated.
+func catalogImport(ctx context.Context, env *Env, args []string) error {
+	f := newFlags("catalog import", true)
+	branchID := f.Uint("branch-id", 0, "branch to import into (required)")
+	userID := f.Uint("user-id", 0, "owner or admin of the branch's company, sent as "+middleware.HeaderUserID+" (required)")
+	file := f.String("file", "", "catalog JSON file (required)")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if *branchID == 0 || *file == "" || *userID == 0 {
+		return errors.New("--branch-id, --file and --user-id are required")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	var catalog catalogFile
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return fmt.Errorf("parse %s: %w", *file, err)
+	}
+
+	ctx, err = env.asUser(ctx, uint(*userID))
+	if err != nil {
+		return err
+	}
+	branch := uint(*branchID)
+
+	categories, err := env.Company.GetBranchCategories(ctx, branch)
+	if err != nil {
+		return err
+	}
+	categoryIDs := make(map[string]uint, len(categories))
+	for _, c := range categories {
+		categoryIDs[c.Name] = c.ID
+	}
+	services, err := env.Company.GetBranchServices(ctx, branch)
+	if err != nil {
+		return err
+	}
+	serviceIDs := make(map[string]uint, len(services))
+	for _, s := range services {
+		serviceIDs[s.Name] = s.ID
+	}
+
+	rows := []catalogRow{}
+	addService := func(s catalogService, category string, categoryID *uint) error {
+		row := catalogRow{Kind: "service", Name: s.Name, Category: category}
+		switch id, ok := serviceIDs[s.Name]; {
+		case ok:
+			row.ID, row.Action = id, "exists"
+		case f.dryRun:
+			row.Action = "create"
+		default:
+			created, err := env.Company.AddService(ctx, branch, clients.AddServiceRequest{
+				CategoryID:      categoryID,
+				Name:            s.Name,
+				Description:     s.Description,
+				Price:           s.Price,
+				DurationMinutes: s.DurationMinutes,
+			})
+			if err != nil {
+				return fmt.Errorf("service %q: %w", s.Name, err)
+			}
+			row.ID, row.Action = created.ID, "created"
+			serviceIDs[s.Name] = created.ID
+		}
+		rows = append(rows, row)
+		return nil
+	}
+
+	for _, c := range catalog.Categories {
+		row := catalogRow{Kind: "category", Name: c.Name}
+		switch id, ok := categoryIDs[c.Name]; {
+		case ok:
+			row.ID, row.Action = id, "exists"
+		case f.dryRun:
+			row.Action = "create"
+		default:
+			created, err := env.Company.AddCategory(ctx, branch, c.Name)
+			if err != nil {
+				return fmt.Errorf("category %q: %w", c.Name, err)
+			}
+			row.ID, row.Action = created.ID, "created"
+			categoryIDs[c.Name] = created.ID
+		}
+		rows = append(rows, row)
+
+		var categoryID *uint
+		if row.ID != 0 {
+			categoryID = &row.ID
+		}
+		for _, s := range c.Services {
+			if err := addService(s, c.Name, categoryID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, s := range catalog.Services {
+		if err := addService(s, "", nil); err != nil {
+			return err
+		}
+	}
+
+	table := make([][]string, len(rows))
+	for i, r := range rows {
+		table[i] = []string{r.Kind, id(r.ID), r.Name, r.Category, r.Action}
+	}
+	return f.print(env.Out, rows, []string{"KIND", "ID", "NAME", "CATEGORY", "ACTION"}, table)
+}
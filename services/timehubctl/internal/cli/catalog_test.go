@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/clients/fake"
+)
+
+const catalogJSON = `{
+	"categories": [
+		{"name": "Hair", "services": [{"name": "Haircut", "price": 30, "duration_minutes": 45}, {"name": "Color", "price": 60, "duration_minutes": 90}]},
+		{"name": "Nails", "services": [{"name": "Manicure", "price": 25, "duration_minutes": 40}]}
+	],
+	"services": [{"name": "Consultation", "duration_minutes": 15}]
+}`
+
+// catalogCompany has branch 3 with the Hair category and its Haircut.
+func catalogCompany() *fake.Company {
+	hair := uint(100)
+	return &fake.Company{
+		Categories: map[uint][]clients.Category{3: {{ID: hair, BranchID: 3, Name: "Hair"}}},
+		Services:   map[uint][]clients.Service{3: {{ID: 101, BranchID: 3, CategoryID: &hair, Name: "Haircut"}}},
+	}
+}
+
+func importCatalog(t *testing.T, env *Env, out *bytes.Buffer, extra ...string) []catalogRow {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(file, []byte(catalogJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	args := append([]string{"catalog", "import", "--branch-id", "3", "--user-id", "7", "--file", file, "-o", "json"}, extra...)
+	if err := env.Run(context.Background(), args); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	var rows []catalogRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("output: %v", err)
+	}
+	return rows
+}
+
+func actions(rows []catalogRow) map[string]string {
+	got := make(map[string]string, len(rows))
+	for _, r := range rows {
+		got[r.Kind+" "+r.Name] = r.Action
+	}
+	return got
+}
+
+func TestCatalogImportDryRun(t *testing.T) {
+	env, out := testEnv()
+	company := catalogCompany()
+	env.Company = company
+
+	rows := importCatalog(t, env, out, "--dry-run")
+	want := map[string]string{
+		"category Hair": "exists", "service Haircut": "exists", "service Color": "create",
+		"category Nails": "create", "service Manicure": "create", "service Consultation": "create",
+	}
+	if got := actions(rows); len(rows) != len(want) || !maps.Equal(got, want) {
+		t.Errorf("actions = %v, want %v", got, want)
+	}
+	if len(company.Categories[3]) != 1 || len(company.Services[3]) != 1 {
+		t.Errorf("dry run changed the branch: categories %v, services %v", company.Categories[3], company.Services[3])
+	}
+}
+
+func TestCatalogImport(t *testing.T) {
+	env, out := testEnv()
+	company := catalogCompany()
+	env.Company = company
+
+	rows := importCatalog(t, env, out)
+	want := map[string]string{
+		"category Hair": "exists", "service Haircut": "exists", "service Color": "created",
+		"category Nails": "created", "service Manicure": "created", "service Consultation": "created",
+	}
+	if got := actions(rows); !maps.Equal(got, want) {
+		t.Errorf("actions = %v, want %v", got, want)
+	}
+	if len(company.Categories[3]) != 2 || len(company.Services[3]) != 4 {
+		t.Fatalf("branch has categories %v, services %v", company.Categories[3], company.Services[3])
+	}
+	categories := map[string]uint{}
+	for _, c := range company.Categories[3] {
+		categories[c.Name] = c.ID
+	}
+	for _, s := range company.Services[3] {
+		var want uint
+		switch s.Name {
+		case "Haircut", "Color":
+			want = categories["Hair"]
+		case "Manicure":
+			want = categories["Nails"]
+		}
+		if got := s.CategoryID; want == 0 && got != nil || want != 0 && (got == nil || *got != want) {
+			t.Errorf("service %s in category %v, want %d", s.Name, got, want)
+		}
+	}
+
+	// A second import finds everything in place.
+	for _, r := range importCatalog(t, env, out) {
+		if r.Action != "exists" || r.ID == 0 {
+			t.Errorf("second import: %+v, want it to exist", r)
+		}
+	}
+}
+
+func TestCatalogImportErrors(t *testing.T) {
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing file flag", args: []string{"--branch-id", "3", "--user-id", "7"}, wantErr: "--branch-id, --file and --user-id are required"},
+		{name: "missing user", args: []string{"--branch-id", "3", "--file", bad}, wantErr: "--branch-id, --file and --user-id are required"},
+		{name: "invalid JSON", args: []string{"--branch-id", "3", "--user-id", "7", "--file", bad}, wantErr: "parse " + bad + ": unexpected end of JSON input"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env, _ := testEnv()
+			err := env.Run(context.Background(), append([]string{"catalog", "import"}, tc.args...))
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package cli implements the timehubctl subcommands.
+//
+// Commands reach the services through pkg/clients (company, availability,
+// catalog) or work directly on a service's database (keys, migrate). Every
+// command prints a table by default or JSON with -o json; commands that
+// change data accept --dry-run to show what they would do.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/pkg/db"
+	"github.com/vipos89/timehub/pkg/identity"
+	"github.com/vipos89/timehub/services/timehub/storage"
+)
+
+// command is a subcommand; name is its full path, e.g. "keys rotate".
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, env *Env, args []string) error
+}
+
+var commands = []command{
+	{"company create", "create a company with its owner account", companyCreate},
+	{"keys list", "list token signing keys", keysList},
+	{"keys rotate", "create a new signing key, optionally retiring the others", keysRotate},
+	{"migrate", "apply, revert or inspect database migrations", migrate},
+	{"availability reindex", "recompute free slots of a company's employees", availabilityReindex},
+	{"catalog import", "import categories and services into a branch", catalogImport},
+}
+
+// Env is what commands share: configuration, clients and the output stream.
+type Env struct {
+	Config  *config.Config
+	Out     io.Writer
+	Company clients.CompanyClient
+	Booking clients.BookingClient
+	Auth    clients.AuthClient
+	// Database connects to a service's database for keys and migrate.
+	Database func(schema storage.Schema, allInOne bool) (*gorm.DB, error)
+}
+
+// NewEnv builds clients for the upstream URLs in cfg.
+func NewEnv(cfg *config.Config, out io.Writer) *Env {
+	opts := clients.OptionsFrom(cfg.Upstreams)
+	env := &Env{
+		Config:  cfg,
+		Out:     out,
+		Company: clients.NewCompany(cfg.Upstreams.CompanyServiceURL, opts),
+		Booking: clients.NewBooking(cfg.Upstreams.BookingServiceURL, opts),
+		Auth:    clients.NewAuth(cfg.Upstreams.AuthServiceURL, opts),
+	}
+	env.Database = env.openDatabase
+	return env
+}
+
+// asUser returns ctx with identity headers for userID, signed like the
+// gateway signs them, so services accept the calls as made by that user.
+func (env *Env) asUser(ctx context.Context, userID uint) (context.Context, error) {
+	secret := env.Config.Auth.IdentitySecret.Reveal()
+	if secret == "" {
+		return nil, errors.New("auth.identity_secret is required to act as a user")
+	}
+	h := http.Header{}
+	identity.Set(h, secret, identity.Claims{UserID: userID})
+	return clients.WithHeaders(ctx, h), nil
+}
+
+// Run dispatches args (what is left after the global flags) to a command.
+func (env *Env) Run(ctx context.Context, args []string) error {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd.run(ctx, env, args[len(words):])
+		}
+	}
+	env.usage()
+	if len(args) == 0 {
+		return errors.New("missing command")
+	}
+	return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+}
+
+func (env *Env) usage() {
+	tw := tabwriter.NewWriter(env.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Usage: timehubctl [global flags] <command> [flags]")
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "Global flags are the service config flags, e.g. --config, --database.url, --upstreams.company.")
+	tw.Flush()
+}
+
+// flags is the flag set of one command with the shared -o and --dry-run flags.
+type flags struct {
+	*flag.FlagSet
+	output string
+	dryRun bool
+}
+
+func newFlags(name string, dryRun bool) *flags {
+	f := &flags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.StringVar(&f.output, "o", "table", "output format: table or json")
+	f.StringVar(&f.output, "output", "table", "output format: table or json")
+	if dryRun {
+		f.BoolVar(&f.dryRun, "dry-run", false, "show what would change without changing it")
+	}
+	return f
+}
+
+func (f *flags) parse(args []string) error {
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if f.output != "table" && f.output != "json" {
+		return fmt.Errorf("invalid output %q: want table or json", f.output)
+	}
+	return nil
+}
+
+// print writes v as indented JSON, or as a table with header and rows.
+func (f *flags) print(w io.Writer, v any, header []string, rows [][]string) error {
+	if f.output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// openDatabase connects to a service's database: its schema of the shared
+// database with allInOne, otherwise --database.url as given.
+func (env *Env) openDatabase(schema storage.Schema, allInOne bool) (*gorm.DB, error) {
+	if env.Config.Database.URL.Reveal() == "" {
+		return nil, errors.New("--database.url (or DATABASE_URL) is required")
+	}
+	if allInOne {
+		return schema.Open(env.Config.Database)
+	}
+	return db.Open(env.Config.Database)
+}
+
+// schemaFor finds the storage schema of a service by name.
+func schemaFor(service string) (storage.Schema, error) {
+	names := make([]string, 0, len(storage.Schemas))
+	for _, s := range storage.Schemas {
+		if s.Service == service || s.Name == service {
+			return s, nil
+		}
+		names = append(names, s.Service)
+	}
+	sort.Strings(names)
+	return storage.Schema{}, fmt.Errorf("unknown service %q: want one of %s", service, strings.Join(names, ", "))
+}
+
+func closeDatabase(database *gorm.DB) {
+	if sqlDB, err := database.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
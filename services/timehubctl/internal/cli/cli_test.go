@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/vipos89/timehub/pkg/clients"
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/pkg/config"
+	"github.com/vipos89/timehub/services/timehub/storage"
+)
+
+// testEnv runs commands against fake clients; commands that need a database
+// fail unless the test sets env.Database.
+func testEnv() (*Env, *bytes.Buffer) {
+	cfg := config.Default()
+	cfg.Auth.IdentitySecret = "identity"
+	out := &bytes.Buffer{}
+	return &Env{
+		Config:  cfg,
+		Out:     out,
+		Company: &fake.Company{},
+		Booking: &fake.Booking{},
+		Auth:    &fake.Auth{},
+		Database: func(storage.Schema, bool) (*gorm.DB, error) {
+			return nil, errors.New("no database")
+		},
+	}, out
+}
+
+func TestRunDispatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		wantOut string
+	}{
+		{name: "no command", wantErr: "missing command", wantOut: "Usage: timehubctl"},
+		{name: "unknown command", args: []string{"nope"}, wantErr: `unknown command "nope"`, wantOut: "keys rotate"},
+		{name: "group without subcommand", args: []string{"keys"}, wantErr: `unknown command "keys"`, wantOut: "catalog import"},
+		{name: "command with flags", args: []string{"company", "create", "--name", "Salon", "--owner-email", "ann@example.com"}, wantOut: "Salon"},
+		{name: "command flags are checked", args: []string{"availability", "reindex"}, wantErr: "--company-id is required"},
+		{name: "database command", args: []string{"keys", "list"}, wantErr: "no database"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env, out := testEnv()
+			err := env.Run(context.Background(), tc.args)
+			if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Errorf("output = %q, want it to contain %q", out.String(), tc.wantOut)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "invalid output", args: []string{"keys", "list", "-o", "yaml"}, wantErr: `invalid output "yaml": want table or json`},
+		{name: "unknown flag", args: []string{"keys", "list", "--force"}, wantErr: "flag provided but not defined: -force"},
+		{name: "dry run on a read-only command", args: []string{"availability", "reindex", "--dry-run"}, wantErr: "flag provided but not defined: -dry-run"},
+		{name: "invalid date", args: []string{"availability", "reindex", "--company-id", "1", "--from", "tomorrow"}, wantErr: "invalid --from: want YYYY-MM-DD"},
+		{name: "range backwards", args: []string{"availability", "reindex", "--company-id", "1", "--from", "2026-03-10", "--to", "2026-03-09"}, wantErr: "--to is before --from"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env, _ := testEnv()
+			if err := env.Run(context.Background(), tc.args); err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	seed := func(env *Env) {
+		env.Company = &fake.Company{
+			Employees: map[uint][]clients.Employee{1: {{ID: 10, Name: "Ann"}}},
+			Menus:     map[uint][]clients.EmployeeService{10: {{EmployeeID: 10, ServiceID: 5}}},
+		}
+		env.Booking = &fake.Booking{Slots: map[uint][]clients.Slot{10: {{IsFree: true}, {IsFree: false}, {IsFree: true}}}}
+	}
+	args := []string{"availability", "reindex", "--company-id", "1", "--from", "2026-03-10", "--to", "2026-03-11"}
+
+	env, out := testEnv()
+	seed(env)
+	if err := env.Run(context.Background(), append(args, "-o", "json")); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var rows []availabilityRow
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("json output %q: %v", out.String(), err)
+	}
+	want := availabilityRow{EmployeeID: 10, Employee: "Ann", ServiceID: 5, Date: "2026-03-11", FreeSlots: 2, TotalSlots: 3}
+	if len(rows) != 2 || rows[0].Date != "2026-03-10" || rows[1] != want {
+		t.Errorf("rows = %+v, want two days ending with %+v", rows, want)
+	}
+
+	env, out = testEnv()
+	seed(env)
+	if err := env.Run(context.Background(), args); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || strings.Join(strings.Fields(lines[0]), " ") != "EMPLOYEE ID EMPLOYEE SERVICE ID DATE FREE TOTAL" ||
+		strings.Join(strings.Fields(lines[2]), " ") != "10 Ann 5 2026-03-11 2 3" {
+		t.Errorf("table = %q", out.String())
+	}
+}
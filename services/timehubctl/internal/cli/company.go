@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/vipos89/timehub/pkg/clients"
+)
+
+// companyCreation is the output of "company create".
+type companyCreation struct {
+	Owner   *clients.User    `json:"owner"`
+	Company *clients.Company `json:"company"`
+	// Password is set when it was generated, so it can be handed to the owner.
+	Password string `json:"password,omitempty"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// companyCreate registers the owner in auth-service, then creates the
+// company (and its main branch) on their behalf in company-service.
+func companyCreate(ctx context.Context, env *Env, args []string) error {
+	f := newFlags("company create", true)
+	name := f.String("name", "", "company name (required)")
+	email := f.String("owner-email", "", "email of the owner account to register (required)")
+	password := f.String("owner-password", "", "owner password; generated and printed when empty")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" {
+		return errors.New("--name and --owner-email are required")
+	}
+
+	out := companyCreation{
+		Owner:   &clients.User{Email: *email, Role: "owner"},
+		Company: &clients.Company{Name: *name},
+		DryRun:  f.dryRun,
+	}
+	if *password == "" {
+		generated, err := randomPassword()
+		if err != nil {
+			return err
+		}
+		*password, out.Password = generated, generated
+	}
+
+	if !f.dryRun {
+		owner, err := env.Auth.Register(ctx, clients.RegisterRequest{Email: *email, Password: *password, Role: "owner"})
+		if err != nil {
+			return err
+		}
+		out.Owner = owner
+
+		ownerCtx, err := env.asUser(ctx, owner.ID)
+		if err != nil {
+			return err
+		}
+		company, err := env.Company.CreateCompany(ownerCtx, *name)
+		if err != nil {
+			return err
+		}
+		out.Company = company
+	}
+
+	return f.print(env.Out, out,
+		[]string{"COMPANY ID", "COMPANY", "OWNER ID", "OWNER EMAIL", "PASSWORD", "DRY RUN"},
+		[][]string{{id(out.Company.ID), out.Company.Name, id(out.Owner.ID), out.Owner.Email, out.Password, strconv.FormatBool(out.DryRun)}})
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// id formats an ID for tables; zero (not created yet) prints as "-".
+func id(v uint) string {
+	if v == 0 {
+		return "-"
+	}
+	return strconv.FormatUint(uint64(v), 10)
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/vipos89/timehub/pkg/clients/fake"
+	"github.com/vipos89/timehub/pkg/config"
+)
+
+func TestCompanyCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantPassword string
+		wantDryRun   bool
+	}{
+		{name: "given password", args: []string{"--owner-password", "secret1"}},
+		{name: "generated password", wantPassword: "generated"},
+		{name: "dry run", args: []string{"--dry-run"}, wantPassword: "generated", wantDryRun: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env, out := testEnv()
+			auth, company := &fake.Auth{}, &fake.Company{}
+			env.Auth, env.Company = auth, company
+			args := append([]string{"company", "create", "--name", "Salon", "--owner-email", "ann@example.com", "-o", "json"}, tc.args...)
+
+			if err := env.Run(context.Background(), args); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			var got companyCreation
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("output %q: %v", out.String(), err)
+			}
+			if got.DryRun != tc.wantDryRun || got.Company.Name != "Salon" || got.Owner.Email != "ann@example.com" || got.Owner.Role != "owner" {
+				t.Errorf("output = %+v", got)
+			}
+			if (got.Password != "") != (tc.wantPassword != "") || tc.wantPassword != "" && len(got.Password) < 12 {
+				t.Errorf("password = %q, want %s", got.Password, tc.wantPassword)
+			}
+
+			if tc.wantDryRun {
+				if len(auth.Users) != 0 || len(company.Companies) != 0 || got.Owner.ID != 0 || got.Company.ID != 0 {
+					t.Errorf("dry run created users %v and companies %v", auth.Users, company.Companies)
+				}
+				return
+			}
+			if owner := auth.Users["ann@example.com"]; owner == nil || owner.ID != got.Owner.ID {
+				t.Errorf("users = %v, want the owner registered", auth.Users)
+			}
+			if c := company.Companies[got.Company.ID]; c == nil || c.Name != "Salon" || len(c.Branches) != 1 {
+				t.Errorf("companies = %v, want Salon with its main branch", company.Companies)
+			}
+		})
+	}
+}
+
+func TestCompanyCreateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		secret  string
+		wantErr string
+	}{
+		{name: "missing name", args: []string{"--owner-email", "ann@example.com"}, secret: "identity", wantErr: "--name and --owner-email are required"},
+		{name: "missing email", args: []string{"--name", "Salon"}, secret: "identity", wantErr: "--name and --owner-email are required"},
+		{name: "no identity secret", args: []string{"--name", "Salon", "--owner-email", "ann@example.com"}, wantErr: "auth.identity_secret is required to act as a user"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env, _ := testEnv()
+			env.Config.Auth.IdentitySecret = config.Secret(tc.secret)
+			company := &fake.Company{}
+			env.Company = company
+
+			err := env.Run(context.Background(), append([]string{"company", "create"}, tc.args...))
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+			if len(company.Companies) != 0 {
+				t.Errorf("companies = %v, want none", company.Companies)
+			}
+		})
+	}
+}
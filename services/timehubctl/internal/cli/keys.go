@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	auth "github.com/vipos89/timehub/services/auth-service/app"
+	"github.com/vipos89/timehub/services/timehub/storage"
+)
+
+// keysList prints the signing keys in auth-service's database.
+func keysList(ctx context.Context, env *Env, args []string) error {
+	f := newFlags("keys list", false)
+	allInOne := f.Bool("all-in-one", false, "use the auth schema of the all-in-one database")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+
+	database, err := env.Database(storage.Auth, *allInOne)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database)
+
+	keys, err := auth.Keys(database, env.Config.Timeouts.Context).ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(keys))
+	for i, k := range keys {
+		rows[i] = []string{id(k.ID), k.KID, k.CreatedAt.Format(time.RFC3339), timestamp(k.RetiredAt)}
+	}
+	return f.print(env.Out, keys, []string{"ID", "KID", "CREATED AT", "RETIRED AT"}, rows)
+}
+
+// keysRotate creates a new signing key; new tokens are signed with it.
+func keysRotate(ctx context.Context, env *Env, args []string) error {
+	f := newFlags("keys rotate", true)
+	allInOne := f.Bool("all-in-one", false, "use the auth schema of the all-in-one database")
+	retirePrevious := f.Bool("retire-previous", false, "mark every other key and the configured JWT secret retired")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+
+	database, err := env.Database(storage.Auth, *allInOne)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database)
+
+	rotation, err := auth.Keys(database, env.Config.Timeouts.Context).RotateKey(ctx, *retirePrevious, f.dryRun)
+	if err != nil {
+		return err
+	}
+	rows := [][]string{{"created", rotation.Key.KID, strconv.FormatBool(rotation.DryRun)}}
+	for _, k := range rotation.Retired {
+		rows = append(rows, []string{"retired", k.KID, strconv.FormatBool(rotation.DryRun)})
+	}
+	return f.print(env.Out, rotation, []string{"ACTION", "KID", "DRY RUN"}, rows)
+}
+
+func timestamp(at *time.Time) string {
+	if at == nil {
+		return "-"
+	}
+	return at.Format(time.RFC3339)
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/vipos89/timehub/services/timehub/storage"
+)
+
+// mockDatabase makes env.Database return a connection to mock.
+func mockDatabase(t *testing.T, env *Env) sqlmock.Sqlmock {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Database = func(schema storage.Schema, _ bool) (*gorm.DB, error) {
+		if schema.Name != storage.Auth.Name {
+			t.Errorf("opened %s, want the auth database", schema.Name)
+		}
+		return database, nil
+	}
+	return mock
+}
+
+// expectRotation expects a rotation that retires key 1 and the configured
+// secret, ending in a commit or, for a dry run, a rollback.
+func expectRotation(mock sqlmock.Sqlmock, dryRun bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "signing_keys"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(`SELECT \* FROM "signing_keys" WHERE retired_at IS NULL AND id <> \$1`).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kid", "secret", "created_at"}).AddRow(1, "old", "s", time.Now()))
+	mock.ExpectExec(`UPDATE "signing_keys" SET "retired_at"=\$1 WHERE id IN \(\$2\)`).WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "signing_keys" WHERE kid = \$1 AND retired_at IS NOT NULL`).WithArgs("configured").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`INSERT INTO "signing_keys"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	if dryRun {
+		mock.ExpectRollback()
+	} else {
+		mock.ExpectCommit()
+	}
+	mock.ExpectClose()
+}
+
+func TestKeysRotate(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "rotate"},
+		{name: "dry run", dryRun: true},
+	}
+	for _, tc := range tests {
+		dryRun := tc.dryRun
+		t.Run(tc.name, func(t *testing.T) {
+			env, out := testEnv()
+			mock := mockDatabase(t, env)
+			expectRotation(mock, dryRun)
+			args := []string{"keys", "rotate", "--retire-previous", "-o", "json"}
+			if dryRun {
+				args = append(args, "--dry-run")
+			}
+
+			if err := env.Run(context.Background(), args); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+			var got struct {
+				Key struct {
+					KID string `json:"kid"`
+				} `json:"key"`
+				Retired []struct {
+					KID string `json:"kid"`
+				} `json:"retired"`
+				DryRun bool `json:"dry_run"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("output %q: %v", out.String(), err)
+			}
+			if got.DryRun != dryRun || len(got.Key.KID) != 16 || len(got.Retired) != 2 || got.Retired[0].KID != "old" || got.Retired[1].KID != "configured" {
+				t.Errorf("output = %+v", got)
+			}
+			if strings.Contains(out.String(), "secret") {
+				t.Errorf("output shows a secret: %s", out.String())
+			}
+		})
+	}
+}
+
+func TestKeysList(t *testing.T) {
+	env, out := testEnv()
+	mock := mockDatabase(t, env)
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	retired := created.AddDate(0, 0, 7)
+	mock.ExpectQuery(`SELECT \* FROM "signing_keys" ORDER BY created_at DESC, id DESC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kid", "secret", "created_at", "retired_at"}).
+			AddRow(2, "new", "s2", retired, nil).
+			AddRow(1, "old", "s1", created, retired))
+	mock.ExpectClose()
+
+	if err := env.Run(context.Background(), []string{"keys", "list"}); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want := []string{
+		"ID KID CREATED AT RETIRED AT",
+		"2 new 2026-03-08T09:00:00Z -",
+		"1 old 2026-03-01T09:00:00Z 2026-03-08T09:00:00Z",
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for i := range lines {
+		lines[i] = strings.Join(strings.Fields(lines[i]), " ")
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("table =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vipos89/timehub/pkg/db"
+	"github.com/vipos89/timehub/services/timehub/storage"
+)
+
+// migrationRow is one line of "migrate" output.
+type migrationRow struct {
+	Service   string     `json:"service"`
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Action    string     `json:"action"` // applied, reverted, pending or done
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	DryRun    bool       `json:"dry_run"`
+}
+
+// migrate runs "up", "down [steps]" or "status" against one service's
+// database, or against every schema of the all-in-one database.
+func migrate(ctx context.Context, env *Env, args []string) error {
+	f := newFlags("migrate", true)
+	allInOne := f.Bool("all-in-one", false, "migrate the schemas of the all-in-one database")
+	service := f.String("service", "", "auth-service, company-service or booking-service; every service with --all-in-one when empty")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	args = f.Args()
+	if len(args) == 0 {
+		return errors.New("usage: migrate [flags] up|down [steps]|status")
+	}
+	steps := 1
+	if args[0] == "down" && len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid steps %q", args[1])
+		}
+		steps = n
+	}
+
+	schemas := storage.Schemas
+	switch {
+	case *service != "":
+		s, err := schemaFor(*service)
+		if err != nil {
+			return err
+		}
+		schemas = []storage.Schema{s}
+	case !*allInOne:
+		return errors.New("--service is required unless --all-in-one is set")
+	}
+
+	rows := []migrationRow{}
+	for _, schema := range schemas {
+		done, err := migrateSchema(ctx, env, schema, *allInOne, args[0], steps, f.dryRun)
+		rows = append(rows, done...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", schema.Service, err)
+		}
+	}
+
+	table := make([][]string, len(rows))
+	for i, r := range rows {
+		table[i] = []string{r.Service, strconv.FormatInt(r.Version, 10), r.Name, r.Action, timestamp(r.AppliedAt)}
+	}
+	return f.print(env.Out, rows, []string{"SERVICE", "VERSION", "NAME", "ACTION", "APPLIED AT"}, table)
+}
+
+func migrateSchema(ctx context.Context, env *Env, schema storage.Schema, allInOne bool, cmd string, steps int, dryRun bool) ([]migrationRow, error) {
+	database, err := env.Database(schema, allInOne)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDatabase(database)
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := schema.Migrator(sqlDB, env.Config.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []migrationRow{}
+	add := func(m db.Migration, action string) {
+		rows = append(rows, migrationRow{Service: schema.Service, Version: m.Version, Name: m.Name, Action: action, DryRun: dryRun})
+	}
+
+	switch {
+	case cmd == "status" || dryRun && (cmd == "up" || cmd == "down"):
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch cmd {
+		case "status":
+			for _, st := range statuses {
+				row := migrationRow{Service: schema.Service, Version: st.Version, Name: st.Name, Action: "pending", AppliedAt: st.AppliedAt}
+				if st.AppliedAt != nil {
+					row.Action = "done"
+				}
+				rows = append(rows, row)
+			}
+		case "up":
+			for _, st := range statuses {
+				if st.AppliedAt == nil {
+					add(db.Migration{Version: st.Version, Name: st.Name}, "applied")
+				}
+			}
+		case "down":
+			// Down reverts the latest applied migrations first.
+			for i := len(statuses) - 1; i >= 0 && steps > 0; i-- {
+				if statuses[i].AppliedAt != nil {
+					add(db.Migration{Version: statuses[i].Version, Name: statuses[i].Name}, "reverted")
+					steps--
+				}
+			}
+		}
+		return rows, nil
+	case cmd == "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			add(m, "applied")
+		}
+		return rows, err
+	case cmd == "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			add(m, "reverted")
+		}
+		return rows, err
+	default:
+		return nil, fmt.Errorf("unknown migrate command %q", cmd)
+	}
+}